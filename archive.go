@@ -0,0 +1,329 @@
+package ubolt
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// The archive format is a gzip compressed stream beginning with archiveMagic and a version byte, followed by a series of records.
+// Each record starts with a tag byte:
+//
+//	archiveBucket: uvarint length prefixed name followed by the uvarint bucket sequence, starting a (possibly nested) bucket
+//	archiveKV:     uvarint length prefixed key followed by a uvarint length prefixed value within the current bucket
+//	archiveEnd:    ends the current bucket
+//	archiveEOF:    ends the archive
+const (
+	archiveVersion byte = 1
+
+	archiveEOF    byte = 0x00
+	archiveBucket byte = 0x01
+	archiveKV     byte = 0x02
+	archiveEnd    byte = 0x03
+
+	// archiveBatchSize is the number of records written per transaction during ImportArchive
+	archiveBatchSize = 10000
+)
+
+var archiveMagic = []byte("UBOLTARC")
+
+// ErrInvalidArchive is returned by ImportArchive when the archive could not be read.
+type ErrInvalidArchive struct {
+	reason string
+}
+
+// Error returns the formatted configuration error.
+func (ia ErrInvalidArchive) Error() string {
+	return fmt.Sprintf("Invalid archive: %s", ia.reason)
+}
+
+// Is allows testing using errors.Is
+func (ia ErrInvalidArchive) Is(target error) bool {
+	_, is := target.(ErrInvalidArchive)
+
+	return is
+}
+
+// ExportArchive writes a logical backup of every bucket, including nested buckets and bucket sequences, to w as a versioned gzip compressed
+// stream. Unlike WriteTo the archive does not depend on the physical layout of the database, so may be restored using ImportArchive into a
+// database opened with different options. If an error is returned the gzip stream is still closed, and the incomplete archive written to w
+// is rejected by ImportArchive.
+func (db *Database) ExportArchive(w io.Writer) (err error) {
+	zw := gzip.NewWriter(w)
+	bw := bufio.NewWriter(zw)
+
+	// the gzip stream is closed on every path, so a failed export leaves a complete stream whose archive lacks its end marker and is
+	// rejected by ImportArchive, with the first error returned
+	defer func() {
+		if cerr := zw.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	if _, err := bw.Write(append(append([]byte{}, archiveMagic...), archiveVersion)); err != nil {
+		return err
+	}
+
+	if err := db.view(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			return exportBucket(bw, name, b)
+		})
+	}); err != nil {
+		return err
+	}
+
+	if err := bw.WriteByte(archiveEOF); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// ExportArchive writes a logical backup of the entire database to w. This is forwarded to the Database implementation.
+func (b *Bucket) ExportArchive(w io.Writer) error {
+	return b.db.ExportArchive(w)
+}
+
+// ImportArchive recreates the buckets, sequences, keys and values from an archive written by ExportArchive. Records are written in batches
+// across multiple read/write transactions, so a failure part way through leaves the records imported so far in place.
+//
+// Existing keys are overwritten by the archive unless a different ConflictPolicy is provided using WithConflictPolicy. Each key written is
+// passed to the write hooks as OpImport, so preloaded buckets, Bloom filters, quotas, mirrors and the audit log reflect the import, while the
+// expiry and timestamps of keys are restored from the archive rather than being reset.
+func (db *Database) ImportArchive(r io.Reader, opts ...ImportOption) error {
+	o := newImportOptions(opts)
+
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return ErrInvalidArchive{err.Error()}
+	}
+	defer zr.Close()
+
+	br := bufio.NewReader(zr)
+
+	header := make([]byte, len(archiveMagic)+1)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return ErrInvalidArchive{"missing header"}
+	}
+
+	if !bytes.Equal(header[:len(archiveMagic)], archiveMagic) {
+		return ErrInvalidArchive{"bad magic"}
+	}
+
+	if v := header[len(archiveMagic)]; v != archiveVersion {
+		return ErrInvalidArchive{fmt.Sprintf("unsupported version %d", v)}
+	}
+
+	var path [][]byte
+	// bucket is the full path of the current bucket as passed to the write hooks
+	var bucket []byte
+	done := false
+
+	p := newProgress(o.progress, -1)
+
+	for !done {
+		if err := db.update(func(tx *bolt.Tx) error {
+			var b *bolt.Bucket
+			if len(path) > 0 {
+				b = bucketPath(tx, path)
+			}
+
+			for n := 0; n < archiveBatchSize; n++ {
+				tag, err := br.ReadByte()
+				if err != nil {
+					return ErrInvalidArchive{"unexpected end of archive"}
+				}
+
+				switch tag {
+				case archiveEOF:
+					if len(path) != 0 {
+						return ErrInvalidArchive{"unterminated bucket"}
+					}
+
+					done = true
+
+					return nil
+				case archiveBucket:
+					name, err := readArchiveBytes(br)
+					if err != nil {
+						return err
+					}
+
+					seq, err := binary.ReadUvarint(br)
+					if err != nil {
+						return ErrInvalidArchive{"bad sequence"}
+					}
+
+					if b == nil {
+						b, err = tx.CreateBucketIfNotExists(name)
+					} else {
+						b, err = b.CreateBucketIfNotExists(name)
+					}
+					if err != nil {
+						return err
+					}
+
+					if err := b.SetSequence(seq); err != nil {
+						return err
+					}
+
+					path = append(path, name)
+					bucket = BucketPath(path...)
+
+					if !isReserved(bucket) {
+						db.recordMirror(tx, mutation{op: opCreateBucket, bucket: bucket})
+					}
+				case archiveKV:
+					if b == nil {
+						return ErrInvalidArchive{"key outside of bucket"}
+					}
+
+					key, err := readArchiveBytes(br)
+					if err != nil {
+						return err
+					}
+
+					value, err := readArchiveBytes(br)
+					if err != nil {
+						return err
+					}
+
+					value, write, err := o.resolve(bucket, key, b.Get(key), value)
+					if err != nil {
+						return err
+					}
+
+					if !write {
+						continue
+					}
+
+					prev := previous(tx, bucket, b, key)
+
+					if err := b.Put(key, value); err != nil {
+						return err
+					}
+
+					if err := db.onMutation(tx, mutation{op: OpImport, bucket: bucket, key: key, value: value, prev: prev}); err != nil {
+						return err
+					}
+
+					p.add(1)
+				case archiveEnd:
+					if len(path) == 0 {
+						return ErrInvalidArchive{"unexpected end of bucket"}
+					}
+
+					path = path[:len(path)-1]
+					bucket = BucketPath(path...)
+					b = bucketPath(tx, path)
+				default:
+					return ErrInvalidArchive{fmt.Sprintf("unknown record type %d", tag)}
+				}
+			}
+
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	p.finish()
+
+	return nil
+}
+
+// ImportArchive recreates the contents of an archive written by ExportArchive. This is forwarded to the Database implementation.
+func (b *Bucket) ImportArchive(r io.Reader, opts ...ImportOption) error {
+	return b.db.ImportArchive(r, opts...)
+}
+
+func exportBucket(w *bufio.Writer, name []byte, b *bolt.Bucket) error {
+	if err := w.WriteByte(archiveBucket); err != nil {
+		return err
+	}
+
+	if err := writeArchiveBytes(w, name); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(binary.AppendUvarint(nil, b.Sequence())); err != nil {
+		return err
+	}
+
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if v == nil {
+			if err := exportBucket(w, k, b.Bucket(k)); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := w.WriteByte(archiveKV); err != nil {
+			return err
+		}
+
+		if err := writeArchiveBytes(w, k); err != nil {
+			return err
+		}
+
+		if err := writeArchiveBytes(w, v); err != nil {
+			return err
+		}
+	}
+
+	return w.WriteByte(archiveEnd)
+}
+
+func writeArchiveBytes(w *bufio.Writer, b []byte) error {
+	if _, err := w.Write(binary.AppendUvarint(nil, uint64(len(b)))); err != nil {
+		return err
+	}
+
+	_, err := w.Write(b)
+
+	return err
+}
+
+func readArchiveBytes(r *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil || n > bolt.MaxValueSize {
+		return nil, ErrInvalidArchive{"bad length"}
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+			return nil, ErrInvalidArchive{"unexpected end of archive"}
+		}
+
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// bucketPath returns the nested bucket at path, or nil if any bucket along the path does not exist or path is empty.
+func bucketPath(tx *bolt.Tx, path [][]byte) *bolt.Bucket {
+	if len(path) == 0 {
+		return nil
+	}
+
+	b := tx.Bucket(path[0])
+	for _, name := range path[1:] {
+		if b == nil {
+			return nil
+		}
+
+		b = b.Bucket(name)
+	}
+
+	return b
+}
@@ -0,0 +1,200 @@
+package ubolt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestArchive(t *testing.T) {
+	_ = os.Remove(testdb)
+	_ = os.Remove(testbackup)
+	defer os.Remove(testdb)
+	defer os.Remove(testbackup)
+
+	src, err := Open(testdb)
+	if err != nil {
+		panic(err)
+	}
+	defer src.Close()
+
+	binkey := []byte{0x00, 0xff, 0x10, 0x00}
+
+	// build a database with nested buckets, sequences and binary keys
+	if err := src.bdb().Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucket(testbucket)
+		if err != nil {
+			return err
+		}
+
+		if err := b.Put(testkey, testvalue); err != nil {
+			return err
+		}
+
+		if err := b.Put(binkey, []byte{0xde, 0xad, 0x00}); err != nil {
+			return err
+		}
+
+		if err := b.Put([]byte("empty"), []byte{}); err != nil {
+			return err
+		}
+
+		if err := b.SetSequence(42); err != nil {
+			return err
+		}
+
+		nested, err := b.CreateBucket([]byte("nested"))
+		if err != nil {
+			return err
+		}
+
+		if err := nested.SetSequence(7); err != nil {
+			return err
+		}
+
+		deeper, err := nested.CreateBucket([]byte("deeper"))
+		if err != nil {
+			return err
+		}
+
+		if err := deeper.Put([]byte("deep"), []byte("value")); err != nil {
+			return err
+		}
+
+		_, err = tx.CreateBucket([]byte("emptybucket"))
+
+		return err
+	}); err != nil {
+		panic(err)
+	}
+
+	var buf bytes.Buffer
+	assert.Nil(t, src.ExportArchive(&buf), "ExportArchive")
+
+	dst, err := Open(testbackup)
+	if err != nil {
+		panic(err)
+	}
+	defer dst.Close()
+
+	assert.Nil(t, dst.ImportArchive(bytes.NewReader(buf.Bytes())), "ImportArchive")
+
+	// compare a second export of each database
+	var want, got bytes.Buffer
+	_ = src.ExportArchive(&want)
+	_ = dst.ExportArchive(&got)
+
+	wantData, _ := gunzip(want.Bytes())
+	gotData, _ := gunzip(got.Bytes())
+	assert.Equal(t, wantData, gotData, "ImportArchive - round trip")
+
+	assert.Nil(t, dst.bdb().View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(testbucket)
+		assert.Equal(t, uint64(42), b.Sequence(), "ImportArchive - sequence")
+		assert.Equal(t, []byte{0xde, 0xad, 0x00}, b.Get(binkey), "ImportArchive - binary key")
+		assert.NotNil(t, b.Get([]byte("empty")), "ImportArchive - empty value")
+		assert.Equal(t, uint64(7), b.Bucket([]byte("nested")).Sequence(), "ImportArchive - nested sequence")
+		assert.Equal(t, []byte("value"), b.Bucket([]byte("nested")).Bucket([]byte("deeper")).Get([]byte("deep")), "ImportArchive - nested value")
+		assert.NotNil(t, tx.Bucket([]byte("emptybucket")), "ImportArchive - empty bucket")
+
+		return nil
+	}), "ImportArchive - verify")
+
+	// invalid archives
+	assert.ErrorIs(t, dst.ImportArchive(bytes.NewReader([]byte("not an archive"))), ErrInvalidArchive{}, "ImportArchive - not gzip")
+
+	var bad bytes.Buffer
+	zw := gzip.NewWriter(&bad)
+	_, _ = zw.Write(append(append([]byte{}, archiveMagic...), 99))
+	_ = zw.Close()
+	assert.ErrorIs(t, dst.ImportArchive(&bad), ErrInvalidArchive{}, "ImportArchive - bad version")
+
+	truncated, _ := gunzip(buf.Bytes())
+	bad.Reset()
+	zw = gzip.NewWriter(&bad)
+	_, _ = zw.Write(truncated[:len(truncated)-10])
+	_ = zw.Close()
+	assert.ErrorIs(t, dst.ImportArchive(&bad), ErrInvalidArchive{}, "ImportArchive - truncated")
+}
+
+func gunzip(b []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(zr)
+
+	return buf.Bytes(), err
+}
+
+// failingWriter accepts n bytes then fails every write.
+type failingWriter struct {
+	n int
+}
+
+func (fw *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > fw.n {
+		n := fw.n
+		fw.n = 0
+
+		return n, os.ErrClosed
+	}
+
+	fw.n -= len(p)
+
+	return len(p), nil
+}
+
+func TestExportArchiveErrors(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	db, err := Open(testdb)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := db.CreateBucket(testbucket); err != nil {
+		panic(err)
+	}
+
+	for i := 0; i < 1000; i++ {
+		if err := db.Put(testbucket, []byte(fmt.Sprintf("key%04d", i)), bytes.Repeat([]byte{byte(i)}, 256)); err != nil {
+			panic(err)
+		}
+	}
+
+	// a failing writer is reported
+	assert.ErrorIs(t, db.ExportArchive(&failingWriter{n: 100}), os.ErrClosed, "ExportArchive - failing writer")
+
+	// an export that fails part way still closes the gzip stream, leaving an archive that is rejected
+	assert.Nil(t, db.Close(), "Close")
+
+	var partial bytes.Buffer
+	assert.NotNil(t, db.ExportArchive(&partial), "ExportArchive - closed database")
+
+	zr, err := gzip.NewReader(bytes.NewReader(partial.Bytes()))
+	if assert.Nil(t, err, "gzip.NewReader - partial") {
+		_, err = io.ReadAll(zr)
+		assert.Nil(t, err, "ReadAll - partial stream complete")
+	}
+
+	_ = os.Remove(testbackup)
+	defer os.Remove(testbackup)
+
+	dst, err := Open(testbackup)
+	if err != nil {
+		panic(err)
+	}
+	defer dst.Close()
+
+	assert.ErrorIs(t, dst.ImportArchive(bytes.NewReader(partial.Bytes())), ErrInvalidArchive{}, "ImportArchive - partial")
+}
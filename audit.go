@@ -0,0 +1,143 @@
+package ubolt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var auditBucket = []byte("__audit")
+
+// AuditEntry is a single mutation recorded by the audit log.
+type AuditEntry struct {
+	// Time is when the mutation was made.
+	Time time.Time
+	// Op is the kind of mutation.
+	Op Op
+	// Bucket is the bucket that was changed.
+	Bucket []byte
+	// Key is the key that was changed, which is nil when a bucket was deleted.
+	Key []byte
+	// ValueHash is the SHA-256 hash of the value that was written, which is nil for deletions.
+	ValueHash []byte
+	// Actor is the value returned by the actor function provided to WithAuditLog.
+	Actor []byte
+}
+
+// WithAuditLog records every Put, PutV, Encode, Delete and DeleteBucket, along with the keys written by Merge, ImportArchive and CloneBucket,
+// to a reserved audit bucket within the same transaction as the mutation, so a committed change can never be missing from the log. The actor function, which may be nil, is called for each mutation to identify
+// who made the change.
+//
+// Entries may be retrieved using AuditEntries and removed using PurgeAudit.
+func WithAuditLog(actor func() []byte) Option {
+	return func(db *Database) {
+		db.audit = true
+		db.auditActor = actor
+	}
+}
+
+// appendAudit appends an entry for the mutation to the audit bucket. Keys are the entry timestamp followed by a sequence number so entries
+// are ordered by time.
+func (db *Database) appendAudit(tx *bolt.Tx, m mutation) error {
+	b, err := tx.CreateBucketIfNotExists(auditBucket)
+	if err != nil {
+		return err
+	}
+
+	entry := AuditEntry{
+		Time:   time.Now().UTC(),
+		Op:     m.op,
+		Bucket: m.bucket,
+		Key:    m.key,
+	}
+
+	if m.op != OpDelete && m.op != OpDeleteBucket {
+		sum := sha256.Sum256(m.value)
+		entry.ValueHash = sum[:]
+	}
+
+	if db.auditActor != nil {
+		entry.Actor = db.auditActor()
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(entry); err != nil {
+		return err
+	}
+
+	seq, err := b.NextSequence()
+	if err != nil {
+		return err
+	}
+
+	return b.Put(append(itob(uint64(entry.Time.UnixNano())), itob(seq)...), buf.Bytes())
+}
+
+// AuditEntries calls fn for every audit log entry recorded at or after since, in the order they were recorded. Iteration stops at the first error returned by fn.
+func (db *Database) AuditEntries(since time.Time, fn func(AuditEntry) error) error {
+	return db.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(auditBucket)
+		if b == nil {
+			return nil
+		}
+
+		c := b.Cursor()
+		for k, v := c.Seek(auditKey(since)); k != nil; k, v = c.Next() {
+			var entry AuditEntry
+
+			if err := gob.NewDecoder(bytes.NewReader(v)).Decode(&entry); err != nil {
+				return err
+			}
+
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// AuditEntries calls fn for every audit log entry recorded at or after since. This is forwarded to the Database implementation so includes entries for all buckets.
+func (b *Bucket) AuditEntries(since time.Time, fn func(AuditEntry) error) error {
+	return b.db.AuditEntries(since, fn)
+}
+
+// PurgeAudit removes all audit log entries recorded before the provided time and returns the number of entries removed.
+func (db *Database) PurgeAudit(before time.Time) (n int, err error) {
+	err = db.update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(auditBucket)
+		if b == nil {
+			return nil
+		}
+
+		end := auditKey(before)
+
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil && bytes.Compare(k, end) < 0; k, _ = c.First() {
+			if err := c.Delete(); err != nil {
+				return err
+			}
+			n++
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+func auditKey(t time.Time) []byte {
+	if t.IsZero() || t.UnixNano() < 0 {
+		return nil
+	}
+
+	return itob(uint64(t.UnixNano()))
+}
@@ -0,0 +1,129 @@
+package ubolt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLog(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	db, err := Open(testdb, WithAuditLog(func() []byte { return []byte("tester") }))
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	start := time.Now()
+
+	if err := db.CreateBucket(testbucket); err != nil {
+		panic(err)
+	}
+
+	assert.Nil(t, db.Put(testbucket, testkey, testvalue), "Put")
+	_, err = db.PutV(testbucket, testvalue)
+	assert.Nil(t, err, "PutV")
+	assert.Nil(t, db.Encode(testbucket, []byte("encoded"), "value"), "Encode")
+	assert.Nil(t, db.Delete(testbucket, testkey), "Delete")
+	assert.Nil(t, db.DeleteBucket(testbucket), "DeleteBucket")
+
+	// audit and health check buckets must not be audited or visible
+	assert.Nil(t, db.HealthCheck(), "HealthCheck")
+	assert.Empty(t, db.GetBuckets(), "GetBuckets")
+
+	var entries []AuditEntry
+	err = db.AuditEntries(time.Time{}, func(e AuditEntry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	assert.Nil(t, err, "AuditEntries")
+
+	ops := make([]Op, 0)
+	for _, e := range entries {
+		ops = append(ops, e.Op)
+		assert.Equal(t, testbucket, e.Bucket, "AuditEntries - bucket")
+		assert.Equal(t, []byte("tester"), e.Actor, "AuditEntries - actor")
+		assert.False(t, e.Time.Before(start.Add(-time.Second)), "AuditEntries - time")
+	}
+	assert.Equal(t, []Op{OpPut, OpPutV, OpEncode, OpDelete, OpDeleteBucket}, ops, "AuditEntries - ops")
+
+	sum := sha256.Sum256(testvalue)
+	assert.Equal(t, sum[:], entries[0].ValueHash, "AuditEntries - value hash")
+	assert.Equal(t, testkey, entries[0].Key, "AuditEntries - key")
+	assert.Nil(t, entries[3].ValueHash, "AuditEntries - delete value hash")
+
+	// entries since the future are empty
+	count := 0
+	_ = db.AuditEntries(time.Now().Add(time.Hour), func(e AuditEntry) error {
+		count++
+		return nil
+	})
+	assert.Equal(t, 0, count, "AuditEntries - since")
+
+	n, err := db.PurgeAudit(entries[2].Time)
+	assert.Nil(t, err, "PurgeAudit")
+	assert.Equal(t, 2, n, "PurgeAudit")
+
+	n, err = db.PurgeAudit(time.Now().Add(time.Hour))
+	assert.Nil(t, err, "PurgeAudit - all")
+	assert.Equal(t, 3, n, "PurgeAudit - all")
+}
+
+func TestAuditLogImportClone(t *testing.T) {
+	_ = os.Remove(testdb)
+	_ = os.Remove(testbackup)
+	defer os.Remove(testdb)
+	defer os.Remove(testbackup)
+
+	src, err := Open(testbackup)
+	if err != nil {
+		panic(err)
+	}
+	defer src.Close()
+
+	if err := src.CreateBucket(testbucket); err != nil {
+		panic(err)
+	}
+	if err := src.Put(testbucket, testkey, testvalue); err != nil {
+		panic(err)
+	}
+
+	var archive bytes.Buffer
+	if err := src.ExportArchive(&archive); err != nil {
+		panic(err)
+	}
+
+	db, err := Open(testdb, WithAuditLog(nil))
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	clone := []byte("clone")
+
+	assert.Nil(t, db.ImportArchive(&archive), "ImportArchive")
+	assert.Nil(t, db.CloneBucket(testbucket, clone), "CloneBucket")
+
+	var entries []AuditEntry
+	err = db.AuditEntries(time.Time{}, func(e AuditEntry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	assert.Nil(t, err, "AuditEntries")
+
+	sum := sha256.Sum256(testvalue)
+	if assert.Len(t, entries, 2, "AuditEntries - count") {
+		assert.Equal(t, OpImport, entries[0].Op, "AuditEntries - import op")
+		assert.Equal(t, testbucket, entries[0].Bucket, "AuditEntries - import bucket")
+		assert.Equal(t, sum[:], entries[0].ValueHash, "AuditEntries - import value hash")
+		assert.Equal(t, OpPut, entries[1].Op, "AuditEntries - clone op")
+		assert.Equal(t, clone, entries[1].Bucket, "AuditEntries - clone bucket")
+		assert.Equal(t, testkey, entries[1].Key, "AuditEntries - clone key")
+	}
+}
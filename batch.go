@@ -0,0 +1,115 @@
+package ubolt
+
+import (
+	"errors"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// PutBatch sets each key in the chosen bucket to its value in the order the entries are provided, within a single read/write transaction
+// so the batch is written with one sync rather than one per key. The batch is atomic, so if any entry can not be written, such as when its
+// key is empty, nothing is written. When a key appears more than once the last entry wins.
+//
+// ErrBucketNotFound is returned before anything is written if the bucket does not exist. PutAll may be used to write the entries of a map,
+// or to split a large batch across multiple transactions.
+func (db *Database) PutBatch(bucket []byte, entries []Entry) error {
+	return db.update(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		for _, e := range entries {
+			key := db.canonicalKey(e.Key)
+
+			value, err := db.wrapValue(bucket, key, e.Value)
+			if err != nil {
+				return err
+			}
+
+			prev := previous(tx, bucket, b, key)
+
+			if err := b.Put(key, value); err != nil {
+				return err
+			}
+
+			if err := db.onMutation(tx, mutation{op: OpPut, bucket: bucket, key: key, value: value, prev: prev}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// PutBatch sets each key to its value in the order the entries are provided within a single read/write transaction. This is forwarded to
+// the Database implementation.
+func (b *Bucket) PutBatch(entries []Entry) error {
+	return b.db.PutBatch(b.bucket, entries)
+}
+
+// GetBatch retrieves the specified keys from the chosen bucket within a single read-only transaction, returning a copy of each value in a
+// map keyed by the key as provided. Keys that do not exist or have expired are absent from the map rather than returning an error, while
+// ErrBucketNotFound is returned if the bucket does not exist.
+func (db *Database) GetBatch(bucket []byte, keys [][]byte) (map[string][]byte, error) {
+	values := make(map[string][]byte, len(keys))
+
+	if err := db.ViewMany(func(get func(bucket, key []byte) ([]byte, error)) error {
+		for _, key := range keys {
+			value, err := get(bucket, key)
+			if errors.Is(err, ErrKeyNotFound{}) {
+				continue
+			}
+
+			if err != nil {
+				return err
+			}
+
+			values[string(key)] = value
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// GetBatch retrieves the specified keys within a single read-only transaction, returning a copy of each value found. This is forwarded to
+// the Database implementation.
+func (b *Bucket) GetBatch(keys [][]byte) (map[string][]byte, error) {
+	return b.db.GetBatch(b.bucket, keys)
+}
+
+// DeleteBatch removes each of the specified keys from the chosen bucket within a single read/write transaction, so either every key is
+// removed or, if any can not be, none are. Removing a key that does not exist is not an error. ErrBucketNotFound is returned if the bucket
+// does not exist.
+func (db *Database) DeleteBatch(bucket []byte, keys [][]byte) error {
+	return db.update(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		for _, k := range keys {
+			key := db.canonicalKey(k)
+			prev := previous(tx, bucket, b, key)
+
+			if err := b.Delete(key); err != nil {
+				return err
+			}
+
+			if err := db.onMutation(tx, mutation{op: OpDelete, bucket: bucket, key: key, prev: prev}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteBatch removes each of the specified keys within a single read/write transaction. This is forwarded to the Database implementation.
+func (b *Bucket) DeleteBatch(keys [][]byte) error {
+	return b.db.DeleteBatch(b.bucket, keys)
+}
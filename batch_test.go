@@ -0,0 +1,137 @@
+package ubolt
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPutBatch(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.ErrorIs(t, b.db.PutBatch(missing, []Entry{{Key: testkey, Value: testvalue}}), ErrBucketNotFound{}, "PutBatch - missing bucket")
+	assert.Nil(t, b.PutBatch(nil), "PutBatch - empty")
+
+	assert.Nil(t, b.PutBatch([]Entry{
+		{Key: []byte("a"), Value: []byte("1")},
+		{Key: []byte("b"), Value: []byte("2")},
+		{Key: []byte("a"), Value: []byte("3")},
+	}), "PutBatch")
+	assert.Equal(t, []byte("3"), b.Get([]byte("a")), "PutBatch - last entry wins")
+	assert.Equal(t, []byte("2"), b.Get([]byte("b")), "PutBatch - written")
+
+	// a failed entry rolls back the whole batch
+	assert.NotNil(t, b.PutBatch([]Entry{
+		{Key: []byte("c"), Value: []byte("4")},
+		{Key: []byte{}, Value: []byte("5")},
+	}), "PutBatch - empty key")
+	assert.False(t, b.Exists([]byte("c")), "PutBatch - rolled back")
+}
+
+func benchmarkPuts(b *testing.B, put func(db *Bucket, entries []Entry) error) {
+	value := make([]byte, 128)
+	entries := make([]Entry, 1000)
+	for i := range entries {
+		entries[i] = Entry{Key: []byte(fmt.Sprintf("key%04d", i)), Value: value}
+	}
+
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	db, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		if err := put(db, entries); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func BenchmarkPutLoop(b *testing.B) {
+	benchmarkPuts(b, func(db *Bucket, entries []Entry) error {
+		for _, e := range entries {
+			if err := db.Put(e.Key, e.Value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func BenchmarkPutBatch(b *testing.B) {
+	benchmarkPuts(b, func(db *Bucket, entries []Entry) error {
+		return db.PutBatch(entries)
+	})
+}
+
+func TestGetBatch(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.Nil(t, b.PutAll(map[string][]byte{"a": []byte("1"), "b": []byte("2"), "empty": {}}), "PutAll")
+
+	_, err = b.db.GetBatch(missing, [][]byte{testkey})
+	assert.ErrorIs(t, err, ErrBucketNotFound{}, "GetBatch - missing bucket")
+
+	values, err := b.GetBatch([][]byte{[]byte("a"), []byte("b"), []byte("empty"), missing})
+	assert.Nil(t, err, "GetBatch")
+	assert.Equal(t, map[string][]byte{"a": []byte("1"), "b": []byte("2"), "empty": {}}, values, "GetBatch - values")
+	assert.NotNil(t, values["empty"], "GetBatch - empty value")
+
+	// values remain valid once the transaction has closed
+	assert.Nil(t, b.Put([]byte("a"), []byte("changed")), "Put")
+	assert.Equal(t, []byte("1"), values["a"], "GetBatch - copied")
+
+	values, err = b.GetBatch(nil)
+	assert.Nil(t, err, "GetBatch - no keys")
+	assert.Empty(t, values, "GetBatch - no keys")
+}
+
+func TestDeleteBatch(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.Nil(t, b.PutAll(map[string][]byte{"a": []byte("1"), "b": []byte("2"), "c": []byte("3")}), "PutAll")
+
+	assert.ErrorIs(t, b.db.DeleteBatch(missing, [][]byte{[]byte("a")}), ErrBucketNotFound{}, "DeleteBatch - missing bucket")
+	assert.Nil(t, b.DeleteBatch(nil), "DeleteBatch - no keys")
+	assert.Nil(t, b.DeleteBatch([][]byte{}), "DeleteBatch - empty keys")
+
+	assert.Nil(t, b.DeleteBatch([][]byte{[]byte("a"), missing, []byte("c")}), "DeleteBatch - existing and missing keys")
+	assert.False(t, b.Exists([]byte("a")), "DeleteBatch - deleted")
+	assert.False(t, b.Exists([]byte("c")), "DeleteBatch - deleted")
+	assert.True(t, b.Exists([]byte("b")), "DeleteBatch - kept")
+
+	// a failed delete rolls back the whole batch
+	assert.Nil(t, b.db.CreateBucket(BucketPath(testbucket, []byte("nested"))), "CreateBucket - nested")
+	assert.NotNil(t, b.DeleteBatch([][]byte{[]byte("b"), []byte("nested")}), "DeleteBatch - nested bucket")
+	assert.True(t, b.Exists([]byte("b")), "DeleteBatch - rolled back")
+}
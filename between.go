@@ -0,0 +1,90 @@
+package ubolt
+
+import (
+	"bytes"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// GetKeysBetween returns up to limit keys from the chosen bucket in ascending order that sort at or after start and strictly before end.
+// A nil start begins at the first key, a nil end continues to the last key and a limit of zero or less returns every key in the range.
+// The returned keys are copies and no values are read.
+func (db *Database) GetKeysBetween(bucket, start, end []byte, limit int) (keys [][]byte, err error) {
+	start, end = db.canonicalKey(start), db.canonicalKey(end)
+
+	if err := db.view(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		c := b.Cursor()
+		k, _ := c.First()
+		if start != nil {
+			k, _ = c.Seek(start)
+		}
+
+		for ; k != nil && (end == nil || bytes.Compare(k, end) < 0); k, _ = c.Next() {
+			if limit > 0 && len(keys) == limit {
+				break
+			}
+
+			keys = append(keys, append([]byte{}, k...))
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// GetKeysBetween returns up to limit keys in ascending order that sort at or after start and strictly before end.
+func (b *Bucket) GetKeysBetween(start, end []byte, limit int) (keys [][]byte, err error) {
+	return b.db.GetKeysBetween(b.bucket, start, end, limit)
+}
+
+// GetKeysBetweenReverse performs the same process as GetKeysBetween however keys are returned in descending order, starting from the last
+// key before end, so the limit applies to the newest keys when keys are ordered by time.
+func (db *Database) GetKeysBetweenReverse(bucket, start, end []byte, limit int) (keys [][]byte, err error) {
+	start, end = db.canonicalKey(start), db.canonicalKey(end)
+
+	if err := db.view(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		c := b.Cursor()
+
+		var k []byte
+		if end == nil {
+			k, _ = c.Last()
+		} else if k, _ = c.Seek(end); k == nil {
+			// end sorts after every key
+			k, _ = c.Last()
+		} else {
+			k, _ = c.Prev()
+		}
+
+		for ; k != nil && (start == nil || bytes.Compare(k, start) >= 0); k, _ = c.Prev() {
+			if limit > 0 && len(keys) == limit {
+				break
+			}
+
+			keys = append(keys, append([]byte{}, k...))
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// GetKeysBetweenReverse returns up to limit keys in descending order that sort at or after start and strictly before end.
+func (b *Bucket) GetKeysBetweenReverse(start, end []byte, limit int) (keys [][]byte, err error) {
+	return b.db.GetKeysBetweenReverse(b.bucket, start, end, limit)
+}
@@ -0,0 +1,59 @@
+package ubolt
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetKeysBetween(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	for _, k := range []string{"a", "b", "c", "d", "e"} {
+		assert.Nil(t, b.Put([]byte(k), testvalue), "Put")
+	}
+
+	toStrings := func(keys [][]byte) []string {
+		s := make([]string, 0, len(keys))
+		for _, k := range keys {
+			s = append(s, string(k))
+		}
+		return s
+	}
+
+	tests := []struct {
+		name        string
+		start, end  []byte
+		limit       int
+		want, wantR []string
+	}{
+		{"all", nil, nil, 0, []string{"a", "b", "c", "d", "e"}, []string{"e", "d", "c", "b", "a"}},
+		{"bounded", []byte("b"), []byte("d"), 0, []string{"b", "c"}, []string{"c", "b"}},
+		{"limit", nil, nil, 2, []string{"a", "b"}, []string{"e", "d"}},
+		{"open start", nil, []byte("c"), 0, []string{"a", "b"}, []string{"b", "a"}},
+		{"open end", []byte("cc"), nil, 0, []string{"d", "e"}, []string{"e", "d"}},
+		{"end past last", []byte("d"), []byte("z"), 0, []string{"d", "e"}, []string{"e", "d"}},
+		{"empty", []byte("x"), []byte("z"), 0, []string{}, []string{}},
+	}
+
+	for _, tt := range tests {
+		keys, err := b.GetKeysBetween(tt.start, tt.end, tt.limit)
+		assert.Nil(t, err, tt.name)
+		assert.Equal(t, tt.want, toStrings(keys), tt.name)
+
+		keys, err = b.GetKeysBetweenReverse(tt.start, tt.end, tt.limit)
+		assert.Nil(t, err, tt.name)
+		assert.Equal(t, tt.wantR, toStrings(keys), tt.name+" - reverse")
+	}
+
+	_, err = b.db.GetKeysBetween([]byte("missing"), nil, nil, 0)
+	assert.ErrorIs(t, err, ErrBucketNotFound{}, "GetKeysBetween - missing bucket")
+}
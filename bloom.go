@@ -0,0 +1,228 @@
+package ubolt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"sync/atomic"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrNoBloomFilter is returned when a Bloom filter method is called for a bucket that WithBloomFilter was not used for.
+type ErrNoBloomFilter struct {
+	bucket []byte
+}
+
+// Error returns the formatted configuration error.
+func (nbf ErrNoBloomFilter) Error() string {
+	return fmt.Sprintf("Bucket %s has no Bloom filter", string(nbf.bucket))
+}
+
+// Is allows testing using errors.Is
+func (nbf ErrNoBloomFilter) Is(target error) bool {
+	_, is := target.(ErrNoBloomFilter)
+
+	return is
+}
+
+// bloomFilter is a fixed size Bloom filter safe for concurrent use.
+type bloomFilter struct {
+	words []atomic.Uint64
+	m     uint64
+	k     uint64
+}
+
+func newBloomFilter(expectedKeys int, fpRate float64) *bloomFilter {
+	if expectedKeys <= 0 {
+		expectedKeys = 1000
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		fpRate = 0.01
+	}
+
+	m := uint64(math.Ceil(-float64(expectedKeys) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	k := uint64(math.Max(1, math.Round(float64(m)/float64(expectedKeys)*math.Ln2)))
+
+	return &bloomFilter{words: make([]atomic.Uint64, (m+63)/64), m: m, k: k}
+}
+
+// locations calls fn with each bit position for key using double hashing.
+func (f *bloomFilter) locations(key []byte, fn func(bit uint64) bool) {
+	h := fnv.New128a()
+	_, _ = h.Write(key)
+	sum := h.Sum(nil)
+
+	h1, h2 := binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:])
+	for i := uint64(0); i < f.k; i++ {
+		if !fn((h1 + i*h2) % f.m) {
+			return
+		}
+	}
+}
+
+func (f *bloomFilter) add(key []byte) {
+	f.locations(key, func(bit uint64) bool {
+		f.words[bit/64].Or(1 << (bit % 64))
+		return true
+	})
+}
+
+// mayContain returns false only if key was never added.
+func (f *bloomFilter) mayContain(key []byte) bool {
+	found := true
+	f.locations(key, func(bit uint64) bool {
+		found = f.words[bit/64].Load()&(1<<(bit%64)) != 0
+		return found
+	})
+
+	return found
+}
+
+// fpRate estimates the current false positive rate from the fraction of bits set.
+func (f *bloomFilter) fpRate() float64 {
+	var set int
+	for i := range f.words {
+		set += bits.OnesCount64(f.words[i].Load())
+	}
+
+	return math.Pow(float64(set)/float64(f.m), float64(f.k))
+}
+
+// bloomConfig holds the parameters passed to WithBloomFilter so the filter can be rebuilt.
+type bloomConfig struct {
+	expectedKeys int
+	fpRate       float64
+	filter       atomic.Pointer[bloomFilter]
+}
+
+// WithBloomFilter maintains an in-memory Bloom filter of the keys in the chosen bucket, which allows GetE, Get, GetOK and Exists to return
+// early for keys that definitely do not exist without starting a transaction. The filter is sized for expectedKeys with the provided false
+// positive rate, and is built from the existing keys when the database is opened then updated by every Put, PutV, Encode and import.
+//
+// As a key cannot be removed from a Bloom filter, deleted keys continue to cost a full lookup until RebuildBloomFilter is called. A filtered
+// lookup for a missing key returns ErrKeyNotFound even if the bucket itself does not exist. This option may be provided more than once to
+// filter multiple buckets.
+func WithBloomFilter(bucket []byte, expectedKeys int, fpRate float64) Option {
+	return func(db *Database) {
+		if db.blooms == nil {
+			db.blooms = make(map[string]*bloomConfig)
+		}
+
+		db.blooms[string(bucket)] = &bloomConfig{expectedKeys: expectedKeys, fpRate: fpRate}
+	}
+}
+
+// RebuildBloomFilter discards and recreates the Bloom filter for the chosen bucket from its current keys, which removes deleted keys from
+// the filter. ErrNoBloomFilter is returned if WithBloomFilter was not used for the bucket.
+func (db *Database) RebuildBloomFilter(bucket []byte) error {
+	return db.RebuildBloomFilterWithProgress(bucket, nil)
+}
+
+// RebuildBloomFilterWithProgress performs the same process as RebuildBloomFilter while reporting the number of keys added to the filter to
+// progress. The total is reported as -1.
+func (db *Database) RebuildBloomFilterWithProgress(bucket []byte, progress ProgressFunc) error {
+	cfg, ok := db.blooms[string(bucket)]
+	if !ok {
+		return ErrNoBloomFilter{bucket}
+	}
+
+	var p *progressReporter
+
+	build := func(tx *bolt.Tx) error {
+		p = newProgress(progress, -1)
+
+		f := newBloomFilter(cfg.expectedKeys, cfg.fpRate)
+		if b := lookupBucket(tx, bucket); b != nil {
+			if err := b.ForEach(func(k, v []byte) error {
+				f.add(k)
+				p.add(1)
+
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+
+		cfg.filter.Store(f)
+
+		return nil
+	}
+
+	// building within a write transaction ensures no key is written between the scan and the filter being replaced
+	var err error
+	if db.IsReadOnly() {
+		err = db.view(build)
+	} else {
+		err = db.update(build)
+	}
+	if err != nil {
+		return err
+	}
+
+	p.finish()
+
+	return nil
+}
+
+// RebuildBloomFilter discards and recreates the Bloom filter for the bucket from its current keys.
+func (b *Bucket) RebuildBloomFilter() error {
+	return b.db.RebuildBloomFilter(b.bucket)
+}
+
+// RebuildBloomFilterWithProgress performs the same process as RebuildBloomFilter while reporting the number of keys added to progress.
+func (b *Bucket) RebuildBloomFilterWithProgress(progress ProgressFunc) error {
+	return b.db.RebuildBloomFilterWithProgress(b.bucket, progress)
+}
+
+// BloomFilterFPRate returns the estimated false positive rate of the Bloom filter for the chosen bucket based on the proportion of its bits
+// that are set. ErrNoBloomFilter is returned if WithBloomFilter was not used for the bucket.
+func (db *Database) BloomFilterFPRate(bucket []byte) (float64, error) {
+	f := db.bloomFilter(bucket)
+	if f == nil {
+		return 0, ErrNoBloomFilter{bucket}
+	}
+
+	return f.fpRate(), nil
+}
+
+// BloomFilterFPRate returns the estimated false positive rate of the Bloom filter for the bucket.
+func (b *Bucket) BloomFilterFPRate() (float64, error) {
+	return b.db.BloomFilterFPRate(b.bucket)
+}
+
+// buildBloomFilters builds every configured Bloom filter when the database is opened.
+func (db *Database) buildBloomFilters() error {
+	for name := range db.blooms {
+		if err := db.RebuildBloomFilter([]byte(name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (db *Database) bloomFilter(bucket []byte) *bloomFilter {
+	if cfg, ok := db.blooms[string(bucket)]; ok {
+		return cfg.filter.Load()
+	}
+
+	return nil
+}
+
+// bloomAdd records the key in the Bloom filter of the bucket if one is enabled. This must be called for every key written within the
+// transaction that writes it so the filter never reports a committed key as absent.
+func (db *Database) bloomAdd(bucket, key []byte) {
+	if f := db.bloomFilter(bucket); f != nil {
+		f.add(key)
+	}
+}
+
+// bloomMiss returns true if the Bloom filter for the bucket shows the key definitely does not exist.
+func (db *Database) bloomMiss(bucket, key []byte) bool {
+	f := db.bloomFilter(bucket)
+
+	return f != nil && !f.mayContain(key)
+}
@@ -0,0 +1,150 @@
+package ubolt
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBloomFilter(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	// existing keys are added when the database is opened
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	for i := 0; i < 500; i++ {
+		if err := b.Put([]byte(fmt.Sprintf("key%04d", i)), testvalue); err != nil {
+			panic(err)
+		}
+	}
+	if err := b.Close(); err != nil {
+		panic(err)
+	}
+
+	b, err = OpenBucket(testdb, testbucket, WithBloomFilter(testbucket, 1000, 0.01))
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	// new keys are added as they are written
+	for i := 500; i < 1000; i++ {
+		if err := b.Put([]byte(fmt.Sprintf("key%04d", i)), testvalue); err != nil {
+			panic(err)
+		}
+	}
+	key, err := b.PutV(testvalue)
+	if err != nil {
+		panic(err)
+	}
+
+	// a key that exists is never reported as absent
+	for i := 0; i < 1000; i++ {
+		k := []byte(fmt.Sprintf("key%04d", i))
+		assert.True(t, b.Exists(k), "Exists - "+string(k))
+	}
+	assert.Equal(t, testvalue, b.Get(key), "Get - PutV key")
+
+	// most missing keys are filtered
+	filter := b.db.bloomFilter(testbucket)
+	var misses int
+	for i := 0; i < 1000; i++ {
+		k := []byte(fmt.Sprintf("missing%04d", i))
+		if !filter.mayContain(k) {
+			misses++
+		}
+
+		_, err := b.GetE(k)
+		assert.ErrorIs(t, err, ErrKeyNotFound{}, "GetE - missing")
+	}
+	assert.Greater(t, misses, 950, "Bloom filter - missing keys filtered")
+
+	rate, err := b.BloomFilterFPRate()
+	assert.Nil(t, err, "BloomFilterFPRate")
+	assert.Less(t, rate, 0.05, "BloomFilterFPRate")
+
+	// deleted keys remain in the filter until it is rebuilt
+	if _, err := b.DeleteRange(nil, []byte("key0900")); err != nil {
+		panic(err)
+	}
+	assert.True(t, filter.mayContain([]byte("key0000")), "Bloom filter - deleted key")
+	assert.Nil(t, b.RebuildBloomFilter(), "RebuildBloomFilter")
+	assert.False(t, b.db.bloomFilter(testbucket).mayContain([]byte("key0000")), "Bloom filter - rebuilt")
+	assert.True(t, b.Exists([]byte("key0950")), "Exists - after rebuild")
+
+	after, err := b.BloomFilterFPRate()
+	assert.Nil(t, err, "BloomFilterFPRate - after rebuild")
+	assert.Less(t, after, rate, "BloomFilterFPRate - after rebuild")
+
+	_, err = b.db.BloomFilterFPRate(missing)
+	assert.ErrorIs(t, err, ErrNoBloomFilter{}, "BloomFilterFPRate - no filter")
+	assert.ErrorIs(t, b.db.RebuildBloomFilter(missing), ErrNoBloomFilter{}, "RebuildBloomFilter - no filter")
+}
+
+func TestBloomFilterClone(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	clone := []byte("clone")
+
+	db, err := Open(testdb, WithBloomFilter(clone, 100, 0.01))
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	if err := db.CreateBucket(testbucket); err != nil {
+		panic(err)
+	}
+	if err := db.Put(testbucket, testkey, testvalue); err != nil {
+		panic(err)
+	}
+
+	// cloned keys pass through the write hooks so are added to the filter
+	assert.Nil(t, db.CloneBucket(testbucket, clone), "CloneBucket")
+	assert.True(t, db.Exists(clone, testkey), "Exists - cloned key")
+}
+
+func TestBloomFilterImportNested(t *testing.T) {
+	_ = os.Remove(testdb)
+	_ = os.Remove(testbackup)
+	defer os.Remove(testdb)
+	defer os.Remove(testbackup)
+
+	nested := BucketPath(testbucket, []byte("nested"))
+
+	src, err := Open(testbackup)
+	if err != nil {
+		panic(err)
+	}
+	defer src.Close()
+
+	if err := src.CreateBucket(nested); err != nil {
+		panic(err)
+	}
+	if err := src.Put(nested, testkey, testvalue); err != nil {
+		panic(err)
+	}
+
+	var archive bytes.Buffer
+	if err := src.ExportArchive(&archive); err != nil {
+		panic(err)
+	}
+
+	db, err := Open(testdb, WithBloomFilter(nested, 100, 0.01))
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	// keys imported into nested buckets are added to the filter
+	assert.Nil(t, db.ImportArchive(&archive), "ImportArchive")
+	assert.True(t, db.Exists(nested, testkey), "Exists - imported key")
+	assert.Equal(t, testvalue, db.Get(nested, testkey), "Get - imported key")
+}
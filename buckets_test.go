@@ -0,0 +1,99 @@
+package ubolt
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucketsWithPrefix(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	db, err := Open(testdb)
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	for _, name := range []string{"tenant:2", "tenant:1", "tenants", "other", "tenant:10"} {
+		assert.Nil(t, db.CreateBucket([]byte(name)), "CreateBucket")
+	}
+
+	// reserved buckets are excluded
+	assert.Nil(t, db.SetMeta([]byte("key"), []byte("value")), "SetMeta")
+
+	tests := []struct {
+		name   string
+		prefix string
+		want   [][]byte
+	}{
+		{"tenants", "tenant:", [][]byte{[]byte("tenant:1"), []byte("tenant:10"), []byte("tenant:2")}},
+		{"all", "", [][]byte{[]byte("other"), []byte("tenant:1"), []byte("tenant:10"), []byte("tenant:2"), []byte("tenants")}},
+		{"reserved", "__", [][]byte{}},
+		{"none", "missing", [][]byte{}},
+	}
+
+	for _, tt := range tests {
+		got, err := db.BucketsWithPrefix([]byte(tt.prefix))
+		assert.Nil(t, err, tt.name)
+		assert.Equal(t, tt.want, got, tt.name)
+
+		n, err := db.CountBucketsWithPrefix([]byte(tt.prefix))
+		assert.Nil(t, err, tt.name)
+		assert.Equal(t, len(tt.want), n, tt.name+" - count")
+	}
+}
+
+func TestOpenBuckets(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	names := [][]byte{[]byte("users"), []byte("sessions"), BucketPath([]byte("tenants"), []byte("acme"))}
+
+	buckets, db, err := OpenBuckets(testdb, names)
+	assert.Nil(t, err, "OpenBuckets")
+	assert.Len(t, buckets, 3, "OpenBuckets")
+
+	for _, name := range names {
+		b := buckets[string(name)]
+		assert.NotNil(t, b, "OpenBuckets - %s", bucketName(name))
+		assert.Nil(t, b.Put(testkey, testvalue), "Put - %s", bucketName(name))
+		assert.Same(t, db, b.db, "OpenBuckets - shared database")
+	}
+
+	assert.Equal(t, testvalue, buckets["sessions"].Get(testkey), "Get")
+
+	// closing the database invalidates every handle
+	assert.Nil(t, db.Close(), "Close")
+
+	for _, name := range names {
+		_, err := buckets[string(name)].GetE(testkey)
+		assert.NotNil(t, err, "GetE after Close - %s", bucketName(name))
+	}
+
+	// a bucket that can not be created closes the database and is named in the error
+	_, _, err = OpenBuckets(testdb, [][]byte{[]byte("users"), []byte("new"), {}})
+	assert.ErrorIs(t, err, ErrOpenBucket{}, "OpenBuckets - empty name")
+
+	var ob ErrOpenBucket
+	if assert.ErrorAs(t, err, &ob, "OpenBuckets - empty name") {
+		assert.Equal(t, []byte{}, ob.Bucket(), "ErrOpenBucket.Bucket")
+	}
+
+	// no buckets were created and the database was closed so it can be opened again
+	db, err = Open(testdb, WithReadOnly())
+	assert.Nil(t, err, "Open after failure")
+	assert.NotContains(t, db.GetBuckets(), []byte("new"), "OpenBuckets - partial create")
+	assert.Nil(t, db.Close(), "Close")
+
+	// a read-only database only verifies the buckets exist
+	buckets, db, err = OpenBuckets(testdb, names[:2], WithReadOnly())
+	assert.Nil(t, err, "OpenBuckets - read-only")
+	assert.Len(t, buckets, 2, "OpenBuckets - read-only")
+	assert.Nil(t, db.Close(), "Close")
+
+	_, _, err = OpenBuckets(testdb, [][]byte{[]byte("users"), []byte("missing")}, WithReadOnly())
+	assert.ErrorIs(t, err, ErrBucketNotFound{}, "OpenBuckets - read-only missing")
+}
@@ -0,0 +1,91 @@
+package ubolt
+
+import (
+	"bytes"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrValueMismatch is returned by CompareAndSwap when the current value of the key does not match the expected value.
+type ErrValueMismatch struct {
+	bucket  []byte
+	key     []byte
+	current []byte
+}
+
+// Error returns the formatted configuration error.
+func (vm ErrValueMismatch) Error() string {
+	if vm.current == nil {
+		return fmt.Sprintf("Key %s in bucket %s does not exist", string(vm.key), bucketName(vm.bucket))
+	}
+
+	return fmt.Sprintf("Value of key %s in bucket %s does not match the expected value", string(vm.key), bucketName(vm.bucket))
+}
+
+// Is allows testing using errors.Is
+func (vm ErrValueMismatch) Is(target error) bool {
+	_, is := target.(ErrValueMismatch)
+
+	return is
+}
+
+// Current returns a copy of the value of the key at the time of the comparison, which is nil if the key did not exist. An existing empty
+// value is returned as a non-nil empty slice.
+func (vm ErrValueMismatch) Current() []byte {
+	if vm.current == nil {
+		return nil
+	}
+
+	return append([]byte{}, vm.current...)
+}
+
+// CompareAndSwap sets the specified key in the chosen bucket to replacement only if its current value is byte-wise equal to old, with a nil
+// old meaning the key must not exist. The comparison and the write are made within the same read/write transaction, so concurrent updates
+// of the key are never lost. A key that has expired does not exist.
+//
+// When the current value does not match an ErrValueMismatch holding the current value is returned, which allows the caller to retry the
+// update from that value. ErrBucketNotFound is returned if the bucket does not exist.
+func (db *Database) CompareAndSwap(bucket, key, old, replacement []byte) error {
+	key = db.canonicalKey(key)
+
+	value, err := db.wrapValue(bucket, key, replacement)
+	if err != nil {
+		return err
+	}
+
+	return db.update(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		var current []byte
+		if data := b.Get(key); data != nil && !db.ttlExpired(tx, bucket, key) {
+			if current, err = db.unwrapValue(bucket, key, data); err != nil {
+				return err
+			}
+
+			// the value is copied as it is only valid for the life of the transaction
+			current = append([]byte{}, current...)
+		}
+
+		if (old == nil) != (current == nil) || !bytes.Equal(old, current) {
+			return ErrValueMismatch{bucket: bucket, key: key, current: current}
+		}
+
+		prev := previous(tx, bucket, b, key)
+
+		if err := b.Put(key, value); err != nil {
+			return err
+		}
+
+		return db.onMutation(tx, mutation{op: OpPut, bucket: bucket, key: key, value: value, prev: prev})
+	})
+}
+
+// CompareAndSwap sets the specified key to replacement only if its current value is byte-wise equal to old, with a nil old meaning the key
+// must not exist. This is forwarded to the Database implementation.
+func (b *Bucket) CompareAndSwap(key, old, replacement []byte) error {
+	return b.db.CompareAndSwap(b.bucket, key, old, replacement)
+}
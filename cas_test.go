@@ -0,0 +1,80 @@
+package ubolt
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareAndSwap(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.ErrorIs(t, b.db.CompareAndSwap(missing, testkey, nil, testvalue), ErrBucketNotFound{}, "CompareAndSwap - missing bucket")
+
+	// a nil old value creates the key
+	assert.Nil(t, b.CompareAndSwap(testkey, nil, []byte("1")), "CompareAndSwap - create")
+	assert.Equal(t, []byte("1"), b.Get(testkey), "CompareAndSwap - created")
+
+	err = b.CompareAndSwap(testkey, nil, []byte("2"))
+	assert.ErrorIs(t, err, ErrValueMismatch{}, "CompareAndSwap - create existing key")
+
+	var mismatch ErrValueMismatch
+	if assert.True(t, errors.As(err, &mismatch), "ErrValueMismatch") {
+		assert.Equal(t, []byte("1"), mismatch.Current(), "ErrValueMismatch - current")
+	}
+
+	assert.Nil(t, b.CompareAndSwap(testkey, []byte("1"), []byte("2")), "CompareAndSwap - swap")
+	assert.Equal(t, []byte("2"), b.Get(testkey), "CompareAndSwap - swapped")
+
+	err = b.CompareAndSwap(testkey, []byte("1"), []byte("3"))
+	assert.ErrorIs(t, err, ErrValueMismatch{}, "CompareAndSwap - mismatch")
+	assert.Equal(t, []byte("2"), b.Get(testkey), "CompareAndSwap - mismatch unchanged")
+
+	// a missing key does not match an empty value
+	err = b.CompareAndSwap(missing, []byte{}, testvalue)
+	assert.ErrorIs(t, err, ErrValueMismatch{}, "CompareAndSwap - missing key")
+	if assert.True(t, errors.As(err, &mismatch), "ErrValueMismatch") {
+		assert.Nil(t, mismatch.Current(), "ErrValueMismatch - missing key")
+	}
+
+	assert.Nil(t, b.Put([]byte("empty"), []byte{}), "Put")
+	assert.ErrorIs(t, b.CompareAndSwap([]byte("empty"), nil, testvalue), ErrValueMismatch{}, "CompareAndSwap - empty value exists")
+	assert.Nil(t, b.CompareAndSwap([]byte("empty"), []byte{}, testvalue), "CompareAndSwap - empty value")
+
+	// concurrent increments retried on mismatch are never lost
+	assert.Nil(t, b.Put([]byte("counter"), []byte{0}), "Put")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			current := b.Get([]byte("counter"))
+			for {
+				var mismatch ErrValueMismatch
+
+				err := b.CompareAndSwap([]byte("counter"), current, []byte{current[0] + 1})
+				if !errors.As(err, &mismatch) {
+					assert.Nil(t, err, "CompareAndSwap - concurrent")
+					return
+				}
+
+				current = mismatch.Current()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, []byte{10}, b.Get([]byte("counter")), "CompareAndSwap - concurrent")
+}
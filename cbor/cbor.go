@@ -0,0 +1,76 @@
+// Package cbor provides a ubolt.Codec that encodes values using CBOR (RFC 8949) so they may be read by other languages. Encoding is
+// performed by github.com/fxamacker/cbor/v2.
+//
+// Importing the package registers Codec using ubolt.RegisterCodec, which allows ubolt.WithCBOR and ubolt.CBORCodec to be used and values
+// written by Codec to be decoded whichever ubolt.Codec is configured:
+//
+//	import _ "github.com/andrewheberle/ubolt/cbor"
+//
+// Structs are encoded as maps keyed by field name, which may be overridden using a "cbor" or "json" struct tag. Map entries are sorted by
+// their encoded key, as required for deterministic encoding by RFC 8949, so equal maps produce equal bytes. A time.Time is encoded as an RFC
+// 3339 string with tag 0. Both tag 0 and the epoch based tag 1, which is decoded in the local time zone, are accepted.
+//
+// Decoding into an empty interface produces nil, bool, uint64, int64 (for negative integers), float64, string, []byte, time.Time,
+// []interface{} and map[interface{}]interface{}, while items with other tags produce a Tag. Cyclic values must not be encoded as the
+// library does not detect cycles.
+package cbor
+
+import (
+	"github.com/andrewheberle/ubolt"
+	"github.com/fxamacker/cbor/v2"
+)
+
+// Tag is produced when an item with a tag other than 0 or 1 is decoded into an empty interface.
+type Tag = cbor.Tag
+
+// Codec encodes values using Marshal and decodes them using Unmarshal, recording ubolt.CodecCBOR in the encoding header of each value.
+var Codec ubolt.IdentifiedCodec = codec{}
+
+var (
+	encMode cbor.EncMode
+	decMode cbor.DecMode
+)
+
+func init() {
+	var err error
+
+	encMode, err = cbor.EncOptions{
+		Sort:    cbor.SortCoreDeterministic,
+		Time:    cbor.TimeRFC3339Nano,
+		TimeTag: cbor.EncTagRequired,
+	}.EncMode()
+	if err != nil {
+		panic(err)
+	}
+
+	decMode, err = cbor.DecOptions{}.DecMode()
+	if err != nil {
+		panic(err)
+	}
+
+	ubolt.RegisterCodec(Codec)
+}
+
+type codec struct{}
+
+func (codec) CodecID() ubolt.CodecID {
+	return ubolt.CodecCBOR
+}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	return Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	return Unmarshal(data, v)
+}
+
+// Marshal returns the CBOR encoding of v.
+func Marshal(v interface{}) ([]byte, error) {
+	return encMode.Marshal(v)
+}
+
+// Unmarshal decodes the CBOR data into the value pointed to by v. The data must hold exactly one item.
+func Unmarshal(data []byte, v interface{}) error {
+	return decMode.Unmarshal(data, v)
+}
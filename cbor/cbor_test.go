@@ -0,0 +1,225 @@
+package cbor
+
+import (
+	"encoding/hex"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type golden struct {
+	Name   string `cbor:"name"`
+	Age    int    `cbor:"age"`
+	Tags   []string
+	When   time.Time
+	Score  float64
+	Delta  int16
+	Raw    []byte
+	Secret string `cbor:"-"`
+	Note   string `cbor:"note,omitempty"`
+}
+
+func TestGolden(t *testing.T) {
+	value := golden{
+		Name:   "alice",
+		Age:    30,
+		Tags:   []string{"a", "b"},
+		When:   time.Unix(1700000000, 0).UTC(),
+		Score:  -1.5,
+		Delta:  -200,
+		Raw:    []byte{1, 2},
+		Secret: "hidden",
+	}
+
+	// fields are sorted by their encoded name, so shorter names come first
+	want := "" +
+		"a7" + // map of 7 entries
+		"63526177" + "420102" + // "Raw": byte string 0x01 0x02
+		"63616765" + "181e" + // "age": 30
+		"6454616773" + "8261616162" + // "Tags": ["a", "b"]
+		"645768656e" + "c074" + hex.EncodeToString([]byte("2023-11-14T22:13:20Z")) + // "When": tag 0 date/time
+		"646e616d65" + "65616c696365" + // "name": "alice"
+		"6544656c7461" + "38c7" + // "Delta": -200
+		"6553636f7265" + "fbbff8000000000000" // "Score": -1.5
+
+	data, err := Marshal(value)
+	assert.Nil(t, err, "Marshal")
+	assert.Equal(t, want, hex.EncodeToString(data), "Marshal")
+
+	var got golden
+	assert.Nil(t, Unmarshal(data, &got), "Unmarshal")
+
+	value.Secret = ""
+	assert.Equal(t, value, got, "Unmarshal")
+
+	// examples from RFC 8949 appendix A
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, "f6"},
+		{true, "f5"},
+		{0, "00"},
+		{23, "17"},
+		{24, "1818"},
+		{1000000, "1a000f4240"},
+		{uint64(math.MaxUint64), "1bffffffffffffffff"},
+		{-1, "20"},
+		{-1000, "3903e7"},
+		{int64(math.MinInt64), "3b7fffffffffffffff"},
+		{1.1, "fb3ff199999999999a"},
+		{float32(100000.0), "fa47c35000"},
+		{"IETF", "6449455446"},
+		{"\u00fc", "62c3bc"},
+		{[]int{1, 2, 3}, "83010203"},
+		{map[string]string{"a": "A", "b": "B", "c": "C", "d": "D", "e": "E"}, "a56161614161626142616361436164614461656145"},
+		{time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC), "c074323031332d30332d32315432303a30343a30305a"},
+	}
+
+	for _, tt := range tests {
+		data, err := Marshal(tt.value)
+		assert.Nil(t, err, "Marshal %v", tt.value)
+		assert.Equal(t, tt.want, hex.EncodeToString(data), "Marshal %v", tt.value)
+	}
+}
+
+type record struct {
+	ID       uint32
+	Name     string
+	Scores   []float64
+	Labels   map[string]string
+	Created  time.Time
+	Parent   *record
+	Children []record
+	Hash     [4]byte
+	Any      interface{}
+}
+
+func TestRoundTrip(t *testing.T) {
+	created := time.Date(2024, 2, 29, 12, 30, 45, 123456789, time.UTC)
+
+	tests := []struct {
+		name  string
+		value interface{}
+		into  func() interface{}
+	}{
+		{"string", "hello", func() interface{} { return new(string) }},
+		{"int", -123456789, func() interface{} { return new(int) }},
+		{"float", math.Pi, func() interface{} { return new(float64) }},
+		{"bytes", []byte("raw"), func() interface{} { return new([]byte) }},
+		{"slice", []int{1, -2, 300, 70000}, func() interface{} { return new([]int) }},
+		{"map", map[string]int{"one": 1, "two": 2}, func() interface{} { return new(map[string]int) }},
+		{"int keys", map[int]string{1: "one", -1: "minus one"}, func() interface{} { return new(map[int]string) }},
+		{"time", created, func() interface{} { return new(time.Time) }},
+		{"far time", time.Date(2600, 1, 1, 0, 0, 0, 1, time.UTC), func() interface{} { return new(time.Time) }},
+		{"struct", record{
+			ID:       7,
+			Name:     "parent",
+			Scores:   []float64{1.5, -2.25},
+			Labels:   map[string]string{"env": "prod"},
+			Created:  created,
+			Parent:   &record{ID: 1, Name: "root", Created: created},
+			Children: []record{{ID: 8, Name: "child", Created: created}},
+			Hash:     [4]byte{0xde, 0xad, 0xbe, 0xef},
+			Any:      "text",
+		}, func() interface{} { return new(record) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Marshal(tt.value)
+			assert.Nil(t, err, "Marshal")
+
+			got := tt.into()
+			assert.Nil(t, Unmarshal(data, got), "Unmarshal")
+
+			// dereference the pointer returned by into
+			assert.Equal(t, tt.value, deref(got), "Unmarshal")
+		})
+	}
+}
+
+func TestUnmarshalInterface(t *testing.T) {
+	data, err := Marshal(map[string]interface{}{
+		"list":  []interface{}{"a", int64(1), true, nil},
+		"float": 2.5,
+		"big":   uint64(math.MaxUint64),
+	})
+	assert.Nil(t, err, "Marshal")
+
+	var got interface{}
+	assert.Nil(t, Unmarshal(data, &got), "Unmarshal")
+	assert.Equal(t, map[interface{}]interface{}{
+		"list":  []interface{}{"a", uint64(1), true, nil},
+		"float": 2.5,
+		"big":   uint64(math.MaxUint64),
+	}, got, "Unmarshal")
+
+	// negative integers produce an int64
+	data, err = Marshal(map[int]string{-1: "minus one"})
+	assert.Nil(t, err, "Marshal")
+
+	got = nil
+	assert.Nil(t, Unmarshal(data, &got), "Unmarshal")
+	assert.Equal(t, map[interface{}]interface{}{int64(-1): "minus one"}, got, "Unmarshal")
+}
+
+func TestUnmarshalInterop(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want interface{}
+	}{
+		// examples from RFC 8949 appendix A
+		{"half float", "f93e00", 1.5},
+		{"half float subnormal", "f90001", 5.960464477539063e-08},
+		{"half float infinity", "f97c00", math.Inf(1)},
+		{"undefined", "f7", nil},
+		{"date time", "c074323031332d30332d32315432303a30343a30305a", time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC)},
+		{"epoch time", "c11a514b67b0", time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC).Local()},
+		{"epoch time float", "c1fb41d452d9ec200000", time.Date(2013, 3, 21, 20, 4, 0, 500000000, time.UTC).Local()},
+		{"unknown tag", "d74401020304", Tag{Number: 23, Content: []byte{1, 2, 3, 4}}},
+		{"indefinite bytes", "5f42010243030405ff", []byte{1, 2, 3, 4, 5}},
+		{"indefinite string", "7f657374726561646d696e67ff", "streaming"},
+		{"indefinite array", "9f018202039f0405ffff", []interface{}{uint64(1), []interface{}{uint64(2), uint64(3)}, []interface{}{uint64(4), uint64(5)}}},
+		{"indefinite map", "bf61610161629f0203ffff", map[interface{}]interface{}{"a": uint64(1), "b": []interface{}{uint64(2), uint64(3)}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tt.data)
+			assert.Nil(t, err, "DecodeString")
+
+			var got interface{}
+			assert.Nil(t, Unmarshal(data, &got), "Unmarshal")
+			assert.Equal(t, tt.want, got, "Unmarshal")
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	_, err := Marshal(make(chan int))
+	assert.NotNil(t, err, "Marshal - chan")
+
+	var n int
+	assert.NotNil(t, Unmarshal([]byte{0xf6}, n), "Unmarshal - not a pointer")
+	assert.NotNil(t, Unmarshal([]byte{0x61, 'a'}, &n), "Unmarshal - string into int")
+
+	var small int8
+	assert.NotNil(t, Unmarshal([]byte{0x18, 0xff}, &small), "Unmarshal - overflow")
+
+	assert.NotNil(t, Unmarshal([]byte{0x1c}, &n), "Unmarshal - reserved additional information")
+	assert.NotNil(t, Unmarshal([]byte{0xff}, &n), "Unmarshal - unexpected break")
+	assert.NotNil(t, Unmarshal([]byte{0x19, 0x01}, &n), "Unmarshal - truncated")
+	assert.NotNil(t, Unmarshal([]byte{0x01, 0x02}, &n), "Unmarshal - trailing data")
+
+	var got interface{}
+	assert.NotNil(t, Unmarshal([]byte{0x9a, 0xff, 0xff, 0xff, 0xff}, &got), "Unmarshal - hostile length")
+}
+
+func deref(v interface{}) interface{} {
+	return reflect.ValueOf(v).Elem().Interface()
+}
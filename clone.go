@@ -0,0 +1,240 @@
+package ubolt
+
+import (
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// cloneBucket records buckets that are part way through being cloned by CloneBucket.
+var cloneBucket = []byte("__clone")
+
+// DefaultCloneChunkSize is the number of keys copied per read/write transaction by CloneBucket.
+const DefaultCloneChunkSize = 10000
+
+// ErrBucketExists is returned when creating a bucket that already exists.
+type ErrBucketExists struct {
+	bucket []byte
+}
+
+// Error returns the formatted configuration error.
+func (be ErrBucketExists) Error() string {
+	return fmt.Sprintf("Bucket %s already exists", string(be.bucket))
+}
+
+// Is allows testing using errors.Is
+func (be ErrBucketExists) Is(target error) bool {
+	_, is := target.(ErrBucketExists)
+
+	return is
+}
+
+// ErrNestedBucket is returned when an operation that does not support nested buckets encounters one.
+type ErrNestedBucket struct {
+	bucket []byte
+	key    []byte
+}
+
+// Error returns the formatted configuration error.
+func (nb ErrNestedBucket) Error() string {
+	return fmt.Sprintf("Bucket %s contains nested bucket %s", string(nb.bucket), string(nb.key))
+}
+
+// Is allows testing using errors.Is
+func (nb ErrNestedBucket) Is(target error) bool {
+	_, is := target.(ErrNestedBucket)
+
+	return is
+}
+
+// CloneOptions controls the behaviour of CloneBucketWithOptions.
+type CloneOptions struct {
+	// Overwrite deletes and recreates the destination bucket if it exists rather than returning ErrBucketExists.
+	Overwrite bool
+
+	// ChunkSize is the number of keys copied per read/write transaction. A value of zero or less uses DefaultCloneChunkSize.
+	ChunkSize int
+
+	// Progress, if set, is called as transactions are committed with the number of keys copied out of the number of keys in src.
+	Progress ProgressFunc
+}
+
+// CloneBucket copies every key and value along with the sequence counter of the src bucket into a new dst bucket. ErrBucketExists is
+// returned if dst exists and ErrNestedBucket if src contains nested buckets, which are not supported.
+//
+// Keys are copied in batches across multiple read/write transactions, so writes made to src while the clone is running may or may not be
+// reflected in dst. Until the clone completes dst is marked as partial, which may be checked using IsPartialClone. Each key copied is passed
+// to the write hooks as a Put to dst, so preloaded buckets, Bloom filters, quotas, mirrors and the audit log reflect the clone.
+func (db *Database) CloneBucket(src, dst []byte) error {
+	return db.CloneBucketWithOptions(src, dst, CloneOptions{})
+}
+
+// CloneBucketWithOptions performs the same process as CloneBucket with the behaviour controlled by the provided CloneOptions.
+func (db *Database) CloneBucketWithOptions(src, dst []byte, opts CloneOptions) error {
+	chunk := opts.ChunkSize
+	if chunk <= 0 {
+		chunk = DefaultCloneChunkSize
+	}
+
+	var p *progressReporter
+
+	// validate src, then create dst and mark it as partial
+	if err := db.update(func(tx *bolt.Tx) error {
+		s := lookupBucket(tx, src)
+		if s == nil {
+			return ErrBucketNotFound{bucket: src}
+		}
+
+		var total int64
+		if err := s.ForEach(func(k, v []byte) error {
+			if v == nil {
+				return ErrNestedBucket{bucket: src, key: k}
+			}
+
+			total++
+
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if lookupBucket(tx, dst) != nil {
+			if !opts.Overwrite {
+				return ErrBucketExists{dst}
+			}
+
+			if err := db.deleteBucket(tx, dst); err != nil {
+				return err
+			}
+		}
+
+		if _, err := createBucketPath(tx, dst); err != nil {
+			return err
+		}
+
+		db.recordMirror(tx, mutation{op: opCreateBucket, bucket: dst})
+
+		marker, err := tx.CreateBucketIfNotExists(cloneBucket)
+		if err != nil {
+			return err
+		}
+
+		p = newProgress(opts.Progress, total)
+
+		return marker.Put(dst, src)
+	}); err != nil {
+		return err
+	}
+
+	var after []byte
+	for done := false; !done; {
+		n := 0
+
+		if err := db.update(func(tx *bolt.Tx) error {
+			s, d := lookupBucket(tx, src), lookupBucket(tx, dst)
+			if s == nil {
+				return ErrBucketNotFound{bucket: src}
+			}
+			if d == nil {
+				return ErrBucketNotFound{bucket: dst}
+			}
+
+			n = 0
+			err := scanPrefixFrom(s.Cursor(), nil, after, func(k, v []byte) error {
+				if n == chunk {
+					return ErrStop{}
+				}
+
+				if v == nil {
+					return ErrNestedBucket{bucket: src, key: k}
+				}
+
+				prev := previous(tx, dst, d, k)
+
+				if err := d.Put(k, v); err != nil {
+					return err
+				}
+
+				if err := db.onMutation(tx, mutation{op: OpPut, bucket: dst, key: k, value: v, prev: prev}); err != nil {
+					return err
+				}
+
+				after = append(after[:0], k...)
+				n++
+
+				return nil
+			})
+			done = err == nil
+
+			return ignoreStop(err)
+		}); err != nil {
+			return err
+		}
+
+		p.add(int64(n))
+	}
+
+	// copy the sequence and key encoding marker then clear the partial marker
+	if err := db.update(func(tx *bolt.Tx) error {
+		s, d := lookupBucket(tx, src), lookupBucket(tx, dst)
+		if s == nil {
+			return ErrBucketNotFound{bucket: src}
+		}
+		if d == nil {
+			return ErrBucketNotFound{bucket: dst}
+		}
+
+		if err := d.SetSequence(s.Sequence()); err != nil {
+			return err
+		}
+
+		// the sequence is passed to a mirror along with the bucket
+		db.recordMirror(tx, mutation{op: opCreateBucket, bucket: dst})
+
+		if err := clearKeyEncoding(tx, dst); err != nil {
+			return err
+		}
+
+		if marker := tx.Bucket(sequenceBucket); marker != nil {
+			if v := marker.Get(src); v != nil {
+				if err := marker.Put(dst, v); err != nil {
+					return err
+				}
+			}
+		}
+
+		return clearPartialClone(tx, dst)
+	}); err != nil {
+		return err
+	}
+
+	p.finish()
+
+	return nil
+}
+
+// IsPartialClone returns true if the bucket was created by CloneBucket and the clone has not completed, for example because it failed or
+// the process exited part way through.
+func (db *Database) IsPartialClone(bucket []byte) (partial bool, err error) {
+	if err := db.view(func(tx *bolt.Tx) error {
+		if marker := tx.Bucket(cloneBucket); marker != nil {
+			partial = marker.Get(bucket) != nil
+		}
+
+		return nil
+	}); err != nil {
+		return false, err
+	}
+
+	return partial, nil
+}
+
+// clearPartialClone removes any partial clone marker for the bucket.
+func clearPartialClone(tx *bolt.Tx, bucket []byte) error {
+	marker := tx.Bucket(cloneBucket)
+	if marker == nil {
+		return nil
+	}
+
+	return marker.Delete(bucket)
+}
@@ -0,0 +1,101 @@
+package ubolt
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestCloneBucket(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	db, err := Open(testdb)
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	if err := db.CreateBucket(testbucket); err != nil {
+		panic(err)
+	}
+	for i := 0; i < 25; i++ {
+		if _, err := db.PutV(testbucket, []byte(fmt.Sprintf("value%02d", i))); err != nil {
+			panic(err)
+		}
+	}
+
+	backup := []byte("backup")
+
+	assert.ErrorIs(t, db.CloneBucket(missing, backup), ErrBucketNotFound{}, "CloneBucket - missing src")
+
+	// clone in several chunks
+	err = db.CloneBucketWithOptions(testbucket, backup, CloneOptions{ChunkSize: 10})
+	assert.Nil(t, err, "CloneBucket")
+	assert.Equal(t, db.GetValues(testbucket), db.GetValues(backup), "CloneBucket - values")
+
+	partial, err := db.IsPartialClone(backup)
+	assert.Nil(t, err, "IsPartialClone")
+	assert.False(t, partial, "IsPartialClone - complete")
+
+	// the sequence continues from the source
+	key, err := db.PutV(backup, testvalue)
+	assert.Nil(t, err, "PutV - clone")
+	id, _ := db.KeyID(key)
+	assert.Equal(t, uint64(26), id, "PutV - clone sequence")
+
+	// existing destination
+	assert.ErrorIs(t, db.CloneBucket(testbucket, backup), ErrBucketExists{}, "CloneBucket - dst exists")
+	assert.Nil(t, db.CloneBucketWithOptions(testbucket, backup, CloneOptions{Overwrite: true}), "CloneBucket - overwrite")
+	assert.Equal(t, db.GetValues(testbucket), db.GetValues(backup), "CloneBucket - overwrite values")
+
+	// nested buckets are rejected before dst is created
+	if err := db.bdb().Update(func(tx *bolt.Tx) error {
+		_, err := tx.Bucket(testbucket).CreateBucket([]byte("nested"))
+		return err
+	}); err != nil {
+		panic(err)
+	}
+	assert.ErrorIs(t, db.CloneBucket(testbucket, []byte("nested-backup")), ErrNestedBucket{}, "CloneBucket - nested")
+	assert.NotContains(t, db.GetBuckets(), []byte("nested-backup"), "CloneBucket - nested dst not created")
+}
+
+func TestIsPartialClone(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	db, err := Open(testdb)
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	// simulate a clone interrupted after creating dst
+	if err := db.bdb().Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucket(testbucket); err != nil {
+			return err
+		}
+
+		marker, err := tx.CreateBucketIfNotExists(cloneBucket)
+		if err != nil {
+			return err
+		}
+
+		return marker.Put(testbucket, []byte("src"))
+	}); err != nil {
+		panic(err)
+	}
+
+	partial, err := db.IsPartialClone(testbucket)
+	assert.Nil(t, err, "IsPartialClone")
+	assert.True(t, partial, "IsPartialClone - partial")
+
+	// deleting the partial bucket clears the marker
+	assert.Nil(t, db.DeleteBucket(testbucket), "DeleteBucket")
+	partial, err = db.IsPartialClone(testbucket)
+	assert.Nil(t, err, "IsPartialClone")
+	assert.False(t, partial, "IsPartialClone - deleted")
+}
@@ -0,0 +1,374 @@
+// Command ubolt inspects and edits ubolt databases.
+//
+// Usage:
+//
+//	ubolt <command> [flags] <file> [arguments]
+//
+// The commands are:
+//
+//	buckets                          list buckets
+//	keys [--prefix p] <bucket>       list keys in a bucket
+//	get [--raw|--gob|--json] <bucket> <key>
+//	                                 print a value
+//	put --write <bucket> <key> <value>
+//	                                 set a value
+//	delete --write <bucket> <key>    delete a key
+//	stats                            print database statistics
+//	export [--format json|csv] <bucket>
+//	                                 write every key and value of a bucket
+//
+// Databases are opened read-only unless --write is provided. Keys and values are read and printed as strings by default, which may be
+// changed to hex or base64 using --key-format and --value-format.
+//
+// The exit status is 0 on success, 1 on error, 2 for invalid usage and 3 when a bucket or key is not found.
+package main
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/andrewheberle/ubolt"
+)
+
+const (
+	exitOK       = 0
+	exitError    = 1
+	exitUsage    = 2
+	exitNotFound = 3
+)
+
+// errUsage is returned when the command line is invalid.
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command line in args and returns the exit status.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, "usage: ubolt <buckets|keys|get|put|delete|stats|export> [flags] <file> [arguments]")
+		return exitUsage
+	}
+
+	c := &command{name: args[0], stdout: stdout}
+
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.BoolVar(&c.write, "write", false, "open the database for writing")
+	fs.StringVar(&c.keyFormat, "key-format", "string", "format of keys: string, hex or base64")
+	fs.StringVar(&c.valueFormat, "value-format", "string", "format of values: string, hex or base64")
+	fs.StringVar(&c.prefix, "prefix", "", "only list keys beginning with this prefix")
+	fs.BoolVar(&c.raw, "raw", false, "print the value without formatting")
+	fs.BoolVar(&c.gob, "gob", false, "decode the value as a gob encoded basic type")
+	fs.BoolVar(&c.json, "json", false, "print the value as indented JSON")
+	fs.StringVar(&c.format, "format", "json", "export format: json or csv")
+
+	positional, err := parseInterspersed(fs, args[1:])
+	if err != nil {
+		return exitUsage
+	}
+
+	if err := c.run(positional); err != nil {
+		fmt.Fprintf(stderr, "ubolt %s: %v\n", c.name, err)
+
+		switch {
+		case errors.Is(err, errUsage):
+			return exitUsage
+		case errors.Is(err, ubolt.ErrKeyNotFound{}), errors.Is(err, ubolt.ErrBucketNotFound{}):
+			return exitNotFound
+		}
+
+		return exitError
+	}
+
+	return exitOK
+}
+
+// parseInterspersed parses flags that may appear before, between or after the positional arguments, which are returned.
+func parseInterspersed(fs *flag.FlagSet, args []string) (positional []string, err error) {
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+type command struct {
+	name   string
+	stdout io.Writer
+
+	write       bool
+	keyFormat   string
+	valueFormat string
+	prefix      string
+	raw         bool
+	gob         bool
+	json        bool
+	format      string
+}
+
+func (c *command) run(args []string) error {
+	want := map[string]int{"buckets": 1, "keys": 2, "get": 3, "put": 4, "delete": 3, "stats": 1, "export": 2}
+
+	n, ok := want[c.name]
+	if !ok {
+		return fmt.Errorf("%w: unknown command %q", errUsage, c.name)
+	}
+
+	if len(args) != n {
+		return fmt.Errorf("%w: expected %d arguments but got %d", errUsage, n, len(args))
+	}
+
+	if (c.name == "put" || c.name == "delete") && !c.write {
+		return fmt.Errorf("%w: %s requires --write", errUsage, c.name)
+	}
+
+	opts := []ubolt.Option{ubolt.WithTimeout(time.Second)}
+	if !c.write {
+		opts = append(opts, ubolt.WithReadOnly(), ubolt.WithNoCreate())
+	}
+
+	db, err := ubolt.Open(args[0], opts...)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch c.name {
+	case "buckets":
+		return c.buckets(db)
+	case "keys":
+		return c.keys(db, []byte(args[1]))
+	case "stats":
+		return c.stats(db)
+	case "export":
+		return c.export(db, []byte(args[1]))
+	}
+
+	key, err := decodeBytes(c.keyFormat, args[2])
+	if err != nil {
+		return err
+	}
+
+	switch c.name {
+	case "get":
+		return c.get(db, []byte(args[1]), key)
+	case "put":
+		value, err := decodeBytes(c.valueFormat, args[3])
+		if err != nil {
+			return err
+		}
+
+		return db.Put([]byte(args[1]), key, value)
+	}
+
+	// delete reports missing keys so scripts can tell nothing was removed
+	if _, err := db.GetE([]byte(args[1]), key); err != nil {
+		return err
+	}
+
+	return db.Delete([]byte(args[1]), key)
+}
+
+func (c *command) buckets(db *ubolt.Database) error {
+	buckets, err := db.GetBucketsE()
+	if err != nil {
+		return err
+	}
+
+	for _, b := range buckets {
+		fmt.Fprintln(c.stdout, string(b))
+	}
+
+	return nil
+}
+
+func (c *command) keys(db *ubolt.Database, bucket []byte) error {
+	prefix, err := decodeBytes(c.keyFormat, c.prefix)
+	if err != nil {
+		return err
+	}
+
+	return db.Scan(bucket, prefix, func(k, v []byte) error {
+		_, err := fmt.Fprintln(c.stdout, encodeBytes(c.keyFormat, k))
+
+		return err
+	})
+}
+
+func (c *command) get(db *ubolt.Database, bucket, key []byte) error {
+	value, err := db.GetE(bucket, key)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case c.raw:
+		_, err = c.stdout.Write(value)
+	case c.gob:
+		var v interface{}
+		if v, err = decodeGob(db, value); err == nil {
+			_, err = fmt.Fprintf(c.stdout, "%v\n", v)
+		}
+	case c.json:
+		var info ubolt.EncodingInfo
+		if info, err = db.ValueInfo(bucket, key); err != nil {
+			return err
+		}
+
+		// values written by Encode begin with an encoding header
+		var v interface{}
+		if err = json.Unmarshal(value[len(value)-info.Size:], &v); err == nil {
+			enc := json.NewEncoder(c.stdout)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(v)
+		}
+	default:
+		_, err = fmt.Fprintln(c.stdout, encodeBytes(c.valueFormat, value))
+	}
+
+	return err
+}
+
+func (c *command) stats(db *ubolt.Database) error {
+	size, err := db.Size()
+	if err != nil {
+		return err
+	}
+
+	ps, err := db.PageStats()
+	if err != nil {
+		return err
+	}
+
+	overview, err := db.Overview()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "path: %s\nsize: %d\npage size: %d\nfree pages: %d\nfragmentation: %.2f\n", db.Path(), size, ps.PageSize, ps.FreePageN, ps.FragmentationRatio())
+
+	for _, b := range overview {
+		fmt.Fprintf(c.stdout, "bucket %s: keys=%d leaf=%d branch=%d nested=%t\n", b.Name, b.KeyCount, b.LeafBytes, b.BranchBytes, b.HasNested)
+	}
+
+	return nil
+}
+
+func (c *command) export(db *ubolt.Database, bucket []byte) error {
+	switch c.format {
+	case "json":
+		type entry struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		}
+
+		entries := []entry{}
+		if err := db.ForEach(bucket, func(k, v []byte) error {
+			// skip nested buckets
+			if v != nil {
+				entries = append(entries, entry{Key: encodeBytes(c.keyFormat, k), Value: encodeBytes(c.valueFormat, v)})
+			}
+
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(entries)
+	case "csv":
+		w := csv.NewWriter(c.stdout)
+
+		if err := w.Write([]string{"key", "value"}); err != nil {
+			return err
+		}
+
+		if err := db.ForEach(bucket, func(k, v []byte) error {
+			if v == nil {
+				return nil
+			}
+
+			return w.Write([]string{encodeBytes(c.keyFormat, k), encodeBytes(c.valueFormat, v)})
+		}); err != nil {
+			return err
+		}
+
+		w.Flush()
+
+		return w.Error()
+	}
+
+	return fmt.Errorf("%w: unknown export format %q", errUsage, c.format)
+}
+
+// decodeGob decodes a value written by Encode using the default gob codec. As gob streams do not describe Go types, only basic types and
+// simple collections of them can be decoded without the original type.
+func decodeGob(db *ubolt.Database, value []byte) (interface{}, error) {
+	candidates := []func() interface{}{
+		func() interface{} { return new(string) },
+		func() interface{} { return new(int64) },
+		func() interface{} { return new(uint64) },
+		func() interface{} { return new(float64) },
+		func() interface{} { return new(bool) },
+		func() interface{} { return new([]byte) },
+		func() interface{} { return new([]string) },
+		func() interface{} { return new([]int64) },
+		func() interface{} { return new(map[string]string) },
+		func() interface{} { return new(map[string]int64) },
+	}
+
+	for _, candidate := range candidates {
+		v := candidate()
+		if err := db.DecodeValue(value, v); err == nil {
+			return reflect.ValueOf(v).Elem().Interface(), nil
+		}
+	}
+
+	return nil, errors.New("value is not a gob encoded basic type, use --raw to print it unchanged")
+}
+
+// decodeBytes converts a command line argument in the chosen format to bytes.
+func decodeBytes(format, s string) ([]byte, error) {
+	switch format {
+	case "string":
+		return []byte(s), nil
+	case "hex":
+		return hex.DecodeString(s)
+	case "base64":
+		return base64.StdEncoding.DecodeString(s)
+	}
+
+	return nil, fmt.Errorf("%w: unknown format %q", errUsage, format)
+}
+
+// encodeBytes converts bytes to a string in the chosen format, with unknown formats treated as string.
+func encodeBytes(format string, b []byte) string {
+	switch format {
+	case "hex":
+		return hex.EncodeToString(b)
+	case "base64":
+		return base64.StdEncoding.EncodeToString(b)
+	}
+
+	return string(b)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/andrewheberle/ubolt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	db, err := ubolt.Open(path)
+	if err != nil {
+		panic(err)
+	}
+	assert.Nil(t, db.CreateBucket([]byte("users")), "CreateBucket")
+	assert.Nil(t, db.Put([]byte("users"), []byte("alice"), []byte("admin")), "Put")
+	assert.Nil(t, db.Encode([]byte("users"), []byte("count"), int64(42)), "Encode")
+	assert.Nil(t, db.Close(), "Close")
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+		out  string
+	}{
+		{"buckets", []string{"buckets", path}, exitOK, "users\n"},
+		{"keys", []string{"keys", path, "users"}, exitOK, "alice\ncount\n"},
+		{"keys prefix", []string{"keys", path, "users", "--prefix", "al"}, exitOK, "alice\n"},
+		{"get", []string{"get", path, "users", "alice"}, exitOK, "admin\n"},
+		{"get hex", []string{"get", "--value-format", "hex", path, "users", "alice"}, exitOK, "61646d696e\n"},
+		{"get gob", []string{"get", "--gob", path, "users", "count"}, exitOK, "42\n"},
+		{"get missing", []string{"get", path, "users", "bob"}, exitNotFound, ""},
+		{"get missing bucket", []string{"get", path, "groups", "bob"}, exitNotFound, ""},
+		{"put read-only", []string{"put", path, "users", "bob", "user"}, exitUsage, ""},
+		{"put", []string{"put", "--write", path, "users", "bob", "dXNlcg==", "--value-format", "base64"}, exitOK, ""},
+		{"get put", []string{"get", "--raw", path, "users", "bob"}, exitOK, "user"},
+		{"delete", []string{"delete", "--write", path, "users", "bob"}, exitOK, ""},
+		{"delete missing", []string{"delete", "--write", path, "users", "bob"}, exitNotFound, ""},
+		{"export csv", []string{"export", "--format", "csv", "--value-format", "hex", path, "users"}, exitOK, "key,value\nalice,61646d696e\n"},
+		{"unknown", []string{"frobnicate", path}, exitUsage, ""},
+		{"missing file", []string{"buckets", filepath.Join(t.TempDir(), "missing.db")}, exitError, ""},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+
+		code := run(tt.args, &stdout, &stderr)
+		assert.Equal(t, tt.code, code, tt.name+": "+stderr.String())
+
+		if tt.out != "" {
+			if tt.name == "export csv" {
+				// the row for the encoded count follows
+				assert.Contains(t, stdout.String(), tt.out, tt.name)
+				continue
+			}
+
+			assert.Equal(t, tt.out, stdout.String(), tt.name)
+		}
+	}
+}
+
+func TestExportCSVImport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	want := map[string][]byte{
+		"alice":       []byte("admin"),
+		"bin\x00\xff": {0x00, 0x01, 0xfe, 0xff},
+		"comma,quote": []byte("a \"quoted\",\nmulti-line value"),
+	}
+
+	db, err := ubolt.Open(path)
+	if err != nil {
+		panic(err)
+	}
+	assert.Nil(t, db.PutAllWithOptions([]byte("users"), want, ubolt.PutAllOptions{CreateBucket: true}), "PutAll")
+	assert.Nil(t, db.Close(), "Close")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"export", "--format", "csv", "--key-format", "base64", "--value-format", "hex", path, "users"}, &stdout, &stderr)
+	assert.Equal(t, exitOK, code, stderr.String())
+
+	// the export is read back by ImportCSV using the same formats
+	db, err = ubolt.Open(filepath.Join(t.TempDir(), "imported.db"))
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	n, err := db.ImportCSV(&stdout, []byte("users"), ubolt.WithCSVHeader(), ubolt.WithCSVFormat(ubolt.CSVBase64, ubolt.CSVHex))
+	assert.Nil(t, err, "ImportCSV")
+	assert.Equal(t, len(want), n, "ImportCSV")
+
+	for k, v := range want {
+		assert.Equal(t, v, db.Get([]byte("users"), []byte(k)), "ImportCSV - %q", k)
+	}
+}
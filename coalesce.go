@@ -0,0 +1,75 @@
+package ubolt
+
+import (
+	"errors"
+	"sync"
+)
+
+// WithReadCoalescing deduplicates concurrent GetE calls for the same bucket and key so only one read transaction is performed while the
+// remaining callers wait for its result. Every caller receives an independent copy of the value, and any error is returned to all waiters.
+//
+// Reads are never coalesced across a committed write, as the write generation of the database forms part of the key used to group callers.
+func WithReadCoalescing() Option {
+	return func(db *Database) {
+		db.flights = &flightGroup{calls: make(map[string]*flightCall)}
+	}
+}
+
+type flightCall struct {
+	wg    sync.WaitGroup
+	value []byte
+	err   error
+}
+
+// flightGroup ensures only one call for a given key is in progress at a time.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// errFlightPanic is returned to callers waiting on a call that panicked.
+var errFlightPanic = errors.New("concurrent call panicked")
+
+// do calls fn once for all concurrent callers using the same key, returning a copy of the result to each. If fn panics the panic is
+// propagated to its caller and the waiting callers receive an error. The zero flightGroup is ready to use.
+func (g *flightGroup) do(key string, fn func() ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+
+	c, ok := g.calls[key]
+	if !ok {
+		c = &flightCall{err: errFlightPanic}
+		c.wg.Add(1)
+		g.calls[key] = c
+		g.mu.Unlock()
+
+		defer func() {
+			g.mu.Lock()
+			delete(g.calls, key)
+			g.mu.Unlock()
+			c.wg.Done()
+		}()
+
+		c.value, c.err = fn()
+	} else {
+		g.mu.Unlock()
+		c.wg.Wait()
+	}
+
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return append([]byte{}, c.value...), nil
+}
+
+// flightKey builds a key unique to the bucket, key and current write generation.
+func (db *Database) flightKey(bucket, key []byte) string {
+	k := itob(db.generation.Load())
+	k = append(k, itob(uint64(len(bucket)))...)
+	k = append(k, bucket...)
+
+	return string(append(k, key...))
+}
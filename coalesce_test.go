@@ -0,0 +1,109 @@
+package ubolt
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlightGroup(t *testing.T) {
+	g := &flightGroup{calls: make(map[string]*flightCall)}
+
+	var calls atomic.Int32
+	var wg sync.WaitGroup
+	release := make(chan struct{})
+	results := make([][]byte, 10)
+
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = g.do("key", func() ([]byte, error) {
+				calls.Add(1)
+				<-release
+				return testvalue, nil
+			})
+		}(i)
+	}
+
+	// give all goroutines time to join the flight
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load(), "do - calls")
+	for i := range results {
+		assert.Equal(t, testvalue, results[i], "do - result")
+	}
+
+	// each caller must get an independent slice
+	results[0][0] = 'X'
+	assert.Equal(t, testvalue, results[1], "do - independent copies")
+
+	// errors propagate
+	_, err := g.do("key", func() ([]byte, error) { return nil, errors.New("failed") })
+	assert.NotNil(t, err, "do - error")
+
+	// a panic is propagated to its caller and waiters receive an error
+	var zero flightGroup
+	started := make(chan struct{})
+	waiter := make(chan error)
+
+	go func() {
+		<-started
+		_, err := zero.do("key", func() ([]byte, error) { return testvalue, nil })
+		waiter <- err
+	}()
+
+	assert.Panics(t, func() {
+		_, _ = zero.do("key", func() ([]byte, error) {
+			close(started)
+			time.Sleep(50 * time.Millisecond)
+			panic("failed")
+		})
+	}, "do - panic")
+	assert.ErrorIs(t, <-waiter, errFlightPanic, "do - panic waiter")
+}
+
+func TestReadCoalescing(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket, WithReadCoalescing())
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	_, err = b.GetE(testkey)
+	assert.ErrorIs(t, err, ErrKeyNotFound{}, "GetE - missing key")
+
+	if err := b.Put(testkey, testvalue); err != nil {
+		panic(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := b.GetE(testkey)
+			assert.Nil(t, err, "GetE - concurrent")
+			assert.Equal(t, testvalue, got, "GetE - concurrent")
+		}()
+	}
+	wg.Wait()
+
+	// the write generation changes the flight key
+	before := b.db.flightKey(testbucket, testkey)
+	if err := b.Put(testkey, []byte("value2")); err != nil {
+		panic(err)
+	}
+	assert.NotEqual(t, before, b.db.flightKey(testbucket, testkey), "flightKey - after write")
+	assert.Equal(t, []byte("value2"), b.Get(testkey), "Get - after write")
+}
@@ -0,0 +1,154 @@
+package ubolt
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"sync"
+)
+
+// Codec converts values to and from the byte slices stored by Encode and read by Decode.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// ErrCodecNotRegistered is returned when encoding or decoding using MsgpackCodec or CBORCodec, or decoding a value that records their
+// CodecID, without the package that provides the codec being imported.
+type ErrCodecNotRegistered struct {
+	codec CodecID
+}
+
+// Error returns the formatted configuration error.
+func (nr ErrCodecNotRegistered) Error() string {
+	return fmt.Sprintf("Codec %s is not registered, import the package that provides it", nr.codec)
+}
+
+// Is allows testing using errors.Is
+func (nr ErrCodecNotRegistered) Is(target error) bool {
+	_, is := target.(ErrCodecNotRegistered)
+
+	return is
+}
+
+// GobCodec encodes values using "encoding/gob" and is the default Codec.
+var GobCodec Codec = gobCodec{}
+
+type gobCodec struct{}
+
+func (gobCodec) CodecID() CodecID {
+	return CodecGob
+}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// MsgpackCodec encodes values using MessagePack so they may be read by other languages. It is provided by the msgpack package, which must
+// be imported for it to be used, otherwise ErrCodecNotRegistered is returned.
+var MsgpackCodec Codec = codecRef{CodecMsgpack}
+
+// CBORCodec encodes values using CBOR (RFC 8949) so they may be read by other languages. It is provided by the cbor package, which must be
+// imported for it to be used, otherwise ErrCodecNotRegistered is returned.
+var CBORCodec Codec = codecRef{CodecCBOR}
+
+// codecRef forwards to the codec registered for its CodecID, so the codecs provided by other packages may be named without importing them.
+type codecRef struct {
+	id CodecID
+}
+
+func (c codecRef) CodecID() CodecID {
+	return c.id
+}
+
+func (c codecRef) Marshal(v interface{}) ([]byte, error) {
+	codec, ok := registeredCodec(c.id)
+	if !ok {
+		return nil, ErrCodecNotRegistered{c.id}
+	}
+
+	return codec.Marshal(v)
+}
+
+func (c codecRef) Unmarshal(data []byte, v interface{}) error {
+	codec, ok := registeredCodec(c.id)
+	if !ok {
+		return ErrCodecNotRegistered{c.id}
+	}
+
+	return codec.Unmarshal(data, v)
+}
+
+var (
+	codecsMu sync.RWMutex
+	// codecs are the codecs that can decode values that identify them, whichever Codec is configured.
+	codecs = map[CodecID]Codec{CodecGob: GobCodec}
+)
+
+// RegisterCodec allows values that record the CodecID of codec to be decoded whichever Codec is configured, and makes codec available
+// through MsgpackCodec or CBORCodec when it uses their CodecID. The msgpack and cbor packages call RegisterCodec when they are imported.
+// Registering a CodecID again replaces the codec registered previously, while registering CodecCustom has no effect.
+func RegisterCodec(codec IdentifiedCodec) {
+	id := codec.CodecID()
+	if _, ref := codec.(codecRef); ref || id == CodecCustom {
+		return
+	}
+
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+
+	codecs[id] = codec
+}
+
+// registeredCodec returns the codec registered for id.
+func registeredCodec(id CodecID) (Codec, bool) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	c, ok := codecs[id]
+
+	return c, ok
+}
+
+// WithCodec sets the Codec used by Encode, Decode and the other methods that encode values, which defaults to GobCodec.
+//
+// Encode records the codec used in a header before each value. Values written by GobCodec or any other IdentifiedCodec that is registered
+// using RegisterCodec, such as MsgpackCodec or CBORCodec once their package is imported, can be decoded after the configured Codec changes,
+// however values written by a custom Codec that does not implement IdentifiedCodec can only be decoded by the configured Codec.
+func WithCodec(codec Codec) Option {
+	return func(db *Database) {
+		db.codec = codec
+	}
+}
+
+// Codec returns the Codec used to encode and decode values.
+func (db *Database) Codec() Codec {
+	return db.codec
+}
+
+// Codec returns the Codec used to encode and decode values.
+func (b *Bucket) Codec() Codec {
+	return b.db.Codec()
+}
+
+// WithMsgpack sets the Codec used to encode values to MsgpackCodec. Values written by other registered codecs remain readable. The msgpack
+// package must be imported for MsgpackCodec to be used.
+func WithMsgpack() Option {
+	return WithCodec(MsgpackCodec)
+}
+
+// WithCBOR sets the Codec used to encode values to CBORCodec. Values written by other registered codecs remain readable. The cbor package
+// must be imported for CBORCodec to be used.
+func WithCBOR() Option {
+	return WithCodec(CBORCodec)
+}
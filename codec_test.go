@@ -0,0 +1,192 @@
+package ubolt_test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/andrewheberle/ubolt"
+	_ "github.com/andrewheberle/ubolt/cbor"
+	_ "github.com/andrewheberle/ubolt/msgpack"
+	"github.com/stretchr/testify/assert"
+)
+
+const testcodecdb = "codec.db"
+
+type profile struct {
+	Name string
+	Age  int
+}
+
+type codecRecord struct {
+	Name     string
+	Count    int64
+	Ratio    float64
+	Tags     []string
+	Labels   map[string]int
+	Created  time.Time
+	Children []profile
+	Parent   *profile
+}
+
+func TestBuiltinCodecs(t *testing.T) {
+	created := time.Date(2024, 2, 29, 12, 30, 45, 123456789, time.UTC)
+
+	corpus := map[string]interface{}{
+		"string": "hello",
+		"int":    -42,
+		"slice":  []string{"a", "b", "c"},
+		"map":    map[string]int{"one": 1, "two": 2},
+		"time":   created,
+		"struct": codecRecord{
+			Name:     "record",
+			Count:    1 << 40,
+			Ratio:    0.25,
+			Tags:     []string{"x", "y"},
+			Labels:   map[string]int{"env": 3},
+			Created:  created,
+			Children: []profile{{Name: "bob", Age: 4}},
+			Parent:   &profile{Name: "alice", Age: 30},
+		},
+	}
+
+	// decode reads key into a new value of the same type as its corpus entry
+	decode := func(b *ubolt.Bucket, key string) (interface{}, error) {
+		var err error
+
+		switch corpus[key].(type) {
+		case string:
+			var v string
+			err = b.Decode([]byte(key), &v)
+			return v, err
+		case int:
+			var v int
+			err = b.Decode([]byte(key), &v)
+			return v, err
+		case []string:
+			var v []string
+			err = b.Decode([]byte(key), &v)
+			return v, err
+		case map[string]int:
+			var v map[string]int
+			err = b.Decode([]byte(key), &v)
+			return v, err
+		case time.Time:
+			var v time.Time
+			err = b.Decode([]byte(key), &v)
+			return v.UTC(), err
+		}
+
+		// msgpack decodes times in the local time zone
+		var v codecRecord
+		err = b.Decode([]byte(key), &v)
+		v.Created = v.Created.UTC()
+
+		return v, err
+	}
+
+	tests := []struct {
+		name string
+		opt  ubolt.Option
+		id   ubolt.CodecID
+	}{
+		{"gob", ubolt.WithCodec(ubolt.GobCodec), ubolt.CodecGob},
+		{"msgpack", ubolt.WithMsgpack(), ubolt.CodecMsgpack},
+		{"cbor", ubolt.WithCBOR(), ubolt.CodecCBOR},
+	}
+
+	_ = os.Remove(testcodecdb)
+	defer os.Remove(testcodecdb)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ubolt.OpenBucket(testcodecdb, []byte(tt.name), tt.opt)
+			if err != nil {
+				panic(err)
+			}
+			defer b.Close()
+
+			assert.Equal(t, tt.name, tt.id.String(), "CodecID.String")
+
+			for key, value := range corpus {
+				assert.Nil(t, b.Encode([]byte(key), value), "Encode %s", key)
+
+				info, err := b.ValueInfo([]byte(key))
+				assert.Nil(t, err, "ValueInfo %s", key)
+				assert.Equal(t, tt.id, info.Codec, "ValueInfo %s", key)
+
+				got, err := decode(b, key)
+				assert.Nil(t, err, "Decode %s", key)
+				assert.Equal(t, value, got, "Decode %s", key)
+			}
+		})
+	}
+
+	// every bucket remains readable value by value whichever codec is configured
+	for _, tt := range tests {
+		t.Run("mixed "+tt.name, func(t *testing.T) {
+			db, err := ubolt.Open(testcodecdb, tt.opt)
+			if err != nil {
+				panic(err)
+			}
+			defer db.Close()
+
+			for _, written := range tests {
+				b, err := db.Bucket([]byte(written.name))
+				assert.Nil(t, err, "Bucket %s", written.name)
+
+				for key, value := range corpus {
+					got, err := decode(b, key)
+					assert.Nil(t, err, "Decode %s written by %s", key, written.name)
+					assert.Equal(t, value, got, "Decode %s written by %s", key, written.name)
+				}
+			}
+		})
+	}
+}
+
+// jsonCodec is an IdentifiedCodec that is not built in.
+type jsonCodec struct{}
+
+func (jsonCodec) CodecID() ubolt.CodecID {
+	return 200
+}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func TestRegisterCodec(t *testing.T) {
+	_ = os.Remove(testcodecdb)
+	defer os.Remove(testcodecdb)
+
+	bucket, key := []byte("json"), []byte("key")
+
+	b, err := ubolt.OpenBucket(testcodecdb, bucket, ubolt.WithCodec(jsonCodec{}))
+	if err != nil {
+		panic(err)
+	}
+
+	assert.Nil(t, b.Encode(key, profile{Name: "alice", Age: 30}), "Encode")
+	assert.Nil(t, b.Close(), "Close")
+
+	b, err = ubolt.OpenBucket(testcodecdb, bucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	// values of a codec that is not registered can only be decoded once it is configured
+	var got profile
+	assert.ErrorIs(t, b.Decode(key, &got), ubolt.ErrUnsupportedEncoding{}, "Decode - not registered")
+
+	ubolt.RegisterCodec(jsonCodec{})
+
+	assert.Nil(t, b.Decode(key, &got), "Decode - registered")
+	assert.Equal(t, profile{Name: "alice", Age: 30}, got, "Decode - registered")
+}
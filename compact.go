@@ -0,0 +1,281 @@
+package ubolt
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrCompactReopen is returned by CompactInPlace when the database file was replaced by its compacted copy but could not be reopened. The
+// database is closed, so later operations return ErrDatabaseClosed rather than writing to the replaced file, and the compacted file may be
+// opened again once the cause has been resolved.
+type ErrCompactReopen struct {
+	path string
+	err  error
+}
+
+// Error returns the formatted configuration error.
+func (cr ErrCompactReopen) Error() string {
+	return fmt.Sprintf("Compacted database %s could not be reopened and has been closed: %v", cr.path, cr.err)
+}
+
+// Is allows testing using errors.Is
+func (cr ErrCompactReopen) Is(target error) bool {
+	_, is := target.(ErrCompactReopen)
+
+	return is
+}
+
+// Unwrap returns the error that prevented the database from being reopened.
+func (cr ErrCompactReopen) Unwrap() error {
+	return cr.err
+}
+
+// CompactFunc is called after every compaction made by WithAutoCompact with the size of the database file before and after, or the error
+// that caused the compaction to fail.
+type CompactFunc func(before, after int64, err error)
+
+// autoCompact holds the state of the background compaction started by WithAutoCompact.
+type autoCompact struct {
+	interval  time.Duration
+	threshold float64
+	fn        CompactFunc
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	// inCallback is set while fn is called, so Close called from fn does not wait for the goroutine that called it
+	inCallback atomic.Bool
+	wg         sync.WaitGroup
+}
+
+// WithAutoCompact checks the FragmentationRatio reported by PageStats every checkInterval and runs CompactInPlace once it reaches
+// fragmentationThreshold. The option has no effect on a read-only database.
+func WithAutoCompact(checkInterval time.Duration, fragmentationThreshold float64) Option {
+	return func(db *Database) {
+		if db.autoCompact == nil {
+			db.autoCompact = &autoCompact{}
+		}
+
+		db.autoCompact.interval = checkInterval
+		db.autoCompact.threshold = fragmentationThreshold
+	}
+}
+
+// WithCompactCallback sets a function that is called after every compaction made by WithAutoCompact, which allows success or failure to be
+// logged or reported. The function may call Close, in which case no further compactions are started once it returns.
+func WithCompactCallback(fn CompactFunc) Option {
+	return func(db *Database) {
+		if db.autoCompact == nil {
+			db.autoCompact = &autoCompact{}
+		}
+
+		db.autoCompact.fn = fn
+	}
+}
+
+// CompactInPlace rewrites the database into a new file without any free pages then replaces the database file with it and reopens the
+// handle, returning the size of the file before and after.
+//
+// The database is frozen while the compaction runs, so writes either wait until it completes or fail with ErrFrozen if WithFailWhenFrozen
+// was used. Reads continue to be served throughout. If the compaction fails the original database file is left in place. If the compacted
+// file replaces the original but can not be reopened, ErrCompactReopen is returned and the database is closed.
+func (db *Database) CompactInPlace() (before, after int64, err error) {
+	return db.CompactInPlaceWithProgress(nil)
+}
+
+// CompactInPlaceWithProgress performs the same process as CompactInPlace while reporting the number of keys copied into the new file to
+// progress. The total is reported as -1.
+func (db *Database) CompactInPlaceWithProgress(progress ProgressFunc) (before, after int64, err error) {
+	if db.IsReadOnly() {
+		return 0, 0, ErrReadOnly{bolt.ErrDatabaseReadOnly}
+	}
+
+	db.compactMu.Lock()
+	defer db.compactMu.Unlock()
+
+	if db.closed.Load() {
+		return 0, 0, ErrDatabaseClosed{}
+	}
+
+	thaw, err := db.Freeze()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer thaw()
+
+	old := db.bdb()
+	path := old.Path()
+	tmp := path + ".compact"
+
+	if before, err = db.Size(); err != nil {
+		return 0, 0, err
+	}
+
+	_ = db.fs.Remove(tmp)
+
+	p := newProgress(progress, -1)
+
+	if err := compactTo(old, tmp, db.boltOptions, p); err != nil {
+		_ = db.fs.Remove(tmp)
+		return 0, 0, err
+	}
+
+	// the old handle continues to serve reads from the replaced file until it is closed
+	if err := db.fs.Rename(tmp, path); err != nil {
+		_ = db.fs.Remove(tmp)
+		return 0, 0, err
+	}
+
+	bdb, err := bolt.Open(path, 0600, &db.boltOptions)
+	if err != nil {
+		// writes using the old handle would be lost once it is closed as its file has been replaced, so close the database instead
+		_ = old.Close()
+		db.closed.Store(true)
+
+		return 0, 0, ErrCompactReopen{path: path, err: err}
+	}
+	bdb.StrictMode = db.strictMode
+	bdb.AllocSize = old.AllocSize
+
+	db.handle.Store(bdb)
+
+	// Close waits for any reads still using the old handle
+	if err := old.Close(); err != nil {
+		return 0, 0, err
+	}
+
+	db.generation.Add(1)
+	db.lastCompaction.Store(time.Now().UnixNano())
+
+	if after, err = db.Size(); err != nil {
+		return 0, 0, err
+	}
+
+	p.finish()
+
+	return before, after, nil
+}
+
+// CompactInPlace rewrites the database into a new file without any free pages then replaces the database file with it. This is forwarded
+// to the Database implementation.
+func (b *Bucket) CompactInPlace() (before, after int64, err error) {
+	return b.db.CompactInPlace()
+}
+
+// compactTo copies the contents of src into a new database at path, recording each key copied with p.
+func compactTo(src *bolt.DB, path string, opts bolt.Options, p *progressReporter) error {
+	opts.ReadOnly = false
+	opts.InitialMmapSize = 0
+
+	dst, err := bolt.Open(path, 0600, &opts)
+	if err != nil {
+		return err
+	}
+
+	if err := src.View(func(stx *bolt.Tx) error {
+		return dst.Update(func(dtx *bolt.Tx) error {
+			return stx.ForEach(func(name []byte, b *bolt.Bucket) error {
+				nb, err := dtx.CreateBucket(name)
+				if err != nil {
+					return err
+				}
+
+				return compactBucket(b, nb, p)
+			})
+		})
+	}); err != nil {
+		_ = dst.Close()
+		return err
+	}
+
+	return dst.Close()
+}
+
+// compactBucket copies the sequence, keys and nested buckets of src into dst with pages filled completely, as per bolt.Compact.
+func compactBucket(src, dst *bolt.Bucket, p *progressReporter) error {
+	dst.FillPercent = 1.0
+
+	if err := dst.SetSequence(src.Sequence()); err != nil {
+		return err
+	}
+
+	return src.ForEach(func(k, v []byte) error {
+		if v == nil {
+			nb, err := dst.CreateBucket(k)
+			if err != nil {
+				return err
+			}
+
+			return compactBucket(src.Bucket(k), nb, p)
+		}
+
+		p.add(1)
+
+		return dst.Put(k, v)
+	})
+}
+
+// startAutoCompact starts the background compaction enabled by WithAutoCompact.
+func (db *Database) startAutoCompact() {
+	ac := db.autoCompact
+	if ac == nil || ac.interval <= 0 || db.IsReadOnly() {
+		return
+	}
+
+	ac.stop = make(chan struct{})
+	ac.wg.Add(1)
+
+	go func() {
+		defer ac.wg.Done()
+
+		t := time.NewTicker(ac.interval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-ac.stop:
+				return
+			case <-t.C:
+				// the ticker may fire alongside stop, which must take priority
+				select {
+				case <-ac.stop:
+					return
+				default:
+				}
+
+				ps, err := db.PageStats()
+				if err != nil || ps.FragmentationRatio() < ac.threshold {
+					continue
+				}
+
+				before, after, err := db.CompactInPlace()
+				if ac.fn != nil {
+					ac.inCallback.Store(true)
+					ac.fn(before, after, err)
+					ac.inCallback.Store(false)
+				}
+			}
+		}
+	}()
+}
+
+// stopAutoCompact stops the background compaction and waits for any compaction in progress to complete. It may be called more than once
+// and concurrently. While the CompactFunc is running it does not wait, so Close may be called from the CompactFunc, as the compaction has
+// completed and the goroutine that called the CompactFunc exits once it returns.
+func (db *Database) stopAutoCompact() {
+	ac := db.autoCompact
+	if ac == nil || ac.stop == nil {
+		return
+	}
+
+	ac.stopOnce.Do(func() {
+		close(ac.stop)
+	})
+
+	if !ac.inCallback.Load() {
+		ac.wg.Wait()
+	}
+}
@@ -0,0 +1,201 @@
+package ubolt
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/andrewheberle/ubolt/ubolttest"
+)
+
+// fragment writes then deletes enough data to leave most of the database in free pages.
+func fragment(t *testing.T, b *Bucket) {
+	value := make([]byte, 1024)
+	for i := 0; i < 2000; i++ {
+		assert.Nil(t, b.Put([]byte(fmt.Sprintf("key-%05d", i)), value), "Put")
+	}
+	for i := 0; i < 1990; i++ {
+		assert.Nil(t, b.Delete([]byte(fmt.Sprintf("key-%05d", i))), "Delete")
+	}
+}
+
+func TestCompactInPlace(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	fragment(t, b)
+
+	before, after, err := b.CompactInPlace()
+	assert.Nil(t, err, "CompactInPlace")
+	assert.Less(t, after, before, "CompactInPlace - size")
+
+	size, err := b.Size()
+	assert.Nil(t, err, "Size")
+	assert.Equal(t, after, size, "Size")
+
+	keys, err := b.GetKeysE()
+	assert.Nil(t, err, "GetKeysE")
+	assert.Len(t, keys, 10, "GetKeysE")
+
+	// writes made while compacting wait until the swap is complete
+	thaw, err := b.Freeze()
+	assert.Nil(t, err, "Freeze")
+
+	done := make(chan error)
+	go func() {
+		_, _, err := b.CompactInPlace()
+		done <- err
+	}()
+	go func() {
+		done <- b.Put(testkey, testvalue)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	thaw()
+
+	assert.Nil(t, <-done, "CompactInPlace/Put - concurrent")
+	assert.Nil(t, <-done, "CompactInPlace/Put - concurrent")
+	assert.Equal(t, testvalue, b.Get(testkey), "Get - after compaction")
+
+	assert.Nil(t, b.Close(), "Close")
+
+	_, _, err = b.CompactInPlace()
+	assert.ErrorIs(t, err, ErrDatabaseClosed{}, "CompactInPlace - closed")
+}
+
+func TestCompactInPlaceReopenFails(t *testing.T) {
+	fsys := ubolttest.NewFaultyFS()
+	defer fsys.Close()
+
+	b, err := OpenBucket(testdb, testbucket, WithFS(fsys))
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.Nil(t, b.Put(testkey, testvalue), "Put")
+
+	// the first open creates the compacted file and the second reopens it once renamed
+	fsys.FailOpenFile(2, syscall.EMFILE)
+
+	_, _, err = b.CompactInPlace()
+	assert.ErrorIs(t, err, ErrCompactReopen{}, "CompactInPlace - reopen fails")
+	assert.ErrorIs(t, err, syscall.EMFILE, "CompactInPlace - reopen fails cause")
+
+	// nothing may be written to the replaced file
+	assert.ErrorIs(t, b.Put([]byte("lost"), testvalue), ErrDatabaseClosed{}, "Put - after failed reopen")
+	_, err = b.GetE(testkey)
+	assert.ErrorIs(t, err, ErrDatabaseClosed{}, "GetE - after failed reopen")
+	assert.Nil(t, b.Close(), "Close - after failed reopen")
+
+	// the compacted file holds every committed write
+	b, err = OpenBucket(testdb, testbucket, WithFS(fsys), WithNoCreate())
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.Equal(t, testvalue, b.Get(testkey), "Get - reopened")
+	assert.False(t, b.Exists([]byte("lost")), "Exists - reopened")
+}
+
+func TestAutoCompact(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	type result struct {
+		before, after int64
+		err           error
+	}
+
+	results := make(chan result, 10)
+
+	b, err := OpenBucket(testdb, testbucket,
+		WithAutoCompact(20*time.Millisecond, 0.5),
+		WithCompactCallback(func(before, after int64, err error) {
+			results <- result{before, after, err}
+		}),
+	)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	fragment(t, b)
+
+	select {
+	case r := <-results:
+		assert.Nil(t, r.err, "auto compact")
+		assert.Less(t, r.after, r.before, "auto compact - size")
+	case <-time.After(5 * time.Second):
+		t.Fatal("auto compact did not run")
+	}
+
+	assert.Equal(t, 10, len(b.GetKeys()), "GetKeys - after auto compact")
+	assert.Nil(t, b.Close(), "Close")
+}
+
+func TestAutoCompactClose(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	// concurrent calls to Close stop the compaction once
+	b, err := OpenBucket(testdb, testbucket, WithAutoCompact(time.Hour, 0.5))
+	if err != nil {
+		panic(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_ = b.Close()
+		}()
+	}
+	wg.Wait()
+
+	b, err = OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+
+	fragment(t, b)
+	assert.Nil(t, b.Close(), "Close")
+
+	// Close called from the callback does not wait for itself
+	opened, closed := make(chan *Bucket, 1), make(chan error, 1)
+
+	b, err = OpenBucket(testdb, testbucket,
+		WithAutoCompact(20*time.Millisecond, 0.5),
+		WithCompactCallback(func(before, after int64, err error) {
+			closed <- (<-opened).Close()
+		}),
+	)
+	if err != nil {
+		panic(err)
+	}
+	opened <- b
+
+	select {
+	case err := <-closed:
+		assert.Nil(t, err, "Close - from callback")
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close from callback did not return")
+	}
+
+	assert.Nil(t, b.Close(), "Close - again")
+}
@@ -0,0 +1,98 @@
+package ubolt
+
+import (
+	"fmt"
+)
+
+// ConflictPolicy decides the value written when an import-style operation encounters a key that already exists. It is called inside the
+// write transaction of the import with the existing and incoming values, so its decision is atomic with the write. A nested bucket is
+// passed as its full path, as built by BucketPath.
+//
+// The returned value is written to the key, unless it is nil in which case the existing value is left unchanged. Returning an error aborts
+// the import.
+type ConflictPolicy func(bucket, key, existing, incoming []byte) ([]byte, error)
+
+// ErrConflict is returned by import-style operations using ErrorOnConflict when a key already exists.
+type ErrConflict struct {
+	bucket []byte
+	key    []byte
+}
+
+// Error returns the formatted configuration error.
+func (c ErrConflict) Error() string {
+	return fmt.Sprintf("Key %s already exists in bucket %s", string(c.key), bucketName(c.bucket))
+}
+
+// Is allows testing using errors.Is
+func (c ErrConflict) Is(target error) bool {
+	_, is := target.(ErrConflict)
+
+	return is
+}
+
+var (
+	// OverwriteOnConflict replaces the existing value with the incoming value. This is the default policy.
+	OverwriteOnConflict ConflictPolicy = func(bucket, key, existing, incoming []byte) ([]byte, error) {
+		return incoming, nil
+	}
+
+	// SkipOnConflict keeps the existing value.
+	SkipOnConflict ConflictPolicy = func(bucket, key, existing, incoming []byte) ([]byte, error) {
+		return nil, nil
+	}
+
+	// ErrorOnConflict aborts with ErrConflict, which reports the bucket and key of the conflict.
+	ErrorOnConflict ConflictPolicy = func(bucket, key, existing, incoming []byte) ([]byte, error) {
+		return nil, ErrConflict{bucket: bucket, key: key}
+	}
+)
+
+// ImportOption is used to change the behaviour of import-style operations such as ImportArchive.
+type ImportOption func(*importOptions)
+
+type importOptions struct {
+	conflict ConflictPolicy
+	progress ProgressFunc
+}
+
+// WithConflictPolicy sets the policy used when an imported key already exists. The default is OverwriteOnConflict.
+func WithConflictPolicy(policy ConflictPolicy) ImportOption {
+	return func(o *importOptions) {
+		o.conflict = policy
+	}
+}
+
+// WithImportProgress reports the number of keys imported to progress as the import runs. The total is reported as -1.
+func WithImportProgress(progress ProgressFunc) ImportOption {
+	return func(o *importOptions) {
+		o.progress = progress
+	}
+}
+
+func newImportOptions(opts []ImportOption) importOptions {
+	o := importOptions{conflict: OverwriteOnConflict}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.conflict == nil {
+		o.conflict = OverwriteOnConflict
+	}
+
+	return o
+}
+
+// resolve returns the value to write for key and whether it should be written at all, consulting the conflict policy if a value already exists.
+func (o importOptions) resolve(bucket, key, existing, incoming []byte) ([]byte, bool, error) {
+	if existing == nil {
+		return incoming, true, nil
+	}
+
+	value, err := o.conflict(bucket, key, existing, incoming)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value, value != nil, nil
+}
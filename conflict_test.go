@@ -0,0 +1,143 @@
+package ubolt
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConflictPolicy(t *testing.T) {
+	_ = os.Remove(testdb)
+	_ = os.Remove(testbackup)
+	defer os.Remove(testdb)
+	defer os.Remove(testbackup)
+
+	src, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer src.Close()
+
+	if err := src.PutAll(map[string][]byte{"key1": []byte("old"), "key2": []byte("new")}); err != nil {
+		panic(err)
+	}
+
+	var archive bytes.Buffer
+	if err := src.ExportArchive(&archive); err != nil {
+		panic(err)
+	}
+
+	dst, err := OpenBucket(testbackup, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer dst.Close()
+
+	tests := []struct {
+		name    string
+		opts    []ImportOption
+		want    string
+		wantErr error
+	}{
+		{"ImportArchive - default", nil, "old", nil},
+		{"ImportArchive - overwrite", []ImportOption{WithConflictPolicy(OverwriteOnConflict)}, "old", nil},
+		{"ImportArchive - skip", []ImportOption{WithConflictPolicy(SkipOnConflict)}, "newer", nil},
+		{"ImportArchive - error", []ImportOption{WithConflictPolicy(ErrorOnConflict)}, "newer", ErrConflict{}},
+		{"ImportArchive - merge", []ImportOption{WithConflictPolicy(func(bucket, key, existing, incoming []byte) ([]byte, error) {
+			assert.Equal(t, testbucket, bucket, "ConflictPolicy - bucket")
+			assert.Equal(t, []byte("key1"), key, "ConflictPolicy - key")
+			return append(append([]byte{}, existing...), incoming...), nil
+		})}, "newerold", nil},
+	}
+
+	for _, tt := range tests {
+		// reset destination
+		if err := dst.db.DeleteBucket(testbucket); err != nil {
+			panic(err)
+		}
+
+		if err := dst.db.CreateBucket(testbucket); err != nil {
+			panic(err)
+		}
+
+		if err := dst.Put([]byte("key1"), []byte("newer")); err != nil {
+			panic(err)
+		}
+
+		err := dst.ImportArchive(bytes.NewReader(archive.Bytes()), tt.opts...)
+		if tt.wantErr != nil {
+			assert.ErrorIs(t, err, tt.wantErr, tt.name)
+			assert.Contains(t, err.Error(), "key1", tt.name)
+
+			// the failed transaction must be rolled back
+			assert.Nil(t, dst.Get([]byte("key2")), tt.name)
+		} else {
+			assert.Nil(t, err, tt.name)
+			assert.Equal(t, []byte("new"), dst.Get([]byte("key2")), tt.name)
+		}
+
+		assert.Equal(t, []byte(tt.want), dst.Get([]byte("key1")), tt.name)
+	}
+}
+
+func TestConflictPolicyNested(t *testing.T) {
+	_ = os.Remove(testdb)
+	_ = os.Remove(testbackup)
+	defer os.Remove(testdb)
+	defer os.Remove(testbackup)
+
+	src, err := Open(testdb)
+	if err != nil {
+		panic(err)
+	}
+	defer src.Close()
+
+	ax, bx := BucketPath([]byte("a"), []byte("x")), BucketPath([]byte("b"), []byte("x"))
+
+	for _, bucket := range [][]byte{ax, bx} {
+		if err := src.CreateBucket(bucket); err != nil {
+			panic(err)
+		}
+
+		if err := src.Put(bucket, testkey, []byte("old")); err != nil {
+			panic(err)
+		}
+	}
+
+	var archive bytes.Buffer
+	if err := src.ExportArchive(&archive); err != nil {
+		panic(err)
+	}
+
+	dst, err := Open(testbackup)
+	if err != nil {
+		panic(err)
+	}
+	defer dst.Close()
+
+	if err := dst.CreateBucket(bx); err != nil {
+		panic(err)
+	}
+
+	if err := dst.Put(bx, testkey, []byte("newer")); err != nil {
+		panic(err)
+	}
+
+	// the full path of the nested bucket is passed to the policy
+	var buckets [][]byte
+	err = dst.ImportArchive(bytes.NewReader(archive.Bytes()), WithConflictPolicy(func(bucket, key, existing, incoming []byte) ([]byte, error) {
+		buckets = append(buckets, append([]byte{}, bucket...))
+		return nil, nil
+	}))
+	assert.Nil(t, err, "ImportArchive - nested")
+	assert.Equal(t, [][]byte{bx}, buckets, "ConflictPolicy - nested bucket")
+	assert.Equal(t, []byte("old"), dst.Get(ax, testkey), "ImportArchive - nested written")
+	assert.Equal(t, []byte("newer"), dst.Get(bx, testkey), "ImportArchive - nested kept")
+
+	// ErrConflict names the full path of the nested bucket
+	err = dst.ImportArchive(bytes.NewReader(archive.Bytes()), WithConflictPolicy(ErrorOnConflict))
+	assert.ErrorIs(t, err, ErrConflict{}, "ImportArchive - nested error")
+	assert.Contains(t, err.Error(), "in bucket a/x", "ImportArchive - nested error bucket")
+}
@@ -0,0 +1,202 @@
+package ubolt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// updateContext wraps fn in a read/write transaction. Failures caused by a read-only database or filesystem are returned as ErrReadOnly.
+//
+// As bolt only allows a single writer at a time, the wait to begin the transaction is bounded by ctx and ctx.Err() is returned if it is done
+// before the transaction begins. Once the transaction has begun it runs to completion regardless of ctx.
+func (db *Database) updateContext(ctx context.Context, fn func(tx *bolt.Tx) error) error {
+	start := time.Now()
+
+	tx, err := db.beginWrite(ctx)
+	if err != nil {
+		return readOnlyError(err)
+	}
+	defer db.gate.RUnlock()
+
+	err = db.runWrite(tx, fn)
+	db.counters.writes.Add(1)
+	db.counters.writeNanos.Add(uint64(time.Since(start)))
+
+	// the write was committed even if it could not be mirrored
+	if err != nil && !errors.Is(err, ErrMirror{}) {
+		return readOnlyError(err)
+	}
+
+	db.generation.Add(1)
+
+	return err
+}
+
+// beginWrite acquires the write gate for reading and begins a read/write transaction. If ctx is done first the acquisition is abandoned and
+// the gate and transaction are released in the background once they are obtained.
+func (db *Database) beginWrite(ctx context.Context) (*bolt.Tx, error) {
+	if db.failWhenFrozen && !db.gate.TryRLock() {
+		return nil, ErrFrozen{}
+	}
+
+	begin := func() (*bolt.Tx, error) {
+		if !db.failWhenFrozen {
+			db.gate.RLock()
+		}
+
+		tx, err := db.bdb().Begin(true)
+		if err != nil {
+			db.gate.RUnlock()
+			return nil, db.closedError(err)
+		}
+
+		return tx, nil
+	}
+
+	// avoid the goroutine when there is no deadline
+	if ctx.Done() == nil {
+		return begin()
+	}
+
+	if err := ctx.Err(); err != nil {
+		if db.failWhenFrozen {
+			db.gate.RUnlock()
+		}
+
+		return nil, err
+	}
+
+	type result struct {
+		tx  *bolt.Tx
+		err error
+	}
+
+	ch := make(chan result, 1)
+	go func() {
+		tx, err := begin()
+		ch <- result{tx, err}
+	}()
+
+	select {
+	case r := <-ch:
+		return r.tx, r.err
+	case <-ctx.Done():
+		go func() {
+			if r := <-ch; r.err == nil {
+				_ = r.tx.Rollback()
+				db.gate.RUnlock()
+			}
+		}()
+
+		return nil, ctx.Err()
+	}
+}
+
+// runWrite calls fn with the transaction then commits it, or rolls it back if fn returns an error or panics.
+func (db *Database) runWrite(tx *bolt.Tx, fn func(tx *bolt.Tx) error) error {
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	db.checkPreallocate(tx)
+	db.mirrorPending = nil
+	db.preloadPending = nil
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	preloads := db.preloadPending
+	db.preloadPending = nil
+
+	if len(preloads) > 0 {
+		db.preloadMu.Lock()
+		defer db.preloadMu.Unlock()
+	}
+
+	err := db.commitMirrored(tx)
+	if err != nil && !errors.Is(err, ErrMirror{}) {
+		return err
+	}
+
+	applyPreload(preloads)
+
+	stats := tx.Stats()
+	db.lastWrite.Store(&stats)
+
+	return err
+}
+
+// ErrIterationCanceled is returned by ScanContext, ForEachContext and ForEachAllContext when ctx is done before iteration completes. It wraps
+// the error returned by ctx.Err(), so may be tested using errors.Is with context.Canceled or context.DeadlineExceeded.
+type ErrIterationCanceled struct {
+	bucket  []byte
+	key     []byte
+	visited int64
+	err     error
+}
+
+// Error returns the formatted configuration error.
+func (ic ErrIterationCanceled) Error() string {
+	return fmt.Sprintf("Iteration of bucket %s stopped after %d keys: %v", bucketName(ic.bucket), ic.visited, ic.err)
+}
+
+// Is allows testing using errors.Is
+func (ic ErrIterationCanceled) Is(target error) bool {
+	_, is := target.(ErrIterationCanceled)
+
+	return is
+}
+
+// Unwrap returns the error returned by ctx.Err().
+func (ic ErrIterationCanceled) Unwrap() error {
+	return ic.err
+}
+
+// Visited returns the number of keys passed to the iteration function before it was canceled.
+func (ic ErrIterationCanceled) Visited() int64 {
+	return ic.visited
+}
+
+// LastKey returns a copy of the last key passed to the iteration function before it was canceled, or nil if no key was passed.
+func (ic ErrIterationCanceled) LastKey() []byte {
+	return ic.key
+}
+
+// cancelCheck stops an iteration once its context is done, counting the keys visited across every bucket it wraps.
+type cancelCheck struct {
+	ctx     context.Context
+	visited int64
+	last    []byte
+}
+
+func newCancelCheck(ctx context.Context) *cancelCheck {
+	return &cancelCheck{ctx: ctx}
+}
+
+// wrap returns fn with ctx checked before each call. As the check is made for every key, a fast fn is still stopped promptly. When ctx can
+// never be done fn is returned unchanged.
+func (c *cancelCheck) wrap(bucket []byte, fn func(k, v []byte) error) func(k, v []byte) error {
+	if c.ctx.Done() == nil {
+		return fn
+	}
+
+	return func(k, v []byte) error {
+		if err := c.ctx.Err(); err != nil {
+			return ErrIterationCanceled{bucket: bucket, key: append([]byte(nil), c.last...), visited: c.visited, err: err}
+		}
+
+		c.visited++
+		c.last = k
+
+		return fn(k, v)
+	}
+}
@@ -0,0 +1,134 @@
+package ubolt
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestPutContext(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	db, err := Open(testdb)
+	assert.Nil(t, err, "expected no error on open")
+	defer db.Close()
+
+	err = db.CreateBucket(testbucket)
+	assert.Nil(t, err, "expected no error creating bucket")
+
+	// hold the writer lock until released
+	started, release := make(chan struct{}), make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- db.bdb().Update(func(tx *bolt.Tx) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = db.PutContext(ctx, testbucket, testkey, testvalue)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "expected deadline exceeded while writer is held")
+
+	close(release)
+	assert.Nil(t, <-done, "expected no error from blocking writer")
+
+	err = db.PutContext(context.Background(), testbucket, testkey, testvalue)
+	assert.Nil(t, err, "expected no error once writer released")
+
+	value, err := db.GetE(testbucket, testkey)
+	assert.Nil(t, err, "expected no error on get")
+	assert.Equal(t, testvalue, value, "expected value to be set")
+
+	// an already cancelled context never starts the transaction
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	err = db.DeleteContext(cancelled, testbucket, testkey)
+	assert.ErrorIs(t, err, context.Canceled, "expected cancelled error")
+	assert.Equal(t, testvalue, db.Get(testbucket, testkey), "expected value to remain")
+}
+
+func TestIterationContext(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	for i := 0; i < 10; i++ {
+		if err := b.Put([]byte(fmt.Sprintf("key%d", i)), testvalue); err != nil {
+			panic(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		run  func(ctx context.Context, fn func(k, v []byte) error) error
+	}{
+		{"ScanContext", func(ctx context.Context, fn func(k, v []byte) error) error {
+			return b.ScanContext(ctx, []byte("key"), fn)
+		}},
+		{"ForEachContext", func(ctx context.Context, fn func(k, v []byte) error) error {
+			return b.ForEachContext(ctx, fn)
+		}},
+		{"ForEachAllContext", func(ctx context.Context, fn func(k, v []byte) error) error {
+			return b.db.ForEachAllContext(ctx, func(bucket, k, v []byte) error {
+				return fn(k, v)
+			})
+		}},
+	}
+
+	for _, tt := range tests {
+		// canceled part way through
+		ctx, cancel := context.WithCancel(context.Background())
+
+		n := 0
+		err := tt.run(ctx, func(k, v []byte) error {
+			n++
+			if n == 3 {
+				cancel()
+			}
+
+			return nil
+		})
+		assert.ErrorIs(t, err, ErrIterationCanceled{}, tt.name)
+		assert.ErrorIs(t, err, context.Canceled, tt.name)
+		assert.Equal(t, 3, n, "%s - keys visited", tt.name)
+
+		var ic ErrIterationCanceled
+		if assert.ErrorAs(t, err, &ic, tt.name) {
+			assert.Equal(t, int64(3), ic.Visited(), "%s - Visited", tt.name)
+			assert.Equal(t, []byte("key2"), ic.LastKey(), "%s - LastKey", tt.name)
+		}
+
+		// canceled before starting
+		n = 0
+		err = tt.run(ctx, func(k, v []byte) error {
+			n++
+			return nil
+		})
+		assert.ErrorIs(t, err, context.Canceled, tt.name)
+		assert.Equal(t, 0, n, "%s - no keys visited", tt.name)
+
+		// not canceled
+		err = tt.run(context.Background(), func(k, v []byte) error {
+			n++
+			return nil
+		})
+		assert.Nil(t, err, tt.name)
+		assert.Equal(t, 10, n, "%s - all keys visited", tt.name)
+	}
+}
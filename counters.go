@@ -0,0 +1,244 @@
+package ubolt
+
+import (
+	"fmt"
+	"math"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrInvalidCounter is returned when the value of a counter is not an 8-byte value written by Counters.
+type ErrInvalidCounter struct {
+	bucket []byte
+	name   string
+}
+
+// Error returns the formatted configuration error.
+func (ic ErrInvalidCounter) Error() string {
+	return fmt.Sprintf("Counter %s in bucket %s is not a valid counter", ic.name, bucketName(ic.bucket))
+}
+
+// Is allows testing using errors.Is
+func (ic ErrInvalidCounter) Is(target error) bool {
+	_, is := target.(ErrInvalidCounter)
+
+	return is
+}
+
+// ErrCounterOverflow is returned by Incr when adding delta would overflow the counter.
+type ErrCounterOverflow struct {
+	bucket []byte
+	name   string
+}
+
+// Error returns the formatted configuration error.
+func (co ErrCounterOverflow) Error() string {
+	return fmt.Sprintf("Counter %s in bucket %s would overflow", co.name, bucketName(co.bucket))
+}
+
+// Is allows testing using errors.Is
+func (co ErrCounterOverflow) Is(target error) bool {
+	_, is := target.(ErrCounterOverflow)
+
+	return is
+}
+
+// Counters maintains named int64 counters in a bucket, with the name of each counter as its key. Values are stored as 8-byte big-endian
+// integers with the sign bit flipped, so the stored values sort in numeric order.
+//
+// A counter that does not exist has a value of zero. The bucket should only hold counters written using Counters.
+type Counters struct {
+	db     *Database
+	bucket []byte
+}
+
+// NewCounters returns the Counters stored in the chosen bucket, which is created when first written.
+func NewCounters(db *Database, bucket []byte) *Counters {
+	return &Counters{db: db, bucket: bucket}
+}
+
+// Counters returns the Counters stored in the bucket.
+func (b *Bucket) Counters() *Counters {
+	return NewCounters(b.db, b.bucket)
+}
+
+// Incr adds delta, which may be negative, to the named counter and returns the new value. Each call is made within its own read/write
+// transaction so concurrent calls never lose an increment. ErrCounterOverflow is returned, leaving the counter unchanged, if the result
+// would overflow an int64.
+func (c *Counters) Incr(name string, delta int64) (value int64, err error) {
+	key := c.db.canonicalKey([]byte(name))
+
+	if err := c.db.update(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, c.bucket)
+		if b == nil {
+			var err error
+			if b, err = createBucketPath(tx, c.bucket); err != nil {
+				return err
+			}
+		}
+
+		current, err := c.decode(name, key, b.Get(key))
+		if err != nil {
+			return err
+		}
+
+		if (delta > 0 && current > math.MaxInt64-delta) || (delta < 0 && current < math.MinInt64-delta) {
+			return ErrCounterOverflow{bucket: c.bucket, name: name}
+		}
+
+		value = current + delta
+
+		data, err := c.db.wrapValue(c.bucket, key, encodeCounter(value))
+		if err != nil {
+			return err
+		}
+
+		prev := previous(tx, c.bucket, b, key)
+
+		if err := b.Put(key, data); err != nil {
+			return err
+		}
+
+		return c.db.onMutation(tx, mutation{op: OpPut, bucket: c.bucket, key: key, value: data, prev: prev})
+	}); err != nil {
+		return 0, err
+	}
+
+	return value, nil
+}
+
+// Get returns the value of the named counter, which is zero if the counter or bucket does not exist.
+func (c *Counters) Get(name string) (value int64, err error) {
+	key := c.db.canonicalKey([]byte(name))
+
+	if err := c.db.view(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, c.bucket)
+		if b == nil {
+			return nil
+		}
+
+		value, err = c.decode(name, key, b.Get(key))
+
+		return err
+	}); err != nil {
+		return 0, err
+	}
+
+	return value, nil
+}
+
+// Reset removes the named counter so its value returns to zero.
+func (c *Counters) Reset(name string) error {
+	key := c.db.canonicalKey([]byte(name))
+
+	return c.db.update(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, c.bucket)
+		if b == nil || b.Get(key) == nil {
+			return nil
+		}
+
+		prev := previous(tx, c.bucket, b, key)
+
+		if err := b.Delete(key); err != nil {
+			return err
+		}
+
+		return c.db.onMutation(tx, mutation{op: OpDelete, bucket: c.bucket, key: key, prev: prev})
+	})
+}
+
+// All returns the value of every counter keyed by name, read within a single read-only transaction so the values are consistent with each
+// other. An empty map is returned if the bucket does not exist.
+func (c *Counters) All() (map[string]int64, error) {
+	counters := make(map[string]int64)
+
+	if err := c.db.view(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, c.bucket)
+		if b == nil {
+			return nil
+		}
+
+		return b.ForEach(func(k, v []byte) error {
+			// skip nested buckets
+			if v == nil {
+				return nil
+			}
+
+			value, err := c.decode(string(k), k, v)
+			if err != nil {
+				return err
+			}
+
+			counters[string(k)] = value
+
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	return counters, nil
+}
+
+// ResetAll removes every counter within a single read/write transaction.
+func (c *Counters) ResetAll() error {
+	return c.db.update(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, c.bucket)
+		if b == nil {
+			return nil
+		}
+
+		var keys [][]byte
+		if err := b.ForEach(func(k, v []byte) error {
+			// skip nested buckets
+			if v != nil {
+				keys = append(keys, append([]byte{}, k...))
+			}
+
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		for _, k := range keys {
+			prev := previous(tx, c.bucket, b, k)
+
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+
+			if err := c.db.onMutation(tx, mutation{op: OpDelete, bucket: c.bucket, key: k, prev: prev}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// decode returns the value of a counter as stored in the bucket, which is zero when data is nil.
+func (c *Counters) decode(name string, key, data []byte) (int64, error) {
+	if data == nil {
+		return 0, nil
+	}
+
+	data, err := c.db.unwrapValue(c.bucket, key, data)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(data) != 8 {
+		return 0, ErrInvalidCounter{bucket: c.bucket, name: name}
+	}
+
+	return decodeCounter(data), nil
+}
+
+// encodeCounter encodes v as 8 big-endian bytes with the sign bit flipped so negative values sort before positive values.
+func encodeCounter(v int64) []byte {
+	return itob(uint64(v) ^ (1 << 63))
+}
+
+func decodeCounter(b []byte) int64 {
+	return int64(btoi(b) ^ (1 << 63))
+}
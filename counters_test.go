@@ -0,0 +1,106 @@
+package ubolt
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounters(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	db, err := Open(testdb)
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	c := NewCounters(db, []byte("counters"))
+
+	// missing counters and buckets read as zero
+	v, err := c.Get("hits")
+	assert.Nil(t, err, "Get - missing bucket")
+	assert.Equal(t, int64(0), v, "Get - missing bucket")
+
+	all, err := c.All()
+	assert.Nil(t, err, "All - missing bucket")
+	assert.Empty(t, all, "All - missing bucket")
+
+	// concurrent increments are not lost
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 50; j++ {
+				if _, err := c.Incr("hits", 1); err != nil {
+					panic(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	v, err = c.Get("hits")
+	assert.Nil(t, err, "Get")
+	assert.Equal(t, int64(500), v, "Get - concurrent increments")
+
+	v, err = c.Incr("quota", -5)
+	assert.Nil(t, err, "Incr - negative")
+	assert.Equal(t, int64(-5), v, "Incr - negative")
+
+	all, err = c.All()
+	assert.Nil(t, err, "All")
+	assert.Equal(t, map[string]int64{"hits": 500, "quota": -5}, all, "All")
+
+	// overflow leaves the counter unchanged
+	_, err = c.Incr("quota", math.MinInt64)
+	assert.ErrorIs(t, err, ErrCounterOverflow{}, "Incr - overflow")
+
+	v, err = c.Get("quota")
+	assert.Nil(t, err, "Get")
+	assert.Equal(t, int64(-5), v, "Get - after overflow")
+
+	assert.Nil(t, c.Reset("quota"), "Reset")
+	assert.Nil(t, c.Reset("quota"), "Reset - missing")
+
+	all, err = c.All()
+	assert.Nil(t, err, "All")
+	assert.Equal(t, map[string]int64{"hits": 500}, all, "All - after Reset")
+
+	// values that were not written by Counters are rejected
+	if err := db.Put([]byte("counters"), []byte("bad"), []byte("x")); err != nil {
+		panic(err)
+	}
+
+	_, err = c.Get("bad")
+	assert.ErrorIs(t, err, ErrInvalidCounter{}, "Get - invalid")
+
+	_, err = c.Incr("bad", 1)
+	assert.ErrorIs(t, err, ErrInvalidCounter{}, "Incr - invalid")
+
+	assert.Nil(t, c.ResetAll(), "ResetAll")
+
+	all, err = c.All()
+	assert.Nil(t, err, "All")
+	assert.Empty(t, all, "All - after ResetAll")
+}
+
+func TestCounterEncoding(t *testing.T) {
+	values := []int64{math.MinInt64, -1000, -1, 0, 1, 1000, math.MaxInt64}
+
+	for i, v := range values {
+		assert.Len(t, encodeCounter(v), 8, "encodeCounter - length")
+		assert.Equal(t, v, decodeCounter(encodeCounter(v)), "decodeCounter")
+
+		if i > 0 {
+			assert.Equal(t, -1, bytes.Compare(encodeCounter(values[i-1]), encodeCounter(v)), "encodeCounter - order")
+		}
+	}
+}
@@ -0,0 +1,320 @@
+package ubolt
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// CSVFormat is the format of the keys or values held in a CSV column.
+type CSVFormat int
+
+const (
+	// CSVString columns hold keys or values as-is.
+	CSVString CSVFormat = iota
+	// CSVHex columns hold keys or values as hexadecimal.
+	CSVHex
+	// CSVBase64 columns hold keys or values as standard base64 with padding.
+	CSVBase64
+)
+
+// DefaultCSVChunkSize is the number of rows written in each read/write transaction by ImportCSV.
+const DefaultCSVChunkSize = 1000
+
+// CSVOption is used to change the behaviour of ImportCSV.
+type CSVOption func(*csvOptions)
+
+type csvOptions struct {
+	keyColumn, valueColumn int
+	keyFormat, valueFormat CSVFormat
+	header                 bool
+	comma                  rune
+	chunkSize              int
+	skip                   func(line int, err error)
+	skipMalformed          bool
+	conflict               ConflictPolicy
+}
+
+// WithCSVColumns sets the zero-based columns holding the key and the value, which default to the first and second columns.
+func WithCSVColumns(key, value int) CSVOption {
+	return func(o *csvOptions) {
+		o.keyColumn, o.valueColumn = key, value
+	}
+}
+
+// WithCSVFormat sets the format of the key and value columns, which default to CSVString.
+func WithCSVFormat(key, value CSVFormat) CSVOption {
+	return func(o *csvOptions) {
+		o.keyFormat, o.valueFormat = key, value
+	}
+}
+
+// WithCSVHeader skips the first row, which holds the column names.
+func WithCSVHeader() CSVOption {
+	return func(o *csvOptions) {
+		o.header = true
+	}
+}
+
+// WithCSVComma sets the field delimiter, which defaults to a comma.
+func WithCSVComma(comma rune) CSVOption {
+	return func(o *csvOptions) {
+		o.comma = comma
+	}
+}
+
+// WithCSVChunkSize sets the number of rows written in each read/write transaction, which defaults to DefaultCSVChunkSize.
+func WithCSVChunkSize(n int) CSVOption {
+	return func(o *csvOptions) {
+		o.chunkSize = n
+	}
+}
+
+// WithCSVSkipMalformed skips malformed rows rather than stopping the import. If report is not nil it is called with the line number and
+// reason for each row skipped, so skipped rows may be counted or logged.
+func WithCSVSkipMalformed(report func(line int, err error)) CSVOption {
+	return func(o *csvOptions) {
+		o.skipMalformed = true
+		o.skip = report
+	}
+}
+
+// WithCSVConflictPolicy sets the policy used when an imported key already exists, including a key repeated by a later row, as per
+// WithConflictPolicy. The default is OverwriteOnConflict.
+func WithCSVConflictPolicy(policy ConflictPolicy) CSVOption {
+	return func(o *csvOptions) {
+		o.conflict = policy
+	}
+}
+
+// ErrCSVRow is returned by ImportCSV when a row is malformed, such as when it can not be parsed, has too few columns, has an empty key or
+// holds a key or value that can not be decoded in the configured CSVFormat.
+type ErrCSVRow struct {
+	line int
+	err  error
+}
+
+// Error returns the formatted configuration error.
+func (cr ErrCSVRow) Error() string {
+	return fmt.Sprintf("Malformed CSV row on line %d: %v", cr.line, cr.err)
+}
+
+// Is allows testing using errors.Is
+func (cr ErrCSVRow) Is(target error) bool {
+	_, is := target.(ErrCSVRow)
+
+	return is
+}
+
+// Unwrap returns the reason the row is malformed.
+func (cr ErrCSVRow) Unwrap() error {
+	return cr.err
+}
+
+// Line returns the line number, starting at 1, on which the malformed row begins.
+func (cr ErrCSVRow) Line() int {
+	return cr.line
+}
+
+// ImportCSV reads rows of CSV from r and sets the key held in one column of each row to the value held in another, returning the number
+// of rows imported. The bucket is created if it does not exist. Rows are streamed from r and written in chunks of DefaultCSVChunkSize per
+// read/write transaction, so the import is not atomic and rows written before an error are left in place. Rows whose key already exists are
+// resolved using the ConflictPolicy set by WithCSVConflictPolicy, so by default later rows overwrite earlier rows with the same key, and
+// rows that the policy leaves unchanged are not counted.
+//
+// By default the first column is the key and the second the value, both held as-is, and every row is imported. The output of the ubolt
+// command's CSV export may be imported using WithCSVHeader along with the WithCSVFormat matching its --key-format and --value-format.
+//
+// A malformed row stops the import with ErrCSVRow, which reports the line the row begins on, unless WithCSVSkipMalformed is used.
+func (db *Database) ImportCSV(r io.Reader, bucket []byte, opts ...CSVOption) (int, error) {
+	o := csvOptions{keyColumn: 0, valueColumn: 1, comma: ',', chunkSize: DefaultCSVChunkSize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.chunkSize <= 0 {
+		o.chunkSize = DefaultCSVChunkSize
+	}
+
+	imports := newImportOptions([]ImportOption{WithConflictPolicy(o.conflict)})
+
+	cr := csv.NewReader(r)
+	cr.Comma = o.comma
+	cr.FieldsPerRecord = -1
+
+	var total int
+	var keys, values [][]byte
+
+	flush := func() error {
+		if len(keys) == 0 {
+			return nil
+		}
+
+		written := 0
+
+		if err := db.update(func(tx *bolt.Tx) error {
+			b, err := createBucketPath(tx, bucket)
+			if err != nil {
+				return err
+			}
+
+			written = 0
+
+			for i, k := range keys {
+				key := db.canonicalKey(k)
+
+				var existing []byte
+				if data := b.Get(key); data != nil {
+					if existing, err = db.unwrapValue(bucket, key, data); err != nil {
+						return ErrDecode{bucket: bucket, key: key, err: err}
+					}
+
+					// an existing empty value is still a conflict
+					if existing == nil {
+						existing = []byte{}
+					}
+				}
+
+				value, write, err := imports.resolve(bucket, key, existing, values[i])
+				if err != nil {
+					return err
+				}
+
+				if !write {
+					continue
+				}
+
+				if value, err = db.wrapValue(bucket, key, value); err != nil {
+					return err
+				}
+
+				prev := previous(tx, bucket, b, key)
+
+				if err := b.Put(key, value); err != nil {
+					return err
+				}
+
+				if err := db.onMutation(tx, mutation{op: OpPut, bucket: bucket, key: key, value: value, prev: prev}); err != nil {
+					return err
+				}
+
+				written++
+			}
+
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		total += written
+		keys, values = keys[:0], values[:0]
+
+		return nil
+	}
+
+	for first := true; ; first = false {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var line int
+		if err == nil {
+			line, _ = cr.FieldPos(0)
+		}
+
+		var pe *csv.ParseError
+		if errors.As(err, &pe) {
+			line, err = pe.StartLine, pe.Err
+		} else if err != nil {
+			return total, err
+		}
+
+		if first && o.header {
+			if err != nil {
+				return total, ErrCSVRow{line: line, err: err}
+			}
+
+			continue
+		}
+
+		var key, value []byte
+		if err == nil {
+			key, value, err = o.row(record)
+		}
+
+		if err != nil {
+			if !o.skipMalformed {
+				if ferr := flush(); ferr != nil {
+					return total, ferr
+				}
+
+				return total, ErrCSVRow{line: line, err: err}
+			}
+
+			if o.skip != nil {
+				o.skip(line, err)
+			}
+
+			continue
+		}
+
+		keys, values = append(keys, key), append(values, value)
+
+		if len(keys) == o.chunkSize {
+			if err := flush(); err != nil {
+				return total, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return total, err
+	}
+
+	return total, nil
+}
+
+// ImportCSV reads rows of CSV from r and sets the key held in one column of each row to the value held in another. This is forwarded to
+// the Database implementation.
+func (b *Bucket) ImportCSV(r io.Reader, opts ...CSVOption) (int, error) {
+	return b.db.ImportCSV(r, b.bucket, opts...)
+}
+
+// row returns the decoded key and value of a record.
+func (o csvOptions) row(record []string) (key, value []byte, err error) {
+	if n := max(o.keyColumn, o.valueColumn) + 1; len(record) < n {
+		return nil, nil, fmt.Errorf("expected at least %d columns, got %d", n, len(record))
+	}
+
+	if key, err = decodeCSV(o.keyFormat, record[o.keyColumn]); err != nil {
+		return nil, nil, fmt.Errorf("invalid key: %w", err)
+	}
+
+	if len(key) == 0 {
+		return nil, nil, errors.New("empty key")
+	}
+
+	if value, err = decodeCSV(o.valueFormat, record[o.valueColumn]); err != nil {
+		return nil, nil, fmt.Errorf("invalid value: %w", err)
+	}
+
+	return key, value, nil
+}
+
+// decodeCSV decodes a field held in the provided format.
+func decodeCSV(format CSVFormat, field string) ([]byte, error) {
+	switch format {
+	case CSVHex:
+		return hex.DecodeString(field)
+	case CSVBase64:
+		return base64.StdEncoding.DecodeString(field)
+	}
+
+	return []byte(field), nil
+}
@@ -0,0 +1,107 @@
+package ubolt
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportCSV(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	// rows are written in several chunks and later rows overwrite earlier ones
+	n, err := b.ImportCSV(strings.NewReader("a,1\nb,2\nc,3\na,4\nd,\"quoted, value\"\n"), WithCSVChunkSize(2))
+	assert.Nil(t, err, "ImportCSV")
+	assert.Equal(t, 5, n, "ImportCSV")
+	assert.Equal(t, []byte("4"), b.Get([]byte("a")), "ImportCSV - overwrite")
+	assert.Equal(t, []byte("quoted, value"), b.Get([]byte("d")), "ImportCSV - quoted")
+
+	// columns, formats, delimiter and header are configurable, and a missing bucket is created
+	data := "id;name;payload\n0001;ignored;aGVsbG8=\nff;ignored;AAE=\n"
+	n, err = b.db.ImportCSV(strings.NewReader(data), []byte("binary"), WithCSVHeader(), WithCSVComma(';'), WithCSVColumns(0, 2), WithCSVFormat(CSVHex, CSVBase64))
+	assert.Nil(t, err, "ImportCSV - options")
+	assert.Equal(t, 2, n, "ImportCSV - options")
+	assert.Equal(t, []byte("hello"), b.db.Get([]byte("binary"), []byte{0x00, 0x01}), "ImportCSV - options")
+	assert.Equal(t, []byte{0x00, 0x01}, b.db.Get([]byte("binary"), []byte{0xff}), "ImportCSV - options")
+
+	// a malformed row stops the import, reporting its line, with the rows before it written
+	malformed := "k1,v1\nk2\nk3,v3\n,empty\nk4,\"unterminated\nk5,v5\nk6,v6\n"
+
+	n, err = b.db.ImportCSV(strings.NewReader(malformed), []byte("malformed"))
+	assert.ErrorIs(t, err, ErrCSVRow{}, "ImportCSV - malformed")
+	assert.Equal(t, 1, n, "ImportCSV - malformed")
+
+	var cr ErrCSVRow
+	if assert.ErrorAs(t, err, &cr, "ImportCSV - malformed") {
+		assert.Equal(t, 2, cr.Line(), "ErrCSVRow.Line")
+	}
+
+	assert.Equal(t, []byte("v1"), b.db.Get([]byte("malformed"), []byte("k1")), "ImportCSV - written before error")
+	assert.Nil(t, b.db.Get([]byte("malformed"), []byte("k3")), "ImportCSV - stopped at error")
+
+	// malformed rows may be skipped and counted instead
+	var skipped []int
+	n, err = b.db.ImportCSV(strings.NewReader(malformed), []byte("skipped"), WithCSVSkipMalformed(func(line int, err error) {
+		skipped = append(skipped, line)
+	}))
+	assert.Nil(t, err, "ImportCSV - skip malformed")
+	assert.Equal(t, 2, n, "ImportCSV - skip malformed")
+	assert.Equal(t, []int{2, 4, 5}, skipped, "ImportCSV - skipped lines")
+
+	n, err = b.db.ImportCSV(strings.NewReader("zz,value\n"), []byte("hex"), WithCSVFormat(CSVHex, CSVString))
+	assert.ErrorIs(t, err, ErrCSVRow{}, "ImportCSV - invalid hex")
+	assert.Equal(t, 0, n, "ImportCSV - invalid hex")
+}
+
+func TestImportCSVConflictPolicy(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	if err := b.Put([]byte("a"), []byte("newer")); err != nil {
+		panic(err)
+	}
+
+	dump := "a,older\nb,2\n"
+
+	// existing keys are kept when skipping
+	n, err := b.ImportCSV(strings.NewReader(dump), WithCSVConflictPolicy(SkipOnConflict))
+	assert.Nil(t, err, "ImportCSV - skip")
+	assert.Equal(t, 1, n, "ImportCSV - skip count")
+	assert.Equal(t, []byte("newer"), b.Get([]byte("a")), "ImportCSV - skip kept")
+	assert.Equal(t, []byte("2"), b.Get([]byte("b")), "ImportCSV - skip written")
+
+	// an error reports the bucket and key and rolls back the chunk
+	n, err = b.ImportCSV(strings.NewReader("c,3\na,older\n"), WithCSVConflictPolicy(ErrorOnConflict))
+	assert.ErrorIs(t, err, ErrConflict{}, "ImportCSV - error")
+	assert.Equal(t, 0, n, "ImportCSV - error count")
+	assert.Contains(t, err.Error(), "Key a already exists in bucket "+string(testbucket), "ImportCSV - error message")
+	assert.Nil(t, b.Get([]byte("c")), "ImportCSV - error rolled back")
+
+	// a callback decides the value using the existing and incoming values, including keys repeated within the import
+	var seen [][3]string
+	n, err = b.ImportCSV(strings.NewReader("a,older\nd,4\nd,5\n"), WithCSVConflictPolicy(func(bucket, key, existing, incoming []byte) ([]byte, error) {
+		seen = append(seen, [3]string{string(key), string(existing), string(incoming)})
+
+		return append(append([]byte{}, existing...), incoming...), nil
+	}))
+	assert.Nil(t, err, "ImportCSV - callback")
+	assert.Equal(t, 3, n, "ImportCSV - callback count")
+	assert.Equal(t, [][3]string{{"a", "newer", "older"}, {"d", "4", "5"}}, seen, "ImportCSV - callback calls")
+	assert.Equal(t, []byte("newerolder"), b.Get([]byte("a")), "ImportCSV - callback value")
+	assert.Equal(t, []byte("45"), b.Get([]byte("d")), "ImportCSV - callback repeated key")
+}
@@ -0,0 +1,175 @@
+package ubolt
+
+import (
+	"errors"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrDecode is returned when a stored value could not be decoded using the configured Codec.
+type ErrDecode struct {
+	bucket []byte
+	key    []byte
+	err    error
+}
+
+// Error returns the formatted configuration error.
+func (d ErrDecode) Error() string {
+	return fmt.Sprintf("Key %s in bucket %s could not be decoded: %v", string(d.key), bucketName(d.bucket), d.err)
+}
+
+// Is allows testing using errors.Is
+func (d ErrDecode) Is(target error) bool {
+	_, is := target.(ErrDecode)
+
+	return is
+}
+
+// Unwrap returns the error returned by the Codec.
+func (d ErrDecode) Unwrap() error {
+	return d.err
+}
+
+// ErrValueTooLarge is returned by DecodeLimited, or by Decode when WithDecodeLimit is used, when a value is larger than the limit.
+type ErrValueTooLarge struct {
+	bucket []byte
+	key    []byte
+	size   int64
+	limit  int64
+}
+
+// Error returns the formatted configuration error.
+func (vtl ErrValueTooLarge) Error() string {
+	return fmt.Sprintf("Value of key %s in bucket %s is %d bytes which exceeds the limit of %d bytes", string(vtl.key), bucketName(vtl.bucket), vtl.size, vtl.limit)
+}
+
+// Is allows testing using errors.Is
+func (vtl ErrValueTooLarge) Is(target error) bool {
+	_, is := target.(ErrValueTooLarge)
+
+	return is
+}
+
+// WithDecodeLimit sets the maximum size in bytes of a value that Decode will attempt to decode, as per DecodeLimited. By default there is
+// no limit.
+func WithDecodeLimit(maxBytes int64) Option {
+	return func(db *Database) {
+		db.decodeLimit = maxBytes
+	}
+}
+
+// DecodeLimited performs the same process as Decode however ErrValueTooLarge is returned without attempting to decode the value if it is
+// larger than maxBytes, which guards against corrupt or hostile values causing large allocations while decoding. A maxBytes of zero or
+// less means no limit.
+//
+// ErrInvalidDestination is returned before the database is read if value is not a non-nil pointer. Errors returned by the Codec,
+// including any panic while decoding, are returned as ErrDecode identifying the bucket and key.
+func (db *Database) DecodeLimited(bucket, key []byte, value interface{}, maxBytes int64) error {
+	if err := checkDestination(value); err != nil {
+		return err
+	}
+
+	data, err := db.GetE(bucket, key)
+	if err != nil {
+		return err
+	}
+
+	if maxBytes > 0 && int64(len(data)) > maxBytes {
+		return ErrValueTooLarge{bucket: bucket, key: key, size: int64(len(data)), limit: maxBytes}
+	}
+
+	if err := db.safeUnmarshal(data, value); err != nil {
+		return ErrDecode{bucket: bucket, key: key, err: err}
+	}
+
+	return nil
+}
+
+// DecodeLimited performs the same process as Decode however ErrValueTooLarge is returned if the value is larger than maxBytes.
+func (b *Bucket) DecodeLimited(key []byte, value interface{}, maxBytes int64) error {
+	return b.db.DecodeLimited(b.bucket, key, value, maxBytes)
+}
+
+// safeUnmarshal performs the same process as unmarshal however a panic by the Codec is returned as an error.
+func (db *Database) safeUnmarshal(data []byte, value interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic while decoding: %v", r)
+		}
+	}()
+
+	return db.unmarshal(data, value)
+}
+
+// DecodeMultiOptions controls the behaviour of DecodeMultiWithOptions.
+type DecodeMultiOptions struct {
+	// RequireAll reports keys that do not exist as ErrKeyNotFound rather than omitting them from the result.
+	RequireAll bool
+}
+
+// DecodeMulti reads and decodes the values of several keys from the chosen bucket within a single read-only transaction using the
+// configured Codec. The result is keyed by the string form of each key, with keys that do not exist omitted.
+//
+// Keys that fail to decode are reported as ErrDecode, joined into a single error, alongside the values that were decoded successfully.
+func DecodeMulti[T any](db *Database, bucket []byte, keys [][]byte) (map[string]T, error) {
+	return DecodeMultiWithOptions[T](db, bucket, keys, DecodeMultiOptions{})
+}
+
+// DecodeMultiWithOptions performs the same process as DecodeMulti with the behaviour controlled by the provided DecodeMultiOptions.
+func DecodeMultiWithOptions[T any](db *Database, bucket []byte, keys [][]byte, opts DecodeMultiOptions) (map[string]T, error) {
+	values := make(map[string]T, len(keys))
+
+	var errs []error
+	if err := db.view(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		for _, key := range keys {
+			ck := db.canonicalKey(key)
+
+			data := b.Get(ck)
+			if data == nil {
+				if opts.RequireAll {
+					errs = append(errs, ErrKeyNotFound{bucket: bucket, key: key})
+				}
+
+				continue
+			}
+
+			data, err := db.unwrapValue(bucket, ck, data)
+			if err != nil {
+				errs = append(errs, ErrDecode{bucket: bucket, key: key, err: err})
+				continue
+			}
+
+			var v T
+			if err := db.unmarshal(data, &v); err != nil {
+				errs = append(errs, ErrDecode{bucket: bucket, key: key, err: err})
+				continue
+			}
+
+			values[string(key)] = v
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	db.counters.gets.Add(uint64(len(keys)))
+
+	return values, errors.Join(errs...)
+}
+
+// DecodeMultiBucket performs the same process as DecodeMulti for the bucket opened by OpenBucket.
+func DecodeMultiBucket[T any](b *Bucket, keys [][]byte) (map[string]T, error) {
+	return DecodeMulti[T](b.db, b.bucket, keys)
+}
+
+// DecodeMultiBucketWithOptions performs the same process as DecodeMultiWithOptions for the bucket opened by OpenBucket.
+func DecodeMultiBucketWithOptions[T any](b *Bucket, keys [][]byte, opts DecodeMultiOptions) (map[string]T, error) {
+	return DecodeMultiWithOptions[T](b.db, b.bucket, keys, opts)
+}
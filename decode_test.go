@@ -0,0 +1,123 @@
+package ubolt
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+type profile struct {
+	Name string
+	Age  int
+}
+
+func TestDecodeMulti(t *testing.T) {
+	tests := []struct {
+		name  string
+		codec Codec
+	}{
+		{"gob", GobCodec},
+		{"json", jsonCodec{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = os.Remove(testdb)
+			defer os.Remove(testdb)
+
+			b, err := OpenBucket(testdb, testbucket, WithCodec(tt.codec))
+			if err != nil {
+				panic(err)
+			}
+			defer b.Close()
+
+			want := map[string]profile{
+				"alice": {"Alice", 30},
+				"bob":   {"Bob", 40},
+			}
+			for k, v := range want {
+				if err := b.Encode([]byte(k), v); err != nil {
+					panic(err)
+				}
+			}
+			if err := b.Put([]byte("corrupt"), []byte("not a profile")); err != nil {
+				panic(err)
+			}
+
+			keys := [][]byte{[]byte("alice"), []byte("bob"), []byte("carol")}
+
+			// missing keys are omitted
+			got, err := DecodeMultiBucket[profile](b, keys)
+			assert.Nil(t, err, "DecodeMulti")
+			assert.Equal(t, want, got, "DecodeMulti")
+
+			// or reported
+			got, err = DecodeMultiBucketWithOptions[profile](b, keys, DecodeMultiOptions{RequireAll: true})
+			assert.ErrorIs(t, err, ErrKeyNotFound{}, "DecodeMulti - RequireAll")
+			assert.Contains(t, err.Error(), "carol", "DecodeMulti - RequireAll")
+			assert.Equal(t, want, got, "DecodeMulti - RequireAll")
+
+			// decode failures name the key and the remaining values are returned
+			got, err = DecodeMultiBucket[profile](b, append(keys, []byte("corrupt")))
+			assert.ErrorIs(t, err, ErrDecode{}, "DecodeMulti - corrupt")
+			assert.Contains(t, err.Error(), "corrupt", "DecodeMulti - corrupt")
+			assert.Equal(t, want, got, "DecodeMulti - corrupt")
+
+			_, err = DecodeMulti[profile](b.db, missing, keys)
+			assert.ErrorIs(t, err, ErrBucketNotFound{}, "DecodeMulti - missing bucket")
+		})
+	}
+}
+
+// panicCodec panics when decoding, as a faulty Codec might on a corrupt value.
+type panicCodec struct{ jsonCodec }
+
+func (panicCodec) Unmarshal(data []byte, v interface{}) error { panic("corrupt") }
+
+func TestDecodeLimited(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket, WithDecodeLimit(64))
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.Nil(t, b.Encode([]byte("small"), "value"), "Encode")
+	assert.Nil(t, b.Encode([]byte("large"), strings.Repeat("x", 100)), "Encode")
+
+	var s string
+	assert.Nil(t, b.Decode([]byte("small"), &s), "Decode")
+	assert.Equal(t, "value", s, "Decode")
+
+	err = b.Decode([]byte("large"), &s)
+	assert.ErrorIs(t, err, ErrValueTooLarge{}, "Decode - over default limit")
+
+	assert.Nil(t, b.DecodeLimited([]byte("large"), &s, 0), "DecodeLimited - no limit")
+	assert.ErrorIs(t, b.DecodeLimited([]byte("small"), &s, 4), ErrValueTooLarge{}, "DecodeLimited - over limit")
+
+	var n int
+	err = b.Decode([]byte("small"), &n)
+	assert.ErrorIs(t, err, ErrDecode{}, "Decode - wrong type")
+	assert.Contains(t, err.Error(), "Key small in bucket", "Decode - wrong type")
+
+	assert.Nil(t, b.Close(), "Close")
+
+	b, err = OpenBucket(testdb, testbucket, WithCodec(panicCodec{}))
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.Nil(t, b.Put([]byte("raw"), []byte("{}")), "Put")
+	assert.ErrorIs(t, b.Decode([]byte("raw"), &s), ErrDecode{}, "Decode - panic")
+}
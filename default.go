@@ -0,0 +1,43 @@
+package ubolt
+
+import (
+	"errors"
+)
+
+// GetOrDefault retrieves the specified key from the chosen bucket as per GetE, however a copy of def is returned with a nil error if the
+// key does not exist or has expired. Any other error, such as ErrBucketNotFound when the bucket does not exist, is returned as per GetE.
+func (db *Database) GetOrDefault(bucket, key, def []byte) ([]byte, error) {
+	value, err := db.GetE(bucket, key)
+	if errors.Is(err, ErrKeyNotFound{}) {
+		if def == nil {
+			return nil, nil
+		}
+
+		return append([]byte{}, def...), nil
+	}
+
+	return value, err
+}
+
+// GetOrDefault retrieves the specified key, returning a copy of def if the key does not exist. This is forwarded to the Database
+// implementation.
+func (b *Bucket) GetOrDefault(key, def []byte) ([]byte, error) {
+	return b.db.GetOrDefault(b.bucket, key, def)
+}
+
+// DecodeOrDefault performs the same process as Decode however if the key does not exist or has expired value is left untouched and nil is
+// returned, so value may be set to a default before the call. Any other error, such as ErrBucketNotFound when the bucket does not exist,
+// is returned as per Decode.
+func (db *Database) DecodeOrDefault(bucket, key []byte, value interface{}) error {
+	if err := db.Decode(bucket, key, value); err != nil && !errors.Is(err, ErrKeyNotFound{}) {
+		return err
+	}
+
+	return nil
+}
+
+// DecodeOrDefault performs the same process as Decode however value is left untouched if the key does not exist. This is forwarded to the
+// Database implementation.
+func (b *Bucket) DecodeOrDefault(key []byte, value interface{}) error {
+	return b.db.DecodeOrDefault(b.bucket, key, value)
+}
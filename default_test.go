@@ -0,0 +1,73 @@
+package ubolt
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOrDefault(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	def := []byte("default")
+
+	assert.Nil(t, b.Put(testkey, testvalue), "Put")
+
+	value, err := b.GetOrDefault(testkey, def)
+	assert.Nil(t, err, "GetOrDefault - existing key")
+	assert.Equal(t, testvalue, value, "GetOrDefault - existing key")
+
+	value, err = b.GetOrDefault(missing, def)
+	assert.Nil(t, err, "GetOrDefault - missing key")
+	assert.Equal(t, def, value, "GetOrDefault - missing key")
+
+	// the default is copied
+	value[0] = 'X'
+	assert.Equal(t, []byte("default"), def, "GetOrDefault - default copied")
+
+	value, err = b.GetOrDefault(missing, nil)
+	assert.Nil(t, err, "GetOrDefault - nil default")
+	assert.Nil(t, value, "GetOrDefault - nil default")
+
+	// a missing bucket is not mistaken for a missing key
+	value, err = b.db.GetOrDefault(missing, testkey, def)
+	assert.ErrorIs(t, err, ErrBucketNotFound{}, "GetOrDefault - missing bucket")
+	assert.Nil(t, value, "GetOrDefault - missing bucket")
+}
+
+func TestDecodeOrDefault(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.Nil(t, b.Encode(testkey, 42), "Encode")
+
+	value := 1
+	assert.Nil(t, b.DecodeOrDefault(testkey, &value), "DecodeOrDefault - existing key")
+	assert.Equal(t, 42, value, "DecodeOrDefault - existing key")
+
+	value = 1
+	assert.Nil(t, b.DecodeOrDefault(missing, &value), "DecodeOrDefault - missing key")
+	assert.Equal(t, 1, value, "DecodeOrDefault - missing key untouched")
+
+	assert.ErrorIs(t, b.db.DecodeOrDefault(missing, testkey, &value), ErrBucketNotFound{}, "DecodeOrDefault - missing bucket")
+	assert.Equal(t, 1, value, "DecodeOrDefault - missing bucket untouched")
+
+	assert.ErrorIs(t, b.DecodeOrDefault(missing, value), ErrInvalidDestination{}, "DecodeOrDefault - invalid destination")
+
+	assert.Nil(t, b.Put([]byte("raw"), []byte("not encoded")), "Put")
+	assert.ErrorIs(t, b.DecodeOrDefault([]byte("raw"), &value), ErrDecode{}, "DecodeOrDefault - decode error")
+}
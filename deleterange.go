@@ -0,0 +1,212 @@
+package ubolt
+
+import (
+	"bytes"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrEmptyPrefix is returned by DeletePrefix when the prefix is empty, as a prefix of zero length matches every key in the bucket.
+type ErrEmptyPrefix struct {
+	bucket []byte
+}
+
+// Error returns the formatted configuration error.
+func (ep ErrEmptyPrefix) Error() string {
+	return fmt.Sprintf("An empty prefix may not be used to delete from bucket %s", bucketName(ep.bucket))
+}
+
+// Is allows testing using errors.Is
+func (ep ErrEmptyPrefix) Is(target error) bool {
+	_, is := target.(ErrEmptyPrefix)
+
+	return is
+}
+
+// DeleteRangeOptions controls the behaviour of DeleteRangeWithOptions.
+type DeleteRangeOptions struct {
+	// ChunkSize splits the deletes across multiple transactions of at most ChunkSize keys each. A value of zero or less deletes every key
+	// in a single transaction.
+	//
+	// When chunking is used the operation is no longer atomic, as chunks committed before a failure are not rolled back.
+	ChunkSize int
+
+	// Progress, if set, is called as transactions are committed with the number of keys removed. The total is reported as -1.
+	Progress ProgressFunc
+}
+
+// DeleteRange removes every key in the chosen bucket that sorts at or after start and strictly before end, returning the number of keys
+// removed. A nil start begins at the first key and a nil end continues to the last key. All keys are removed in a single read/write
+// transaction.
+func (db *Database) DeleteRange(bucket, start, end []byte) (int, error) {
+	return db.DeleteRangeWithOptions(bucket, start, end, DeleteRangeOptions{})
+}
+
+// DeleteRange removes every key that sorts at or after start and strictly before end, returning the number of keys removed.
+func (b *Bucket) DeleteRange(start, end []byte) (int, error) {
+	return b.db.DeleteRange(b.bucket, start, end)
+}
+
+// DeleteRangeWithOptions performs the same process as DeleteRange with the behaviour controlled by the provided DeleteRangeOptions. The
+// number of keys removed includes those in chunks committed before any error.
+func (db *Database) DeleteRangeWithOptions(bucket, start, end []byte, opts DeleteRangeOptions) (int, error) {
+	return db.deleteRange(bucket, db.canonicalKey(start), db.canonicalKey(end), opts)
+}
+
+// DeleteRangeWithOptions performs the same process as DeleteRange with the behaviour controlled by the provided DeleteRangeOptions.
+func (b *Bucket) DeleteRangeWithOptions(start, end []byte, opts DeleteRangeOptions) (int, error) {
+	return b.db.DeleteRangeWithOptions(b.bucket, start, end, opts)
+}
+
+// DeletePrefix removes every key in the chosen bucket that begins with prefix within a single read/write transaction, returning the number
+// of keys removed. Nested buckets are left in place. ErrEmptyPrefix is returned if prefix is empty, so a zero length prefix can not remove
+// every key by mistake, and DeleteRange should be used to intentionally empty the bucket.
+func (db *Database) DeletePrefix(bucket, prefix []byte) (int, error) {
+	if len(prefix) == 0 {
+		return 0, ErrEmptyPrefix{bucket: bucket}
+	}
+
+	prefix = db.canonicalKey(prefix)
+
+	return db.deleteRange(bucket, prefix, PrefixSuccessor(prefix), DeleteRangeOptions{})
+}
+
+// DeletePrefix removes every key that begins with prefix within a single read/write transaction, returning the number of keys removed. This
+// is forwarded to the Database implementation.
+func (b *Bucket) DeletePrefix(prefix []byte) (int, error) {
+	return b.db.DeletePrefix(b.bucket, prefix)
+}
+
+// DeletePrefixChunked removes every key in the chosen bucket that begins with prefix using transactions of at most perTx keys each,
+// releasing the writer lock between transactions so other writes are not blocked for the whole operation. The number of keys removed is
+// returned, including those in transactions committed before any error. A perTx of zero or less removes every key in a single
+// transaction and an empty prefix removes every key in the bucket.
+//
+// The operation is not atomic however it is resumable, as calling it again after an interruption removes the keys that remain.
+func (db *Database) DeletePrefixChunked(bucket, prefix []byte, perTx int) (int, error) {
+	return db.DeletePrefixChunkedWithOptions(bucket, prefix, DeleteRangeOptions{ChunkSize: perTx})
+}
+
+// DeletePrefixChunked removes every key that begins with prefix using transactions of at most perTx keys each.
+func (b *Bucket) DeletePrefixChunked(prefix []byte, perTx int) (int, error) {
+	return b.db.DeletePrefixChunked(b.bucket, prefix, perTx)
+}
+
+// DeletePrefixChunkedWithOptions performs the same process as DeletePrefixChunked with the transaction size and progress reporting
+// controlled by the provided DeleteRangeOptions.
+func (db *Database) DeletePrefixChunkedWithOptions(bucket, prefix []byte, opts DeleteRangeOptions) (int, error) {
+	prefix = db.canonicalKey(prefix)
+
+	return db.deleteRange(bucket, prefix, PrefixSuccessor(prefix), opts)
+}
+
+// DeletePrefixChunkedWithOptions performs the same process as DeletePrefixChunked with the behaviour controlled by the provided
+// DeleteRangeOptions.
+func (b *Bucket) DeletePrefixChunkedWithOptions(prefix []byte, opts DeleteRangeOptions) (int, error) {
+	return b.db.DeletePrefixChunkedWithOptions(b.bucket, prefix, opts)
+}
+
+// DeleteBucketChunked removes every key in the chosen bucket using transactions of at most perTx keys each, releasing the writer lock
+// between transactions, and finally removes the empty bucket along with any nested buckets it contains. This avoids the memory use and
+// long writer lock of DeleteBucket for very large buckets. A perTx of zero or less removes every key in a single transaction.
+//
+// The operation is not atomic however it is resumable, as calling it again after an interruption continues with the keys that remain.
+func (db *Database) DeleteBucketChunked(bucket []byte, perTx int) error {
+	return db.DeleteBucketChunkedWithOptions(bucket, DeleteRangeOptions{ChunkSize: perTx})
+}
+
+// DeleteBucketChunked removes every key in the bucket opened using transactions of at most perTx keys each, then removes the bucket.
+func (b *Bucket) DeleteBucketChunked(perTx int) error {
+	return b.db.DeleteBucketChunked(b.bucket, perTx)
+}
+
+// DeleteBucketChunkedWithOptions performs the same process as DeleteBucketChunked with the transaction size and progress reporting
+// controlled by the provided DeleteRangeOptions.
+func (db *Database) DeleteBucketChunkedWithOptions(bucket []byte, opts DeleteRangeOptions) error {
+	if isReserved(bucket) {
+		return ErrReservedBucket{bucket}
+	}
+
+	if _, err := db.deleteRange(bucket, nil, nil, opts); err != nil {
+		return err
+	}
+
+	return db.update(func(tx *bolt.Tx) error {
+		return db.deleteBucket(tx, bucket)
+	})
+}
+
+// DeleteBucketChunkedWithOptions performs the same process as DeleteBucketChunked with the behaviour controlled by the provided
+// DeleteRangeOptions.
+func (b *Bucket) DeleteBucketChunkedWithOptions(opts DeleteRangeOptions) error {
+	return b.db.DeleteBucketChunkedWithOptions(b.bucket, opts)
+}
+
+// deleteRange removes the keys between the already canonical start and end bounds as per DeleteRangeWithOptions.
+func (db *Database) deleteRange(bucket, start, end []byte, opts DeleteRangeOptions) (int, error) {
+	var total int
+
+	p := newProgress(opts.Progress, -1)
+
+	for {
+		var keys [][]byte
+
+		if err := db.update(func(tx *bolt.Tx) error {
+			b := lookupBucket(tx, bucket)
+			if b == nil {
+				return ErrBucketNotFound{bucket: bucket}
+			}
+
+			keys = keys[:0]
+
+			c := b.Cursor()
+			k, v := c.First()
+			if start != nil {
+				k, v = c.Seek(start)
+			}
+
+			for ; k != nil && (end == nil || bytes.Compare(k, end) < 0); k, v = c.Next() {
+				if opts.ChunkSize > 0 && len(keys) == opts.ChunkSize {
+					break
+				}
+
+				// skip nested buckets
+				if v == nil {
+					continue
+				}
+
+				keys = append(keys, append([]byte{}, k...))
+			}
+
+			// keys are gathered before any are removed, as deleting while iterating a bbolt cursor may skip the following key
+			for _, k := range keys {
+				prev := previous(tx, bucket, b, k)
+
+				if err := b.Delete(k); err != nil {
+					return err
+				}
+
+				if err := db.onMutation(tx, mutation{op: OpDelete, bucket: bucket, key: k, prev: prev}); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}); err != nil {
+			return total, err
+		}
+
+		total += len(keys)
+		p.add(int64(len(keys)))
+
+		if opts.ChunkSize <= 0 || len(keys) < opts.ChunkSize {
+			p.finish()
+
+			return total, nil
+		}
+
+		// resume after the last key removed, skipping any nested buckets already passed
+		start = append(keys[len(keys)-1], 0)
+	}
+}
@@ -0,0 +1,186 @@
+package ubolt
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteRange(t *testing.T) {
+	tests := []struct {
+		name  string
+		start []byte
+		end   []byte
+		chunk int
+		want  []string
+	}{
+		{"all", nil, nil, 0, nil},
+		{"start inclusive", []byte("key3"), nil, 0, []string{"key0", "key1", "key2"}},
+		{"end exclusive", nil, []byte("key3"), 0, []string{"key3", "key4", "key5"}},
+		{"between", []byte("key1"), []byte("key4"), 0, []string{"key0", "key4", "key5"}},
+		{"bounds between keys", []byte("key1a"), []byte("key3a"), 0, []string{"key0", "key1", "key4", "key5"}},
+		{"empty range", []byte("key3"), []byte("key3"), 0, []string{"key0", "key1", "key2", "key3", "key4", "key5"}},
+		{"chunked", []byte("key1"), []byte("key5"), 3, []string{"key0", "key5"}},
+		{"chunked exact", nil, nil, 2, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = os.Remove(testdb)
+			defer os.Remove(testdb)
+
+			b, err := OpenBucket(testdb, testbucket)
+			if err != nil {
+				panic(err)
+			}
+			defer b.Close()
+
+			for i := 0; i < 6; i++ {
+				if err := b.Put([]byte(fmt.Sprintf("key%d", i)), testvalue); err != nil {
+					panic(err)
+				}
+			}
+
+			n, err := b.DeleteRangeWithOptions(tt.start, tt.end, DeleteRangeOptions{ChunkSize: tt.chunk})
+			assert.Nil(t, err, "DeleteRange")
+			assert.Equal(t, 6-len(tt.want), n, "DeleteRange - count")
+			assert.Equal(t, tt.want, b.GetKeysString(), "DeleteRange - remaining")
+		})
+	}
+}
+
+func TestDeleteChunked(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	db, err := Open(testdb)
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	bucket := []byte("huge")
+	if err := db.CreateBucket(bucket); err != nil {
+		panic(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		if err := db.Put(bucket, []byte(fmt.Sprintf("a%d", i)), testvalue); err != nil {
+			panic(err)
+		}
+
+		if err := db.Put(bucket, []byte(fmt.Sprintf("b%d", i)), testvalue); err != nil {
+			panic(err)
+		}
+	}
+
+	var progress [][2]int64
+	n, err := db.DeletePrefixChunkedWithOptions(bucket, []byte("a"), DeleteRangeOptions{ChunkSize: 4, Progress: func(done, total int64) {
+		progress = append(progress, [2]int64{done, total})
+	}})
+	assert.Nil(t, err, "DeletePrefixChunked")
+	assert.Equal(t, 10, n, "DeletePrefixChunked - count")
+	assert.Equal(t, [][2]int64{{10, -1}}, progress, "DeletePrefixChunked - progress")
+	assert.Len(t, db.GetKeys(bucket), 10, "DeletePrefixChunked - remaining")
+
+	// simulate an interrupted run that only removed the first chunk
+	n, err = db.DeleteRange(bucket, nil, []byte("b3"))
+	assert.Nil(t, err, "DeleteRange")
+	assert.Equal(t, 3, n, "DeleteRange - count")
+
+	progress = nil
+	err = db.DeleteBucketChunkedWithOptions(bucket, DeleteRangeOptions{ChunkSize: 3, Progress: func(done, total int64) {
+		progress = append(progress, [2]int64{done, total})
+	}})
+	assert.Nil(t, err, "DeleteBucketChunked")
+	assert.Equal(t, [][2]int64{{7, -1}}, progress, "DeleteBucketChunked - progress")
+	assert.NotContains(t, db.GetBuckets(), bucket, "DeleteBucketChunked - bucket removed")
+
+	assert.ErrorIs(t, db.DeleteBucketChunked(bucket, 3), ErrBucketNotFound{}, "DeleteBucketChunked - missing")
+	assert.ErrorIs(t, db.DeleteBucketChunked(metaBucket, 3), ErrReservedBucket{}, "DeleteBucketChunked - reserved")
+}
+
+func TestDeletePrefix(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	for _, k := range []string{"a", "ab1", "ab2", "ab3", "ac", "b", "\xff\xff1", "\xff\xff2"} {
+		if err := b.Put([]byte(k), testvalue); err != nil {
+			panic(err)
+		}
+	}
+
+	if err := b.db.CreateBucket(BucketPath(testbucket, []byte("ab4"))); err != nil {
+		panic(err)
+	}
+
+	n, err := b.DeletePrefix([]byte("ab"))
+	assert.Nil(t, err, "DeletePrefix")
+	assert.Equal(t, 3, n, "DeletePrefix - count")
+	assert.Equal(t, []string{"a", "ab4", "ac", "b", "\xff\xff1", "\xff\xff2"}, b.GetKeysString(), "DeletePrefix - remaining with nested bucket")
+
+	n, err = b.DeletePrefix([]byte("\xff\xff"))
+	assert.Nil(t, err, "DeletePrefix - no successor")
+	assert.Equal(t, 2, n, "DeletePrefix - no successor count")
+
+	n, err = b.DeletePrefix([]byte("zz"))
+	assert.Nil(t, err, "DeletePrefix - no match")
+	assert.Equal(t, 0, n, "DeletePrefix - no match count")
+
+	for _, prefix := range [][]byte{nil, {}} {
+		n, err = b.DeletePrefix(prefix)
+		assert.ErrorIs(t, err, ErrEmptyPrefix{}, "DeletePrefix - empty prefix")
+		assert.Equal(t, 0, n, "DeletePrefix - empty prefix count")
+	}
+	assert.Equal(t, []string{"a", "ab4", "ac", "b"}, b.GetKeysString(), "DeletePrefix - empty prefix untouched")
+
+	_, err = b.db.DeletePrefix(missing, []byte("a"))
+	assert.ErrorIs(t, err, ErrBucketNotFound{}, "DeletePrefix - missing bucket")
+}
+
+func TestDeleteRangeLarge(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	// ordered keys spread across many pages
+	const count = 5000
+	entries := make([]Entry, count)
+	for i := range entries {
+		entries[i] = Entry{Key: Int64Key(int64(i)), Value: testvalue}
+	}
+
+	if err := b.PutBatch(entries); err != nil {
+		panic(err)
+	}
+
+	n, err := b.DeleteRange(nil, Int64Key(3000))
+	assert.Nil(t, err, "DeleteRange")
+	assert.Equal(t, 3000, n, "DeleteRange - count")
+
+	keys := b.GetKeys()
+	assert.Len(t, keys, count-3000, "DeleteRange - remaining")
+	for i, k := range keys {
+		if !assert.Equal(t, Int64Key(int64(3000+i)), k, "DeleteRange - remaining key") {
+			break
+		}
+	}
+
+	n, err = b.DeleteRange(Int64Key(3500), nil)
+	assert.Nil(t, err, "DeleteRange - to last key")
+	assert.Equal(t, count-3500, n, "DeleteRange - to last key count")
+	assert.Len(t, b.GetKeys(), 500, "DeleteRange - to last key remaining")
+}
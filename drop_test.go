@@ -0,0 +1,44 @@
+package ubolt
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDropRecreate(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.Nil(t, b.Put(testkey, testvalue), "Put")
+	_, err = b.PutV(testvalue)
+	assert.Nil(t, err, "PutV")
+
+	assert.Nil(t, b.Drop(), "Drop")
+
+	// operations on a dropped handle fail
+	assert.ErrorIs(t, b.Put(testkey, testvalue), ErrBucketNotFound{}, "Put - dropped")
+	_, err = b.GetE(testkey)
+	assert.ErrorIs(t, err, ErrBucketNotFound{}, "GetE - dropped")
+	assert.ErrorIs(t, b.Ping(), ErrBucketNotFound{}, "Ping - dropped")
+	assert.NotNil(t, b.Drop(), "Drop - dropped")
+
+	assert.Nil(t, b.Recreate(), "Recreate")
+	assert.Nil(t, b.Put(testkey, testvalue), "Put - recreated")
+	assert.Equal(t, testvalue, b.Get(testkey), "Get - recreated")
+
+	// recreating an existing bucket resets its contents and sequence
+	assert.Nil(t, b.Recreate(), "Recreate - existing")
+	assert.Empty(t, b.GetKeys(), "GetKeys - recreated")
+
+	id, err := b.PutVID(testvalue)
+	assert.Nil(t, err, "PutVID - recreated")
+	assert.Equal(t, uint64(1), id, "PutVID - sequence reset")
+}
@@ -0,0 +1,86 @@
+package ubolt
+
+import (
+	bolt "go.etcd.io/bbolt"
+)
+
+// EmptyBucketOptions control the behaviour of EmptyBucketWithOptions.
+type EmptyBucketOptions struct {
+	// PreserveSequence keeps the sequence of the bucket, so ids returned by PutV and PutVID continue from those issued before the bucket was
+	// emptied rather than starting again from one.
+	PreserveSequence bool
+}
+
+// EmptyBucket removes every key and nested bucket from the chosen bucket by deleting and creating it again within a single read/write
+// transaction, so other transactions never see the bucket missing. As with Recreate the sequence of the bucket is reset, which may be
+// avoided using EmptyBucketWithOptions. ErrBucketNotFound is returned if the bucket does not exist.
+func (db *Database) EmptyBucket(bucket []byte) error {
+	return db.EmptyBucketWithOptions(bucket, EmptyBucketOptions{})
+}
+
+// Empty removes every key and nested bucket from the bucket opened within a single read/write transaction. This is forwarded to the
+// Database implementation.
+func (b *Bucket) Empty() error {
+	return b.db.EmptyBucket(b.bucket)
+}
+
+// EmptyBucketWithOptions performs the same process as EmptyBucket with the behaviour controlled by the provided EmptyBucketOptions.
+func (db *Database) EmptyBucketWithOptions(bucket []byte, opts EmptyBucketOptions) error {
+	if isReserved(bucket) {
+		return ErrReservedBucket{bucket}
+	}
+
+	return db.update(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		sequence := b.Sequence()
+
+		var encoding []byte
+		if marker := tx.Bucket(sequenceBucket); marker != nil {
+			encoding = append(encoding, marker.Get(bucket)...)
+		}
+
+		if err := db.deleteBucket(tx, bucket); err != nil {
+			return err
+		}
+
+		b, err := createBucketPath(tx, bucket)
+		if err != nil {
+			return err
+		}
+
+		if opts.PreserveSequence {
+			if err := b.SetSequence(sequence); err != nil {
+				return err
+			}
+		}
+
+		// the sequence is passed to a mirror along with the bucket
+		db.recordMirror(tx, mutation{op: opCreateBucket, bucket: bucket})
+
+		if !opts.PreserveSequence || sequence == 0 {
+			return nil
+		}
+
+		// the key encoding marker must match the preserved sequence so ids keep the same form
+		if len(encoding) == 0 {
+			return nil
+		}
+
+		marker, err := tx.CreateBucketIfNotExists(sequenceBucket)
+		if err != nil {
+			return err
+		}
+
+		return marker.Put(bucket, encoding)
+	})
+}
+
+// EmptyWithOptions performs the same process as Empty with the behaviour controlled by the provided EmptyBucketOptions. This is forwarded to
+// the Database implementation.
+func (b *Bucket) EmptyWithOptions(opts EmptyBucketOptions) error {
+	return b.db.EmptyBucketWithOptions(b.bucket, opts)
+}
@@ -0,0 +1,65 @@
+package ubolt
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmptyBucket(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     EmptyBucketOptions
+		encoding SequenceKeyEncoding
+		want     uint64
+	}{
+		{"reset sequence", EmptyBucketOptions{}, FixedSequenceKeys, 1},
+		{"preserve sequence", EmptyBucketOptions{PreserveSequence: true}, FixedSequenceKeys, 4},
+		{"preserve sequence with encoding", EmptyBucketOptions{PreserveSequence: true}, CompactSequenceKeys, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = os.Remove(testdb)
+			defer os.Remove(testdb)
+
+			b, err := OpenBucket(testdb, testbucket, WithSequenceKeyEncoding(tt.encoding))
+			if err != nil {
+				panic(err)
+			}
+			defer b.Close()
+
+			for i := 0; i < 3; i++ {
+				if _, err := b.PutV(testvalue); err != nil {
+					panic(err)
+				}
+			}
+
+			assert.Nil(t, b.Put(testkey, testvalue), "Put")
+			assert.Nil(t, b.db.CreateBucket(BucketPath(testbucket, []byte("nested"))), "CreateBucket - nested")
+
+			assert.Nil(t, b.EmptyWithOptions(tt.opts), "EmptyWithOptions")
+			assert.Empty(t, b.GetKeys(), "GetKeys - emptied")
+			assert.Nil(t, b.Ping(), "Ping - bucket kept")
+
+			id, err := b.PutVID(testvalue)
+			assert.Nil(t, err, "PutVID - emptied")
+			assert.Equal(t, tt.want, id, "PutVID - sequence")
+		})
+	}
+}
+
+func TestEmptyBucketErrors(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	db, err := Open(testdb)
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	assert.ErrorIs(t, db.EmptyBucket(missing), ErrBucketNotFound{}, "EmptyBucket - missing")
+	assert.ErrorIs(t, db.EmptyBucket(metaBucket), ErrReservedBucket{}, "EmptyBucket - reserved")
+}
@@ -0,0 +1,250 @@
+package ubolt
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// encodingMagic begins the header written by Encode. Neither a gob stream nor a text encoding such as JSON can begin with this byte, so
+// values written before the header was introduced are still recognised.
+const encodingMagic = 0xe5
+
+// encodingHeaderSize is the length of the header, which is the magic byte, the CodecID and the flags.
+const encodingHeaderSize = 3
+
+// CodecID identifies the Codec that encoded a value in the header written by Encode.
+type CodecID uint8
+
+const (
+	// CodecCustom is recorded for values encoded by a Codec that does not implement IdentifiedCodec. These values are decoded using the
+	// configured Codec.
+	CodecCustom CodecID = 0
+	// CodecGob is recorded for values encoded by GobCodec.
+	CodecGob CodecID = 1
+	// CodecMsgpack is recorded for values encoded by MsgpackCodec, which is registered by the msgpack package.
+	CodecMsgpack CodecID = 2
+	// CodecCBOR is recorded for values encoded by CBORCodec, which is registered by the cbor package.
+	CodecCBOR CodecID = 3
+)
+
+// String returns the name of the codec.
+func (id CodecID) String() string {
+	switch id {
+	case CodecCustom:
+		return "custom"
+	case CodecGob:
+		return "gob"
+	case CodecMsgpack:
+		return "msgpack"
+	case CodecCBOR:
+		return "cbor"
+	}
+
+	return fmt.Sprintf("unknown(%d)", uint8(id))
+}
+
+// IdentifiedCodec is implemented by a Codec that records its identity in the header written by Encode, which allows its values to be
+// decoded even once the configured Codec has changed.
+type IdentifiedCodec interface {
+	Codec
+	CodecID() CodecID
+}
+
+// EncodingInfo describes how a value written by Encode was encoded.
+type EncodingInfo struct {
+	// Header is false for values written without an encoding header, which are decoded using the configured Codec.
+	Header bool
+	// Codec is the codec that encoded the value. This is CodecCustom when there is no header.
+	Codec CodecID
+	// Flags are reserved for features such as compression and are currently always zero.
+	Flags uint8
+	// Size is the length of the encoded value excluding the header.
+	Size int
+}
+
+// ErrUnsupportedEncoding is returned when a value has an encoding header with a codec or flags that cannot be decoded.
+type ErrUnsupportedEncoding struct {
+	codec CodecID
+	flags uint8
+}
+
+// Error returns the formatted configuration error.
+func (ue ErrUnsupportedEncoding) Error() string {
+	return fmt.Sprintf("Unsupported encoding using codec %s with flags %#x", ue.codec, ue.flags)
+}
+
+// Is allows testing using errors.Is
+func (ue ErrUnsupportedEncoding) Is(target error) bool {
+	_, is := target.(ErrUnsupportedEncoding)
+
+	return is
+}
+
+// ErrNotEncodable is returned by Encode when the value cannot be encoded, either because it is nil or because the Codec returned an error.
+type ErrNotEncodable struct {
+	bucket []byte
+	key    []byte
+	typ    string
+	err    error
+}
+
+// Error returns the formatted configuration error.
+func (ne ErrNotEncodable) Error() string {
+	return fmt.Sprintf("Value of type %s for key %s in bucket %s cannot be encoded: %v", ne.typ, string(ne.key), bucketName(ne.bucket), ne.err)
+}
+
+// Is allows testing using errors.Is
+func (ne ErrNotEncodable) Is(target error) bool {
+	_, is := target.(ErrNotEncodable)
+
+	return is
+}
+
+// Unwrap returns the reason the value could not be encoded, which may be the error returned by the Codec.
+func (ne ErrNotEncodable) Unwrap() error {
+	return ne.err
+}
+
+// ErrInvalidDestination is returned by Decode and similar methods when the value to decode into is not a non-nil pointer.
+type ErrInvalidDestination struct {
+	typ string
+}
+
+// Error returns the formatted configuration error.
+func (id ErrInvalidDestination) Error() string {
+	return fmt.Sprintf("Cannot decode into value of type %s as it is not a non-nil pointer", id.typ)
+}
+
+// Is allows testing using errors.Is
+func (id ErrInvalidDestination) Is(target error) bool {
+	_, is := target.(ErrInvalidDestination)
+
+	return is
+}
+
+var (
+	errNilValue   = errors.New("value is nil")
+	errNilPointer = errors.New("value is a nil pointer")
+)
+
+// encode validates and encodes value for storage in the key of the chosen bucket, returning ErrNotEncodable on failure.
+func (db *Database) encode(bucket, key []byte, value interface{}) ([]byte, error) {
+	if value == nil {
+		return nil, ErrNotEncodable{bucket: bucket, key: key, typ: "<nil>", err: errNilValue}
+	}
+
+	typ := fmt.Sprintf("%T", value)
+
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil, ErrNotEncodable{bucket: bucket, key: key, typ: typ, err: errNilPointer}
+	}
+
+	data, err := db.marshal(value)
+	if err != nil {
+		return nil, ErrNotEncodable{bucket: bucket, key: key, typ: typ, err: err}
+	}
+
+	return data, nil
+}
+
+// checkDestination returns ErrInvalidDestination unless value is a non-nil pointer.
+func checkDestination(value interface{}) error {
+	if rv := reflect.ValueOf(value); rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return ErrInvalidDestination{typ: fmt.Sprintf("%T", value)}
+	}
+
+	return nil
+}
+
+// codecID returns the identity of the codec as recorded in the encoding header.
+func codecID(c Codec) CodecID {
+	if ic, ok := c.(IdentifiedCodec); ok {
+		return ic.CodecID()
+	}
+
+	return CodecCustom
+}
+
+// parseEncoding splits a value written by Encode into its EncodingInfo and encoded payload.
+func parseEncoding(data []byte) (EncodingInfo, []byte) {
+	if len(data) < encodingHeaderSize || data[0] != encodingMagic {
+		return EncodingInfo{Size: len(data)}, data
+	}
+
+	payload := data[encodingHeaderSize:]
+
+	return EncodingInfo{Header: true, Codec: CodecID(data[1]), Flags: data[2], Size: len(payload)}, payload
+}
+
+// marshal encodes v using the configured Codec and prepends the encoding header.
+func (db *Database) marshal(v interface{}) ([]byte, error) {
+	return marshalWith(db.codec, v)
+}
+
+// marshalWith encodes v using c and prepends the encoding header.
+func marshalWith(c Codec, v interface{}) ([]byte, error) {
+	payload, err := c.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, encodingHeaderSize, encodingHeaderSize+len(payload))
+	data[0], data[1], data[2] = encodingMagic, byte(codecID(c)), 0
+
+	return append(data, payload...), nil
+}
+
+// unmarshal decodes a value written by marshal, or a value without a header using the configured Codec.
+func (db *Database) unmarshal(data []byte, v interface{}) error {
+	info, payload := parseEncoding(data)
+	if !info.Header {
+		return db.codec.Unmarshal(payload, v)
+	}
+
+	if info.Flags != 0 {
+		return ErrUnsupportedEncoding{codec: info.Codec, flags: info.Flags}
+	}
+
+	if info.Codec == CodecCustom || info.Codec == codecID(db.codec) {
+		return db.codec.Unmarshal(payload, v)
+	}
+
+	if c, ok := registeredCodec(info.Codec); ok {
+		return c.Unmarshal(payload, v)
+	}
+
+	return ErrUnsupportedEncoding{codec: info.Codec, flags: info.Flags}
+}
+
+// DecodeValue decodes a value read from the database, such as by ForEach or Scan, that was written by Encode into the provided pointer
+// value.
+func (db *Database) DecodeValue(data []byte, value interface{}) error {
+	if err := checkDestination(value); err != nil {
+		return err
+	}
+
+	return db.unmarshal(data, value)
+}
+
+// DecodeValue decodes a value read from the database that was written by Encode into the provided pointer value.
+func (b *Bucket) DecodeValue(data []byte, value interface{}) error {
+	return b.db.DecodeValue(data, value)
+}
+
+// ValueInfo returns how the value of the specified key in the chosen bucket was encoded. Errors are returned as per GetE.
+func (db *Database) ValueInfo(bucket, key []byte) (EncodingInfo, error) {
+	data, err := db.GetE(bucket, key)
+	if err != nil {
+		return EncodingInfo{}, err
+	}
+
+	info, _ := parseEncoding(data)
+
+	return info, nil
+}
+
+// ValueInfo returns how the value of the specified key was encoded. Errors are returned as per GetE.
+func (b *Bucket) ValueInfo(key []byte) (EncodingInfo, error) {
+	return b.db.ValueInfo(b.bucket, key)
+}
@@ -0,0 +1,147 @@
+package ubolt
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodingHeader(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+
+	want := profile{Name: "alice", Age: 30}
+
+	assert.Nil(t, b.Encode([]byte("gob"), want), "Encode")
+
+	info, err := b.ValueInfo([]byte("gob"))
+	assert.Nil(t, err, "ValueInfo")
+	assert.True(t, info.Header, "ValueInfo - header")
+	assert.Equal(t, CodecGob, info.Codec, "ValueInfo - codec")
+	assert.Equal(t, "gob", info.Codec.String(), "CodecID.String")
+
+	// values written before the header was introduced are still decoded
+	legacy, err := GobCodec.Marshal(want)
+	assert.Nil(t, err, "Marshal")
+	assert.Nil(t, b.Put([]byte("legacy"), legacy), "Put")
+
+	info, err = b.ValueInfo([]byte("legacy"))
+	assert.Nil(t, err, "ValueInfo - legacy")
+	assert.False(t, info.Header, "ValueInfo - legacy")
+	assert.Equal(t, len(legacy), info.Size, "ValueInfo - legacy size")
+
+	var got profile
+	assert.Nil(t, b.Decode([]byte("legacy"), &got), "Decode - legacy")
+	assert.Equal(t, want, got, "Decode - legacy")
+
+	// unknown flags are rejected
+	assert.Nil(t, b.Put([]byte("flags"), []byte{encodingMagic, byte(CodecGob), 0x80}), "Put")
+	assert.ErrorIs(t, b.Decode([]byte("flags"), &got), ErrUnsupportedEncoding{}, "Decode - flags")
+
+	assert.Nil(t, b.Close(), "Close")
+
+	// gob values remain readable after the codec changes
+	b, err = OpenBucket(testdb, testbucket, WithCodec(jsonCodec{}))
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	got = profile{}
+	assert.Nil(t, b.Decode([]byte("gob"), &got), "Decode - gob with json codec")
+	assert.Equal(t, want, got, "Decode - gob with json codec")
+
+	assert.Nil(t, b.Encode([]byte("json"), want), "Encode - json")
+
+	info, err = b.ValueInfo([]byte("json"))
+	assert.Nil(t, err, "ValueInfo - json")
+	assert.Equal(t, CodecCustom, info.Codec, "ValueInfo - json")
+
+	got = profile{}
+	assert.Nil(t, b.Decode([]byte("json"), &got), "Decode - json")
+	assert.Equal(t, want, got, "Decode - json")
+}
+
+func TestEncodeErrors(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	type unexported struct {
+		name string
+	}
+
+	type withChan struct {
+		C chan int
+	}
+
+	var nilProfile *profile
+
+	encodeTests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"nil", nil, "Value of type <nil> for key key1 in bucket bucket1 cannot be encoded: value is nil"},
+		{"nil pointer", nilProfile, "Value of type *ubolt.profile for key key1 in bucket bucket1 cannot be encoded: value is a nil pointer"},
+		{"unexported fields", unexported{"x"}, "Value of type ubolt.unexported for key key1 in bucket bucket1 cannot be encoded: gob: type ubolt.unexported has no exported fields"},
+		{"channel field", withChan{make(chan int)}, "Value of type ubolt.withChan for key key1 in bucket bucket1 cannot be encoded: gob: type ubolt.withChan has no exported fields"},
+	}
+
+	for _, tt := range encodeTests {
+		err := b.Encode(testkey, tt.value)
+		assert.ErrorIs(t, err, ErrNotEncodable{}, tt.name)
+		assert.EqualError(t, err, tt.want, tt.name)
+	}
+
+	assert.False(t, b.Exists(testkey), "Exists - nothing written")
+
+	var p profile
+	decodeTests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"nil", nil, "Cannot decode into value of type <nil> as it is not a non-nil pointer"},
+		{"non-pointer", p, "Cannot decode into value of type ubolt.profile as it is not a non-nil pointer"},
+		{"nil pointer", nilProfile, "Cannot decode into value of type *ubolt.profile as it is not a non-nil pointer"},
+	}
+
+	for _, tt := range decodeTests {
+		// the key does not exist so the destination must be checked first
+		err := b.Decode(testkey, tt.value)
+		assert.ErrorIs(t, err, ErrInvalidDestination{}, tt.name)
+		assert.EqualError(t, err, tt.want, tt.name)
+	}
+}
+
+func TestCodecNotRegistered(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket, WithCodec(codecRef{201}))
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.ErrorIs(t, b.Encode(testkey, "value"), ErrCodecNotRegistered{}, "Encode - not registered")
+
+	if err := b.Put(testkey, []byte{encodingMagic, 201, 0, 0xa0}); err != nil {
+		panic(err)
+	}
+
+	var got string
+	assert.ErrorIs(t, b.Decode(testkey, &got), ErrCodecNotRegistered{}, "Decode - not registered")
+}
@@ -0,0 +1,320 @@
+package ubolt
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// The encrypted backup format begins with a header of encryptedMagic, a version byte and a random salt. A key for AES-256-GCM is derived
+// from the provided key as HMAC-SHA256(key, header), so every backup is sealed with a different key even when the same key is reused.
+//
+// The output of WriteTo follows as a series of chunks, each holding up to encryptedChunkSize bytes. A chunk is a big-endian uint32 giving
+// the length of its plaintext, with the top bit set for the final chunk, followed by the sealed plaintext. Each chunk is sealed using the
+// header as additional data and a nonce holding the chunk number and the final chunk flag, so chunks can not be modified, reordered,
+// dropped or truncated without failing authentication.
+const (
+	encryptedVersion byte = 1
+
+	// encryptedSaltSize is the length of the random salt in the header
+	encryptedSaltSize = 32
+
+	// encryptedChunkSize is the maximum length of the plaintext of each chunk
+	encryptedChunkSize = 64 << 10
+
+	// encryptedFinal is set in the length of the final chunk
+	encryptedFinal = 1 << 31
+)
+
+var encryptedMagic = []byte("UBOLTENC")
+
+// ErrDecrypt is returned when an encrypted backup can not be decrypted, because the key is wrong, the backup has been modified or
+// truncated, or the data is not an encrypted backup.
+type ErrDecrypt struct {
+	reason string
+}
+
+// Error returns the formatted configuration error.
+func (de ErrDecrypt) Error() string {
+	return fmt.Sprintf("Could not decrypt backup: %s", de.reason)
+}
+
+// Is allows testing using errors.Is
+func (de ErrDecrypt) Is(target error) bool {
+	_, is := target.(ErrDecrypt)
+
+	return is
+}
+
+// WriteToEncrypted performs the same process as WriteTo however the consistent copy of the database is encrypted and authenticated using
+// AES-256-GCM before being written to w, so a backup may be kept on untrusted storage. The key must be 16, 24 or 32 bytes long, and the
+// number of encrypted bytes written to w is returned.
+//
+// The backup may be restored using DecryptBackup or OpenEncryptedBackup with the same key.
+func (db *Database) WriteToEncrypted(w io.Writer, key []byte) (int64, error) {
+	ew, err := newEncryptWriter(w, key)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := db.WriteTo(ew); err != nil {
+		return ew.n, err
+	}
+
+	err = ew.Close()
+
+	return ew.n, err
+}
+
+// WriteToEncrypted performs the same process as WriteTo however the copy of the database is encrypted before being written to w. This is
+// forwarded to the Database implementation.
+func (b *Bucket) WriteToEncrypted(w io.Writer, key []byte) (int64, error) {
+	return b.db.WriteToEncrypted(w, key)
+}
+
+// DecryptBackup reads an encrypted backup written by WriteToEncrypted from r and writes the decrypted database file to w. An ErrDecrypt is
+// returned if the key is wrong or the backup has been modified or truncated.
+//
+// The backup is decrypted in chunks, each of which is authenticated before it is written, however a backup that was modified or truncated
+// is only detected once the affected chunk is reached. Anything written to w must be discarded unless DecryptBackup returns nil.
+func DecryptBackup(r io.Reader, w io.Writer, key []byte) error {
+	header := make([]byte, len(encryptedMagic)+1+encryptedSaltSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return ErrDecrypt{reason: "missing header"}
+		}
+
+		return err
+	}
+
+	if !bytes.Equal(header[:len(encryptedMagic)], encryptedMagic) {
+		return ErrDecrypt{reason: "not an encrypted backup"}
+	}
+
+	if version := header[len(encryptedMagic)]; version != encryptedVersion {
+		return ErrDecrypt{reason: fmt.Sprintf("unsupported version %d", version)}
+	}
+
+	aead, err := backupCipher(key, header)
+	if err != nil {
+		return err
+	}
+
+	br := bufio.NewReader(r)
+	sealed := make([]byte, encryptedChunkSize+aead.Overhead())
+	plaintext := make([]byte, 0, encryptedChunkSize)
+
+	for counter := uint64(0); ; counter++ {
+		var length [4]byte
+		if _, err := io.ReadFull(br, length[:]); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return ErrDecrypt{reason: "backup is truncated"}
+			}
+
+			return err
+		}
+
+		n := binary.BigEndian.Uint32(length[:])
+		final := n&encryptedFinal != 0
+
+		size := int(n &^ encryptedFinal)
+		if size > encryptedChunkSize {
+			return ErrDecrypt{reason: fmt.Sprintf("chunk %d has an invalid length", counter)}
+		}
+
+		chunk := sealed[:size+aead.Overhead()]
+		if _, err := io.ReadFull(br, chunk); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return ErrDecrypt{reason: "backup is truncated"}
+			}
+
+			return err
+		}
+
+		out, err := aead.Open(plaintext[:0], backupNonce(counter, final), chunk, header)
+		if err != nil {
+			return ErrDecrypt{reason: fmt.Sprintf("authentication of chunk %d failed, the key is wrong or the backup was modified", counter)}
+		}
+
+		if _, err := w.Write(out); err != nil {
+			return err
+		}
+
+		if final {
+			break
+		}
+	}
+
+	if _, err := br.ReadByte(); !errors.Is(err, io.EOF) {
+		if err != nil {
+			return err
+		}
+
+		return ErrDecrypt{reason: "unexpected data after the final chunk"}
+	}
+
+	return nil
+}
+
+// OpenEncryptedBackup decrypts the encrypted backup read from r, as per DecryptBackup, into a new database file at path and opens it using
+// the provided options. The backup is decrypted into a temporary file in the same directory that is only renamed to path once every chunk
+// has been authenticated, so a backup that fails to decrypt never leaves a database at path. If a file already exists at path an
+// *fs.PathError wrapping fs.ErrExist is returned.
+func OpenEncryptedBackup(r io.Reader, key []byte, path string, opts ...Option) (*Database, error) {
+	if _, err := os.Stat(path); err == nil {
+		return nil, &fs.PathError{Op: "open encrypted backup", Path: path, Err: fs.ErrExist}
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+
+	if err := DecryptBackup(r, f, key); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return nil, err
+	}
+
+	return Open(path, opts...)
+}
+
+// backupCipher returns the AEAD that seals the chunks of the backup with the provided header.
+func backupCipher(key, header []byte) (cipher.AEAD, error) {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, aes.KeySizeError(len(key))
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(header)
+
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// backupNonce returns the nonce of a chunk, which is the big-endian chunk number followed by a byte that is 1 for the final chunk.
+func backupNonce(counter uint64, final bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce, counter)
+
+	if final {
+		nonce[11] = 1
+	}
+
+	return nonce
+}
+
+// encryptWriter seals the data written to it in chunks, writing the final chunk on Close.
+type encryptWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	header  []byte
+	buf     []byte
+	counter uint64
+	// n is the number of bytes written to w
+	n int64
+}
+
+// newEncryptWriter writes the header of a new encrypted backup to w and returns an encryptWriter for its contents.
+func newEncryptWriter(w io.Writer, key []byte) (*encryptWriter, error) {
+	header := make([]byte, len(encryptedMagic)+1+encryptedSaltSize)
+	copy(header, encryptedMagic)
+	header[len(encryptedMagic)] = encryptedVersion
+
+	if _, err := rand.Read(header[len(encryptedMagic)+1:]); err != nil {
+		return nil, err
+	}
+
+	aead, err := backupCipher(key, header)
+	if err != nil {
+		return nil, err
+	}
+
+	ew := &encryptWriter{w: w, aead: aead, header: header, buf: make([]byte, 0, encryptedChunkSize)}
+
+	if err := ew.write(header); err != nil {
+		return nil, err
+	}
+
+	return ew, nil
+}
+
+// Write buffers p, sealing each chunk once it is full and more data follows so the final chunk is always sealed by Close.
+func (ew *encryptWriter) Write(p []byte) (written int, err error) {
+	for len(p) > 0 {
+		if len(ew.buf) == encryptedChunkSize {
+			if err := ew.seal(false); err != nil {
+				return written, err
+			}
+		}
+
+		n := min(encryptedChunkSize-len(ew.buf), len(p))
+		ew.buf = append(ew.buf, p[:n]...)
+		p = p[n:]
+		written += n
+	}
+
+	return written, nil
+}
+
+// Close seals and writes the final chunk.
+func (ew *encryptWriter) Close() error {
+	return ew.seal(true)
+}
+
+func (ew *encryptWriter) seal(final bool) error {
+	length := uint32(len(ew.buf))
+	if final {
+		length |= encryptedFinal
+	}
+
+	chunk := make([]byte, 4, 4+len(ew.buf)+ew.aead.Overhead())
+	binary.BigEndian.PutUint32(chunk, length)
+	chunk = ew.aead.Seal(chunk, backupNonce(ew.counter, final), ew.buf, ew.header)
+
+	if err := ew.write(chunk); err != nil {
+		return err
+	}
+
+	ew.counter++
+	ew.buf = ew.buf[:0]
+
+	return nil
+}
+
+func (ew *encryptWriter) write(p []byte) error {
+	n, err := ew.w.Write(p)
+	ew.n += int64(n)
+
+	return err
+}
@@ -0,0 +1,99 @@
+package ubolt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"fmt"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteToEncrypted(t *testing.T) {
+	const restored = "restored.db"
+
+	_ = os.Remove(testdb)
+	_ = os.Remove(restored)
+	defer os.Remove(testdb)
+	defer os.Remove(restored)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	// enough data for the backup to span several chunks
+	for i := 0; i < 200; i++ {
+		assert.Nil(t, b.Put([]byte(fmt.Sprintf("key%03d", i)), bytes.Repeat([]byte{byte(i)}, 1024)), "Put")
+	}
+
+	key := bytes.Repeat([]byte{0x42}, 32)
+
+	var backup bytes.Buffer
+	n, err := b.WriteToEncrypted(&backup, key)
+	assert.Nil(t, err, "WriteToEncrypted")
+	assert.Equal(t, int64(backup.Len()), n, "WriteToEncrypted - bytes written")
+	assert.Greater(t, backup.Len(), 2*encryptedChunkSize, "WriteToEncrypted - several chunks")
+
+	var plain bytes.Buffer
+	_, err = b.db.WriteTo(&plain)
+	assert.Nil(t, err, "WriteTo")
+
+	var decrypted bytes.Buffer
+	assert.Nil(t, DecryptBackup(bytes.NewReader(backup.Bytes()), &decrypted, key), "DecryptBackup")
+	assert.Equal(t, plain.Bytes(), decrypted.Bytes(), "DecryptBackup")
+
+	// each backup uses a new salt so the same database encrypts differently
+	var again bytes.Buffer
+	_, err = b.WriteToEncrypted(&again, key)
+	assert.Nil(t, err, "WriteToEncrypted")
+	assert.NotEqual(t, backup.Bytes()[:100], again.Bytes()[:100], "WriteToEncrypted - salt")
+
+	db, err := OpenEncryptedBackup(bytes.NewReader(backup.Bytes()), key, restored)
+	assert.Nil(t, err, "OpenEncryptedBackup")
+	if db != nil {
+		assert.Equal(t, bytes.Repeat([]byte{7}, 1024), db.Get(testbucket, []byte("key007")), "OpenEncryptedBackup - Get")
+		assert.Nil(t, db.Close(), "Close")
+	}
+
+	_, err = OpenEncryptedBackup(bytes.NewReader(backup.Bytes()), key, restored)
+	assert.ErrorIs(t, err, fs.ErrExist, "OpenEncryptedBackup - existing file")
+	assert.Nil(t, os.Remove(restored), "Remove")
+
+	// a wrong key fails authentication
+	wrong := bytes.Repeat([]byte{0x43}, 32)
+	assert.ErrorIs(t, DecryptBackup(bytes.NewReader(backup.Bytes()), &bytes.Buffer{}, wrong), ErrDecrypt{}, "DecryptBackup - wrong key")
+
+	_, err = OpenEncryptedBackup(bytes.NewReader(backup.Bytes()), wrong, restored)
+	assert.ErrorIs(t, err, ErrDecrypt{}, "OpenEncryptedBackup - wrong key")
+	_, err = os.Stat(restored)
+	assert.ErrorIs(t, err, fs.ErrNotExist, "OpenEncryptedBackup - nothing left behind")
+
+	// any modified byte fails authentication
+	for _, offset := range []int{0, len(encryptedMagic) + 5, 100, encryptedChunkSize + 200, backup.Len() - 1} {
+		tampered := append([]byte{}, backup.Bytes()...)
+		tampered[offset] ^= 0x01
+
+		assert.ErrorIs(t, DecryptBackup(bytes.NewReader(tampered), &bytes.Buffer{}, key), ErrDecrypt{}, "DecryptBackup - tampered at %d", offset)
+	}
+
+	// truncation, including at a chunk boundary, and trailing data are detected
+	boundary := len(encryptedMagic) + 1 + encryptedSaltSize + 4 + encryptedChunkSize + 16
+
+	for name, data := range map[string][]byte{
+		"truncated":  backup.Bytes()[:backup.Len()-10],
+		"boundary":   backup.Bytes()[:boundary],
+		"header":     backup.Bytes()[:10],
+		"trailing":   append(append([]byte{}, backup.Bytes()...), 0x00),
+		"not backup": plain.Bytes(),
+	} {
+		assert.ErrorIs(t, DecryptBackup(bytes.NewReader(data), &bytes.Buffer{}, key), ErrDecrypt{}, "DecryptBackup - %s", name)
+	}
+
+	var sizeErr aes.KeySizeError
+	_, err = b.WriteToEncrypted(&bytes.Buffer{}, []byte("short"))
+	assert.ErrorAs(t, err, &sizeErr, "WriteToEncrypted - invalid key size")
+}
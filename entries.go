@@ -0,0 +1,107 @@
+package ubolt
+
+import (
+	bolt "go.etcd.io/bbolt"
+)
+
+// BucketEntry is a single write applied by PutEntries.
+type BucketEntry struct {
+	// Bucket is the name of the bucket written to, which may be a nested bucket path created using BucketPath.
+	Bucket []byte
+	// Key is the key written to.
+	Key []byte
+	// Value is the value written to the key, or nil to delete the key.
+	Value []byte
+}
+
+// PutEntriesOptions controls the behaviour of PutEntriesWithOptions.
+type PutEntriesOptions struct {
+	// CreateBuckets creates any bucket written to that does not exist rather than returning ErrBucketNotFound. Deleting a key from a
+	// bucket that does not exist does not create the bucket.
+	CreateBuckets bool
+}
+
+// PutEntries applies every entry in order within a single read/write transaction, so writes to multiple buckets, such as a record and its
+// index entry, are committed together or not at all. An entry with a nil Value deletes the key, and removing a key that does not exist is
+// not an error. When a key appears more than once the last entry wins, as the entries are applied in order.
+//
+// ErrBucketNotFound naming the first bucket that does not exist is returned and nothing is written unless PutEntriesWithOptions is used
+// with CreateBuckets set.
+func (db *Database) PutEntries(entries []BucketEntry) error {
+	return db.PutEntriesWithOptions(entries, PutEntriesOptions{})
+}
+
+// PutEntries applies every entry in order within a single read/write transaction. This is forwarded to the Database implementation.
+func (b *Bucket) PutEntries(entries []BucketEntry) error {
+	return b.db.PutEntries(entries)
+}
+
+// PutEntriesWithOptions performs the same process as PutEntries with the behaviour controlled by the provided PutEntriesOptions.
+func (db *Database) PutEntriesWithOptions(entries []BucketEntry, opts PutEntriesOptions) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	return db.update(func(tx *bolt.Tx) error {
+		buckets := make(map[string]*bolt.Bucket)
+
+		for _, e := range entries {
+			key := db.canonicalKey(e.Key)
+
+			b := buckets[string(e.Bucket)]
+			if b == nil {
+				if b = lookupBucket(tx, e.Bucket); b == nil {
+					if !opts.CreateBuckets {
+						return ErrBucketNotFound{bucket: e.Bucket}
+					}
+
+					// there is nothing to delete from a bucket that does not exist
+					if e.Value == nil {
+						continue
+					}
+
+					var err error
+					if b, err = createBucketPath(tx, e.Bucket); err != nil {
+						return err
+					}
+				}
+
+				buckets[string(e.Bucket)] = b
+			}
+
+			prev := previous(tx, e.Bucket, b, key)
+
+			if e.Value == nil {
+				if err := b.Delete(key); err != nil {
+					return err
+				}
+
+				if err := db.onMutation(tx, mutation{op: OpDelete, bucket: e.Bucket, key: key, prev: prev}); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			value, err := db.wrapValue(e.Bucket, key, e.Value)
+			if err != nil {
+				return err
+			}
+
+			if err := b.Put(key, value); err != nil {
+				return err
+			}
+
+			if err := db.onMutation(tx, mutation{op: OpPut, bucket: e.Bucket, key: key, value: value, prev: prev}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// PutEntriesWithOptions performs the same process as PutEntries with the behaviour controlled by the provided PutEntriesOptions.
+func (b *Bucket) PutEntriesWithOptions(entries []BucketEntry, opts PutEntriesOptions) error {
+	return b.db.PutEntriesWithOptions(entries, opts)
+}
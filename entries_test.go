@@ -0,0 +1,62 @@
+package ubolt
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPutEntries(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	db, err := Open(testdb)
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	records, index := []byte("records"), []byte("index")
+
+	for _, bucket := range [][]byte{records, index} {
+		if err := db.CreateBucket(bucket); err != nil {
+			panic(err)
+		}
+	}
+
+	if err := db.Put(records, []byte("old"), testvalue); err != nil {
+		panic(err)
+	}
+
+	assert.Nil(t, db.PutEntries(nil), "PutEntries - empty")
+
+	assert.Nil(t, db.PutEntries([]BucketEntry{
+		{Bucket: records, Key: []byte("1"), Value: []byte("first")},
+		{Bucket: index, Key: []byte("email"), Value: []byte("1")},
+		{Bucket: records, Key: []byte("1"), Value: []byte("second")},
+		{Bucket: records, Key: []byte("old")},
+		{Bucket: records, Key: []byte("missing")},
+	}), "PutEntries")
+	assert.Equal(t, []byte("second"), db.Get(records, []byte("1")), "PutEntries - last entry wins")
+	assert.Equal(t, []byte("1"), db.Get(index, []byte("email")), "PutEntries - second bucket")
+	assert.False(t, db.Exists(records, []byte("old")), "PutEntries - delete")
+
+	// nothing is written when a bucket does not exist
+	err = db.PutEntries([]BucketEntry{
+		{Bucket: records, Key: []byte("2"), Value: testvalue},
+		{Bucket: []byte("missing"), Key: testkey, Value: testvalue},
+		{Bucket: []byte("other"), Key: testkey, Value: testvalue},
+	})
+	assert.ErrorIs(t, err, ErrBucketNotFound{}, "PutEntries - missing bucket")
+	assert.Contains(t, err.Error(), "missing", "PutEntries - first missing bucket")
+	assert.False(t, db.Exists(records, []byte("2")), "PutEntries - not applied")
+
+	assert.Nil(t, db.PutEntriesWithOptions([]BucketEntry{
+		{Bucket: []byte("created"), Key: testkey},
+		{Bucket: []byte("created"), Key: testkey, Value: testvalue},
+		{Bucket: []byte("deleted"), Key: testkey},
+	}, PutEntriesOptions{CreateBuckets: true}), "PutEntriesWithOptions")
+	assert.Equal(t, testvalue, db.Get([]byte("created"), testkey), "PutEntriesWithOptions - created")
+	assert.NotContains(t, db.GetBuckets(), []byte("deleted"), "PutEntriesWithOptions - delete does not create")
+}
@@ -0,0 +1,77 @@
+package ubolt
+
+import (
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrFrozen is returned by mutating methods while the database is frozen and WithFailWhenFrozen was used.
+type ErrFrozen struct{}
+
+// Error returns the formatted configuration error.
+func (f ErrFrozen) Error() string {
+	return "Database is frozen"
+}
+
+// Is allows testing using errors.Is
+func (f ErrFrozen) Is(target error) bool {
+	_, is := target.(ErrFrozen)
+
+	return is
+}
+
+// WithFailWhenFrozen causes mutating methods to return ErrFrozen immediately while the database is frozen, rather than blocking until it is thawed.
+func WithFailWhenFrozen() Option {
+	return func(db *Database) {
+		db.failWhenFrozen = true
+	}
+}
+
+// Freeze blocks all subsequent writes until the returned thaw function is called, which allows external operations such as a filesystem
+// snapshot to run without any writes in flight. Freeze waits for any in-progress writes to complete before returning.
+//
+// While frozen, writes either block or fail with ErrFrozen depending on whether WithFailWhenFrozen was used. Reads continue to work as normal.
+// Calling the thaw function more than once has no effect.
+func (db *Database) Freeze() (thaw func(), err error) {
+	db.gate.Lock()
+
+	// ensure the database is still usable
+	if err := db.view(func(tx *bolt.Tx) error { return nil }); err != nil {
+		db.gate.Unlock()
+		return nil, err
+	}
+
+	var once sync.Once
+
+	return func() {
+		once.Do(db.gate.Unlock)
+	}, nil
+}
+
+// Freeze blocks all subsequent writes until the returned thaw function is called. This is forwarded to the Database implementation.
+func (b *Bucket) Freeze() (thaw func(), err error) {
+	return b.db.Freeze()
+}
+
+// FreezeTimeout performs the same process as Freeze however the database is automatically thawed after the provided duration if the thaw
+// function has not been called.
+func (db *Database) FreezeTimeout(d time.Duration) (thaw func(), err error) {
+	unfreeze, err := db.Freeze()
+	if err != nil {
+		return nil, err
+	}
+
+	t := time.AfterFunc(d, unfreeze)
+
+	return func() {
+		t.Stop()
+		unfreeze()
+	}, nil
+}
+
+// FreezeTimeout performs the same process as Freeze however the database is automatically thawed after the provided duration.
+func (b *Bucket) FreezeTimeout(d time.Duration) (thaw func(), err error) {
+	return b.db.FreezeTimeout(d)
+}
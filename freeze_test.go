@@ -0,0 +1,78 @@
+package ubolt
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFreeze(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	thaw, err := b.Freeze()
+	assert.Nil(t, err, "Freeze")
+
+	done := make(chan error)
+	go func() {
+		done <- b.Put(testkey, testvalue)
+	}()
+
+	// the write must block while frozen
+	select {
+	case <-done:
+		t.Fatal("Put completed while frozen")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// reads work while frozen
+	_, err = b.GetKeysE()
+	assert.Nil(t, err, "GetKeysE - frozen")
+
+	thaw()
+	thaw()
+
+	assert.Nil(t, <-done, "Put - after thaw")
+	assert.Equal(t, testvalue, b.Get(testkey), "Get - after thaw")
+
+	// automatic thaw
+	_, err = b.FreezeTimeout(50 * time.Millisecond)
+	assert.Nil(t, err, "FreezeTimeout")
+	assert.Nil(t, b.Put(testkey, testvalue), "Put - after timeout")
+}
+
+func TestFreezeFailFast(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket, WithFailWhenFrozen())
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	thaw, err := b.FreezeTimeout(time.Minute)
+	assert.Nil(t, err, "FreezeTimeout")
+
+	assert.ErrorIs(t, b.Put(testkey, testvalue), ErrFrozen{}, "Put - frozen")
+	assert.ErrorIs(t, b.Delete(testkey), ErrFrozen{}, "Delete - frozen")
+
+	thaw()
+
+	assert.Nil(t, b.Put(testkey, testvalue), "Put - thawed")
+
+	if err := b.Close(); err != nil {
+		panic(err)
+	}
+
+	_, err = b.Freeze()
+	assert.NotNil(t, err, "Freeze - closed")
+}
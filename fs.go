@@ -0,0 +1,62 @@
+package ubolt
+
+import (
+	"os"
+)
+
+// FS is the filesystem used to open, inspect, rename and remove the database file, which allows the database to be kept somewhere other
+// than the local filesystem, such as in memory during tests.
+//
+// Bolt memory maps, locks, syncs and truncates the database file using the *os.File returned by OpenFile, so these operations always act
+// on a real file descriptor and can not be replaced. Output from WriteTo and ExportArchive is written to the io.Writer provided by the caller
+// so does not use the FS.
+type FS interface {
+	// OpenFile opens the named file as per os.OpenFile.
+	OpenFile(name string, flag int, perm os.FileMode) (*os.File, error)
+	// Stat returns the FileInfo of the named file as per os.Stat.
+	Stat(name string) (os.FileInfo, error)
+	// Rename replaces newpath with oldpath as per os.Rename.
+	Rename(oldpath, newpath string) error
+	// Remove removes the named file as per os.Remove.
+	Remove(name string) error
+}
+
+// osFS is the FS used by default, which passes every operation to the os package.
+type osFS struct{}
+
+func (osFS) OpenFile(name string, flag int, perm os.FileMode) (*os.File, error) {
+	return os.OpenFile(name, flag, perm)
+}
+
+func (osFS) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (osFS) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+func (osFS) Remove(name string) error {
+	return os.Remove(name)
+}
+
+// WithFS sets the filesystem used to open the database file and the files created by CompactInPlace, and to find the size of the database
+// file. This replaces any function set using WithOpenFile. The ubolttest package provides an in-memory implementation and one that injects
+// faults for use in tests.
+func WithFS(fsys FS) Option {
+	return func(db *Database) {
+		db.fs = fsys
+		db.boltOptions.OpenFile = fsys.OpenFile
+	}
+}
+
+// openFileFS is the FS used with WithOpenFile, which opens files using the provided function and passes every other operation to the os
+// package.
+type openFileFS struct {
+	osFS
+	openFile func(name string, flag int, perm os.FileMode) (*os.File, error)
+}
+
+func (o openFileFS) OpenFile(name string, flag int, perm os.FileMode) (*os.File, error) {
+	return o.openFile(name, flag, perm)
+}
@@ -0,0 +1,130 @@
+package ubolt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	healthBucket = []byte("__health")
+	healthKey    = []byte("check")
+)
+
+// ErrHealthCheck is returned when a phase of HealthCheck fails.
+type ErrHealthCheck struct {
+	phase string
+	err   error
+}
+
+// Error returns the formatted configuration error.
+func (hc ErrHealthCheck) Error() string {
+	return fmt.Sprintf("Health check failed during %s: %v", hc.phase, hc.err)
+}
+
+// Is allows testing using errors.Is
+func (hc ErrHealthCheck) Is(target error) bool {
+	_, is := target.(ErrHealthCheck)
+
+	return is
+}
+
+// Unwrap returns the underlying error that caused the health check to fail.
+func (hc ErrHealthCheck) Unwrap() error {
+	return hc.err
+}
+
+// HealthCheck verifies the database is both readable and writable by writing a value to a reserved bucket, reading it back and then removing it.
+//
+// Any failure is returned as ErrHealthCheck which describes the phase (write, read, verify or delete) that failed.
+func (db *Database) HealthCheck() error {
+	return db.HealthCheckContext(context.Background())
+}
+
+// HealthCheck verifies the database is both readable and writable. This is forwarded to the Database implementation.
+func (b *Bucket) HealthCheck() error {
+	return b.db.HealthCheck()
+}
+
+// HealthCheckContext performs the same process as HealthCheck however it returns early once the provided context is done.
+//
+// When the context expires while a phase is blocked (for example waiting on another writer) the phase continues to run in the background
+// until it completes, however its result is discarded.
+func (db *Database) HealthCheckContext(ctx context.Context) error {
+	done := make(chan error, 1)
+
+	go func() {
+		done <- db.healthCheck(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ErrHealthCheck{phase: "wait", err: ctx.Err()}
+	}
+}
+
+// HealthCheckContext performs the same process as HealthCheck however it returns early once the provided context is done.
+func (b *Bucket) HealthCheckContext(ctx context.Context) error {
+	return b.db.HealthCheckContext(ctx)
+}
+
+func (db *Database) healthCheck(ctx context.Context) error {
+	want := []byte(time.Now().UTC().Format(time.RFC3339Nano))
+
+	phases := []struct {
+		name string
+		fn   func() error
+	}{
+		{"write", func() error {
+			return db.update(func(tx *bolt.Tx) error {
+				b, err := tx.CreateBucketIfNotExists(healthBucket)
+				if err != nil {
+					return err
+				}
+
+				return b.Put(healthKey, want)
+			})
+		}},
+		{"read", func() error {
+			return db.view(func(tx *bolt.Tx) error {
+				b := tx.Bucket(healthBucket)
+				if b == nil {
+					return ErrBucketNotFound{bucket: healthBucket}
+				}
+
+				got := b.Get(healthKey)
+				if got == nil {
+					return ErrKeyNotFound{bucket: healthBucket, key: healthKey}
+				}
+
+				if !bytes.Equal(got, want) {
+					return fmt.Errorf("read back %q but wrote %q", got, want)
+				}
+
+				return nil
+			})
+		}},
+		{"delete", func() error {
+			return db.update(func(tx *bolt.Tx) error {
+				return tx.DeleteBucket(healthBucket)
+			})
+		}},
+	}
+
+	for _, p := range phases {
+		if err := ctx.Err(); err != nil {
+			return ErrHealthCheck{phase: p.name, err: err}
+		}
+
+		if err := p.fn(); err != nil {
+			return ErrHealthCheck{phase: p.name, err: err}
+		}
+	}
+
+	return nil
+}
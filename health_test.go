@@ -0,0 +1,42 @@
+package ubolt
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthCheck(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.Nil(t, b.HealthCheck(), "HealthCheck")
+	assert.Nil(t, b.db.HealthCheck(), "HealthCheck - database")
+
+	// reserved bucket must not be visible or left behind
+	assert.Equal(t, [][]byte{testbucket}, b.db.GetBuckets(), "HealthCheck - buckets")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = b.HealthCheckContext(ctx)
+	assert.ErrorIs(t, err, ErrHealthCheck{}, "HealthCheckContext - cancelled")
+	assert.ErrorIs(t, err, context.Canceled, "HealthCheckContext - cancelled")
+
+	// a closed database fails during the write phase
+	if err := b.Close(); err != nil {
+		panic(err)
+	}
+
+	err = b.HealthCheck()
+	assert.ErrorIs(t, err, ErrHealthCheck{}, "HealthCheck - closed")
+	assert.Contains(t, err.Error(), "write", "HealthCheck - closed")
+}
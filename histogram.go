@@ -0,0 +1,194 @@
+package ubolt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// DefaultHistogramBounds are the value size boundaries in bytes used by ValueSizeHistogram when none are provided.
+var DefaultHistogramBounds = []int{16, 64, 256, 1024, 4096, 16384, 65536, 262144, 1048576}
+
+// histogramBarWidth is the width in characters of the longest bar drawn by Histogram.String.
+const histogramBarWidth = 40
+
+// ErrInvalidHistogramBound is returned by ValueSizeHistogram when a size boundary is negative, as a negative UpperBound marks the final
+// range of a Histogram.
+type ErrInvalidHistogramBound struct {
+	bound int
+}
+
+// Error returns the formatted configuration error.
+func (ib ErrInvalidHistogramBound) Error() string {
+	return fmt.Sprintf("Invalid histogram boundary %d: boundaries may not be negative", ib.bound)
+}
+
+// Is allows testing using errors.Is
+func (ib ErrInvalidHistogramBound) Is(target error) bool {
+	_, is := target.(ErrInvalidHistogramBound)
+
+	return is
+}
+
+// Histogram is the distribution of value sizes in a bucket as returned by ValueSizeHistogram.
+type Histogram struct {
+	// Buckets holds the number of values in each size range in ascending order of size.
+	Buckets []HistogramBucket `json:"buckets"`
+	// Count is the number of values measured.
+	Count int `json:"count"`
+	// Total is the total size in bytes of the values measured.
+	Total int64 `json:"total"`
+	// Min is the size in bytes of the smallest value, or zero if there are no values.
+	Min int `json:"min"`
+	// Max is the size in bytes of the largest value, or zero if there are no values.
+	Max int `json:"max"`
+	// Mean is the mean size in bytes of the values, or zero if there are no values.
+	Mean float64 `json:"mean"`
+}
+
+// HistogramBucket is the number of values in a single size range of a Histogram.
+type HistogramBucket struct {
+	// UpperBound is the largest size in bytes counted by this bucket, which also counts every size larger than the UpperBound of the
+	// previous bucket. The last bucket has an UpperBound of -1 and counts every size larger than the highest boundary.
+	UpperBound int `json:"upper_bound"`
+	// Count is the number of values in this size range.
+	Count int `json:"count"`
+}
+
+// ValueSizeHistogram walks the chosen bucket once inside a single read-only transaction and returns the distribution of value sizes, counted
+// into ranges using the provided size boundaries in bytes, along with the minimum, maximum, mean and total size. The boundaries are sorted
+// and duplicates removed, and DefaultHistogramBounds is used when none are provided. A final range counts values larger than the highest
+// boundary. ErrInvalidHistogramBound is returned if any boundary is negative.
+//
+// Value sizes are those stored in the database, so include any encoding header. Nested buckets are skipped.
+func (db *Database) ValueSizeHistogram(bucket []byte, bounds []int) (Histogram, error) {
+	return db.ValueSizeHistogramPrefix(bucket, nil, bounds)
+}
+
+// ValueSizeHistogram walks the bucket once and returns the distribution of value sizes counted into ranges using the provided size
+// boundaries in bytes.
+func (b *Bucket) ValueSizeHistogram(bounds []int) (Histogram, error) {
+	return b.db.ValueSizeHistogram(b.bucket, bounds)
+}
+
+// ValueSizeHistogramPrefix performs the same process as ValueSizeHistogram however only the values of keys beginning with prefix are
+// measured.
+func (db *Database) ValueSizeHistogramPrefix(bucket, prefix []byte, bounds []int) (Histogram, error) {
+	h, err := newHistogram(bounds)
+	if err != nil {
+		return Histogram{}, err
+	}
+
+	if err := db.view(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		return scanPrefix(b.Cursor(), db.canonicalKey(prefix), func(k, v []byte) error {
+			// skip nested buckets
+			if v == nil {
+				return nil
+			}
+
+			h.add(len(v))
+
+			return nil
+		})
+	}); err != nil {
+		return Histogram{}, err
+	}
+
+	if h.Count > 0 {
+		h.Mean = float64(h.Total) / float64(h.Count)
+	}
+
+	return h, nil
+}
+
+// ValueSizeHistogramPrefix performs the same process as ValueSizeHistogram however only the values of keys beginning with prefix are
+// measured.
+func (b *Bucket) ValueSizeHistogramPrefix(prefix []byte, bounds []int) (Histogram, error) {
+	return b.db.ValueSizeHistogramPrefix(b.bucket, prefix, bounds)
+}
+
+// newHistogram returns an empty Histogram with a bucket for each distinct boundary plus one for larger values. ErrInvalidHistogramBound is
+// returned if a boundary is negative, so no boundary can be mistaken for the final bucket.
+func newHistogram(bounds []int) (Histogram, error) {
+	if len(bounds) == 0 {
+		bounds = DefaultHistogramBounds
+	}
+
+	sorted := append([]int{}, bounds...)
+	sort.Ints(sorted)
+
+	if sorted[0] < 0 {
+		return Histogram{}, ErrInvalidHistogramBound{bound: sorted[0]}
+	}
+
+	var h Histogram
+	for i, bound := range sorted {
+		if i > 0 && bound == sorted[i-1] {
+			continue
+		}
+
+		h.Buckets = append(h.Buckets, HistogramBucket{UpperBound: bound})
+	}
+
+	h.Buckets = append(h.Buckets, HistogramBucket{UpperBound: -1})
+
+	return h, nil
+}
+
+// add counts a value of the provided size.
+func (h *Histogram) add(size int) {
+	i := sort.Search(len(h.Buckets)-1, func(i int) bool {
+		return h.Buckets[i].UpperBound >= size
+	})
+	h.Buckets[i].Count++
+
+	if h.Count == 0 || size < h.Min {
+		h.Min = size
+	}
+
+	if size > h.Max {
+		h.Max = size
+	}
+
+	h.Count++
+	h.Total += int64(size)
+}
+
+// String formats the histogram for a terminal, with a summary line followed by one line per size range showing the count as a bar scaled
+// to the largest count.
+func (h Histogram) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Values: %d, total: %d bytes, min: %d, max: %d, mean: %.1f\n", h.Count, h.Total, h.Min, h.Max, h.Mean)
+
+	var largest int
+	for _, b := range h.Buckets {
+		largest = max(largest, b.Count)
+	}
+
+	for i, b := range h.Buckets {
+		label := fmt.Sprintf("<= %d", b.UpperBound)
+		if i == len(h.Buckets)-1 {
+			label = "larger"
+			if i > 0 {
+				label = fmt.Sprintf("> %d", h.Buckets[i-1].UpperBound)
+			}
+		}
+
+		width := 0
+		if largest > 0 {
+			width = b.Count * histogramBarWidth / largest
+		}
+
+		fmt.Fprintf(&sb, "%12s |%-*s| %d\n", label, histogramBarWidth, strings.Repeat("#", width), b.Count)
+	}
+
+	return sb.String()
+}
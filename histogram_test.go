@@ -0,0 +1,82 @@
+package ubolt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValueSizeHistogram(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	for i, size := range []int{1, 10, 10, 50, 100, 1000} {
+		prefix := "a"
+		if size >= 100 {
+			prefix = "b"
+		}
+
+		if err := b.Put([]byte(fmt.Sprintf("%s%d", prefix, i)), bytes.Repeat([]byte("x"), size)); err != nil {
+			panic(err)
+		}
+	}
+
+	h, err := b.ValueSizeHistogram([]int{100, 10, 10})
+	assert.Nil(t, err, "ValueSizeHistogram")
+	assert.Equal(t, Histogram{
+		Buckets: []HistogramBucket{{UpperBound: 10, Count: 3}, {UpperBound: 100, Count: 2}, {UpperBound: -1, Count: 1}},
+		Count:   6,
+		Total:   1171,
+		Min:     1,
+		Max:     1000,
+		Mean:    1171.0 / 6,
+	}, h, "ValueSizeHistogram")
+
+	assert.Equal(t, "Values: 6, total: 1171 bytes, min: 1, max: 1000, mean: 195.2\n"+
+		"       <= 10 |########################################| 3\n"+
+		"      <= 100 |##########################              | 2\n"+
+		"       > 100 |#############                           | 1\n", h.String(), "String")
+
+	data, err := json.Marshal(h)
+	assert.Nil(t, err, "Marshal")
+
+	var decoded Histogram
+	assert.Nil(t, json.Unmarshal(data, &decoded), "Unmarshal")
+	assert.Equal(t, h, decoded, "Unmarshal")
+
+	h, err = b.ValueSizeHistogramPrefix([]byte("a"), nil)
+	assert.Nil(t, err, "ValueSizeHistogramPrefix")
+	assert.Equal(t, 4, h.Count, "ValueSizeHistogramPrefix - count")
+	assert.Equal(t, 1, h.Min, "ValueSizeHistogramPrefix - min")
+	assert.Equal(t, 50, h.Max, "ValueSizeHistogramPrefix - max")
+	assert.Len(t, h.Buckets, len(DefaultHistogramBounds)+1, "ValueSizeHistogramPrefix - default bounds")
+	assert.Equal(t, 3, h.Buckets[0].Count, "ValueSizeHistogramPrefix - first bucket")
+
+	h, err = b.ValueSizeHistogramPrefix([]byte("missing"), nil)
+	assert.Nil(t, err, "ValueSizeHistogramPrefix - no matches")
+	assert.Equal(t, 0, h.Count, "ValueSizeHistogramPrefix - no matches count")
+	assert.Equal(t, float64(0), h.Mean, "ValueSizeHistogramPrefix - no matches mean")
+
+	_, err = b.db.ValueSizeHistogram([]byte("missing"), nil)
+	assert.ErrorIs(t, err, ErrBucketNotFound{}, "ValueSizeHistogram - missing bucket")
+
+	// a negative boundary would be indistinguishable from the final bucket
+	_, err = b.ValueSizeHistogram([]int{10, -1})
+	assert.ErrorIs(t, err, ErrInvalidHistogramBound{}, "ValueSizeHistogram - negative bound")
+	_, err = b.ValueSizeHistogramPrefix([]byte("a"), []int{-5})
+	assert.ErrorIs(t, err, ErrInvalidHistogramBound{}, "ValueSizeHistogramPrefix - negative bound")
+
+	h, err = b.ValueSizeHistogram([]int{0})
+	assert.Nil(t, err, "ValueSizeHistogram - zero bound")
+	assert.Equal(t, []HistogramBucket{{UpperBound: 0, Count: 0}, {UpperBound: -1, Count: 6}}, h.Buckets, "ValueSizeHistogram - zero bound")
+}
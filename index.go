@@ -0,0 +1,308 @@
+package ubolt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrUniqueViolation is returned when writing to a unique Index would give an index key to a second primary key.
+type ErrUniqueViolation struct {
+	indexKey    []byte
+	existingKey []byte
+}
+
+// Error returns the formatted configuration error.
+func (uv ErrUniqueViolation) Error() string {
+	return fmt.Sprintf("Index key %s is already used by key %s", string(uv.indexKey), string(uv.existingKey))
+}
+
+// Is allows testing using errors.Is
+func (uv ErrUniqueViolation) Is(target error) bool {
+	_, is := target.(ErrUniqueViolation)
+
+	return is
+}
+
+// IndexKey returns the index key that was already in use.
+func (uv ErrUniqueViolation) IndexKey() []byte {
+	return uv.indexKey
+}
+
+// ExistingKey returns the primary key that already owns the index key.
+func (uv ErrUniqueViolation) ExistingKey() []byte {
+	return uv.existingKey
+}
+
+// IndexKeyFunc returns the index key for a key and value in the data bucket. A nil index key leaves the entry unindexed.
+type IndexKeyFunc func(k, v []byte) []byte
+
+// Index maintains a secondary index bucket that maps the index key derived from each value in a data bucket back to its primary key.
+//
+// The index is only kept up to date for writes made using the Index, so all writes to the data bucket should be made through it.
+type Index struct {
+	db     *Database
+	data   []byte
+	index  []byte
+	keyFn  IndexKeyFunc
+	unique bool
+}
+
+// NewIndex returns an Index of the data bucket stored in the index bucket, where many primary keys may share the same index key. Both
+// buckets are created when first written.
+func NewIndex(db *Database, data, index []byte, keyFn IndexKeyFunc) *Index {
+	return &Index{db: db, data: data, index: index, keyFn: keyFn}
+}
+
+// NewUniqueIndex returns an Index that allows each index key to be used by a single primary key. Writes that would give an index key to a
+// second primary key fail with ErrUniqueViolation.
+func NewUniqueIndex(db *Database, data, index []byte, keyFn IndexKeyFunc) *Index {
+	return &Index{db: db, data: data, index: index, keyFn: keyFn, unique: true}
+}
+
+// Put sets the key in the data bucket to the provided value and updates the index within the same read/write transaction. If the index
+// key of an existing value changes the old index entry is removed.
+func (idx *Index) Put(key, value []byte) error {
+	key = idx.db.canonicalKey(key)
+
+	return idx.db.update(func(tx *bolt.Tx) error {
+		data, err := createBucketPath(tx, idx.data)
+		if err != nil {
+			return err
+		}
+
+		ib := tx.Bucket(idx.index)
+		if ib == nil {
+			if ib, err = tx.CreateBucket(idx.index); err != nil {
+				return err
+			}
+
+			idx.db.recordMirror(tx, mutation{op: opCreateBucket, bucket: idx.index})
+		}
+
+		ik := idx.keyFn(key, value)
+		if idx.unique && ik != nil {
+			if owner := ib.Get(ik); owner != nil && !bytes.Equal(owner, key) {
+				return ErrUniqueViolation{indexKey: ik, existingKey: append([]byte{}, owner...)}
+			}
+		}
+
+		if old := data.Get(key); old != nil {
+			old, err := idx.db.unwrapValue(idx.data, key, old)
+			if err != nil {
+				return err
+			}
+
+			if oldIK := idx.keyFn(key, old); oldIK != nil && !bytes.Equal(oldIK, ik) {
+				if err := idx.deleteEntry(tx, ib, oldIK, key); err != nil {
+					return err
+				}
+			}
+		}
+
+		stored, err := idx.db.wrapValue(idx.data, key, value)
+		if err != nil {
+			return err
+		}
+
+		prev := previous(tx, idx.data, data, key)
+
+		if err := data.Put(key, stored); err != nil {
+			return err
+		}
+
+		if ik != nil {
+			if err := idx.putEntry(tx, ib, ik, key); err != nil {
+				return err
+			}
+		}
+
+		return idx.db.onMutation(tx, mutation{op: OpPut, bucket: idx.data, key: key, value: stored, prev: prev})
+	})
+}
+
+// Delete removes the key from the data bucket along with its index entry.
+func (idx *Index) Delete(key []byte) error {
+	key = idx.db.canonicalKey(key)
+
+	return idx.db.update(func(tx *bolt.Tx) error {
+		data := lookupBucket(tx, idx.data)
+		if data == nil {
+			return ErrBucketNotFound{bucket: idx.data}
+		}
+
+		if old := data.Get(key); old != nil {
+			old, err := idx.db.unwrapValue(idx.data, key, old)
+			if err != nil {
+				return err
+			}
+
+			if ib := tx.Bucket(idx.index); ib != nil {
+				if oldIK := idx.keyFn(key, old); oldIK != nil {
+					if err := idx.deleteEntry(tx, ib, oldIK, key); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		prev := previous(tx, idx.data, data, key)
+
+		if err := data.Delete(key); err != nil {
+			return err
+		}
+
+		return idx.db.onMutation(tx, mutation{op: OpDelete, bucket: idx.data, key: key, prev: prev})
+	})
+}
+
+// Lookup returns the primary keys that use the index key ordered by key. A unique Index returns at most one key.
+func (idx *Index) Lookup(indexKey []byte) (keys [][]byte, err error) {
+	if err := idx.db.view(func(tx *bolt.Tx) error {
+		ib := tx.Bucket(idx.index)
+		if ib == nil {
+			return ErrBucketNotFound{bucket: idx.index}
+		}
+
+		if idx.unique {
+			if owner := ib.Get(indexKey); owner != nil {
+				keys = append(keys, append([]byte{}, owner...))
+			}
+
+			return nil
+		}
+
+		prefix := idx.entryKey(indexKey, nil)
+
+		return scanPrefix(ib.Cursor(), prefix, func(k, v []byte) error {
+			keys = append(keys, append([]byte{}, k[len(prefix):]...))
+
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// Rebuild discards and recreates the index from the current contents of the data bucket in a single read/write transaction.
+//
+// For a unique Index the first primary key in key order keeps each index key, and any later primary keys using the same index key are
+// left unindexed and returned as violations.
+func (idx *Index) Rebuild() (violations []ErrUniqueViolation, err error) {
+	return idx.RebuildWithProgress(nil)
+}
+
+// RebuildWithProgress performs the same process as Rebuild while reporting the number of keys of the data bucket visited to progress. The
+// total is reported as -1.
+func (idx *Index) RebuildWithProgress(progress ProgressFunc) (violations []ErrUniqueViolation, err error) {
+	var p *progressReporter
+
+	if err := idx.db.update(func(tx *bolt.Tx) error {
+		data := lookupBucket(tx, idx.data)
+		if data == nil {
+			return ErrBucketNotFound{bucket: idx.data}
+		}
+
+		if tx.Bucket(idx.index) != nil {
+			if err := tx.DeleteBucket(idx.index); err != nil {
+				return err
+			}
+
+			idx.db.recordMirror(tx, mutation{op: OpDeleteBucket, bucket: idx.index})
+		}
+
+		ib, err := tx.CreateBucket(idx.index)
+		if err != nil {
+			return err
+		}
+
+		idx.db.recordMirror(tx, mutation{op: opCreateBucket, bucket: idx.index})
+
+		violations = nil
+		p = newProgress(progress, -1)
+
+		return data.ForEach(idx.db.unwrapFunc(idx.data, func(k, v []byte) error {
+			// skip nested buckets
+			if v == nil {
+				return nil
+			}
+
+			p.add(1)
+
+			ik := idx.keyFn(k, v)
+			if ik == nil {
+				return nil
+			}
+
+			if idx.unique {
+				if owner := ib.Get(ik); owner != nil {
+					violations = append(violations, ErrUniqueViolation{
+						indexKey:    append([]byte{}, ik...),
+						existingKey: append([]byte{}, owner...),
+					})
+
+					return nil
+				}
+			}
+
+			return idx.putEntry(tx, ib, ik, k)
+		}))
+	}); err != nil {
+		return nil, err
+	}
+
+	p.finish()
+
+	return violations, nil
+}
+
+// putEntry adds the index entry for the primary key. Index entries do not pass through the write hooks so are recorded for any mirror
+// directly.
+func (idx *Index) putEntry(tx *bolt.Tx, ib *bolt.Bucket, indexKey, key []byte) error {
+	entry, value := idx.entryKey(indexKey, key), []byte{}
+	if idx.unique {
+		value = key
+	}
+
+	if err := ib.Put(entry, value); err != nil {
+		return err
+	}
+
+	idx.db.recordMirror(tx, mutation{op: OpPut, bucket: idx.index, key: entry, value: value})
+
+	return nil
+}
+
+// deleteEntry removes the index entry for the primary key. Entries of a unique index owned by another primary key are left in place.
+func (idx *Index) deleteEntry(tx *bolt.Tx, ib *bolt.Bucket, indexKey, key []byte) error {
+	if idx.unique && !bytes.Equal(ib.Get(indexKey), key) {
+		return nil
+	}
+
+	entry := idx.entryKey(indexKey, key)
+
+	if err := ib.Delete(entry); err != nil {
+		return err
+	}
+
+	idx.db.recordMirror(tx, mutation{op: OpDelete, bucket: idx.index, key: entry})
+
+	return nil
+}
+
+// entryKey returns the key stored in the index bucket. A unique index stores the index key itself with the primary key as the value,
+// otherwise the length prefixed index key is followed by the primary key so many primary keys may share an index key.
+func (idx *Index) entryKey(indexKey, key []byte) []byte {
+	if idx.unique {
+		return indexKey
+	}
+
+	entry := binary.AppendUvarint(nil, uint64(len(indexKey)))
+	entry = append(entry, indexKey...)
+
+	return append(entry, key...)
+}
@@ -0,0 +1,112 @@
+package ubolt
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	usersBucket = []byte("users")
+	emailBucket = []byte("users_by_email")
+)
+
+// emailKey indexes values of the form "email|name" by email.
+func emailKey(k, v []byte) []byte {
+	for i, c := range v {
+		if c == '|' {
+			return v[:i]
+		}
+	}
+
+	return nil
+}
+
+func TestUniqueIndex(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	db, err := Open(testdb)
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	idx := NewUniqueIndex(db, usersBucket, emailBucket, emailKey)
+
+	assert.Nil(t, idx.Put([]byte("u1"), []byte("alice@example.com|Alice")), "Put")
+	assert.Nil(t, idx.Put([]byte("u2"), []byte("bob@example.com|Bob")), "Put")
+
+	// a duplicate index key fails without writing anything
+	err = idx.Put([]byte("u3"), []byte("alice@example.com|Imposter"))
+	assert.ErrorIs(t, err, ErrUniqueViolation{}, "Put - duplicate")
+	var uv ErrUniqueViolation
+	if assert.ErrorAs(t, err, &uv, "Put - duplicate") {
+		assert.Equal(t, []byte("alice@example.com"), uv.IndexKey(), "ErrUniqueViolation - index key")
+		assert.Equal(t, []byte("u1"), uv.ExistingKey(), "ErrUniqueViolation - existing key")
+	}
+	assert.False(t, db.Exists(usersBucket, []byte("u3")), "Put - duplicate not written")
+
+	// the owner may rewrite its own value
+	assert.Nil(t, idx.Put([]byte("u1"), []byte("alice@example.com|Alice Smith")), "Put - same index key")
+
+	// changing the indexed field releases the old index key
+	assert.Nil(t, idx.Put([]byte("u1"), []byte("alice@example.org|Alice")), "Put - new index key")
+	keys, err := idx.Lookup([]byte("alice@example.com"))
+	assert.Nil(t, err, "Lookup - old index key")
+	assert.Nil(t, keys, "Lookup - old index key")
+	keys, err = idx.Lookup([]byte("alice@example.org"))
+	assert.Nil(t, err, "Lookup - new index key")
+	assert.Equal(t, [][]byte{[]byte("u1")}, keys, "Lookup - new index key")
+	assert.Nil(t, idx.Put([]byte("u3"), []byte("alice@example.com|Another Alice")), "Put - released index key")
+
+	// delete removes the index entry
+	assert.Nil(t, idx.Delete([]byte("u2")), "Delete")
+	keys, err = idx.Lookup([]byte("bob@example.com"))
+	assert.Nil(t, err, "Lookup - deleted")
+	assert.Nil(t, keys, "Lookup - deleted")
+
+	// writes made outside the index are picked up by Rebuild, which reports violations
+	if err := db.Put(usersBucket, []byte("u4"), []byte("alice@example.org|Duplicate")); err != nil {
+		panic(err)
+	}
+	violations, err := idx.Rebuild()
+	assert.Nil(t, err, "Rebuild")
+	if assert.Len(t, violations, 1, "Rebuild - violations") {
+		assert.Equal(t, []byte("alice@example.org"), violations[0].IndexKey(), "Rebuild - index key")
+		assert.Equal(t, []byte("u1"), violations[0].ExistingKey(), "Rebuild - existing key")
+	}
+
+	// deleting the unindexed duplicate leaves the owner's entry in place
+	assert.Nil(t, idx.Delete([]byte("u4")), "Delete - unindexed duplicate")
+	keys, err = idx.Lookup([]byte("alice@example.org"))
+	assert.Nil(t, err, "Lookup - after duplicate deleted")
+	assert.Equal(t, [][]byte{[]byte("u1")}, keys, "Lookup - after duplicate deleted")
+}
+
+func TestIndex(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	db, err := Open(testdb)
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	idx := NewIndex(db, usersBucket, emailBucket, emailKey)
+
+	assert.Nil(t, idx.Put([]byte("u1"), []byte("shared@example.com|One")), "Put")
+	assert.Nil(t, idx.Put([]byte("u2"), []byte("shared@example.com|Two")), "Put - shared index key")
+	assert.Nil(t, idx.Put([]byte("u3"), []byte("shared@example.co|Three")), "Put - index key prefix")
+
+	keys, err := idx.Lookup([]byte("shared@example.com"))
+	assert.Nil(t, err, "Lookup")
+	assert.Equal(t, [][]byte{[]byte("u1"), []byte("u2")}, keys, "Lookup")
+
+	assert.Nil(t, idx.Delete([]byte("u1")), "Delete")
+	keys, err = idx.Lookup([]byte("shared@example.com"))
+	assert.Nil(t, err, "Lookup - deleted")
+	assert.Equal(t, [][]byte{[]byte("u2")}, keys, "Lookup - deleted")
+}
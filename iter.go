@@ -0,0 +1,81 @@
+package ubolt
+
+import (
+	"errors"
+	"iter"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// errStopIteration is used internally to end a transaction when the consumer of an iterator stops early.
+var errStopIteration = errors.New("iteration stopped")
+
+// Iterator provides a range-over-func iterator over the keys of a bucket that begin with a prefix.
+// Any error that ended the iteration early, such as a missing bucket, is available from Err once the loop completes.
+type Iterator struct {
+	db     *Database
+	bucket []byte
+	prefix []byte
+	err    error
+}
+
+// All returns an iterator over copies of each key and value, which remain valid after the loop completes.
+// The read-only transaction used is closed when the loop ends, including when the loop body breaks early or panics.
+func (it *Iterator) All() iter.Seq2[[]byte, []byte] {
+	return func(yield func(k, v []byte) bool) {
+		it.err = it.db.view(func(tx *bolt.Tx) error {
+			b := lookupBucket(tx, it.bucket)
+			if b == nil {
+				return ErrBucketNotFound{bucket: it.bucket}
+			}
+
+			return scanPrefix(b.Cursor(), it.prefix, it.db.unwrapFunc(it.bucket, func(k, v []byte) error {
+				var val []byte
+				if v != nil {
+					val = append([]byte{}, v...)
+				}
+
+				if !yield(append([]byte{}, k...), val) {
+					return errStopIteration
+				}
+
+				return nil
+			}))
+		})
+
+		if errors.Is(it.err, errStopIteration) {
+			it.err = nil
+		}
+	}
+}
+
+// Err returns the error, if any, that ended the most recent iteration.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// PrefixIter returns an Iterator over the keys in the chosen bucket beginning with prefix. The boundary handling is identical to Scan.
+func (db *Database) PrefixIter(bucket, prefix []byte) *Iterator {
+	return &Iterator{db: db, bucket: bucket, prefix: db.canonicalKey(prefix)}
+}
+
+// PrefixIter returns an Iterator over the keys beginning with prefix. The boundary handling is identical to Scan.
+func (b *Bucket) PrefixIter(prefix []byte) *Iterator {
+	return b.db.PrefixIter(b.bucket, prefix)
+}
+
+// Prefix returns an iterator over copies of the keys and values in the chosen bucket beginning with prefix, for use as:
+//
+//	for k, v := range db.Prefix(bucket, []byte("user:")) {
+//		...
+//	}
+//
+// Errors such as a missing bucket end the iteration without yielding any values. Use PrefixIter when errors need to be observed.
+func (db *Database) Prefix(bucket, prefix []byte) iter.Seq2[[]byte, []byte] {
+	return db.PrefixIter(bucket, prefix).All()
+}
+
+// Prefix returns an iterator over copies of the keys and values beginning with prefix. Use PrefixIter when errors need to be observed.
+func (b *Bucket) Prefix(prefix []byte) iter.Seq2[[]byte, []byte] {
+	return b.db.Prefix(b.bucket, prefix)
+}
@@ -0,0 +1,86 @@
+package ubolt
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefix(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, k := range []string{"user:1", "user:2", "user:3", "users", "admin:1"} {
+		if err := b.Put([]byte(k), []byte("v"+k)); err != nil {
+			panic(err)
+		}
+	}
+
+	// must match Scan exactly
+	for _, prefix := range []string{"user:", "user", "admin", "missing", ""} {
+		var want, got []string
+
+		_ = b.Scan([]byte(prefix), func(k, v []byte) error {
+			want = append(want, fmt.Sprintf("k=%s;v=%s", k, v))
+			return nil
+		})
+
+		for k, v := range b.Prefix([]byte(prefix)) {
+			got = append(got, fmt.Sprintf("k=%s;v=%s", k, v))
+		}
+
+		assert.Equal(t, want, got, "Prefix - "+prefix)
+	}
+
+	// copies remain valid and early break closes the transaction
+	var keys [][]byte
+	for k := range b.Prefix([]byte("user:")) {
+		keys = append(keys, k)
+		if len(keys) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, "user:1,user:2", string(keys[0])+","+string(keys[1]), "Prefix - break")
+
+	assert.Nil(t, b.Put([]byte("user:4"), []byte("v")), "Put - after break")
+
+	// Close blocks on open transactions so this ensures a panic inside the loop releases the transaction
+	func() {
+		defer func() { _ = recover() }()
+		for range b.Prefix([]byte("user:")) {
+			panic("boom")
+		}
+	}()
+	assert.Nil(t, b.db.Close(), "Close - after panic")
+
+	b, err = OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	// errors are observable via PrefixIter
+	it := b.db.PrefixIter(missing, nil)
+	count := 0
+	for range it.All() {
+		count++
+	}
+	assert.Equal(t, 0, count, "PrefixIter - missing bucket")
+	assert.ErrorIs(t, it.Err(), ErrBucketNotFound{}, "PrefixIter - missing bucket")
+
+	it = b.PrefixIter([]byte("user:"))
+	got := make([]string, 0)
+	for k := range it.All() {
+		got = append(got, string(k))
+	}
+	assert.Nil(t, it.Err(), "PrefixIter")
+	assert.Equal(t, "user:1,user:2,user:3,user:4", strings.Join(got, ","), "PrefixIter")
+}
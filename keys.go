@@ -0,0 +1,74 @@
+package ubolt
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Int64Key returns an 8 byte key for v that sorts in numeric order, including negative values. The key holds v as a big-endian integer
+// with the sign bit flipped so negative values sort before positive ones.
+func Int64Key(v int64) []byte {
+	return itob(uint64(v) ^ (1 << 63))
+}
+
+// KeyInt64 returns the value held in the first 8 bytes of a key created using Int64Key. Any bytes after the first 8 are ignored, so an
+// Int64Key may be used as the prefix of a longer key. If the key is shorter than 8 bytes an ErrInvalidKey is returned.
+func KeyInt64(key []byte) (int64, error) {
+	if len(key) < 8 {
+		return 0, ErrInvalidKey{fmt.Errorf("int64 key must be at least 8 bytes, got %d", len(key))}
+	}
+
+	return int64(btoi(key[:8]) ^ (1 << 63)), nil
+}
+
+// Float64Key returns an 8 byte key for v that sorts in numeric order, with -Inf first and +Inf last. Positive values have their sign bit
+// set and negative values have every bit flipped, so larger magnitudes of negative values sort first. Negative zero is stored as zero so
+// equal values have equal keys, and NaN values sort after +Inf, or before -Inf when their sign bit is set.
+func Float64Key(v float64) []byte {
+	if v == 0 {
+		v = 0
+	}
+
+	bits := math.Float64bits(v)
+	if bits&(1<<63) != 0 {
+		return itob(^bits)
+	}
+
+	return itob(bits | 1<<63)
+}
+
+// KeyFloat64 returns the value held in the first 8 bytes of a key created using Float64Key. Any bytes after the first 8 are ignored, so a
+// Float64Key may be used as the prefix of a longer key. If the key is shorter than 8 bytes an ErrInvalidKey is returned.
+func KeyFloat64(key []byte) (float64, error) {
+	if len(key) < 8 {
+		return 0, ErrInvalidKey{fmt.Errorf("float64 key must be at least 8 bytes, got %d", len(key))}
+	}
+
+	bits := btoi(key[:8])
+	if bits&(1<<63) != 0 {
+		return math.Float64frombits(bits &^ (1 << 63)), nil
+	}
+
+	return math.Float64frombits(^bits), nil
+}
+
+// TimeKey returns an 8 byte key for t that sorts in chronological order, allowing keys that begin with a TimeKey to be selected by time
+// range using GetKeysBetween, DeleteRange or PurgeBefore. The key is the Int64Key of the nanoseconds since the Unix epoch, so times
+// before 1970 sort first and sub-second differences are preserved. Only times between the years 1678 and 2262 can be represented, as per
+// time.Time.UnixNano, and the location and monotonic clock reading of t are not preserved.
+func TimeKey(t time.Time) []byte {
+	return Int64Key(t.UnixNano())
+}
+
+// KeyTime returns the time held in the first 8 bytes of a key created using TimeKey, in UTC. Any bytes after the first 8 are ignored, so a
+// TimeKey may be used as the prefix of a longer key. If the key is shorter than 8 bytes an ErrInvalidKey is returned.
+func KeyTime(key []byte) (time.Time, error) {
+	if len(key) < 8 {
+		return time.Time{}, ErrInvalidKey{fmt.Errorf("time key must be at least 8 bytes, got %d", len(key))}
+	}
+
+	nsec, _ := KeyInt64(key)
+
+	return time.Unix(0, nsec).UTC(), nil
+}
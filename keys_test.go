@@ -0,0 +1,109 @@
+package ubolt
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// compare returns -1, 0 or 1 as a is less than, equal to or greater than b.
+func compare[T int64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+func TestInt64Key(t *testing.T) {
+	// byte order of the keys matches numeric order for random pairs
+	assert.Nil(t, quick.Check(func(a, b int64) bool {
+		return bytes.Compare(Int64Key(a), Int64Key(b)) == compare(a, b)
+	}, nil), "Int64Key - ordering")
+
+	assert.Nil(t, quick.Check(func(v int64) bool {
+		got, err := KeyInt64(Int64Key(v))
+		return err == nil && got == v
+	}, nil), "KeyInt64 - round trip")
+
+	values := []int64{math.MinInt64, -1 << 32, -1, 0, 1, 1 << 32, math.MaxInt64}
+	for i := 1; i < len(values); i++ {
+		assert.Less(t, string(Int64Key(values[i-1])), string(Int64Key(values[i])), "Int64Key - %d", values[i])
+	}
+
+	got, err := KeyInt64(append(Int64Key(-42), []byte("suffix")...))
+	assert.Nil(t, err, "KeyInt64 - suffix")
+	assert.Equal(t, int64(-42), got, "KeyInt64 - suffix")
+
+	_, err = KeyInt64([]byte("short"))
+	assert.ErrorIs(t, err, ErrInvalidKey{}, "KeyInt64 - short key")
+}
+
+func TestFloat64Key(t *testing.T) {
+	// random bit patterns cover subnormals, large magnitudes and both signs
+	random := func(values []reflect.Value, r *rand.Rand) {
+		for i := range values {
+			v := math.Float64frombits(r.Uint64())
+			for math.IsNaN(v) {
+				v = math.Float64frombits(r.Uint64())
+			}
+
+			values[i] = reflect.ValueOf(v)
+		}
+	}
+
+	assert.Nil(t, quick.Check(func(a, b float64) bool {
+		return bytes.Compare(Float64Key(a), Float64Key(b)) == compare(a, b)
+	}, &quick.Config{Values: random}), "Float64Key - ordering")
+
+	assert.Nil(t, quick.Check(func(v float64) bool {
+		got, err := KeyFloat64(Float64Key(v))
+		return err == nil && math.Float64bits(got) == math.Float64bits(v) || v == 0 && got == 0
+	}, &quick.Config{Values: random}), "KeyFloat64 - round trip")
+
+	values := []float64{math.Inf(-1), -math.MaxFloat64, -1, -math.SmallestNonzeroFloat64, 0, math.SmallestNonzeroFloat64, 0.5, 1, math.MaxFloat64, math.Inf(1)}
+	for i := 1; i < len(values); i++ {
+		assert.Less(t, string(Float64Key(values[i-1])), string(Float64Key(values[i])), "Float64Key - %v", values[i])
+	}
+
+	for _, v := range values {
+		got, err := KeyFloat64(Float64Key(v))
+		assert.Nil(t, err, "KeyFloat64 - %v", v)
+		assert.Equal(t, v, got, "KeyFloat64 - %v", v)
+	}
+
+	// negative zero is equal to zero so has the same key
+	assert.Equal(t, Float64Key(0), Float64Key(math.Copysign(0, -1)), "Float64Key - negative zero")
+
+	got, err := KeyFloat64(Float64Key(math.NaN()))
+	assert.Nil(t, err, "KeyFloat64 - NaN")
+	assert.True(t, math.IsNaN(got), "KeyFloat64 - NaN")
+
+	_, err = KeyFloat64([]byte("short"))
+	assert.ErrorIs(t, err, ErrInvalidKey{}, "KeyFloat64 - short key")
+}
+
+func TestTimeKeyOrdering(t *testing.T) {
+	// times within the range of UnixNano, including sub-second differences
+	random := func(values []reflect.Value, r *rand.Rand) {
+		for i := range values {
+			values[i] = reflect.ValueOf(time.Unix(0, r.Int63()-r.Int63()))
+		}
+	}
+
+	assert.Nil(t, quick.Check(func(a, b time.Time) bool {
+		return bytes.Compare(TimeKey(a), TimeKey(b)) == a.Compare(b)
+	}, &quick.Config{Values: random}), "TimeKey - ordering")
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Less(t, string(TimeKey(base)), string(TimeKey(base.Add(time.Nanosecond))), "TimeKey - nanosecond")
+}
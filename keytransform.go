@@ -0,0 +1,115 @@
+package ubolt
+
+import (
+	"bytes"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrKeyConflict is returned by RewriteKeys when two keys have the same canonical form.
+type ErrKeyConflict struct {
+	bucket    []byte
+	key       []byte
+	canonical []byte
+}
+
+// Error returns the formatted configuration error.
+func (kc ErrKeyConflict) Error() string {
+	return fmt.Sprintf("Key %s in bucket %s conflicts with existing key %s", string(kc.key), bucketName(kc.bucket), string(kc.canonical))
+}
+
+// Is allows testing using errors.Is
+func (kc ErrKeyConflict) Is(target error) bool {
+	_, is := target.(ErrKeyConflict)
+
+	return is
+}
+
+// WithKeyTransform applies fn to the key argument of every operation, such as Put, Get, Delete, Exists, Encode and Decode, along with the
+// prefix of Scan and similar methods and the bounds of GetKeysBetween and DeleteRange, so keys are canonicalised in a single place. For
+// example bytes.ToLower makes keys case-insensitive.
+//
+// Keys passed to iteration callbacks and returned by methods such as GetKeys are the stored form returned by fn. Keys generated by PutV are
+// stored unchanged. The transform is not applied to keys already stored when the option is added, which may be migrated using RewriteKeys.
+func WithKeyTransform(fn func([]byte) []byte) Option {
+	return func(db *Database) {
+		db.keyTransform = fn
+	}
+}
+
+// canonicalKey returns key with any WithKeyTransform function applied. A nil key is returned unchanged.
+func (db *Database) canonicalKey(key []byte) []byte {
+	if db.keyTransform == nil || key == nil {
+		return key
+	}
+
+	return db.keyTransform(key)
+}
+
+// RewriteKeys moves every key in the chosen bucket that is not in the form returned by the WithKeyTransform function to its canonical key,
+// returning the number of keys moved. All keys are moved in a single read/write transaction, so if two keys share a canonical form
+// ErrKeyConflict is returned and no keys are changed. Nested buckets are skipped.
+func (db *Database) RewriteKeys(bucket []byte) (n int, err error) {
+	if db.keyTransform == nil {
+		return 0, nil
+	}
+
+	err = db.update(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		var keys [][]byte
+		if err := b.ForEach(func(k, v []byte) error {
+			if v != nil && !bytes.Equal(k, db.canonicalKey(k)) {
+				keys = append(keys, append([]byte{}, k...))
+			}
+
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		for _, k := range keys {
+			canonical := db.canonicalKey(k)
+			if b.Get(canonical) != nil {
+				return ErrKeyConflict{bucket: bucket, key: k, canonical: canonical}
+			}
+
+			v := append([]byte{}, b.Get(k)...)
+
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+
+			if err := db.onMutation(tx, mutation{op: OpDelete, bucket: bucket, key: k, prev: v}); err != nil {
+				return err
+			}
+
+			if err := b.Put(canonical, v); err != nil {
+				return err
+			}
+
+			if err := db.onMutation(tx, mutation{op: OpPut, bucket: bucket, key: canonical, value: v}); err != nil {
+				return err
+			}
+		}
+
+		n = len(keys)
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// RewriteKeys moves every key in the bucket that is not in the form returned by the WithKeyTransform function to its canonical key.
+func (b *Bucket) RewriteKeys() (n int, err error) {
+	return b.db.RewriteKeys(b.bucket)
+}
@@ -0,0 +1,79 @@
+package ubolt
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyTransform(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	// write keys before the transform is in place
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	assert.Nil(t, b.Put([]byte("Legacy"), testvalue), "Put - before transform")
+	assert.Nil(t, b.Close(), "Close")
+
+	b, err = OpenBucket(testdb, testbucket, WithKeyTransform(bytes.ToLower))
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.Nil(t, b.Put([]byte("Foo"), testvalue), "Put")
+	assert.Equal(t, testvalue, b.Get([]byte("FOO")), "Get")
+	assert.True(t, b.Exists([]byte("foo")), "Exists")
+	assert.Equal(t, []string{"Legacy", "foo"}, b.GetKeysString(), "GetKeysString")
+
+	var keys []string
+	assert.Nil(t, b.Scan([]byte("FO"), func(k, v []byte) error {
+		keys = append(keys, string(k))
+		return nil
+	}), "Scan")
+	assert.Equal(t, []string{"foo"}, keys, "Scan")
+
+	// ids generated by PutV are not transformed
+	id, err := b.PutVID(testvalue)
+	assert.Nil(t, err, "PutVID")
+	_, err = b.GetID(id)
+	assert.Nil(t, err, "GetID")
+	assert.Nil(t, b.DeleteID(id), "DeleteID")
+
+	n, err := b.RewriteKeys()
+	assert.Nil(t, err, "RewriteKeys")
+	assert.Equal(t, 1, n, "RewriteKeys")
+	assert.Equal(t, []string{"foo", "legacy"}, b.GetKeysString(), "GetKeysString - rewritten")
+	assert.Equal(t, testvalue, b.Get([]byte("LEGACY")), "Get - rewritten")
+
+	assert.Nil(t, b.Delete([]byte("FOO")), "Delete")
+	assert.False(t, b.Exists([]byte("foo")), "Exists - deleted")
+}
+
+func TestRewriteKeysConflict(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	assert.Nil(t, b.Put([]byte("Key"), []byte("1")), "Put")
+	assert.Nil(t, b.Put([]byte("key"), []byte("2")), "Put")
+	assert.Nil(t, b.Close(), "Close")
+
+	b, err = OpenBucket(testdb, testbucket, WithKeyTransform(bytes.ToLower))
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	_, err = b.RewriteKeys()
+	assert.ErrorIs(t, err, ErrKeyConflict{}, "RewriteKeys - conflict")
+	assert.Equal(t, []string{"Key", "key"}, b.GetKeysString(), "GetKeysString - unchanged")
+}
@@ -0,0 +1,125 @@
+package ubolt
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// LoadOptions controls the behaviour of GetOrLoadWithOptions and GetOrLoadDecodedWithOptions.
+type LoadOptions struct {
+	// TTL, if set, stores loaded values using PutTTL so they expire once TTL has passed. ErrNoTTL is returned, without calling the loader,
+	// unless WithTTL or WithSlidingTTL was used for the bucket. Expired values are treated as missing so are loaded again.
+	TTL time.Duration
+}
+
+// GetOrLoad returns the value of the specified key in the chosen bucket, or if the key does not exist calls loader and stores the value it
+// returns before returning it, allowing the database to be used as a read-through cache. Concurrent calls for the same missing key are
+// deduplicated so loader is called once, with every caller receiving its result.
+//
+// Nothing is stored when loader returns an error, which is returned unchanged to every caller waiting on the load. Errors reading the key
+// other than ErrKeyNotFound, such as ErrBucketNotFound, are returned without calling loader, and an error storing the loaded value is
+// returned in place of the value.
+func (db *Database) GetOrLoad(bucket, key []byte, loader func() ([]byte, error)) ([]byte, error) {
+	return db.GetOrLoadWithOptions(bucket, key, loader, LoadOptions{})
+}
+
+// GetOrLoad returns the value of the specified key, or if the key does not exist calls loader and stores the value it returns.
+func (b *Bucket) GetOrLoad(key []byte, loader func() ([]byte, error)) ([]byte, error) {
+	return b.db.GetOrLoad(b.bucket, key, loader)
+}
+
+// GetOrLoadWithOptions performs the same process as GetOrLoad with the behaviour controlled by the provided LoadOptions.
+func (db *Database) GetOrLoadWithOptions(bucket, key []byte, loader func() ([]byte, error), opts LoadOptions) ([]byte, error) {
+	return db.getOrLoad(OpPut, bucket, key, loader, opts)
+}
+
+// GetOrLoadWithOptions performs the same process as GetOrLoad with the behaviour controlled by the provided LoadOptions.
+func (b *Bucket) GetOrLoadWithOptions(key []byte, loader func() ([]byte, error), opts LoadOptions) ([]byte, error) {
+	return b.db.GetOrLoadWithOptions(b.bucket, key, loader, opts)
+}
+
+// GetOrLoadDecoded performs the same process as GetOrLoad for a value written by Encode, decoding the existing value as a T or encoding
+// the value returned by loader using the configured Codec. Decode failures are returned as ErrDecode and encode failures as
+// ErrNotEncodable.
+func GetOrLoadDecoded[T any](db *Database, bucket, key []byte, loader func() (T, error)) (T, error) {
+	return GetOrLoadDecodedWithOptions(db, bucket, key, loader, LoadOptions{})
+}
+
+// GetOrLoadDecodedWithOptions performs the same process as GetOrLoadDecoded with the behaviour controlled by the provided LoadOptions.
+func GetOrLoadDecodedWithOptions[T any](db *Database, bucket, key []byte, loader func() (T, error), opts LoadOptions) (T, error) {
+	var value T
+
+	data, err := db.getOrLoad(OpEncode, bucket, key, func() ([]byte, error) {
+		v, err := loader()
+		if err != nil {
+			return nil, err
+		}
+
+		return db.encode(bucket, key, v)
+	}, opts)
+	if err != nil {
+		return value, err
+	}
+
+	if db.decodeLimit > 0 && int64(len(data)) > db.decodeLimit {
+		return value, ErrValueTooLarge{bucket: bucket, key: key, size: int64(len(data)), limit: db.decodeLimit}
+	}
+
+	if err := db.safeUnmarshal(data, &value); err != nil {
+		return value, ErrDecode{bucket: bucket, key: key, err: err}
+	}
+
+	return value, nil
+}
+
+// GetOrLoadDecodedBucket performs the same process as GetOrLoadDecoded for the bucket opened.
+func GetOrLoadDecodedBucket[T any](b *Bucket, key []byte, loader func() (T, error)) (T, error) {
+	return GetOrLoadDecoded(b.db, b.bucket, key, loader)
+}
+
+// GetOrLoadDecodedBucketWithOptions performs the same process as GetOrLoadDecodedWithOptions for the bucket opened.
+func GetOrLoadDecodedBucketWithOptions[T any](b *Bucket, key []byte, loader func() (T, error), opts LoadOptions) (T, error) {
+	return GetOrLoadDecodedWithOptions(b.db, b.bucket, key, loader, opts)
+}
+
+// getOrLoad returns the stored value of the key, or calls loader once for all concurrent callers and stores its result using op.
+func (db *Database) getOrLoad(op Op, bucket, key []byte, loader func() ([]byte, error), opts LoadOptions) ([]byte, error) {
+	if _, ok := db.ttls[string(bucket)]; opts.TTL > 0 && !ok {
+		return nil, ErrNoTTL{bucket}
+	}
+
+	value, err := db.GetE(bucket, key)
+	if !errors.Is(err, ErrKeyNotFound{}) {
+		return value, err
+	}
+
+	ck := db.canonicalKey(key)
+
+	flight := itob(uint64(len(bucket)))
+	flight = append(append(flight, bucket...), ck...)
+
+	return db.loads.do(string(flight), func() ([]byte, error) {
+		// a load that completed since the read above has already stored the value
+		value, err := db.GetE(bucket, key)
+		if !errors.Is(err, ErrKeyNotFound{}) {
+			return value, err
+		}
+
+		if value, err = loader(); err != nil {
+			return nil, err
+		}
+
+		if opts.TTL > 0 {
+			err = db.PutTTL(bucket, key, value, opts.TTL)
+		} else {
+			err = db.put(context.Background(), op, bucket, key, value)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return value, nil
+	})
+}
@@ -0,0 +1,136 @@
+package ubolt
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOrLoad(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket, WithTTL(testbucket))
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	var calls atomic.Int32
+	loader := func() ([]byte, error) {
+		calls.Add(1)
+		return testvalue, nil
+	}
+
+	_, err = b.db.GetOrLoad(missing, testkey, loader)
+	assert.ErrorIs(t, err, ErrBucketNotFound{}, "GetOrLoad - missing bucket")
+	assert.Equal(t, int32(0), calls.Load(), "GetOrLoad - missing bucket does not load")
+
+	// loader errors are returned and nothing is stored
+	_, err = b.GetOrLoad(testkey, func() ([]byte, error) { return nil, assert.AnError })
+	assert.ErrorIs(t, err, assert.AnError, "GetOrLoad - loader error")
+	assert.False(t, b.Exists(testkey), "GetOrLoad - loader error not stored")
+
+	value, err := b.GetOrLoad(testkey, loader)
+	assert.Nil(t, err, "GetOrLoad - miss")
+	assert.Equal(t, testvalue, value, "GetOrLoad - miss value")
+	assert.Equal(t, testvalue, b.Get(testkey), "GetOrLoad - stored")
+
+	value, err = b.GetOrLoad(testkey, loader)
+	assert.Nil(t, err, "GetOrLoad - hit")
+	assert.Equal(t, testvalue, value, "GetOrLoad - hit value")
+	assert.Equal(t, int32(1), calls.Load(), "GetOrLoad - hit does not load")
+
+	// concurrent loads of the same key call the loader once
+	calls.Store(0)
+	release := make(chan struct{})
+	results := make([][]byte, 20)
+
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = b.GetOrLoad([]byte("concurrent"), func() ([]byte, error) {
+				calls.Add(1)
+				<-release
+				return testvalue, nil
+			})
+		}(i)
+	}
+
+	// give all goroutines time to join the load
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load(), "GetOrLoad - concurrent calls")
+	for i := range results {
+		assert.Equal(t, testvalue, results[i], "GetOrLoad - concurrent result")
+	}
+
+	// loaded values may expire
+	calls.Store(0)
+	opts := LoadOptions{TTL: 50 * time.Millisecond}
+	_, err = b.GetOrLoadWithOptions([]byte("expiring"), loader, opts)
+	assert.Nil(t, err, "GetOrLoadWithOptions - miss")
+	_, err = b.GetOrLoadWithOptions([]byte("expiring"), loader, opts)
+	assert.Nil(t, err, "GetOrLoadWithOptions - hit")
+	assert.Equal(t, int32(1), calls.Load(), "GetOrLoadWithOptions - hit does not load")
+
+	time.Sleep(60 * time.Millisecond)
+
+	_, err = b.GetOrLoadWithOptions([]byte("expiring"), loader, opts)
+	assert.Nil(t, err, "GetOrLoadWithOptions - expired")
+	assert.Equal(t, int32(2), calls.Load(), "GetOrLoadWithOptions - expired reloads")
+
+	assert.Nil(t, b.db.CreateBucket([]byte("nottl")), "CreateBucket")
+	_, err = b.db.GetOrLoadWithOptions([]byte("nottl"), testkey, loader, opts)
+	assert.ErrorIs(t, err, ErrNoTTL{}, "GetOrLoadWithOptions - TTL not enabled")
+	assert.Equal(t, int32(2), calls.Load(), "GetOrLoadWithOptions - TTL not enabled does not load")
+}
+
+func TestGetOrLoadDecoded(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	type item struct {
+		Name  string
+		Count int
+	}
+
+	var calls int
+	loader := func() (item, error) {
+		calls++
+		return item{Name: "loaded", Count: 1}, nil
+	}
+
+	value, err := GetOrLoadDecodedBucket(b, testkey, loader)
+	assert.Nil(t, err, "GetOrLoadDecodedBucket - miss")
+	assert.Equal(t, item{Name: "loaded", Count: 1}, value, "GetOrLoadDecodedBucket - miss value")
+
+	var stored item
+	assert.Nil(t, b.Decode(testkey, &stored), "Decode")
+	assert.Equal(t, value, stored, "GetOrLoadDecodedBucket - stored")
+
+	value, err = GetOrLoadDecodedBucket(b, testkey, loader)
+	assert.Nil(t, err, "GetOrLoadDecodedBucket - hit")
+	assert.Equal(t, item{Name: "loaded", Count: 1}, value, "GetOrLoadDecodedBucket - hit value")
+	assert.Equal(t, 1, calls, "GetOrLoadDecodedBucket - hit does not load")
+
+	// existing values that can not be decoded are not replaced
+	assert.Nil(t, b.Put([]byte("raw"), []byte("not encoded")), "Put")
+	_, err = GetOrLoadDecodedBucket(b, []byte("raw"), loader)
+	assert.ErrorIs(t, err, ErrDecode{}, "GetOrLoadDecodedBucket - decode error")
+	assert.Equal(t, 1, calls, "GetOrLoadDecodedBucket - decode error does not load")
+}
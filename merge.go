@@ -0,0 +1,188 @@
+package ubolt
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// MergeFunc combines the existing value of a key, which is nil when the key does not exist, with an operand passed to Merge and returns
+// the new value of the key. Neither slice may be modified or retained, and as the function is called while the write transaction is held
+// it must not use the Database.
+type MergeFunc func(existing, operand []byte) ([]byte, error)
+
+// ErrNoMergeOperator is returned by Merge and MergeAll when no MergeFunc has been registered for the bucket using RegisterMerge.
+type ErrNoMergeOperator struct {
+	bucket []byte
+}
+
+// Error returns the formatted configuration error.
+func (nm ErrNoMergeOperator) Error() string {
+	return fmt.Sprintf("No merge operator is registered for bucket %s", bucketName(nm.bucket))
+}
+
+// Is allows testing using errors.Is
+func (nm ErrNoMergeOperator) Is(target error) bool {
+	_, is := target.(ErrNoMergeOperator)
+
+	return is
+}
+
+// ErrInvalidMerge is returned by the built-in merge operators when the existing value or the operand can not be combined.
+type ErrInvalidMerge struct {
+	reason string
+}
+
+// Error returns the formatted configuration error.
+func (im ErrInvalidMerge) Error() string {
+	return fmt.Sprintf("Invalid merge: %s", im.reason)
+}
+
+// Is allows testing using errors.Is
+func (im ErrInvalidMerge) Is(target error) bool {
+	_, is := target.(ErrInvalidMerge)
+
+	return is
+}
+
+// MergeAddInt64 is a MergeFunc that adds the int64 operand to the existing int64 value, treating a missing key as zero. Both are encoded
+// using Int64Key, which matches the values written by Counters. ErrInvalidMerge is returned if either is not 8 bytes long or if the result
+// would overflow an int64.
+func MergeAddInt64(existing, operand []byte) ([]byte, error) {
+	var current int64
+
+	if existing != nil {
+		if len(existing) != 8 {
+			return nil, ErrInvalidMerge{reason: fmt.Sprintf("existing value is %d bytes rather than 8", len(existing))}
+		}
+
+		current, _ = KeyInt64(existing)
+	}
+
+	if len(operand) != 8 {
+		return nil, ErrInvalidMerge{reason: fmt.Sprintf("operand is %d bytes rather than 8", len(operand))}
+	}
+
+	delta, _ := KeyInt64(operand)
+
+	if (delta > 0 && current > math.MaxInt64-delta) || (delta < 0 && current < math.MinInt64-delta) {
+		return nil, ErrInvalidMerge{reason: "result would overflow"}
+	}
+
+	return Int64Key(current + delta), nil
+}
+
+// MergeAppend is a MergeFunc that appends the operand to the existing value.
+func MergeAppend(existing, operand []byte) ([]byte, error) {
+	value := make([]byte, 0, len(existing)+len(operand))
+
+	return append(append(value, existing...), operand...), nil
+}
+
+// RegisterMerge sets the MergeFunc used by Merge and MergeAll for the chosen bucket, replacing any previously registered for the bucket.
+// Passing a nil fn removes the registration. Registrations are not persisted so must be repeated each time the database is opened.
+func (db *Database) RegisterMerge(bucket []byte, fn MergeFunc) {
+	db.mergeMu.Lock()
+	defer db.mergeMu.Unlock()
+
+	if fn == nil {
+		delete(db.merges, string(bucket))
+		return
+	}
+
+	if db.merges == nil {
+		db.merges = make(map[string]MergeFunc)
+	}
+
+	db.merges[string(bucket)] = fn
+}
+
+// RegisterMerge sets the MergeFunc used by Merge and MergeAll for the bucket. This is forwarded to the Database implementation.
+func (b *Bucket) RegisterMerge(fn MergeFunc) {
+	b.db.RegisterMerge(b.bucket, fn)
+}
+
+// Merge combines operand with the existing value of the specified key in the chosen bucket using the MergeFunc registered for the bucket,
+// and writes the result to the key. The read and write are performed within a single read/write transaction so concurrent merges of the
+// same key are never lost. A key that does not exist or has expired is passed to the MergeFunc as nil, and as with Put any expiry set
+// by PutTTL is removed.
+//
+// ErrNoMergeOperator is returned if no MergeFunc is registered for the bucket and ErrBucketNotFound if the bucket does not exist. An error
+// returned by the MergeFunc is returned unchanged and leaves the key untouched.
+func (db *Database) Merge(bucket, key, operand []byte) error {
+	return db.MergeAll(bucket, map[string][]byte{string(key): operand})
+}
+
+// Merge combines operand with the existing value of the specified key using the MergeFunc registered for the bucket. This is forwarded to
+// the Database implementation.
+func (b *Bucket) Merge(key, operand []byte) error {
+	return b.db.Merge(b.bucket, key, operand)
+}
+
+// MergeAll performs the same process as Merge for each key and operand in m within a single read/write transaction, so either every key is
+// merged or, if any merge fails, none are. Keys are merged in sorted order.
+func (db *Database) MergeAll(bucket []byte, m map[string][]byte) error {
+	db.mergeMu.RLock()
+	fn, ok := db.merges[string(bucket)]
+	db.mergeMu.RUnlock()
+
+	if !ok {
+		return ErrNoMergeOperator{bucket: bucket}
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return db.update(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		for _, k := range keys {
+			key := db.canonicalKey([]byte(k))
+
+			var existing []byte
+			if data := b.Get(key); data != nil && !db.ttlExpired(tx, bucket, key) {
+				var err error
+				if existing, err = db.unwrapValue(bucket, key, data); err != nil {
+					return err
+				}
+			}
+
+			merged, err := fn(existing, m[k])
+			if err != nil {
+				return err
+			}
+
+			value, err := db.wrapValue(bucket, key, merged)
+			if err != nil {
+				return err
+			}
+
+			prev := previous(tx, bucket, b, key)
+
+			if err := b.Put(key, value); err != nil {
+				return err
+			}
+
+			if err := db.onMutation(tx, mutation{op: OpMerge, bucket: bucket, key: key, value: value, prev: prev}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// MergeAll performs the same process as Merge for each key and operand in m within a single read/write transaction. This is forwarded to
+// the Database implementation.
+func (b *Bucket) MergeAll(m map[string][]byte) error {
+	return b.db.MergeAll(b.bucket, m)
+}
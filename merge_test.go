@@ -0,0 +1,88 @@
+package ubolt
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.ErrorIs(t, b.Merge(testkey, testvalue), ErrNoMergeOperator{}, "Merge - no operator")
+
+	b.RegisterMerge(MergeAppend)
+	b.db.RegisterMerge(missing, MergeAppend)
+	assert.ErrorIs(t, b.db.Merge(missing, testkey, testvalue), ErrBucketNotFound{}, "Merge - missing bucket")
+
+	assert.Nil(t, b.Merge(testkey, []byte("a")), "Merge - absent key")
+	assert.Nil(t, b.Merge(testkey, []byte("b")), "Merge - existing key")
+	assert.Equal(t, []byte("ab"), b.Get(testkey), "Merge - append")
+
+	// merges of many keys are made in one transaction
+	assert.Nil(t, b.MergeAll(map[string][]byte{string(testkey): []byte("c"), "other": []byte("d")}), "MergeAll")
+	assert.Equal(t, []byte("abc"), b.Get(testkey), "MergeAll - existing key")
+	assert.Equal(t, []byte("d"), b.Get([]byte("other")), "MergeAll - absent key")
+
+	// operator errors leave every key untouched
+	b.RegisterMerge(func(existing, operand []byte) ([]byte, error) {
+		if operand == nil {
+			return nil, assert.AnError
+		}
+
+		return operand, nil
+	})
+	assert.ErrorIs(t, b.MergeAll(map[string][]byte{"a": []byte("new"), "b": nil}), assert.AnError, "MergeAll - operator error")
+	assert.False(t, b.Exists([]byte("a")), "MergeAll - operator error rolled back")
+
+	b.RegisterMerge(nil)
+	assert.ErrorIs(t, b.Merge(testkey, testvalue), ErrNoMergeOperator{}, "Merge - operator removed")
+}
+
+func TestMergeAddInt64(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	b.RegisterMerge(MergeAddInt64)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, b.Merge([]byte("hits"), Int64Key(2)), "Merge - concurrent")
+		}()
+	}
+	wg.Wait()
+
+	// values are compatible with Counters
+	value, err := b.Counters().Get("hits")
+	assert.Nil(t, err, "Counters.Get")
+	assert.Equal(t, int64(20), value, "Merge - sum")
+
+	assert.Nil(t, b.Merge([]byte("hits"), Int64Key(-25)), "Merge - negative")
+	value, _ = KeyInt64(b.Get([]byte("hits")))
+	assert.Equal(t, int64(-5), value, "Merge - negative sum")
+
+	assert.ErrorIs(t, b.Merge([]byte("hits"), []byte("short")), ErrInvalidMerge{}, "Merge - invalid operand")
+	assert.Nil(t, b.Put([]byte("invalid"), testvalue), "Put")
+	assert.ErrorIs(t, b.Merge([]byte("invalid"), Int64Key(1)), ErrInvalidMerge{}, "Merge - invalid existing value")
+
+	_, err = MergeAddInt64(Int64Key(1<<62), Int64Key(1<<62))
+	assert.ErrorIs(t, err, ErrInvalidMerge{}, "MergeAddInt64 - overflow")
+}
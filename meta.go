@@ -0,0 +1,116 @@
+package ubolt
+
+import (
+	bolt "go.etcd.io/bbolt"
+)
+
+// metaBucket holds database level metadata set using SetMeta.
+var metaBucket = []byte("__meta")
+
+// SetMeta stores a database level metadata value, such as a schema version, in a reserved bucket that is created when first used. The
+// reserved bucket is not returned by GetBuckets or ForEachAll and may not be removed using DeleteBucket.
+func (db *Database) SetMeta(key, value []byte) error {
+	return db.update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(metaBucket)
+		if err != nil {
+			return err
+		}
+
+		return b.Put(key, value)
+	})
+}
+
+// SetMeta stores a database level metadata value. This is forwarded to the Database implementation.
+func (b *Bucket) SetMeta(key, value []byte) error {
+	return b.db.SetMeta(key, value)
+}
+
+// GetMetaE retrieves a copy of the metadata value set using SetMeta. ErrKeyNotFound is returned if the key was not set.
+func (db *Database) GetMetaE(key []byte) (value []byte, err error) {
+	if err := db.view(func(tx *bolt.Tx) error {
+		var data []byte
+		if b := tx.Bucket(metaBucket); b != nil {
+			data = b.Get(key)
+		}
+
+		if data == nil {
+			return ErrKeyNotFound{bucket: metaBucket, key: key}
+		}
+
+		value = append([]byte{}, data...)
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// GetMetaE retrieves a copy of the metadata value set using SetMeta. This is forwarded to the Database implementation.
+func (b *Bucket) GetMetaE(key []byte) (value []byte, err error) {
+	return b.db.GetMetaE(key)
+}
+
+// GetMeta retrieves a copy of the metadata value set using SetMeta. The value returned may be nil which indicates the key was not set.
+func (db *Database) GetMeta(key []byte) (value []byte) {
+	value, _ = db.GetMetaE(key)
+
+	return value
+}
+
+// GetMeta retrieves a copy of the metadata value set using SetMeta. This is forwarded to the Database implementation.
+func (b *Bucket) GetMeta(key []byte) (value []byte) {
+	return b.db.GetMeta(key)
+}
+
+// DeleteMeta removes the metadata value set using SetMeta. Removing a key that was not set is not an error.
+func (db *Database) DeleteMeta(key []byte) error {
+	return db.update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(metaBucket)
+		if b == nil {
+			return nil
+		}
+
+		return b.Delete(key)
+	})
+}
+
+// DeleteMeta removes the metadata value set using SetMeta. This is forwarded to the Database implementation.
+func (b *Bucket) DeleteMeta(key []byte) error {
+	return b.db.DeleteMeta(key)
+}
+
+// EncodeMeta encodes the provided value using the configured Codec then stores it as per SetMeta.
+func (db *Database) EncodeMeta(key []byte, value interface{}) error {
+	data, err := db.encode(metaBucket, key, value)
+	if err != nil {
+		return err
+	}
+
+	return db.SetMeta(key, data)
+}
+
+// EncodeMeta encodes the provided value using the configured Codec then stores it as per SetMeta.
+func (b *Bucket) EncodeMeta(key []byte, value interface{}) error {
+	return b.db.EncodeMeta(key, value)
+}
+
+// DecodeMeta retrieves and decodes a metadata value set by EncodeMeta into the provided pointer value.
+func (db *Database) DecodeMeta(key []byte, value interface{}) error {
+	if err := checkDestination(value); err != nil {
+		return err
+	}
+
+	data, err := db.GetMetaE(key)
+	if err != nil {
+		return err
+	}
+
+	return db.unmarshal(data, value)
+}
+
+// DecodeMeta retrieves and decodes a metadata value set by EncodeMeta into the provided pointer value.
+func (b *Bucket) DecodeMeta(key []byte, value interface{}) error {
+	return b.db.DecodeMeta(key, value)
+}
@@ -0,0 +1,47 @@
+package ubolt
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeta(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	key := []byte("schema")
+
+	_, err = b.GetMetaE(key)
+	assert.ErrorIs(t, err, ErrKeyNotFound{}, "GetMetaE - not set")
+	assert.Nil(t, b.DeleteMeta(key), "DeleteMeta - not set")
+
+	assert.Nil(t, b.SetMeta(key, []byte("v1")), "SetMeta")
+	value, err := b.GetMetaE(key)
+	assert.Nil(t, err, "GetMetaE")
+	assert.Equal(t, []byte("v1"), value, "GetMetaE")
+
+	// the reserved bucket is hidden and protected
+	assert.Equal(t, [][]byte{testbucket}, b.db.GetBuckets(), "GetBuckets")
+	assert.ErrorIs(t, b.db.DeleteBucket(metaBucket), ErrReservedBucket{}, "DeleteBucket - reserved")
+
+	var n int
+	assert.Nil(t, b.db.ForEachAll(func(bucket, k, v []byte) error { n++; return nil }), "ForEachAll")
+	assert.Equal(t, 0, n, "ForEachAll - meta excluded")
+
+	assert.Nil(t, b.DeleteMeta(key), "DeleteMeta")
+	assert.Nil(t, b.GetMeta(key), "GetMeta - deleted")
+
+	// encoded values
+	assert.Nil(t, b.EncodeMeta(key, 2), "EncodeMeta")
+	var version int
+	assert.Nil(t, b.DecodeMeta(key, &version), "DecodeMeta")
+	assert.Equal(t, 2, version, "DecodeMeta")
+}
@@ -0,0 +1,113 @@
+package ubolt
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// ValueMiddleware transforms values as they are written to and read from the database, for example to compress, encrypt or checksum them.
+// Unwrap must reverse the transformation made by Wrap for the same bucket and key.
+type ValueMiddleware interface {
+	// Wrap returns the value to store in place of value.
+	Wrap(bucket, key, value []byte) ([]byte, error)
+	// Unwrap returns the original value from a stored value.
+	Unwrap(bucket, key, value []byte) ([]byte, error)
+}
+
+// ErrMissingTag is returned by the middleware returned by TagMiddleware when a stored value does not begin with the expected tag.
+type ErrMissingTag struct {
+	bucket []byte
+	key    []byte
+}
+
+// Error returns the formatted configuration error.
+func (mt ErrMissingTag) Error() string {
+	return fmt.Sprintf("Value of key %s in bucket %s is missing the expected tag", string(mt.key), bucketName(mt.bucket))
+}
+
+// Is allows testing using errors.Is
+func (mt ErrMissingTag) Is(target error) bool {
+	_, is := target.(ErrMissingTag)
+
+	return is
+}
+
+// WithValueMiddleware adds middleware that transforms every value written by methods such as Put, PutV, Encode and Writer, and every value
+// read by methods such as GetE, Decode, Scan and ForEach. Middleware is applied in the order provided on writes and in the reverse order
+// on reads. This option may be provided more than once, with later middleware applied after earlier middleware on writes.
+//
+// Operations that copy stored values without interpreting them, such as CloneBucket, ExportArchive and ImportArchive, do not apply
+// middleware. Values stored before middleware was added are not transformed and will likely fail to Unwrap.
+func WithValueMiddleware(m ...ValueMiddleware) Option {
+	return func(db *Database) {
+		db.middleware = append(db.middleware, m...)
+	}
+}
+
+// wrapValue applies the Wrap method of each middleware to value in order.
+func (db *Database) wrapValue(bucket, key, value []byte) ([]byte, error) {
+	for _, m := range db.middleware {
+		var err error
+		if value, err = m.Wrap(bucket, key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return value, nil
+}
+
+// unwrapValue applies the Unwrap method of each middleware to value in reverse order.
+func (db *Database) unwrapValue(bucket, key, value []byte) ([]byte, error) {
+	for i := len(db.middleware) - 1; i >= 0; i-- {
+		var err error
+		if value, err = db.middleware[i].Unwrap(bucket, key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return value, nil
+}
+
+// unwrapFunc returns fn with the value of every key unwrapped before fn is called. Nested buckets, which have a nil value, are passed
+// through unchanged.
+func (db *Database) unwrapFunc(bucket []byte, fn func(k, v []byte) error) func(k, v []byte) error {
+	if len(db.middleware) == 0 {
+		return fn
+	}
+
+	return func(k, v []byte) error {
+		if v == nil {
+			return fn(k, v)
+		}
+
+		v, err := db.unwrapValue(bucket, k, v)
+		if err != nil {
+			return err
+		}
+
+		return fn(k, v)
+	}
+}
+
+// tagMiddleware is returned by TagMiddleware.
+type tagMiddleware struct {
+	tag []byte
+}
+
+// TagMiddleware returns a ValueMiddleware that prefixes every stored value with tag, returning ErrMissingTag when a value without the tag
+// is read. This allows values written by the expected version of an application to be identified.
+func TagMiddleware(tag []byte) ValueMiddleware {
+	return tagMiddleware{tag: append([]byte{}, tag...)}
+}
+
+func (t tagMiddleware) Wrap(bucket, key, value []byte) ([]byte, error) {
+	return append(append(make([]byte, 0, len(t.tag)+len(value)), t.tag...), value...), nil
+}
+
+func (t tagMiddleware) Unwrap(bucket, key, value []byte) ([]byte, error) {
+	if !bytes.HasPrefix(value, t.tag) {
+		return nil, ErrMissingTag{bucket: bucket, key: key}
+	}
+
+	return value[len(t.tag):], nil
+}
@@ -0,0 +1,75 @@
+package ubolt
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+// xorMiddleware inverts every byte of the value so stored values differ from those written.
+type xorMiddleware struct{}
+
+func (xorMiddleware) Wrap(bucket, key, value []byte) ([]byte, error) {
+	out := make([]byte, len(value))
+	for i, c := range value {
+		out[i] = c ^ 0xff
+	}
+	return out, nil
+}
+
+func (m xorMiddleware) Unwrap(bucket, key, value []byte) ([]byte, error) {
+	return m.Wrap(bucket, key, value)
+}
+
+func TestValueMiddleware(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket, WithValueMiddleware(TagMiddleware([]byte("v1:")), xorMiddleware{}))
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.Nil(t, b.Put(testkey, testvalue), "Put")
+	assert.Equal(t, testvalue, b.Get(testkey), "Get")
+
+	key, err := b.PutV([]byte("generated"))
+	assert.Nil(t, err, "PutV")
+	assert.Equal(t, []byte("generated"), b.Get(key), "Get - PutV")
+
+	assert.Nil(t, b.Encode([]byte("encoded"), []int{1, 2}), "Encode")
+	var ints []int
+	assert.Nil(t, b.Decode([]byte("encoded"), &ints), "Decode")
+	assert.Equal(t, []int{1, 2}, ints, "Decode")
+
+	// middleware is applied in order on writes so the tagged value is inverted
+	var stored []byte
+	assert.Nil(t, b.db.bdb().View(func(tx *bolt.Tx) error {
+		stored = append([]byte{}, tx.Bucket(testbucket).Get(testkey)...)
+		return nil
+	}), "View")
+	want, _ := xorMiddleware{}.Wrap(nil, nil, append([]byte("v1:"), testvalue...))
+	assert.Equal(t, want, stored, "stored value")
+
+	// iteration unwraps values
+	assert.Nil(t, b.Scan(testkey, func(k, v []byte) error {
+		assert.Equal(t, testvalue, v, "Scan")
+		return nil
+	}), "Scan")
+	assert.Nil(t, b.ForEach(func(k, v []byte) error {
+		assert.False(t, bytes.HasPrefix(v, []byte("v1:")), "ForEach")
+		return nil
+	}), "ForEach")
+
+	// values written without the middleware fail to unwrap
+	assert.Nil(t, b.db.bdb().Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(testbucket).Put([]byte("raw"), []byte("raw"))
+	}), "Update")
+	_, err = b.GetE([]byte("raw"))
+	assert.ErrorIs(t, err, ErrMissingTag{}, "GetE - untagged")
+	assert.ErrorIs(t, b.ForEach(func(k, v []byte) error { return nil }), ErrMissingTag{}, "ForEach - untagged")
+}
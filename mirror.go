@@ -0,0 +1,453 @@
+package ubolt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"sync"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// opCreateBucket records the creation of a bucket for replay by a mirror. It is never passed to onMutation.
+const opCreateBucket Op = "createbucket"
+
+// ErrMirror is returned when a write was committed to the database however could not be applied to the secondary database set using
+// Mirror. When mirroring asynchronously it is passed to the error callback instead.
+type ErrMirror struct {
+	err error
+}
+
+// Error returns the formatted configuration error.
+func (m ErrMirror) Error() string {
+	return fmt.Sprintf("Write committed however mirroring failed: %v", m.err)
+}
+
+// Is allows testing using errors.Is
+func (m ErrMirror) Is(target error) bool {
+	_, is := target.(ErrMirror)
+
+	return is
+}
+
+// Unwrap returns the error returned by the secondary database.
+func (m ErrMirror) Unwrap() error {
+	return m.err
+}
+
+// ErrMirrorActive is returned by Mirror when the database is already being mirrored.
+type ErrMirrorActive struct{}
+
+// Error returns the formatted configuration error.
+func (ma ErrMirrorActive) Error() string {
+	return "Database is already being mirrored"
+}
+
+// Is allows testing using errors.Is
+func (ma ErrMirrorActive) Is(target error) bool {
+	_, is := target.(ErrMirrorActive)
+
+	return is
+}
+
+// MirrorOption is used to change the behaviour of Mirror.
+type MirrorOption func(*mirror)
+
+// WithMirrorAsync applies mutations to the secondary database from a background goroutine rather than before each write returns. Up to
+// queueSize committed transactions are queued, after which writes block until the queue has space. Any failure to apply a transaction is
+// passed to onError, if set, as ErrMirror and the transaction is not retried.
+func WithMirrorAsync(queueSize int, onError func(error)) MirrorOption {
+	return func(m *mirror) {
+		m.queue = make(chan []mirrorOp, max(queueSize, 1))
+		m.onError = onError
+	}
+}
+
+// mirror replays committed mutations to a secondary database.
+type mirror struct {
+	secondary *Database
+	// mu is acquired before each transaction is committed and held until its mutations are delivered, so they are delivered in commit order
+	mu      sync.Mutex
+	queue   chan []mirrorOp
+	onError func(error)
+	done    chan struct{}
+}
+
+// mirrorOp is a single mutation to replay, which holds copies of the key and value.
+type mirrorOp struct {
+	mutation
+	// sequence is the sequence of the bucket after an OpPutV or opCreateBucket
+	sequence uint64
+}
+
+// Mirror replays every mutation committed through this package, including Put, PutV, Delete, CreateBucket and DeleteBucket, to the
+// secondary database until the returned stop function is called. Values are replayed as stored, so the secondary holds the same bytes
+// regardless of any WithValueMiddleware or WithCodec options, and the write hooks enabled on the secondary are applied as normal.
+//
+// By default each transaction is applied to the secondary in its own read/write transaction before the write returns, and a failure is
+// returned as ErrMirror once the write has been committed to this database. Use WithMirrorAsync to apply transactions from a background
+// goroutine instead. Either way transactions are applied in the order they were committed.
+//
+// Mirror does not copy existing data, so call SyncMirror once Mirror has returned to bring the secondary up to date. State kept in reserved
+// buckets, such as TTLs and metadata, is only copied by SyncMirror. CompareMirror may be used to detect whether the secondary has diverged.
+//
+// Calling stop waits for any queued transactions to be applied. It must not be called from the error callback and should be called before
+// either database is closed.
+func (db *Database) Mirror(secondary *Database, opts ...MirrorOption) (stop func(), err error) {
+	if secondary.closed.Load() {
+		return nil, ErrDatabaseClosed{}
+	}
+
+	m := &mirror{secondary: secondary, done: make(chan struct{})}
+	for _, o := range opts {
+		o(m)
+	}
+
+	// no write is in progress while the gate is held, so each transaction is either mirrored in full or not at all
+	db.gate.Lock()
+	defer db.gate.Unlock()
+
+	if !db.mirror.CompareAndSwap(nil, m) {
+		return nil, ErrMirrorActive{}
+	}
+
+	if m.queue != nil {
+		go m.run()
+	} else {
+		close(m.done)
+	}
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			db.gate.Lock()
+			db.mirror.Store(nil)
+			db.gate.Unlock()
+
+			if m.queue != nil {
+				close(m.queue)
+			}
+
+			<-m.done
+		})
+	}, nil
+}
+
+// Mirror replays every mutation committed through this package to the secondary database until the returned stop function is called.
+func (b *Bucket) Mirror(secondary *Database, opts ...MirrorOption) (stop func(), err error) {
+	return b.db.Mirror(secondary, opts...)
+}
+
+// SyncMirror replaces the entire contents of the secondary database, including reserved buckets, with a copy of this database taken inside a
+// single read-only transaction. Writes are blocked while the copy is made, which is written to the secondary in a single read/write
+// transaction.
+//
+// To keep the secondary up to date without missing any writes call Mirror first then SyncMirror.
+func (db *Database) SyncMirror(secondary *Database) error {
+	db.gate.Lock()
+	defer db.gate.Unlock()
+
+	return db.view(func(tx *bolt.Tx) error {
+		return secondary.update(func(stx *bolt.Tx) error {
+			var names [][]byte
+			if err := stx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+				names = append(names, append([]byte{}, name...))
+
+				return nil
+			}); err != nil {
+				return err
+			}
+
+			for _, name := range names {
+				if err := stx.DeleteBucket(name); err != nil {
+					return err
+				}
+			}
+
+			return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+				nb, err := stx.CreateBucket(name)
+				if err != nil {
+					return err
+				}
+
+				return compactBucket(b, nb, nil)
+			})
+		})
+	})
+}
+
+// SyncMirror replaces the entire contents of the secondary database with a copy of this database.
+func (b *Bucket) SyncMirror(secondary *Database) error {
+	return b.db.SyncMirror(secondary)
+}
+
+// MirrorDiff describes a top-level bucket whose contents differ between a database and its secondary, as returned by CompareMirror.
+type MirrorDiff struct {
+	// Bucket is the name of the bucket.
+	Bucket []byte
+	// PrimaryKeys is the number of keys in the bucket of the database, including those in nested buckets, or -1 if it does not exist.
+	PrimaryKeys int
+	// SecondaryKeys is the number of keys in the bucket of the secondary, including those in nested buckets, or -1 if it does not exist.
+	SecondaryKeys int
+	// PrimaryHash is a hash of every key and value in the bucket of the database.
+	PrimaryHash uint64
+	// SecondaryHash is a hash of every key and value in the bucket of the secondary.
+	SecondaryHash uint64
+}
+
+// CompareMirror returns every top-level bucket whose keys, values or nested buckets differ between this database and the secondary,
+// ordered by name, by comparing the number of keys and a hash of the contents of each bucket. An empty result means the databases hold
+// the same data. Reserved buckets are not compared.
+//
+// Each database is read inside its own read-only transaction, so writes that have not yet been applied by an asynchronous mirror are
+// reported as differences.
+func (db *Database) CompareMirror(secondary *Database) ([]MirrorDiff, error) {
+	primary, err := db.bucketDigests()
+	if err != nil {
+		return nil, err
+	}
+
+	other, err := secondary.bucketDigests()
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []MirrorDiff
+
+	for i, j := 0, 0; i < len(primary) || j < len(other); {
+		var p, s *bucketDigest
+
+		switch {
+		case j == len(other) || (i < len(primary) && bytes.Compare(primary[i].name, other[j].name) < 0):
+			p = &primary[i]
+			i++
+		case i == len(primary) || bytes.Compare(primary[i].name, other[j].name) > 0:
+			s = &other[j]
+			j++
+		default:
+			p, s = &primary[i], &other[j]
+			i++
+			j++
+		}
+
+		d := MirrorDiff{PrimaryKeys: -1, SecondaryKeys: -1}
+		if p != nil {
+			d.Bucket, d.PrimaryKeys, d.PrimaryHash = p.name, p.keys, p.hash
+		}
+		if s != nil {
+			d.Bucket, d.SecondaryKeys, d.SecondaryHash = s.name, s.keys, s.hash
+		}
+
+		if d.PrimaryKeys != d.SecondaryKeys || d.PrimaryHash != d.SecondaryHash {
+			diffs = append(diffs, d)
+		}
+	}
+
+	return diffs, nil
+}
+
+// CompareMirror returns every top-level bucket whose contents differ between this database and the secondary.
+func (b *Bucket) CompareMirror(secondary *Database) ([]MirrorDiff, error) {
+	return b.db.CompareMirror(secondary)
+}
+
+type bucketDigest struct {
+	name []byte
+	keys int
+	hash uint64
+}
+
+// bucketDigests returns the key count and content hash of every top-level bucket ordered by name, excluding reserved buckets.
+func (db *Database) bucketDigests() (digests []bucketDigest, err error) {
+	if err := db.view(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			if isReserved(name) {
+				return nil
+			}
+
+			h := fnv.New64a()
+			keys, err := digestBucket(h, b)
+			if err != nil {
+				return err
+			}
+
+			digests = append(digests, bucketDigest{name: append([]byte{}, name...), keys: keys, hash: h.Sum64()})
+
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	return digests, nil
+}
+
+// digestBucket writes every key and value of the bucket and its nested buckets to h, returning the number of keys written.
+func digestBucket(h hash.Hash64, b *bolt.Bucket) (keys int, err error) {
+	err = b.ForEach(func(k, v []byte) error {
+		writeDigest(h, k)
+
+		if v == nil {
+			// mark the start and end of the nested bucket so its keys can not be confused with those of the parent
+			writeDigest(h, nil)
+
+			n, err := digestBucket(h, b.Bucket(k))
+			keys += n
+			writeDigest(h, nil)
+
+			return err
+		}
+
+		writeDigest(h, v)
+		keys++
+
+		return nil
+	})
+
+	return keys, err
+}
+
+// writeDigest writes the length of data followed by data to h, so adjacent values can not be confused.
+func writeDigest(h hash.Hash64, data []byte) {
+	var n [binary.MaxVarintLen64]byte
+	_, _ = h.Write(n[:binary.PutUvarint(n[:], uint64(len(data)))])
+	_, _ = h.Write(data)
+}
+
+// recordMirror records a mutation of the current read/write transaction for replay once it is committed, if the database is being mirrored.
+func (db *Database) recordMirror(tx *bolt.Tx, m mutation) {
+	if db.mirror.Load() == nil {
+		return
+	}
+
+	op := mirrorOp{mutation: mutation{
+		op:     m.op,
+		bucket: append([]byte{}, m.bucket...),
+		key:    append([]byte{}, m.key...),
+		value:  append([]byte{}, m.value...),
+	}}
+
+	if m.op == OpPutV || m.op == opCreateBucket {
+		if b := lookupBucket(tx, m.bucket); b != nil {
+			op.sequence = b.Sequence()
+		}
+	}
+
+	db.mirrorPending = append(db.mirrorPending, op)
+}
+
+// commitMirrored commits tx and, if it made any mutations while the database is being mirrored, delivers them to the mirror. Delivery is
+// ordered by acquiring the mirror lock while the writer lock is still held.
+func (db *Database) commitMirrored(tx *bolt.Tx) error {
+	pending := db.mirrorPending
+	db.mirrorPending = nil
+
+	m := db.mirror.Load()
+	if m == nil || len(pending) == 0 {
+		return tx.Commit()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if m.queue != nil {
+		m.queue <- pending
+
+		return nil
+	}
+
+	if err := m.apply(pending); err != nil {
+		return ErrMirror{err}
+	}
+
+	return nil
+}
+
+// run applies queued transactions until the queue is closed.
+func (m *mirror) run() {
+	defer close(m.done)
+
+	for ops := range m.queue {
+		if err := m.apply(ops); err != nil && m.onError != nil {
+			m.onError(ErrMirror{err})
+		}
+	}
+}
+
+// apply replays the mutations of a single transaction to the secondary in a single read/write transaction.
+func (m *mirror) apply(ops []mirrorOp) error {
+	sdb := m.secondary
+
+	return sdb.update(func(tx *bolt.Tx) error {
+		for _, op := range ops {
+			switch op.op {
+			case opCreateBucket:
+				b, err := createBucketPath(tx, op.bucket)
+				if err != nil {
+					return err
+				}
+
+				if op.sequence != b.Sequence() {
+					if err := b.SetSequence(op.sequence); err != nil {
+						return err
+					}
+				}
+
+				sdb.recordMirror(tx, op.mutation)
+			case OpDeleteBucket:
+				if lookupBucket(tx, op.bucket) == nil {
+					continue
+				}
+
+				if err := sdb.deleteBucket(tx, op.bucket); err != nil {
+					return err
+				}
+			case OpDelete:
+				b := lookupBucket(tx, op.bucket)
+				if b == nil {
+					continue
+				}
+
+				prev := previous(tx, op.bucket, b, op.key)
+
+				if err := b.Delete(op.key); err != nil {
+					return err
+				}
+
+				if err := sdb.onMutation(tx, mutation{op: op.op, bucket: op.bucket, key: op.key, prev: prev}); err != nil {
+					return err
+				}
+			default:
+				b, err := createBucketPath(tx, op.bucket)
+				if err != nil {
+					return err
+				}
+
+				prev := previous(tx, op.bucket, b, op.key)
+
+				if err := b.Put(op.key, op.value); err != nil {
+					return err
+				}
+
+				if op.sequence > b.Sequence() {
+					if err := b.SetSequence(op.sequence); err != nil {
+						return err
+					}
+				}
+
+				if err := sdb.onMutation(tx, mutation{op: op.op, bucket: op.bucket, key: op.key, value: op.value, prev: prev}); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,248 @@
+package ubolt
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testmirror = "mirror.db"
+
+func TestMirror(t *testing.T) {
+	tests := []struct {
+		name  string
+		async bool
+	}{
+		{"sync", false},
+		{"async", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = os.Remove(testdb)
+			_ = os.Remove(testmirror)
+			defer os.Remove(testdb)
+			defer os.Remove(testmirror)
+
+			db, err := Open(testdb)
+			if err != nil {
+				panic(err)
+			}
+			defer db.Close()
+
+			secondary, err := Open(testmirror)
+			if err != nil {
+				panic(err)
+			}
+			defer secondary.Close()
+
+			// existing data is only copied by SyncMirror
+			if err := db.CreateBucket(testbucket); err != nil {
+				panic(err)
+			}
+			if err := db.Put(testbucket, []byte("existing"), testvalue); err != nil {
+				panic(err)
+			}
+			if err := db.SetMeta([]byte("version"), []byte("1")); err != nil {
+				panic(err)
+			}
+
+			var mu sync.Mutex
+			var errs []error
+
+			var opts []MirrorOption
+			if tt.async {
+				opts = append(opts, WithMirrorAsync(4, func(err error) {
+					mu.Lock()
+					defer mu.Unlock()
+
+					errs = append(errs, err)
+				}))
+			}
+
+			stop, err := db.Mirror(secondary, opts...)
+			assert.Nil(t, err, "Mirror")
+
+			_, err = db.Mirror(secondary)
+			assert.ErrorIs(t, err, ErrMirrorActive{}, "Mirror - already active")
+
+			assert.Nil(t, db.SyncMirror(secondary), "SyncMirror")
+
+			assert.Nil(t, db.Put(testbucket, testkey, testvalue), "Put")
+			key, err := db.PutV(testbucket, []byte("sequenced"))
+			assert.Nil(t, err, "PutV")
+			assert.Nil(t, db.Delete(testbucket, []byte("existing")), "Delete")
+			assert.Nil(t, db.CreateBucket([]byte("empty")), "CreateBucket")
+			assert.Nil(t, db.CreateBucket([]byte("dropped")), "CreateBucket")
+			assert.Nil(t, db.DeleteBucket([]byte("dropped")), "DeleteBucket")
+
+			// a failed write is not mirrored
+			assert.NotNil(t, db.Put([]byte("missing"), testkey, testvalue), "Put - missing bucket")
+
+			stop()
+			stop()
+
+			// writes after stop are not mirrored
+			assert.Nil(t, db.Put(testbucket, []byte("after"), testvalue), "Put - after stop")
+
+			assert.Equal(t, testvalue, secondary.Get(testbucket, testkey), "Mirror - Put")
+			assert.Equal(t, []byte("sequenced"), secondary.Get(testbucket, key), "Mirror - PutV")
+			assert.False(t, secondary.Exists(testbucket, []byte("existing")), "Mirror - Delete")
+			assert.Equal(t, []string{string(testbucket), "empty"}, bucketStrings(secondary.GetBuckets()), "Mirror - buckets")
+			assert.Equal(t, []byte("1"), secondary.GetMeta([]byte("version")), "SyncMirror - reserved buckets")
+
+			// the sequence is kept in step so later writes to the secondary do not reuse keys
+			next, err := secondary.PutV(testbucket, testvalue)
+			assert.Nil(t, err, "PutV - secondary")
+			assert.NotEqual(t, key, next, "PutV - secondary sequence")
+			assert.Nil(t, secondary.Delete(testbucket, next), "Delete - secondary")
+
+			diffs, err := db.CompareMirror(secondary)
+			assert.Nil(t, err, "CompareMirror")
+			assert.Len(t, diffs, 1, "CompareMirror - diverged")
+			if len(diffs) == 1 {
+				assert.Equal(t, testbucket, diffs[0].Bucket, "CompareMirror - bucket")
+				assert.Equal(t, diffs[0].SecondaryKeys+1, diffs[0].PrimaryKeys, "CompareMirror - keys")
+			}
+
+			assert.Nil(t, db.SyncMirror(secondary), "SyncMirror - resync")
+			diffs, err = db.CompareMirror(secondary)
+			assert.Nil(t, err, "CompareMirror")
+			assert.Empty(t, diffs, "CompareMirror - in sync")
+
+			assert.Empty(t, errs, "Mirror - errors")
+		})
+	}
+}
+
+func TestMirrorError(t *testing.T) {
+	_ = os.Remove(testdb)
+	_ = os.Remove(testmirror)
+	defer os.Remove(testdb)
+	defer os.Remove(testmirror)
+
+	db, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	secondary, err := OpenBucket(testmirror, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer secondary.Close()
+
+	stop, err := db.Mirror(secondary.db)
+	assert.Nil(t, err, "Mirror")
+	defer stop()
+
+	// the secondary rejects the write so the mirror fails
+	assert.Nil(t, secondary.SetBucketQuota(0, 1), "SetBucketQuota")
+	assert.Nil(t, db.Put([]byte("first"), testvalue), "Put")
+
+	err = db.Put([]byte("second"), testvalue)
+	assert.ErrorIs(t, err, ErrMirror{}, "Put - mirror failed")
+	assert.ErrorIs(t, err, ErrQuotaExceeded{}, "Put - mirror error")
+	assert.True(t, errors.Is(err, ErrMirror{}) && db.Exists([]byte("second")), "Put - committed")
+	assert.False(t, secondary.Exists([]byte("second")), "Put - not mirrored")
+}
+
+func bucketStrings(buckets [][]byte) []string {
+	s := make([]string, len(buckets))
+	for i, b := range buckets {
+		s[i] = string(b)
+	}
+
+	return s
+}
+
+func TestMirrorImportCloneIndex(t *testing.T) {
+	_ = os.Remove(testdb)
+	_ = os.Remove(testmirror)
+	_ = os.Remove(testbackup)
+	defer os.Remove(testdb)
+	defer os.Remove(testmirror)
+	defer os.Remove(testbackup)
+
+	src, err := Open(testbackup)
+	if err != nil {
+		panic(err)
+	}
+	defer src.Close()
+
+	nested := BucketPath([]byte("parent"), []byte("nested"))
+
+	for _, bucket := range [][]byte{testbucket, nested} {
+		if err := src.CreateBucket(bucket); err != nil {
+			panic(err)
+		}
+		for i := 0; i < 3; i++ {
+			if _, err := src.PutV(bucket, testvalue); err != nil {
+				panic(err)
+			}
+		}
+	}
+	if err := src.Put(nested, testkey, testvalue); err != nil {
+		panic(err)
+	}
+
+	var archive bytes.Buffer
+	if err := src.ExportArchive(&archive); err != nil {
+		panic(err)
+	}
+
+	db, err := Open(testdb)
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	secondary, err := Open(testmirror)
+	if err != nil {
+		panic(err)
+	}
+	defer secondary.Close()
+
+	stop, err := db.Mirror(secondary)
+	assert.Nil(t, err, "Mirror")
+
+	// imported and cloned buckets are replayed along with their sequence
+	assert.Nil(t, db.ImportArchive(&archive), "ImportArchive")
+	assert.Nil(t, db.CreateBucket([]byte("clone")), "CreateBucket")
+	assert.Nil(t, db.Put([]byte("clone"), []byte("stale"), testvalue), "Put")
+	assert.Nil(t, db.CloneBucketWithOptions(testbucket, []byte("clone"), CloneOptions{Overwrite: true, ChunkSize: 1}), "CloneBucket")
+	assert.Nil(t, db.EmptyBucketWithOptions(nested, EmptyBucketOptions{PreserveSequence: true}), "EmptyBucket")
+
+	// index entries are replayed along with the data bucket
+	idx := NewIndex(db, []byte("users"), []byte("users_by_name"), func(k, v []byte) []byte { return v })
+	assert.Nil(t, idx.Put([]byte("1"), []byte("alice")), "Index.Put")
+	assert.Nil(t, idx.Put([]byte("2"), []byte("bob")), "Index.Put")
+	assert.Nil(t, idx.Put([]byte("1"), []byte("carol")), "Index.Put - changed")
+	assert.Nil(t, idx.Delete([]byte("2")), "Index.Delete")
+	_, err = idx.Rebuild()
+	assert.Nil(t, err, "Index.Rebuild")
+
+	stop()
+
+	diffs, err := db.CompareMirror(secondary)
+	assert.Nil(t, err, "CompareMirror")
+	assert.Empty(t, diffs, "CompareMirror - in sync")
+
+	for _, bucket := range [][]byte{testbucket, []byte("clone"), nested} {
+		want, err := db.PutVID(bucket, testvalue)
+		assert.Nil(t, err, "PutVID")
+
+		got, err := secondary.PutVID(bucket, testvalue)
+		assert.Nil(t, err, "PutVID - secondary")
+		assert.Equal(t, want, got, "Mirror - sequence")
+	}
+
+	keys, err := NewIndex(secondary, []byte("users"), []byte("users_by_name"), nil).Lookup([]byte("carol"))
+	assert.Nil(t, err, "Index.Lookup - secondary")
+	assert.Equal(t, [][]byte{[]byte("1")}, keys, "Index.Lookup - secondary")
+}
@@ -0,0 +1,96 @@
+// Package msgpack provides a ubolt.Codec that encodes values using MessagePack (https://msgpack.org) so they may be read by other
+// languages. Encoding is performed by github.com/vmihailenco/msgpack/v5.
+//
+// Importing the package registers Codec using ubolt.RegisterCodec, which allows ubolt.WithMsgpack and ubolt.MsgpackCodec to be used and
+// values written by Codec to be decoded whichever ubolt.Codec is configured:
+//
+//	import _ "github.com/andrewheberle/ubolt/msgpack"
+//
+// Structs are encoded as maps keyed by field name, which may be overridden using a "msgpack" struct tag. Maps from strings to strings, bools
+// or empty interfaces are sorted by key so equal maps produce equal bytes, while other maps are encoded in no particular order. Integers use
+// the smallest representation. A time.Time is encoded using the timestamp extension type and decoded in the local time zone.
+//
+// Decoding into an empty interface produces nil, bool, int64, uint64, float64, string, []byte, time.Time, []interface{} and
+// map[string]interface{}, so maps with keys that are not strings must be decoded into a typed map. Neither cyclic values nor data from
+// untrusted sources should be passed to the package, as the library does not detect cycles and allocates memory in proportion to the
+// lengths declared by the data.
+package msgpack
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/andrewheberle/ubolt"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// ErrTrailingData is returned by Unmarshal when the data holds more than one value.
+type ErrTrailingData struct {
+	n int
+}
+
+// Error returns the formatted configuration error.
+func (td ErrTrailingData) Error() string {
+	return fmt.Sprintf("Invalid msgpack: %d unexpected trailing bytes", td.n)
+}
+
+// Is allows testing using errors.Is
+func (td ErrTrailingData) Is(target error) bool {
+	_, is := target.(ErrTrailingData)
+
+	return is
+}
+
+// Codec encodes values using Marshal and decodes them using Unmarshal, recording ubolt.CodecMsgpack in the encoding header of each value.
+var Codec ubolt.IdentifiedCodec = codec{}
+
+func init() {
+	ubolt.RegisterCodec(Codec)
+}
+
+type codec struct{}
+
+func (codec) CodecID() ubolt.CodecID {
+	return ubolt.CodecMsgpack
+}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	return Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	return Unmarshal(data, v)
+}
+
+// Marshal returns the MessagePack encoding of v.
+func Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetSortMapKeys(true)
+	enc.UseCompactInts(true)
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes the MessagePack data into the value pointed to by v. The data must hold exactly one value.
+func Unmarshal(data []byte, v interface{}) error {
+	r := bytes.NewReader(data)
+
+	dec := msgpack.NewDecoder(r)
+	dec.UseLooseInterfaceDecoding(true)
+
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+
+	if r.Len() != 0 {
+		return ErrTrailingData{r.Len()}
+	}
+
+	return nil
+}
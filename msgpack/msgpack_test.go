@@ -0,0 +1,171 @@
+package msgpack
+
+import (
+	"encoding/hex"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type golden struct {
+	Name   string `msgpack:"name"`
+	Age    int    `msgpack:"age"`
+	Tags   []string
+	When   time.Time
+	Score  float64
+	Delta  int16
+	Raw    []byte
+	Secret string `msgpack:"-"`
+	Note   string `msgpack:"note,omitempty"`
+}
+
+func TestGolden(t *testing.T) {
+	value := golden{
+		Name:   "alice",
+		Age:    30,
+		Tags:   []string{"a", "b"},
+		When:   time.Unix(1700000000, 0),
+		Score:  -1.5,
+		Delta:  -200,
+		Raw:    []byte{1, 2},
+		Secret: "hidden",
+	}
+
+	want := "" +
+		"87" + // map of 7 entries
+		"a46e616d65" + "a5616c696365" + // "name": "alice"
+		"a3616765" + "1e" + // "age": 30
+		"a454616773" + "92a161a162" + // "Tags": ["a", "b"]
+		"a45768656e" + "d6ff6553f100" + // "When": timestamp 32 of 1700000000
+		"a553636f7265" + "cbbff8000000000000" + // "Score": -1.5
+		"a544656c7461" + "d1ff38" + // "Delta": -200
+		"a3526177" + "c4020102" // "Raw": bin 0x01 0x02
+
+	data, err := Marshal(value)
+	assert.Nil(t, err, "Marshal")
+	assert.Equal(t, want, hex.EncodeToString(data), "Marshal")
+
+	var got golden
+	assert.Nil(t, Unmarshal(data, &got), "Unmarshal")
+
+	value.Secret = ""
+	assert.Equal(t, value, got, "Unmarshal")
+
+	// scalars use the smallest representation
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, "c0"},
+		{true, "c3"},
+		{-32, "e0"},
+		{-33, "d0df"},
+		{127, "7f"},
+		{128, "cc80"},
+		{uint64(math.MaxUint64), "cfffffffffffffffff"},
+		{int64(math.MinInt64), "d38000000000000000"},
+		{float32(1.5), "ca3fc00000"},
+		{map[string]interface{}{"b": 2, "a": 1}, "82a16101a16202"},
+		{time.Unix(1700000000, 5).UTC(), "d7ff000000146553f100"},
+		{time.Unix(-1, 0).UTC(), "c70cff00000000ffffffffffffffff"},
+	}
+
+	for _, tt := range tests {
+		data, err := Marshal(tt.value)
+		assert.Nil(t, err, "Marshal %v", tt.value)
+		assert.Equal(t, tt.want, hex.EncodeToString(data), "Marshal %v", tt.value)
+	}
+}
+
+type record struct {
+	ID       uint32
+	Name     string
+	Scores   []float64
+	Labels   map[string]string
+	Created  time.Time
+	Parent   *record
+	Children []record
+	Hash     [4]byte
+	Any      interface{}
+}
+
+func TestRoundTrip(t *testing.T) {
+	// times are decoded in the local time zone
+	created := time.Date(2024, 2, 29, 12, 30, 45, 123456789, time.UTC).Local()
+
+	tests := []struct {
+		name  string
+		value interface{}
+		into  func() interface{}
+	}{
+		{"string", "hello", func() interface{} { return new(string) }},
+		{"int", -123456789, func() interface{} { return new(int) }},
+		{"float", math.Pi, func() interface{} { return new(float64) }},
+		{"bytes", []byte("raw"), func() interface{} { return new([]byte) }},
+		{"slice", []int{1, -2, 300, 70000}, func() interface{} { return new([]int) }},
+		{"map", map[string]int{"one": 1, "two": 2}, func() interface{} { return new(map[string]int) }},
+		{"int keys", map[int]string{1: "one", -1: "minus one"}, func() interface{} { return new(map[int]string) }},
+		{"time", created, func() interface{} { return new(time.Time) }},
+		{"far time", time.Date(2600, 1, 1, 0, 0, 0, 1, time.UTC).Local(), func() interface{} { return new(time.Time) }},
+		{"struct", record{
+			ID:       7,
+			Name:     "parent",
+			Scores:   []float64{1.5, -2.25},
+			Labels:   map[string]string{"env": "prod"},
+			Created:  created,
+			Parent:   &record{ID: 1, Name: "root", Created: created},
+			Children: []record{{ID: 8, Name: "child", Created: created}},
+			Hash:     [4]byte{0xde, 0xad, 0xbe, 0xef},
+			Any:      "text",
+		}, func() interface{} { return new(record) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Marshal(tt.value)
+			assert.Nil(t, err, "Marshal")
+
+			got := tt.into()
+			assert.Nil(t, Unmarshal(data, got), "Unmarshal")
+
+			// dereference the pointer returned by into
+			assert.Equal(t, tt.value, deref(got), "Unmarshal")
+		})
+	}
+}
+
+func TestUnmarshalInterface(t *testing.T) {
+	data, err := Marshal(map[string]interface{}{
+		"list":  []interface{}{"a", int64(1), true, nil},
+		"float": 2.5,
+		"big":   uint64(math.MaxUint64),
+	})
+	assert.Nil(t, err, "Marshal")
+
+	var got interface{}
+	assert.Nil(t, Unmarshal(data, &got), "Unmarshal")
+	assert.Equal(t, map[string]interface{}{
+		"list":  []interface{}{"a", int64(1), true, nil},
+		"float": 2.5,
+		"big":   uint64(math.MaxUint64),
+	}, got, "Unmarshal")
+}
+
+func TestErrors(t *testing.T) {
+	_, err := Marshal(make(chan int))
+	assert.NotNil(t, err, "Marshal - chan")
+
+	var n int
+	assert.NotNil(t, Unmarshal([]byte{0xc0}, n), "Unmarshal - not a pointer")
+	assert.NotNil(t, Unmarshal([]byte{0xa1, 'a'}, &n), "Unmarshal - string into int")
+	assert.NotNil(t, Unmarshal([]byte{0xc1}, &n), "Unmarshal - never used byte")
+	assert.NotNil(t, Unmarshal([]byte{0xcd, 0x01}, &n), "Unmarshal - truncated")
+	assert.ErrorIs(t, Unmarshal([]byte{0x01, 0x02}, &n), ErrTrailingData{}, "Unmarshal - trailing data")
+}
+
+func deref(v interface{}) interface{} {
+	return reflect.ValueOf(v).Elem().Interface()
+}
@@ -0,0 +1,168 @@
+package ubolt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// nestedPrefix marks a bucket name created by BucketPath.
+var nestedPrefix = []byte{0, '/'}
+
+// ErrInvalidBucketName is returned when creating or opening a bucket whose name begins with the bytes 0x00 and '/', which mark a name
+// returned by BucketPath, without being a nested path returned by BucketPath.
+type ErrInvalidBucketName struct {
+	bucket []byte
+}
+
+// Error returns the formatted configuration error.
+func (ibn ErrInvalidBucketName) Error() string {
+	return fmt.Sprintf("Invalid bucket name %q as it begins with the prefix reserved for nested bucket paths", ibn.bucket)
+}
+
+// Is allows testing using errors.Is
+func (ibn ErrInvalidBucketName) Is(target error) bool {
+	_, is := target.(ErrInvalidBucketName)
+
+	return is
+}
+
+// BucketPath returns a bucket name that refers to a nested bucket, where each segment is the name of a bucket within the previous one. The
+// returned name may be used as the bucket argument of any Database method. A path with a single segment is returned unchanged.
+func BucketPath(segments ...[]byte) []byte {
+	if len(segments) == 1 {
+		return segments[0]
+	}
+
+	name := append([]byte{}, nestedPrefix...)
+	for _, segment := range segments {
+		name = binary.AppendUvarint(name, uint64(len(segment)))
+		name = append(name, segment...)
+	}
+
+	return name
+}
+
+// splitBucketPath returns the segments of a bucket name created by BucketPath. Any other name is returned as a single segment.
+func splitBucketPath(name []byte) [][]byte {
+	if !bytes.HasPrefix(name, nestedPrefix) {
+		return [][]byte{name}
+	}
+
+	var segments [][]byte
+	for rest := name[len(nestedPrefix):]; len(rest) > 0; {
+		n, size := binary.Uvarint(rest)
+		if size <= 0 || uint64(len(rest)-size) < n {
+			// not a valid path so treat it as a plain name
+			return [][]byte{name}
+		}
+
+		segments = append(segments, rest[size:size+int(n)])
+		rest = rest[size+int(n):]
+	}
+
+	if len(segments) == 0 {
+		return [][]byte{name}
+	}
+
+	return segments
+}
+
+// checkBucketName returns ErrInvalidBucketName if name begins with nestedPrefix but is not a path of at least two segments, as BucketPath
+// returns, so it can not be told apart from a nested path.
+func checkBucketName(name []byte) error {
+	if bytes.HasPrefix(name, nestedPrefix) && len(splitBucketPath(name)) < 2 {
+		return ErrInvalidBucketName{bucket: name}
+	}
+
+	return nil
+}
+
+// bucketName returns a printable form of a bucket name, with the segments of a nested bucket separated by "/".
+func bucketName(name []byte) string {
+	segments := splitBucketPath(name)
+
+	s := make([]string, len(segments))
+	for i, segment := range segments {
+		s[i] = string(segment)
+	}
+
+	return strings.Join(s, "/")
+}
+
+// lookupBucket returns the bucket, which may be nested, or nil if it does not exist.
+func lookupBucket(tx *bolt.Tx, name []byte) *bolt.Bucket {
+	return bucketPath(tx, splitBucketPath(name))
+}
+
+// createBucketPath returns the bucket, which may be nested, creating it and any parent buckets that do not exist.
+func createBucketPath(tx *bolt.Tx, name []byte) (*bolt.Bucket, error) {
+	segments := splitBucketPath(name)
+
+	b, err := tx.CreateBucketIfNotExists(segments[0])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, segment := range segments[1:] {
+		if b, err = b.CreateBucketIfNotExists(segment); err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+// deleteBucketPath deletes the bucket, which may be nested, leaving any parent buckets in place.
+func deleteBucketPath(tx *bolt.Tx, name []byte) error {
+	segments := splitBucketPath(name)
+	if len(segments) == 1 {
+		return tx.DeleteBucket(name)
+	}
+
+	parent := bucketPath(tx, segments[:len(segments)-1])
+	if parent == nil {
+		return bolt.ErrBucketNotFound
+	}
+
+	return parent.DeleteBucket(segments[len(segments)-1])
+}
+
+// OpenBucketPath opens the database at the provided path and returns a Bucket for the nested bucket at bucketPath, creating it and any
+// parent buckets that do not exist. Every operation using the returned Bucket, including DeleteBucket, acts on the innermost bucket only.
+func OpenBucketPath(path string, bucketPath ...[]byte) (*Bucket, error) {
+	if len(bucketPath) == 0 {
+		return nil, ErrBucketNotFound{}
+	}
+
+	return OpenBucket(path, BucketPath(bucketPath...))
+}
+
+// Bucket returns a Bucket for the existing bucket at bucketPath, which is nested when more than one segment is provided. The bucket is not
+// created, and when it does not exist the returned ErrBucketNotFound names the first segment of the path that is missing.
+//
+// The returned Bucket shares the database, so closing it closes the Database.
+func (db *Database) Bucket(bucketPath ...[]byte) (*Bucket, error) {
+	name := BucketPath(bucketPath...)
+
+	if len(bucketPath) == 0 {
+		return nil, ErrBucketNotFound{}
+	}
+
+	if err := db.view(func(tx *bolt.Tx) error {
+		for i := range bucketPath {
+			if bucketPath[i] == nil || lookupBucket(tx, BucketPath(bucketPath[:i+1]...)) == nil {
+				return ErrBucketNotFound{bucket: name, missing: bucketPath[i]}
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return &Bucket{db: db, bucket: name}, nil
+}
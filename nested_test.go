@@ -0,0 +1,93 @@
+package ubolt
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenBucketPath(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	tenant, collection := []byte("tenant"), []byte("collection")
+
+	b, err := OpenBucketPath(testdb, tenant, collection)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.Nil(t, b.Put(testkey, testvalue), "Put")
+	assert.Nil(t, b.Put([]byte("other"), testvalue), "Put")
+	assert.Equal(t, testvalue, b.Get(testkey), "Get")
+	assert.Equal(t, []string{string(testkey), "other"}, b.GetKeysString(), "GetKeysString")
+
+	// the parent only contains the nested bucket and no keys
+	assert.Equal(t, [][]byte{tenant}, b.db.GetBuckets(), "GetBuckets")
+	assert.Equal(t, []string{string(collection)}, b.db.GetKeysString(tenant), "GetKeysString - parent")
+
+	var keys []string
+	assert.Nil(t, b.Scan([]byte("oth"), func(k, v []byte) error {
+		keys = append(keys, string(k))
+		return nil
+	}), "Scan")
+	assert.Equal(t, []string{"other"}, keys, "Scan")
+
+	// existing nested bucket
+	nb, err := b.db.Bucket(tenant, collection)
+	assert.Nil(t, err, "Bucket")
+	assert.Equal(t, testvalue, nb.Get(testkey), "Bucket - Get")
+
+	// missing segments are reported
+	_, err = b.db.Bucket(tenant, []byte("missing"), collection)
+	assert.ErrorIs(t, err, ErrBucketNotFound{}, "Bucket - missing")
+	assert.EqualError(t, err, "Bucket tenant/missing/collection not found as missing does not exist", "Bucket - missing")
+
+	_, err = b.db.GetE(BucketPath(tenant, []byte("missing")), testkey)
+	assert.EqualError(t, err, "Bucket tenant/missing not found", "GetE - missing")
+
+	// deleting the handle's bucket leaves the parent in place
+	assert.Nil(t, b.db.DeleteBucket(b.bucket), "DeleteBucket")
+	assert.ErrorIs(t, b.Ping(), ErrBucketNotFound{}, "Ping - deleted")
+	assert.Equal(t, [][]byte{tenant}, b.db.GetBuckets(), "GetBuckets - after delete")
+
+	assert.ErrorIs(t, b.db.DeleteBucket(BucketPath([]byte("__meta"), collection)), ErrReservedBucket{}, "DeleteBucket - reserved")
+}
+
+func TestInvalidBucketName(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	tests := []struct {
+		name   string
+		bucket []byte
+	}{
+		{"prefix only", []byte{0, '/'}},
+		{"single segment", append([]byte{0, '/', 3}, "abc"...)},
+		{"bad length", append([]byte{0, '/', 9}, "abc"...)},
+	}
+
+	for _, tt := range tests {
+		_, err := OpenBucket(testdb, tt.bucket)
+		assert.ErrorIs(t, err, ErrInvalidBucketName{}, "OpenBucket - "+tt.name)
+
+		_, _, err = OpenBuckets(testdb, [][]byte{testbucket, tt.bucket})
+		assert.ErrorIs(t, err, ErrInvalidBucketName{}, "OpenBuckets - "+tt.name)
+	}
+
+	db, err := Open(testdb)
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	for _, tt := range tests {
+		assert.ErrorIs(t, db.CreateBucket(tt.bucket), ErrInvalidBucketName{}, "CreateBucket - "+tt.name)
+	}
+
+	// paths returned by BucketPath are accepted
+	assert.Nil(t, db.CreateBucket(BucketPath([]byte("a"), []byte("b"))), "CreateBucket - path")
+	assert.Equal(t, [][]byte{[]byte("a")}, db.GetBuckets(), "GetBuckets")
+}
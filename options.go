@@ -0,0 +1,58 @@
+package ubolt
+
+import (
+	"os"
+	"time"
+)
+
+// Option is used to change the behaviour of a Database when it is opened via Open or OpenBucket.
+type Option func(*Database)
+
+// WithSequenceKeyEncoding sets the encoding used for keys generated by PutV and PutVID, which defaults to FixedSequenceKeys.
+func WithSequenceKeyEncoding(enc SequenceKeyEncoding) Option {
+	return func(db *Database) {
+		db.keyEncoding = enc
+	}
+}
+
+// WithTimeout sets how long Open waits to obtain the file lock on the database before returning ErrLockTimeout, which defaults to 5 seconds.
+// A timeout of zero waits indefinitely.
+func WithTimeout(timeout time.Duration) Option {
+	return func(db *Database) {
+		db.boltOptions.Timeout = timeout
+	}
+}
+
+// WithReadOnly opens the database in read-only mode, which allows multiple processes to open the database at once.
+// All mutating methods return ErrReadOnly and OpenBucket returns ErrBucketNotFound rather than creating a missing bucket.
+func WithReadOnly() Option {
+	return func(db *Database) {
+		db.boltOptions.ReadOnly = true
+	}
+}
+
+// WithStrictMode enables bolt's strict mode, which checks the consistency of the database after every commit and panics if any corruption
+// is found. This is intended for use in tests only, as the panic on corruption is not recoverable and every commit becomes considerably
+// slower as the database grows.
+func WithStrictMode() Option {
+	return func(db *Database) {
+		db.strictMode = true
+	}
+}
+
+// WithNoCreate makes Open return ErrDatabaseNotFound when the database file does not exist rather than creating a new empty database.
+// The check is made using the function provided by WithOpenFile when both options are used.
+func WithNoCreate() Option {
+	return func(db *Database) {
+		db.noCreate = true
+	}
+}
+
+// WithOpenFile sets the function used to open the database file in place of os.OpenFile, which may be used to wrap or mock the filesystem.
+// All other filesystem operations use the os package, so WithFS should be used to replace the filesystem entirely.
+func WithOpenFile(fn func(name string, flag int, perm os.FileMode) (*os.File, error)) Option {
+	return func(db *Database) {
+		db.fs = openFileFS{openFile: fn}
+		db.boltOptions.OpenFile = fn
+	}
+}
@@ -0,0 +1,91 @@
+package ubolt
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestNoCreate(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	// missing
+	_, err := Open(testdb, WithNoCreate())
+	assert.ErrorIs(t, err, ErrDatabaseNotFound{}, "Open - missing")
+	_, err = os.Stat(testdb)
+	assert.True(t, os.IsNotExist(err), "Open - file not created")
+
+	// present
+	db, err := Open(testdb)
+	if err != nil {
+		panic(err)
+	}
+	if err := db.Close(); err != nil {
+		panic(err)
+	}
+
+	db, err = Open(testdb, WithNoCreate())
+	assert.Nil(t, err, "Open - present")
+	assert.Nil(t, db.Close(), "Close")
+}
+
+func TestNoCreateOpenFile(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	// the existence check is made through the provided open function, which here maps a virtual name onto the test database
+	var opened []string
+	openFile := func(name string, flag int, perm os.FileMode) (*os.File, error) {
+		opened = append(opened, name)
+		if name == "virtual.db" {
+			name = testdb
+		}
+
+		return os.OpenFile(name, flag, perm)
+	}
+
+	_, err := Open("virtual.db", WithNoCreate(), WithOpenFile(openFile))
+	assert.ErrorIs(t, err, ErrDatabaseNotFound{}, "Open - missing")
+	assert.Equal(t, []string{"virtual.db"}, opened, "Open - check made via open function")
+
+	db, err := Open(testdb)
+	if err != nil {
+		panic(err)
+	}
+	if err := db.Close(); err != nil {
+		panic(err)
+	}
+
+	opened = nil
+	db, err = Open("virtual.db", WithNoCreate(), WithOpenFile(openFile))
+	assert.Nil(t, err, "Open - present")
+	assert.Equal(t, []string{"virtual.db", "virtual.db"}, opened, "Open - check then open via open function")
+	assert.Nil(t, db.Close(), "Close")
+}
+
+func TestLockTimeout(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	db, err := Open(testdb)
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	// the first handle holds the lock
+	_, err = Open(testdb, WithTimeout(200*time.Millisecond))
+	assert.ErrorIs(t, err, ErrLockTimeout{}, "Open - locked")
+	assert.ErrorIs(t, err, bolt.ErrTimeout, "Open - underlying error")
+
+	var lt ErrLockTimeout
+	if assert.ErrorAs(t, err, &lt, "Open - locked") {
+		assert.Equal(t, testdb, lt.Path, "ErrLockTimeout - path")
+		assert.GreaterOrEqual(t, lt.Waited, 100*time.Millisecond, "ErrLockTimeout - waited")
+		assert.Contains(t, err.Error(), testdb, "ErrLockTimeout - message")
+	}
+}
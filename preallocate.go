@@ -0,0 +1,102 @@
+package ubolt
+
+import (
+	"fmt"
+	"os"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrPreallocate is returned when the database file could not be grown by Preallocate.
+type ErrPreallocate struct {
+	path string
+	size int64
+	err  error
+}
+
+// Error returns the formatted configuration error.
+func (p ErrPreallocate) Error() string {
+	return fmt.Sprintf("Could not preallocate %s to %d bytes: %v", p.path, p.size, p.err)
+}
+
+// Is allows testing using errors.Is
+func (p ErrPreallocate) Is(target error) bool {
+	_, is := target.(ErrPreallocate)
+
+	return is
+}
+
+// Unwrap returns the underlying error returned by the filesystem.
+func (p ErrPreallocate) Unwrap() error {
+	return p.err
+}
+
+// WithInitialMmapSize sets the initial size in bytes of the memory map used for the database. Read transactions will not block write
+// transactions while the database is smaller than this size, and no remapping is required as the database grows up to this size.
+func WithInitialMmapSize(size int) Option {
+	return func(db *Database) {
+		db.boltOptions.InitialMmapSize = size
+	}
+}
+
+// Preallocate grows the database file to at least the requested number of bytes ahead of a bulk import, so the file and memory map do not
+// need to be repeatedly grown and remapped as data is written. Nothing is done if the file is already at least this size.
+//
+// ErrReadOnly is returned for a read-only database and ErrPreallocate if the filesystem refused to grow the file.
+func (db *Database) Preallocate(size int64) error {
+	return db.update(func(tx *bolt.Tx) error {
+		info, err := db.fs.Stat(db.Path())
+		if err != nil {
+			return ErrPreallocate{path: db.Path(), size: size, err: err}
+		}
+
+		if info.Size() >= size {
+			return nil
+		}
+
+		f, err := db.fs.OpenFile(db.Path(), os.O_RDWR, 0)
+		if err != nil {
+			return ErrPreallocate{path: db.Path(), size: size, err: err}
+		}
+		defer f.Close()
+
+		if err := f.Truncate(size); err != nil {
+			return ErrPreallocate{path: db.Path(), size: size, err: err}
+		}
+
+		if err := f.Sync(); err != nil {
+			return ErrPreallocate{path: db.Path(), size: size, err: err}
+		}
+
+		// bolt truncates the file to its high water mark plus AllocSize the next time it grows, so the allocation step is raised until
+		// the file has grown past the requested size to ensure the preallocated space is kept
+		if db.allocSize == 0 {
+			db.allocSize = db.bdb().AllocSize
+		}
+		if db.preallocFrom == 0 || info.Size() < db.preallocFrom {
+			db.preallocFrom = info.Size()
+		}
+		if step := int(size - tx.Size()); step > db.bdb().AllocSize {
+			db.bdb().AllocSize = step
+		}
+
+		return nil
+	})
+}
+
+// Preallocate grows the database file to at least the requested number of bytes. This is forwarded to the Database implementation.
+func (b *Bucket) Preallocate(size int64) error {
+	return b.db.Preallocate(size)
+}
+
+// checkPreallocate restores the allocation step changed by Preallocate once bolt has grown the file. This is known to have happened once
+// the high water mark of the database passes the file size prior to preallocation, as bolt never considers the file larger than that.
+// This must only be called while holding the writer lock.
+func (db *Database) checkPreallocate(tx *bolt.Tx) {
+	if db.preallocFrom == 0 || tx.Size() <= db.preallocFrom {
+		return
+	}
+
+	db.bdb().AllocSize = db.allocSize
+	db.preallocFrom = 0
+}
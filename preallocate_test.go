@@ -0,0 +1,101 @@
+package ubolt
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreallocate(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	const want = 64 << 20
+
+	assert.Nil(t, b.Preallocate(want), "Preallocate")
+
+	size, err := b.Size()
+	assert.Nil(t, err, "Size")
+	assert.GreaterOrEqual(t, size, int64(want), "Preallocate - size")
+
+	// writes must keep the preallocated space
+	for i := 0; i < 1000; i++ {
+		if err := b.Put([]byte(fmt.Sprintf("key%04d", i)), make([]byte, 1024)); err != nil {
+			panic(err)
+		}
+	}
+
+	size, err = b.Size()
+	assert.Nil(t, err, "Size")
+	assert.GreaterOrEqual(t, size, int64(want), "Preallocate - size after writes")
+	assert.Equal(t, []byte("key0999"), b.GetKeys()[999], "Preallocate - data")
+
+	// a smaller request does nothing
+	assert.Nil(t, b.Preallocate(1024), "Preallocate - smaller")
+
+	if err := b.Close(); err != nil {
+		panic(err)
+	}
+
+	// the data must survive reopening
+	b, err = OpenBucket(testdb, testbucket, WithReadOnly())
+	if err != nil {
+		panic(err)
+	}
+
+	assert.Equal(t, 1000, len(b.GetKeys()), "Preallocate - reopen")
+	assert.ErrorIs(t, b.Preallocate(want*2), ErrReadOnly{}, "Preallocate - read-only")
+}
+
+func benchmarkBulkLoad(b *testing.B, prealloc int64) {
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+		_ = os.Remove(testdb)
+
+		db, err := OpenBucket(testdb, testbucket)
+		if err != nil {
+			panic(err)
+		}
+		b.StartTimer()
+
+		if prealloc > 0 {
+			if err := db.Preallocate(prealloc); err != nil {
+				panic(err)
+			}
+		}
+
+		value := make([]byte, 4096)
+		for i := 0; i < 20; i++ {
+			m := make(map[string][]byte, 1000)
+			for j := 0; j < 1000; j++ {
+				m[fmt.Sprintf("key%02d%04d", i, j)] = value
+			}
+
+			if err := db.PutAll(m); err != nil {
+				panic(err)
+			}
+		}
+
+		b.StopTimer()
+		_ = db.Close()
+		b.StartTimer()
+	}
+
+	_ = os.Remove(testdb)
+}
+
+func BenchmarkBulkLoad(b *testing.B) {
+	benchmarkBulkLoad(b, 0)
+}
+
+func BenchmarkBulkLoadPreallocate(b *testing.B) {
+	benchmarkBulkLoad(b, 256<<20)
+}
@@ -0,0 +1,142 @@
+package ubolt
+
+import (
+	"bytes"
+	"sort"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// PrefixStat holds the number of keys and bytes stored under a single key prefix, as returned by PrefixStats.
+type PrefixStat struct {
+	// Prefix is the key prefix the figures relate to.
+	Prefix []byte
+	// KeyCount is the number of keys beginning with Prefix, excluding nested buckets.
+	KeyCount int
+	// KeyBytes is the total length in bytes of the keys beginning with Prefix.
+	KeyBytes int64
+	// ValueBytes is the total length in bytes of the values of the keys beginning with Prefix, as stored in the database.
+	ValueBytes int64
+}
+
+// Bytes returns the total number of key and value bytes stored under the prefix.
+func (ps PrefixStat) Bytes() int64 {
+	return ps.KeyBytes + ps.ValueBytes
+}
+
+// PrefixStats groups the keys in the chosen bucket by the part of the key before the first occurrence of delimiter and returns the number
+// of keys and bytes in each group, sorted by Bytes in descending order with ties ordered by prefix. Keys that do not contain delimiter
+// form a group of their own. The bucket is walked once inside a single read-only transaction and nested buckets are skipped.
+//
+// Value sizes are those stored in the database, so include any encoding header, and exclude the page overhead reported by Overview.
+func (db *Database) PrefixStats(bucket []byte, delimiter byte) ([]PrefixStat, error) {
+	groups := make(map[string]*PrefixStat)
+
+	if err := db.view(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		return b.ForEach(func(k, v []byte) error {
+			// skip nested buckets
+			if v == nil {
+				return nil
+			}
+
+			prefix := k
+			if i := bytes.IndexByte(k, delimiter); i >= 0 {
+				prefix = k[:i]
+			}
+
+			ps, ok := groups[string(prefix)]
+			if !ok {
+				ps = &PrefixStat{Prefix: append([]byte{}, prefix...)}
+				groups[string(prefix)] = ps
+			}
+
+			ps.add(k, v)
+
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	stats := make([]PrefixStat, 0, len(groups))
+	for _, ps := range groups {
+		stats = append(stats, *ps)
+	}
+
+	sortPrefixStats(stats)
+
+	return stats, nil
+}
+
+// PrefixStats groups the keys in the bucket by the part of the key before the first occurrence of delimiter and returns the number of keys
+// and bytes in each group.
+func (b *Bucket) PrefixStats(delimiter byte) ([]PrefixStat, error) {
+	return b.db.PrefixStats(b.bucket, delimiter)
+}
+
+// PrefixStatsFor returns the number of keys and bytes in the chosen bucket beginning with each of the provided prefixes, sorted as per
+// PrefixStats. Every prefix is measured inside a single read-only transaction by seeking to the prefix and visiting only the keys that
+// begin with it. A key matching more than one overlapping prefix is counted under each of them, and prefixes matching no keys are
+// included with zero counts.
+func (db *Database) PrefixStatsFor(bucket []byte, prefixes [][]byte) ([]PrefixStat, error) {
+	stats := make([]PrefixStat, len(prefixes))
+
+	if err := db.view(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		for i, prefix := range prefixes {
+			prefix = db.canonicalKey(prefix)
+			stats[i].Prefix = append([]byte{}, prefix...)
+
+			end := PrefixSuccessor(prefix)
+
+			c := b.Cursor()
+			for k, v := c.Seek(prefix); k != nil && (end == nil || bytes.Compare(k, end) < 0); k, v = c.Next() {
+				// skip nested buckets
+				if v == nil {
+					continue
+				}
+
+				stats[i].add(k, v)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	sortPrefixStats(stats)
+
+	return stats, nil
+}
+
+// PrefixStatsFor returns the number of keys and bytes in the bucket beginning with each of the provided prefixes.
+func (b *Bucket) PrefixStatsFor(prefixes [][]byte) ([]PrefixStat, error) {
+	return b.db.PrefixStatsFor(b.bucket, prefixes)
+}
+
+func (ps *PrefixStat) add(k, v []byte) {
+	ps.KeyCount++
+	ps.KeyBytes += int64(len(k))
+	ps.ValueBytes += int64(len(v))
+}
+
+// sortPrefixStats orders stats by total bytes in descending order then by prefix.
+func sortPrefixStats(stats []PrefixStat) {
+	sort.Slice(stats, func(i, j int) bool {
+		if bi, bj := stats[i].Bytes(), stats[j].Bytes(); bi != bj {
+			return bi > bj
+		}
+
+		return bytes.Compare(stats[i].Prefix, stats[j].Prefix) < 0
+	})
+}
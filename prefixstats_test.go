@@ -0,0 +1,65 @@
+package ubolt
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixStats(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	for k, v := range map[string]string{
+		"acme/1":   "aaaaaaaaaa",
+		"acme/2":   "aaaaaaaaaa",
+		"globex/1": "g",
+		"initech":  "iiiiiiiiiiiiiiiiiiii",
+		"zeta/1":   "z",
+	} {
+		if err := b.Put([]byte(k), []byte(v)); err != nil {
+			panic(err)
+		}
+	}
+
+	if err := b.db.CreateBucket(BucketPath(testbucket, []byte("acme/nested"))); err != nil {
+		panic(err)
+	}
+
+	stats, err := b.PrefixStats('/')
+	assert.Nil(t, err, "PrefixStats")
+	assert.Equal(t, []PrefixStat{
+		{Prefix: []byte("acme"), KeyCount: 2, KeyBytes: 12, ValueBytes: 20},
+		{Prefix: []byte("initech"), KeyCount: 1, KeyBytes: 7, ValueBytes: 20},
+		{Prefix: []byte("globex"), KeyCount: 1, KeyBytes: 8, ValueBytes: 1},
+		{Prefix: []byte("zeta"), KeyCount: 1, KeyBytes: 6, ValueBytes: 1},
+	}, stats, "PrefixStats")
+
+	stats, err = b.PrefixStatsFor([][]byte{[]byte("zeta/"), []byte("a"), []byte("acme/1"), []byte("missing")})
+	assert.Nil(t, err, "PrefixStatsFor")
+	assert.Equal(t, []PrefixStat{
+		{Prefix: []byte("a"), KeyCount: 2, KeyBytes: 12, ValueBytes: 20},
+		{Prefix: []byte("acme/1"), KeyCount: 1, KeyBytes: 6, ValueBytes: 10},
+		{Prefix: []byte("zeta/"), KeyCount: 1, KeyBytes: 6, ValueBytes: 1},
+		{Prefix: []byte("missing")},
+	}, stats, "PrefixStatsFor")
+
+	// a prefix of 0xff bytes has no successor so runs to the last key
+	if err := b.Put([]byte("\xff\xff1"), []byte("f")); err != nil {
+		panic(err)
+	}
+
+	stats, err = b.PrefixStatsFor([][]byte{[]byte("\xff")})
+	assert.Nil(t, err, "PrefixStatsFor - no successor")
+	assert.Equal(t, []PrefixStat{{Prefix: []byte("\xff"), KeyCount: 1, KeyBytes: 3, ValueBytes: 1}}, stats, "PrefixStatsFor - no successor")
+
+	_, err = b.db.PrefixStats([]byte("missing"), '/')
+	assert.ErrorIs(t, err, ErrBucketNotFound{}, "PrefixStats - missing bucket")
+}
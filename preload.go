@@ -0,0 +1,257 @@
+package ubolt
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// DefaultPreloadMaxBytes is the largest total size of keys and values that WithPreload loads when the database is opened, unless changed
+// using WithPreloadMaxBytes.
+const DefaultPreloadMaxBytes = 64 << 20
+
+// ErrPreloadTooLarge is returned by Open when a bucket chosen using WithPreload holds more than the maximum number of bytes allowed.
+type ErrPreloadTooLarge struct {
+	bucket []byte
+	limit  int64
+}
+
+// Error returns the formatted configuration error.
+func (pl ErrPreloadTooLarge) Error() string {
+	return fmt.Sprintf("Bucket %s is too large to preload as it holds more than %d bytes", bucketName(pl.bucket), pl.limit)
+}
+
+// Is allows testing using errors.Is
+func (pl ErrPreloadTooLarge) Is(target error) bool {
+	_, is := target.(ErrPreloadTooLarge)
+
+	return is
+}
+
+// WithPreload copies every key and value of the chosen bucket into memory when the database is opened, so GetE, Get, GetOK and Exists are
+// served for that bucket without starting a transaction. The copy is kept up to date by every write to the bucket as it is committed.
+// Nested buckets are not copied, and as expiry requires a transaction buckets with WithTTL enabled are read from the database as usual.
+//
+// Open returns ErrPreloadTooLarge if the keys and values of the bucket total more than DefaultPreloadMaxBytes, or the limit set using
+// WithPreloadMaxBytes, however the limit is not enforced as the bucket grows afterwards. Open returns ErrReservedBucket for a reserved bucket.
+// This option may be provided more than once to preload multiple buckets.
+func WithPreload(bucket []byte) Option {
+	return func(db *Database) {
+		if db.preloads == nil {
+			db.preloads = make(map[string]*preloadCache)
+		}
+
+		db.preloads[string(bucket)] = &preloadCache{bucket: bucket}
+	}
+}
+
+// WithPreloadMaxBytes sets the largest total size of keys and values that each bucket chosen using WithPreload may hold when the database
+// is opened, which defaults to DefaultPreloadMaxBytes.
+func WithPreloadMaxBytes(n int64) Option {
+	return func(db *Database) {
+		db.preloadMaxBytes = n
+	}
+}
+
+// PreloadStat reports the contents held in memory for a bucket chosen using WithPreload.
+type PreloadStat struct {
+	// Bucket is the name of the bucket.
+	Bucket string
+	// Entries is the number of keys held.
+	Entries int
+	// Bytes is the total size of the keys and values held.
+	Bytes int64
+}
+
+// PreloadStats returns the number of entries and bytes held in memory for each bucket chosen using WithPreload, ordered by bucket name.
+func (db *Database) PreloadStats() []PreloadStat {
+	stats := make([]PreloadStat, 0, len(db.preloads))
+
+	for name, c := range db.preloads {
+		c.mu.RLock()
+		stats = append(stats, PreloadStat{Bucket: name, Entries: len(c.entries), Bytes: c.bytes})
+		c.mu.RUnlock()
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Bucket < stats[j].Bucket
+	})
+
+	return stats
+}
+
+// PreloadStats returns the number of entries and bytes held in memory for each bucket chosen using WithPreload. This is forwarded to the
+// Database implementation.
+func (b *Bucket) PreloadStats() []PreloadStat {
+	return b.db.PreloadStats()
+}
+
+// preloadCache holds the entries of a bucket chosen using WithPreload.
+type preloadCache struct {
+	bucket []byte
+
+	mu sync.RWMutex
+	// entries maps keys to their stored values, before any WithValueMiddleware is unwrapped
+	entries map[string][]byte
+	bytes   int64
+	// exists is true once the bucket is known to exist. Entries are complete even when this is false, as a bucket that was missing or
+	// deleted is empty once created.
+	exists bool
+	// version is incremented by every change so a lookup of whether the bucket exists may detect a concurrent change
+	version uint64
+}
+
+// preloadOp is a committed change to a preloaded bucket.
+type preloadOp struct {
+	c     *preloadCache
+	op    Op
+	key   []byte
+	value []byte
+}
+
+// loadPreloads fills every preloaded bucket from the database when it is opened.
+func (db *Database) loadPreloads() error {
+	limit := db.preloadMaxBytes
+	if limit <= 0 {
+		limit = DefaultPreloadMaxBytes
+	}
+
+	return db.view(func(tx *bolt.Tx) error {
+		for _, c := range db.preloads {
+			if isReserved(c.bucket) {
+				return ErrReservedBucket{c.bucket}
+			}
+
+			c.entries, c.bytes, c.exists = make(map[string][]byte), 0, false
+
+			b := lookupBucket(tx, c.bucket)
+			if b == nil {
+				continue
+			}
+
+			c.exists = true
+
+			if err := b.ForEach(func(k, v []byte) error {
+				// skip nested buckets
+				if v == nil {
+					return nil
+				}
+
+				if c.bytes += int64(len(k) + len(v)); c.bytes > limit {
+					return ErrPreloadTooLarge{bucket: c.bucket, limit: limit}
+				}
+
+				c.entries[string(k)] = append([]byte{}, v...)
+
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// preloadCache returns the cache that serves reads of the bucket, or nil if the bucket is read from the database.
+func (db *Database) preloadCache(bucket []byte) *preloadCache {
+	c, ok := db.preloads[string(bucket)]
+	if !ok {
+		return nil
+	}
+
+	if _, ok := db.ttls[string(bucket)]; ok {
+		return nil
+	}
+
+	return c
+}
+
+// preloadGet returns the stored value of the key from a preloaded bucket. The value ok is false when the bucket is not preloaded or the
+// database must be read to tell whether a missing key's bucket exists.
+func (db *Database) preloadGet(bucket, key []byte) (data []byte, found, ok bool) {
+	c := db.preloadCache(bucket)
+	if c == nil {
+		return nil, false, false
+	}
+
+	c.mu.RLock()
+	data, found = c.entries[string(key)]
+	exists, version := c.exists, c.version
+	c.mu.RUnlock()
+
+	if found || exists {
+		return data, found, true
+	}
+
+	var missing bool
+
+	_ = db.view(func(tx *bolt.Tx) error {
+		missing = lookupBucket(tx, bucket) == nil
+
+		return nil
+	})
+
+	c.mu.Lock()
+	if !missing && c.version == version {
+		c.exists = true
+	}
+	c.mu.Unlock()
+
+	if missing {
+		return nil, false, false
+	}
+
+	return nil, false, true
+}
+
+// recordPreload queues a mutation of a preloaded bucket to be applied once the transaction commits.
+func (db *Database) recordPreload(m mutation) {
+	c, ok := db.preloads[string(m.bucket)]
+	if !ok {
+		return
+	}
+
+	op := preloadOp{c: c, op: m.op}
+	if m.op != OpDeleteBucket {
+		op.key = append([]byte{}, m.key...)
+	}
+
+	if m.op != OpDelete && m.op != OpDeleteBucket {
+		op.value = append([]byte{}, m.value...)
+	}
+
+	db.preloadPending = append(db.preloadPending, op)
+}
+
+// applyPreload applies the mutations of a committed transaction to the preloaded buckets.
+func applyPreload(pending []preloadOp) {
+	for _, p := range pending {
+		c := p.c
+
+		c.mu.Lock()
+
+		switch p.op {
+		case OpDeleteBucket:
+			c.entries, c.bytes, c.exists = make(map[string][]byte), 0, false
+		case OpDelete:
+			if v, ok := c.entries[string(p.key)]; ok {
+				c.bytes -= int64(len(p.key) + len(v))
+				delete(c.entries, string(p.key))
+			}
+		default:
+			if v, ok := c.entries[string(p.key)]; ok {
+				c.bytes -= int64(len(p.key) + len(v))
+			}
+
+			c.entries[string(p.key)] = p.value
+			c.bytes += int64(len(p.key) + len(p.value))
+			c.exists = true
+		}
+
+		c.version++
+		c.mu.Unlock()
+	}
+}
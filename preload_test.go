@@ -0,0 +1,152 @@
+package ubolt
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestPreload(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	config := []byte("config")
+
+	db, err := Open(testdb)
+	if err != nil {
+		panic(err)
+	}
+	assert.Nil(t, db.CreateBucket(config), "CreateBucket")
+	assert.Nil(t, db.Put(config, []byte("mode"), []byte("fast")), "Put")
+	assert.Nil(t, db.Put(config, []byte("empty"), []byte{}), "Put")
+	assert.Nil(t, db.CreateBucket(BucketPath(config, []byte("nested"))), "CreateBucket - nested")
+	assert.Nil(t, db.Close(), "Close")
+
+	db, err = Open(testdb, WithPreload(config), WithPreload([]byte("later")))
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	assert.Equal(t, []PreloadStat{
+		{Bucket: "config", Entries: 2, Bytes: int64(len("mode") + len("fast") + len("empty"))},
+		{Bucket: "later"},
+	}, db.PreloadStats(), "PreloadStats")
+
+	// reads are served from memory, which is shown by changing the file behind the cache's back
+	assert.Nil(t, db.bdb().Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(config).Put([]byte("mode"), []byte("changed"))
+	}), "Update")
+
+	assert.Equal(t, []byte("fast"), db.Get(config, []byte("mode")), "Get")
+
+	value, ok := db.GetOK(config, []byte("empty"))
+	assert.True(t, ok, "GetOK - empty value")
+	assert.Equal(t, []byte{}, value, "GetOK - empty value")
+
+	assert.True(t, db.Exists(config, []byte("mode")), "Exists")
+	assert.False(t, db.Exists(config, []byte("missing")), "Exists - missing")
+
+	_, err = db.GetE(config, []byte("missing"))
+	assert.ErrorIs(t, err, ErrKeyNotFound{}, "GetE - missing")
+
+	// writes keep the cache coherent
+	assert.Nil(t, db.Put(config, []byte("mode"), []byte("safe")), "Put")
+	assert.Nil(t, db.Put(config, []byte("extra"), []byte("x")), "Put")
+	assert.Nil(t, db.Delete(config, []byte("empty")), "Delete")
+
+	assert.Equal(t, []byte("safe"), db.Get(config, []byte("mode")), "Get after Put")
+	assert.True(t, db.Exists(config, []byte("extra")), "Exists after Put")
+	assert.False(t, db.Exists(config, []byte("empty")), "Exists after Delete")
+	assert.Equal(t, int64(len("mode")+len("safe")+len("extra")+len("x")), db.PreloadStats()[0].Bytes, "PreloadStats after writes")
+
+	// a failed transaction leaves the cache unchanged
+	assert.ErrorIs(t, db.update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(config).Put([]byte("mode"), []byte("rolled back")); err != nil {
+			return err
+		}
+
+		if err := db.onMutation(tx, mutation{op: OpPut, bucket: config, key: []byte("mode"), value: []byte("rolled back")}); err != nil {
+			return err
+		}
+
+		return assert.AnError
+	}), assert.AnError, "update - rollback")
+	assert.Equal(t, []byte("safe"), db.Get(config, []byte("mode")), "Get after rollback")
+
+	// a missing bucket is reported as missing until it is created
+	later := []byte("later")
+
+	_, err = db.GetE(later, testkey)
+	assert.ErrorIs(t, err, ErrBucketNotFound{}, "GetE - missing bucket")
+	assert.Nil(t, db.CreateBucket(later), "CreateBucket")
+
+	_, err = db.GetE(later, testkey)
+	assert.ErrorIs(t, err, ErrKeyNotFound{}, "GetE - created bucket")
+	assert.Nil(t, db.Put(later, testkey, testvalue), "Put")
+	assert.Equal(t, testvalue, db.Get(later, testkey), "Get - created bucket")
+
+	// deleting the bucket empties the cache
+	assert.Nil(t, db.DeleteBucket(later), "DeleteBucket")
+	_, err = db.GetE(later, testkey)
+	assert.ErrorIs(t, err, ErrBucketNotFound{}, "GetE - deleted bucket")
+	assert.Equal(t, 0, db.PreloadStats()[1].Entries, "PreloadStats - deleted bucket")
+	assert.Nil(t, db.Close(), "Close")
+
+	// a bucket over the limit prevents the database from opening
+	_, err = Open(testdb, WithPreload(config), WithPreloadMaxBytes(8))
+	assert.ErrorIs(t, err, ErrPreloadTooLarge{}, "Open - too large")
+
+	_, err = Open(testdb, WithPreload(metaBucket))
+	assert.ErrorIs(t, err, ErrReservedBucket{}, "Open - reserved")
+
+	// the file is unlocked after a failed open
+	db, err = Open(testdb, WithPreload(config))
+	assert.Nil(t, err, "Open")
+	assert.Nil(t, db.Close(), "Close")
+}
+
+func TestPreloadImportClone(t *testing.T) {
+	_ = os.Remove(testdb)
+	_ = os.Remove(testbackup)
+	defer os.Remove(testdb)
+	defer os.Remove(testbackup)
+
+	config, copied := []byte("config"), []byte("copied")
+
+	src, err := Open(testbackup)
+	if err != nil {
+		panic(err)
+	}
+	defer src.Close()
+
+	assert.Nil(t, src.CreateBucket(config), "CreateBucket")
+	assert.Nil(t, src.Put(config, []byte("mode"), []byte("imported")), "Put")
+
+	var archive bytes.Buffer
+	assert.Nil(t, src.ExportArchive(&archive), "ExportArchive")
+
+	db, err := Open(testdb, WithPreload(config), WithPreload(copied))
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	assert.Nil(t, db.CreateBucket(config), "CreateBucket")
+	assert.Nil(t, db.Put(config, []byte("mode"), []byte("stale")), "Put")
+
+	// imported keys replace the cached values
+	assert.Nil(t, db.ImportArchive(&archive), "ImportArchive")
+	assert.Equal(t, []byte("imported"), db.Get(config, []byte("mode")), "Get - imported")
+
+	// cloned keys are cached, and an overwritten destination drops the keys it held
+	assert.Nil(t, db.CreateBucket(copied), "CreateBucket")
+	assert.Nil(t, db.Put(copied, []byte("old"), []byte("value")), "Put")
+
+	assert.Nil(t, db.CloneBucketWithOptions(config, copied, CloneOptions{Overwrite: true}), "CloneBucket - overwrite")
+	assert.Equal(t, []byte("imported"), db.Get(copied, []byte("mode")), "Get - cloned")
+	assert.False(t, db.Exists(copied, []byte("old")), "Exists - overwritten")
+}
@@ -0,0 +1,69 @@
+package ubolt
+
+import (
+	"time"
+)
+
+// ProgressFunc is called by long-running operations to report that done of total items have been processed. A total of -1 means the total
+// is not known without an additional pass over the data.
+//
+// A ProgressFunc is called from the goroutine running the operation, at most once every ProgressEvery items or once per ProgressInterval,
+// whichever comes first, and once more when the operation completes successfully so the final count is always reported. It is never called
+// after the operation has returned. Any database operation made from within a ProgressFunc may deadlock, as a transaction may be open.
+type ProgressFunc func(done, total int64)
+
+const (
+	// ProgressEvery is the number of items processed between calls to a ProgressFunc.
+	ProgressEvery = 1000
+
+	// ProgressInterval is the maximum time between calls to a ProgressFunc while items are being processed.
+	ProgressInterval = time.Second
+)
+
+// progressReporter calls a ProgressFunc at a bounded frequency. A nil progressReporter discards all updates, so operations may use it
+// unconditionally.
+type progressReporter struct {
+	fn       ProgressFunc
+	total    int64
+	done     int64
+	reported int64
+	sent     bool
+	last     time.Time
+}
+
+// newProgress returns a progressReporter that reports to fn, or nil if fn is nil.
+func newProgress(fn ProgressFunc, total int64) *progressReporter {
+	if fn == nil {
+		return nil
+	}
+
+	return &progressReporter{fn: fn, total: total, last: time.Now()}
+}
+
+// add records n more items as done, calling the ProgressFunc if one is due.
+func (p *progressReporter) add(n int64) {
+	if p == nil {
+		return
+	}
+
+	p.done += n
+
+	if p.done-p.reported >= ProgressEvery || time.Since(p.last) >= ProgressInterval {
+		p.report()
+	}
+}
+
+// finish calls the ProgressFunc with the final count unless it was already reported.
+func (p *progressReporter) finish() {
+	if p == nil || (p.sent && p.reported == p.done) {
+		return
+	}
+
+	p.report()
+}
+
+func (p *progressReporter) report() {
+	p.fn(p.done, p.total)
+
+	p.reported, p.sent, p.last = p.done, true, time.Now()
+}
@@ -0,0 +1,146 @@
+package ubolt
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressReporter(t *testing.T) {
+	var calls [][2]int64
+	p := newProgress(func(done, total int64) {
+		calls = append(calls, [2]int64{done, total})
+	}, 2500)
+
+	for i := 0; i < 2500; i++ {
+		p.add(1)
+	}
+	p.finish()
+	p.finish()
+
+	assert.Equal(t, [][2]int64{{1000, 2500}, {2000, 2500}, {2500, 2500}}, calls, "progress - every")
+
+	// a slow operation is reported once the interval has passed
+	calls = nil
+	p = newProgress(func(done, total int64) {
+		calls = append(calls, [2]int64{done, total})
+	}, -1)
+	p.last = time.Now().Add(-ProgressInterval)
+	p.add(1)
+	p.finish()
+
+	assert.Equal(t, [][2]int64{{1, -1}}, calls, "progress - interval")
+
+	// the final count is reported even when nothing was processed
+	calls = nil
+	p = newProgress(func(done, total int64) {
+		calls = append(calls, [2]int64{done, total})
+	}, 0)
+	p.finish()
+
+	assert.Equal(t, [][2]int64{{0, 0}}, calls, "progress - empty")
+
+	// a nil reporter discards updates
+	p = newProgress(nil, 0)
+	p.add(1)
+	p.finish()
+}
+
+func TestProgressOperations(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	const count = 2500
+
+	bucket := []byte("progress")
+
+	db, err := Open(testdb, WithSuffixIndex(bucket), WithBloomFilter(bucket, count, 0.01))
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	m := make(map[string][]byte, count)
+	for i := 0; i < count; i++ {
+		m[fmt.Sprintf("key%04d", i)] = testvalue
+	}
+
+	idx := NewIndex(db, bucket, []byte("progress-index"), func(key, value []byte) []byte {
+		return value
+	})
+
+	var archive bytes.Buffer
+
+	tests := []struct {
+		name  string
+		total int64
+		run   func(progress ProgressFunc) error
+	}{
+		{"PutAll", count, func(progress ProgressFunc) error {
+			return db.PutAllWithOptions(bucket, m, PutAllOptions{CreateBucket: true, ChunkSize: 300, Progress: progress})
+		}},
+		{"ForEachAll", -1, func(progress ProgressFunc) error {
+			return db.ForEachAllWithProgress(func(bucket, k, v []byte) error {
+				return nil
+			}, progress)
+		}},
+		{"RebuildSuffixIndex", -1, func(progress ProgressFunc) error {
+			return db.RebuildSuffixIndexWithProgress(bucket, progress)
+		}},
+		{"RebuildBloomFilter", -1, func(progress ProgressFunc) error {
+			return db.RebuildBloomFilterWithProgress(bucket, progress)
+		}},
+		{"Index.Rebuild", -1, func(progress ProgressFunc) error {
+			_, err := idx.RebuildWithProgress(progress)
+			return err
+		}},
+		{"CloneBucket", count, func(progress ProgressFunc) error {
+			return db.CloneBucketWithOptions(bucket, []byte("progress-clone"), CloneOptions{ChunkSize: 700, Progress: progress})
+		}},
+		{"DeleteBucketChunked", -1, func(progress ProgressFunc) error {
+			return db.DeleteBucketChunkedWithOptions([]byte("progress-clone"), DeleteRangeOptions{ChunkSize: 700, Progress: progress})
+		}},
+		{"ImportArchive", -1, func(progress ProgressFunc) error {
+			if err := db.ExportArchive(&archive); err != nil {
+				return err
+			}
+
+			return db.ImportArchive(&archive, WithImportProgress(progress))
+		}},
+		{"CompactInPlace", -1, func(progress ProgressFunc) error {
+			_, _, err := db.CompactInPlaceWithProgress(progress)
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		var calls [][2]int64
+		returned := false
+
+		err := tt.run(func(done, total int64) {
+			assert.False(t, returned, "%s - called after return", tt.name)
+
+			calls = append(calls, [2]int64{done, total})
+		})
+		returned = true
+
+		assert.Nil(t, err, tt.name)
+
+		if assert.NotEmpty(t, calls, tt.name) {
+			assert.GreaterOrEqual(t, calls[len(calls)-1][0], int64(count), "%s - final count", tt.name)
+			assert.LessOrEqual(t, len(calls), int(calls[len(calls)-1][0])/ProgressEvery+2, "%s - bounded frequency", tt.name)
+		}
+
+		for i, c := range calls {
+			assert.Equal(t, tt.total, c[1], "%s - total", tt.name)
+
+			if i > 0 {
+				assert.Greater(t, c[0], calls[i-1][0], "%s - increasing", tt.name)
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package ubolt
+
+import (
+	"bytes"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// PurgeOptions controls the behaviour of PurgeBeforeWithOptions.
+type PurgeOptions struct {
+	// ChunkSize is the maximum number of keys removed in each read/write transaction, which defaults to 1000 when zero or less.
+	ChunkSize int
+
+	// DryRun counts the keys that would be removed, inside a single read-only transaction, without removing them.
+	DryRun bool
+
+	// Progress, if set, is called as keys are removed or counted. The total is reported as -1.
+	Progress ProgressFunc
+}
+
+// defaultPurgeChunkSize is used when PurgeOptions.ChunkSize is not set.
+const defaultPurgeChunkSize = 1000
+
+// PurgeBefore removes every key in the chosen bucket that sorts strictly before cutoff, returning the number of keys removed. This is
+// intended for retention of buckets whose keys begin with a sortable timestamp, such as those created using TimeKey. Keys are removed in
+// chunks of 1000 per read/write transaction so the writer lock is released between chunks, which means the operation is not atomic however
+// calling it again after an interruption removes the keys that remain. Nested buckets are not removed.
+func (db *Database) PurgeBefore(bucket, cutoff []byte) (int, error) {
+	return db.PurgeBeforeWithOptions(bucket, cutoff, PurgeOptions{})
+}
+
+// PurgeBefore removes every key that sorts strictly before cutoff, returning the number of keys removed.
+func (b *Bucket) PurgeBefore(cutoff []byte) (int, error) {
+	return b.db.PurgeBefore(b.bucket, cutoff)
+}
+
+// PurgeBeforeTime removes every key in the chosen bucket that sorts strictly before the TimeKey of cutoff, returning the number of keys
+// removed, as per PurgeBefore.
+func (db *Database) PurgeBeforeTime(bucket []byte, cutoff time.Time) (int, error) {
+	return db.PurgeBefore(bucket, TimeKey(cutoff))
+}
+
+// PurgeBeforeTime removes every key that sorts strictly before the TimeKey of cutoff, returning the number of keys removed.
+func (b *Bucket) PurgeBeforeTime(cutoff time.Time) (int, error) {
+	return b.db.PurgeBeforeTime(b.bucket, cutoff)
+}
+
+// PurgeBeforeWithOptions performs the same process as PurgeBefore with the behaviour controlled by the provided PurgeOptions. The number of
+// keys removed includes those in chunks committed before any error. When DryRun is set the number of keys that would be removed is returned
+// and the bucket is left unchanged.
+func (db *Database) PurgeBeforeWithOptions(bucket, cutoff []byte, opts PurgeOptions) (int, error) {
+	cutoff = db.canonicalKey(cutoff)
+
+	// no key sorts before an empty cutoff, whereas a nil end would select every key
+	if len(cutoff) == 0 {
+		return 0, db.view(func(tx *bolt.Tx) error {
+			if lookupBucket(tx, bucket) == nil {
+				return ErrBucketNotFound{bucket: bucket}
+			}
+
+			return nil
+		})
+	}
+
+	if opts.DryRun {
+		return db.countBefore(bucket, cutoff, opts.Progress)
+	}
+
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = defaultPurgeChunkSize
+	}
+
+	return db.deleteRange(bucket, nil, cutoff, DeleteRangeOptions{ChunkSize: opts.ChunkSize, Progress: opts.Progress})
+}
+
+// PurgeBeforeWithOptions performs the same process as PurgeBefore with the behaviour controlled by the provided PurgeOptions.
+func (b *Bucket) PurgeBeforeWithOptions(cutoff []byte, opts PurgeOptions) (int, error) {
+	return b.db.PurgeBeforeWithOptions(b.bucket, cutoff, opts)
+}
+
+// PurgeBeforeTimeWithOptions performs the same process as PurgeBeforeTime with the behaviour controlled by the provided PurgeOptions.
+func (db *Database) PurgeBeforeTimeWithOptions(bucket []byte, cutoff time.Time, opts PurgeOptions) (int, error) {
+	return db.PurgeBeforeWithOptions(bucket, TimeKey(cutoff), opts)
+}
+
+// PurgeBeforeTimeWithOptions performs the same process as PurgeBeforeTime with the behaviour controlled by the provided PurgeOptions.
+func (b *Bucket) PurgeBeforeTimeWithOptions(cutoff time.Time, opts PurgeOptions) (int, error) {
+	return b.db.PurgeBeforeTimeWithOptions(b.bucket, cutoff, opts)
+}
+
+// countBefore returns the number of keys, excluding nested buckets, that sort strictly before the already canonical cutoff.
+func (db *Database) countBefore(bucket, cutoff []byte, fn ProgressFunc) (int, error) {
+	var n int
+
+	p := newProgress(fn, -1)
+
+	if err := db.view(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		c := b.Cursor()
+		for k, v := c.First(); k != nil && bytes.Compare(k, cutoff) < 0; k, v = c.Next() {
+			// skip nested buckets
+			if v == nil {
+				continue
+			}
+
+			n++
+			p.add(1)
+		}
+
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	p.finish()
+
+	return n, nil
+}
@@ -0,0 +1,96 @@
+package ubolt
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeKey(t *testing.T) {
+	times := []time.Time{
+		time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Unix(-1, 0),
+		time.Unix(0, 0),
+		time.Unix(0, 1),
+		time.Date(2024, 6, 1, 12, 0, 0, 500, time.FixedZone("AEST", 10*60*60)),
+		time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	for i, tm := range times {
+		key := TimeKey(tm)
+		assert.Len(t, key, 8, "TimeKey - length")
+
+		got, err := KeyTime(append(key, []byte("suffix")...))
+		assert.Nil(t, err, "KeyTime")
+		assert.True(t, tm.Equal(got), "KeyTime - round trip")
+		assert.Equal(t, time.UTC, got.Location(), "KeyTime - location")
+
+		if i > 0 {
+			assert.Less(t, string(TimeKey(times[i-1])), string(key), "TimeKey - ordering")
+		}
+	}
+
+	_, err := KeyTime([]byte("short"))
+	assert.ErrorIs(t, err, ErrInvalidKey{}, "KeyTime - short key")
+}
+
+func TestPurgeBefore(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	now := time.Now()
+	for i := 0; i < 50; i++ {
+		key := append(TimeKey(now.Add(-time.Duration(i)*time.Hour)), 'x')
+		if err := b.Put(key, testvalue); err != nil {
+			panic(err)
+		}
+	}
+
+	if err := b.db.CreateBucket(BucketPath(testbucket, []byte("nested"))); err != nil {
+		panic(err)
+	}
+
+	cutoff := now.Add(-30*time.Hour + time.Minute)
+
+	var reported int64
+	n, err := b.PurgeBeforeTimeWithOptions(cutoff, PurgeOptions{DryRun: true, Progress: func(done, total int64) { reported = done }})
+	assert.Nil(t, err, "PurgeBeforeTime - dry run")
+	assert.Equal(t, 20, n, "PurgeBeforeTime - dry run count")
+	assert.Equal(t, int64(20), reported, "PurgeBeforeTime - dry run progress")
+	assert.Len(t, b.GetKeys(), 51, "PurgeBeforeTime - dry run unchanged")
+
+	n, err = b.PurgeBeforeTimeWithOptions(cutoff, PurgeOptions{ChunkSize: 3})
+	assert.Nil(t, err, "PurgeBeforeTime")
+	assert.Equal(t, 20, n, "PurgeBeforeTime - count")
+	assert.Len(t, b.GetKeys(), 31, "PurgeBeforeTime - remaining")
+
+	for _, k := range b.GetKeys() {
+		if string(k) == "nested" {
+			continue
+		}
+
+		ts, err := KeyTime(k)
+		assert.Nil(t, err, "KeyTime")
+		assert.False(t, ts.Before(cutoff), "PurgeBeforeTime - remaining key is not before cutoff")
+	}
+
+	n, err = b.PurgeBeforeTime(cutoff)
+	assert.Nil(t, err, "PurgeBeforeTime - repeat")
+	assert.Equal(t, 0, n, "PurgeBeforeTime - repeat count")
+
+	n, err = b.PurgeBefore(nil)
+	assert.Nil(t, err, "PurgeBefore - empty cutoff")
+	assert.Equal(t, 0, n, "PurgeBefore - empty cutoff count")
+	assert.Len(t, b.GetKeys(), 31, "PurgeBefore - empty cutoff unchanged")
+
+	_, err = b.db.PurgeBefore([]byte("missing"), []byte("key"))
+	assert.ErrorIs(t, err, ErrBucketNotFound{}, "PurgeBefore - missing bucket")
+}
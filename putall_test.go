@@ -0,0 +1,76 @@
+package ubolt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failMiddleware fails to wrap the value of a single key.
+type failMiddleware struct {
+	key string
+}
+
+var errFailMiddleware = errors.New("wrap failed")
+
+func (f failMiddleware) Wrap(bucket, key, value []byte) ([]byte, error) {
+	if string(key) == f.key {
+		return nil, errFailMiddleware
+	}
+
+	return value, nil
+}
+
+func (failMiddleware) Unwrap(bucket, key, value []byte) ([]byte, error) {
+	return value, nil
+}
+
+func TestPutAllChunked(t *testing.T) {
+	m := make(map[string][]byte)
+	for i := 0; i < 10; i++ {
+		m[fmt.Sprintf("key%d", i)] = []byte("0123456789")
+	}
+
+	tests := []struct {
+		name      string
+		opts      PutAllOptions
+		wantErr   error
+		committed int
+		keys      int
+	}{
+		{"single transaction", PutAllOptions{}, errFailMiddleware, 0, 0},
+		{"ops per tx", PutAllOptions{ChunkSize: 3}, ErrPartialWrite{}, 6, 6},
+		{"bytes per tx", PutAllOptions{MaxBytesPerTx: 32}, ErrPartialWrite{}, 6, 6},
+		{"both limits", PutAllOptions{ChunkSize: 4, MaxBytesPerTx: 1000}, ErrPartialWrite{}, 4, 4},
+		{"oversized value", PutAllOptions{MaxBytesPerTx: 1}, ErrPartialWrite{}, 7, 7},
+		{"first chunk", PutAllOptions{ChunkSize: 100}, errFailMiddleware, 0, 0},
+	}
+
+	for _, tt := range tests {
+		_ = os.Remove(testdb)
+
+		// key7 fails so the keys before it are committed when chunking
+		b, err := OpenBucket(testdb, testbucket, WithValueMiddleware(failMiddleware{"key7"}))
+		if err != nil {
+			panic(err)
+		}
+
+		err = b.PutAllWithOptions(m, tt.opts)
+		assert.ErrorIs(t, err, tt.wantErr, tt.name)
+		assert.ErrorIs(t, err, errFailMiddleware, tt.name)
+
+		var pw ErrPartialWrite
+		if errors.As(err, &pw) {
+			assert.Equal(t, tt.committed, pw.Committed(), tt.name)
+		}
+
+		assert.Len(t, b.GetKeys(), tt.keys, tt.name)
+
+		assert.Nil(t, b.Close(), "Close")
+	}
+
+	_ = os.Remove(testdb)
+}
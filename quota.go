@@ -0,0 +1,273 @@
+package ubolt
+
+import (
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// quotaBucket holds the quota and current usage of each bucket with a quota set using SetBucketQuota.
+var quotaBucket = []byte("__quota")
+
+// ErrQuotaExceeded is returned when a write would take a bucket over the quota set using SetBucketQuota. The write is not applied.
+type ErrQuotaExceeded struct {
+	bucket []byte
+	usage  QuotaUsage
+}
+
+// Error returns the formatted configuration error.
+func (qe ErrQuotaExceeded) Error() string {
+	return fmt.Sprintf("Quota exceeded for bucket %s: %d of %d bytes and %d of %d keys used", bucketName(qe.bucket), qe.usage.Bytes,
+		qe.usage.MaxBytes, qe.usage.Keys, qe.usage.MaxKeys)
+}
+
+// Is allows testing using errors.Is
+func (qe ErrQuotaExceeded) Is(target error) bool {
+	_, is := target.(ErrQuotaExceeded)
+
+	return is
+}
+
+// Usage returns the quota and usage of the bucket before the rejected write.
+func (qe ErrQuotaExceeded) Usage() QuotaUsage {
+	return qe.usage
+}
+
+// ErrNoQuota is returned by BucketUsage and RecalculateUsage when no quota was set for the bucket using SetBucketQuota.
+type ErrNoQuota struct {
+	bucket []byte
+}
+
+// Error returns the formatted configuration error.
+func (nq ErrNoQuota) Error() string {
+	return fmt.Sprintf("Bucket %s does not have a quota", bucketName(nq.bucket))
+}
+
+// Is allows testing using errors.Is
+func (nq ErrNoQuota) Is(target error) bool {
+	_, is := target.(ErrNoQuota)
+
+	return is
+}
+
+// QuotaUsage is the quota of a bucket and its current usage.
+type QuotaUsage struct {
+	// Bytes is the total length in bytes of the values in the bucket, as stored in the database.
+	Bytes int64
+	// Keys is the number of keys in the bucket, excluding nested buckets.
+	Keys int64
+	// MaxBytes is the limit on Bytes, or zero if Bytes is not limited.
+	MaxBytes int64
+	// MaxKeys is the limit on Keys, or zero if Keys is not limited.
+	MaxKeys int64
+}
+
+// exceeds returns true if usage u is over a limit that was not already exceeded by the previous usage.
+func (u QuotaUsage) exceeds(previous QuotaUsage) bool {
+	return (u.MaxBytes > 0 && u.Bytes > u.MaxBytes && u.Bytes > previous.Bytes) ||
+		(u.MaxKeys > 0 && u.Keys > u.MaxKeys && u.Keys > previous.Keys)
+}
+
+// SetBucketQuota limits the chosen bucket to maxBytes bytes of values and maxKeys keys, where zero or less means that dimension is not
+// limited. The quota is stored in a reserved bucket so remains in force once the database is reopened. Setting a quota on a bucket without
+// one counts its current usage, which may be above the quota; only writes that increase usage beyond the quota are rejected.
+//
+// Usage is maintained within the read/write transaction of every Put, PutV, Delete and other write made through this package, so a write
+// that would exceed the quota fails with ErrQuotaExceeded and is not applied. This includes keys written by ImportArchive and CloneBucket,
+// where an import over the quota is not applied at all while a clone over the quota leaves the destination marked as partial. Keys in nested
+// buckets count towards the quota of the nested bucket only. Deleting the bucket resets its usage however the quota is kept.
+func (db *Database) SetBucketQuota(bucket []byte, maxBytes, maxKeys int64) error {
+	if isReserved(bucket) {
+		return ErrReservedBucket{bucket}
+	}
+
+	return db.update(func(tx *bolt.Tx) error {
+		q, err := tx.CreateBucketIfNotExists(quotaBucket)
+		if err != nil {
+			return err
+		}
+
+		var usage QuotaUsage
+		if entry := q.Get(bucket); entry != nil {
+			usage = decodeQuota(entry)
+		} else {
+			usage = measureUsage(tx, bucket)
+		}
+
+		usage.MaxBytes, usage.MaxKeys = max(maxBytes, 0), max(maxKeys, 0)
+
+		return q.Put(bucket, encodeQuota(usage))
+	})
+}
+
+// SetBucketQuota limits the bucket to maxBytes bytes of values and maxKeys keys, where zero or less means that dimension is not limited.
+func (b *Bucket) SetBucketQuota(maxBytes, maxKeys int64) error {
+	return b.db.SetBucketQuota(b.bucket, maxBytes, maxKeys)
+}
+
+// RemoveBucketQuota removes the quota set for the chosen bucket using SetBucketQuota, so usage is no longer tracked. Removing a quota
+// that was not set is not an error.
+func (db *Database) RemoveBucketQuota(bucket []byte) error {
+	return db.update(func(tx *bolt.Tx) error {
+		q := tx.Bucket(quotaBucket)
+		if q == nil {
+			return nil
+		}
+
+		return q.Delete(bucket)
+	})
+}
+
+// RemoveBucketQuota removes the quota set for the bucket using SetBucketQuota.
+func (b *Bucket) RemoveBucketQuota() error {
+	return b.db.RemoveBucketQuota(b.bucket)
+}
+
+// BucketUsage returns the quota and current usage of the chosen bucket. ErrNoQuota is returned if no quota was set using SetBucketQuota.
+func (db *Database) BucketUsage(bucket []byte) (usage QuotaUsage, err error) {
+	if err := db.view(func(tx *bolt.Tx) error {
+		var entry []byte
+		if q := tx.Bucket(quotaBucket); q != nil {
+			entry = q.Get(bucket)
+		}
+
+		if entry == nil {
+			return ErrNoQuota{bucket}
+		}
+
+		usage = decodeQuota(entry)
+
+		return nil
+	}); err != nil {
+		return QuotaUsage{}, err
+	}
+
+	return usage, nil
+}
+
+// BucketUsage returns the quota and current usage of the bucket.
+func (b *Bucket) BucketUsage() (QuotaUsage, error) {
+	return b.db.BucketUsage(b.bucket)
+}
+
+// RecalculateUsage counts the current usage of the chosen bucket inside a single read/write transaction, replacing the usage maintained
+// for its quota, and returns the result. This is only needed if the bucket was written without the usage being maintained, such as by
+// another program opening the file directly. ErrNoQuota is returned if no quota was set using SetBucketQuota.
+func (db *Database) RecalculateUsage(bucket []byte) (usage QuotaUsage, err error) {
+	if err := db.update(func(tx *bolt.Tx) error {
+		var entry []byte
+
+		q := tx.Bucket(quotaBucket)
+		if q != nil {
+			entry = q.Get(bucket)
+		}
+
+		if entry == nil {
+			return ErrNoQuota{bucket}
+		}
+
+		quota := decodeQuota(entry)
+
+		usage = measureUsage(tx, bucket)
+		usage.MaxBytes, usage.MaxKeys = quota.MaxBytes, quota.MaxKeys
+
+		return q.Put(bucket, encodeQuota(usage))
+	}); err != nil {
+		return QuotaUsage{}, err
+	}
+
+	return usage, nil
+}
+
+// RecalculateUsage counts the current usage of the bucket, replacing the usage maintained for its quota.
+func (b *Bucket) RecalculateUsage() (QuotaUsage, error) {
+	return b.db.RecalculateUsage(b.bucket)
+}
+
+// measureUsage counts the keys and value bytes in the bucket, which is empty if the bucket does not exist.
+func measureUsage(tx *bolt.Tx, bucket []byte) (usage QuotaUsage) {
+	b := lookupBucket(tx, bucket)
+	if b == nil {
+		return usage
+	}
+
+	_ = b.ForEach(func(k, v []byte) error {
+		// skip nested buckets
+		if v != nil {
+			usage.Keys++
+			usage.Bytes += int64(len(v))
+		}
+
+		return nil
+	})
+
+	return usage
+}
+
+// previous returns the value stored under key in b before it is written or removed, which is recorded in the mutation so the usage of a
+// bucket with a quota can be maintained. The lookup is skipped, returning nil, when the bucket does not have a quota.
+func previous(tx *bolt.Tx, bucket []byte, b *bolt.Bucket, key []byte) []byte {
+	if q := tx.Bucket(quotaBucket); q == nil || q.Get(bucket) == nil {
+		return nil
+	}
+
+	return b.Get(key)
+}
+
+// updateQuota maintains the usage of a bucket with a quota, returning ErrQuotaExceeded if the mutation takes the bucket over its quota.
+func (db *Database) updateQuota(tx *bolt.Tx, m mutation) error {
+	q := tx.Bucket(quotaBucket)
+	if q == nil {
+		return nil
+	}
+
+	entry := q.Get(m.bucket)
+	if entry == nil {
+		return nil
+	}
+
+	usage := decodeQuota(entry)
+	next := usage
+
+	switch m.op {
+	case OpDeleteBucket:
+		next.Bytes, next.Keys = 0, 0
+	case OpDelete:
+		if m.prev != nil {
+			next.Bytes -= int64(len(m.prev))
+			next.Keys--
+		}
+	default:
+		if m.prev != nil {
+			next.Bytes -= int64(len(m.prev))
+		} else {
+			next.Keys++
+		}
+
+		next.Bytes += int64(len(m.value))
+
+		if next.exceeds(usage) {
+			return ErrQuotaExceeded{bucket: m.bucket, usage: usage}
+		}
+	}
+
+	return q.Put(m.bucket, encodeQuota(next))
+}
+
+func encodeQuota(u QuotaUsage) []byte {
+	v := make([]byte, 0, 32)
+	for _, n := range []int64{u.Bytes, u.Keys, u.MaxBytes, u.MaxKeys} {
+		v = append(v, itob(uint64(n))...)
+	}
+
+	return v
+}
+
+func decodeQuota(v []byte) QuotaUsage {
+	return QuotaUsage{
+		Bytes:    int64(btoi(v[0:8])),
+		Keys:     int64(btoi(v[8:16])),
+		MaxBytes: int64(btoi(v[16:24])),
+		MaxKeys:  int64(btoi(v[24:32])),
+	}
+}
@@ -0,0 +1,170 @@
+package ubolt
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestBucketQuota(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+
+	// existing usage is counted when the quota is set
+	if err := b.Put([]byte("existing"), bytes.Repeat([]byte("x"), 10)); err != nil {
+		panic(err)
+	}
+
+	_, err = b.BucketUsage()
+	assert.ErrorIs(t, err, ErrNoQuota{}, "BucketUsage - no quota")
+
+	assert.Nil(t, b.SetBucketQuota(100, 4), "SetBucketQuota")
+	usage, err := b.BucketUsage()
+	assert.Nil(t, err, "BucketUsage")
+	assert.Equal(t, QuotaUsage{Bytes: 10, Keys: 1, MaxBytes: 100, MaxKeys: 4}, usage, "BucketUsage - existing")
+
+	assert.Nil(t, b.Put([]byte("a"), bytes.Repeat([]byte("x"), 50)), "Put")
+	_, err = b.PutV(bytes.Repeat([]byte("x"), 20))
+	assert.Nil(t, err, "PutV")
+
+	// over the byte quota
+	err = b.Put([]byte("b"), bytes.Repeat([]byte("x"), 30))
+	assert.ErrorIs(t, err, ErrQuotaExceeded{}, "Put - over bytes")
+
+	var qe ErrQuotaExceeded
+	if assert.True(t, errors.As(err, &qe), "ErrQuotaExceeded") {
+		assert.Equal(t, QuotaUsage{Bytes: 80, Keys: 3, MaxBytes: 100, MaxKeys: 4}, qe.Usage(), "ErrQuotaExceeded - usage")
+	}
+	assert.False(t, b.Exists([]byte("b")), "Put - over bytes not applied")
+
+	// replacing a value only counts the difference
+	assert.Nil(t, b.Put([]byte("a"), bytes.Repeat([]byte("x"), 60)), "Put - replace")
+	assert.Nil(t, b.Put([]byte("b"), bytes.Repeat([]byte("x"), 10)), "Put - fits")
+
+	// over the key quota
+	assert.ErrorIs(t, b.Put([]byte("c"), nil), ErrQuotaExceeded{}, "Put - over keys")
+
+	assert.Nil(t, b.Delete([]byte("a")), "Delete")
+	assert.Nil(t, b.Delete([]byte("missing")), "Delete - missing")
+
+	w := b.NewWriter()
+	assert.Nil(t, w.Put([]byte("c"), bytes.Repeat([]byte("x"), 5)), "Writer.Put")
+	assert.Nil(t, w.Delete([]byte("b")), "Writer.Delete")
+	assert.Nil(t, w.Flush(), "Writer.Flush")
+
+	usage, err = b.BucketUsage()
+	assert.Nil(t, err, "BucketUsage")
+	assert.Equal(t, QuotaUsage{Bytes: 35, Keys: 3, MaxBytes: 100, MaxKeys: 4}, usage, "BucketUsage - after writes")
+
+	// the quota is kept once reopened
+	assert.Nil(t, b.Close(), "Close")
+	b, err = OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.ErrorIs(t, b.Put([]byte("d"), bytes.Repeat([]byte("x"), 70)), ErrQuotaExceeded{}, "Put - reopened")
+
+	n, err := b.DeleteRange(nil, nil)
+	assert.Nil(t, err, "DeleteRange")
+	assert.Equal(t, 3, n, "DeleteRange - count")
+
+	usage, err = b.BucketUsage()
+	assert.Nil(t, err, "BucketUsage")
+	assert.Equal(t, QuotaUsage{MaxBytes: 100, MaxKeys: 4}, usage, "BucketUsage - after DeleteRange")
+
+	// writes made outside the package cause usage to drift until recalculated
+	if err := b.db.bdb().Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(testbucket).Put([]byte("raw"), bytes.Repeat([]byte("x"), 7))
+	}); err != nil {
+		panic(err)
+	}
+
+	usage, err = b.RecalculateUsage()
+	assert.Nil(t, err, "RecalculateUsage")
+	assert.Equal(t, QuotaUsage{Bytes: 7, Keys: 1, MaxBytes: 100, MaxKeys: 4}, usage, "RecalculateUsage")
+
+	// deleting the bucket keeps the quota
+	assert.Nil(t, b.db.DeleteBucket(testbucket), "DeleteBucket")
+	usage, err = b.BucketUsage()
+	assert.Nil(t, err, "BucketUsage")
+	assert.Equal(t, QuotaUsage{MaxBytes: 100, MaxKeys: 4}, usage, "BucketUsage - after DeleteBucket")
+
+	assert.Nil(t, b.RemoveBucketQuota(), "RemoveBucketQuota")
+	_, err = b.RecalculateUsage()
+	assert.ErrorIs(t, err, ErrNoQuota{}, "RecalculateUsage - no quota")
+
+	assert.ErrorIs(t, b.db.SetBucketQuota(metaBucket, 1, 1), ErrReservedBucket{}, "SetBucketQuota - reserved")
+}
+
+func TestBucketQuotaImportClone(t *testing.T) {
+	_ = os.Remove(testdb)
+	_ = os.Remove(testbackup)
+	defer os.Remove(testdb)
+	defer os.Remove(testbackup)
+
+	src, err := Open(testbackup)
+	if err != nil {
+		panic(err)
+	}
+	defer src.Close()
+
+	if err := src.CreateBucket(testbucket); err != nil {
+		panic(err)
+	}
+
+	for _, key := range []string{"a", "b", "c"} {
+		if err := src.Put(testbucket, []byte(key), bytes.Repeat([]byte("x"), 10)); err != nil {
+			panic(err)
+		}
+	}
+
+	var archive bytes.Buffer
+	if err := src.ExportArchive(&archive); err != nil {
+		panic(err)
+	}
+
+	db, err := Open(testdb)
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	// imported keys are counted, so an import over the quota is not applied
+	assert.Nil(t, db.SetBucketQuota(testbucket, 0, 2), "SetBucketQuota")
+	assert.ErrorIs(t, db.ImportArchive(bytes.NewReader(archive.Bytes())), ErrQuotaExceeded{}, "ImportArchive - over keys")
+	assert.False(t, db.Exists(testbucket, []byte("a")), "ImportArchive - not applied")
+
+	assert.Nil(t, db.SetBucketQuota(testbucket, 0, 3), "SetBucketQuota")
+	assert.Nil(t, db.ImportArchive(bytes.NewReader(archive.Bytes())), "ImportArchive")
+
+	usage, err := db.BucketUsage(testbucket)
+	assert.Nil(t, err, "BucketUsage")
+	assert.Equal(t, QuotaUsage{Bytes: 30, Keys: 3, MaxKeys: 3}, usage, "BucketUsage - imported")
+
+	// cloned keys are counted towards the quota of the destination
+	clone := []byte("clone")
+
+	assert.Nil(t, db.SetBucketQuota(clone, 20, 0), "SetBucketQuota")
+	assert.ErrorIs(t, db.CloneBucket(testbucket, clone), ErrQuotaExceeded{}, "CloneBucket - over bytes")
+	partial, err := db.IsPartialClone(clone)
+	assert.Nil(t, err, "IsPartialClone")
+	assert.True(t, partial, "IsPartialClone - over quota")
+
+	assert.Nil(t, db.SetBucketQuota(clone, 30, 0), "SetBucketQuota")
+	assert.Nil(t, db.CloneBucketWithOptions(testbucket, clone, CloneOptions{Overwrite: true}), "CloneBucket - overwrite")
+
+	usage, err = db.BucketUsage(clone)
+	assert.Nil(t, err, "BucketUsage")
+	assert.Equal(t, QuotaUsage{Bytes: 30, Keys: 3, MaxBytes: 30}, usage, "BucketUsage - cloned")
+}
@@ -0,0 +1,96 @@
+package ubolt
+
+import (
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestReadOnly(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	// set up db
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := b.Put(testkey, testvalue); err != nil {
+		panic(err)
+	}
+
+	assert.False(t, b.IsReadOnly(), "IsReadOnly - read/write")
+
+	if err := b.Close(); err != nil {
+		panic(err)
+	}
+
+	// a missing bucket is not created on a read-only database
+	_, err = OpenBucket(testdb, missing, WithReadOnly())
+	assert.ErrorIs(t, err, ErrBucketNotFound{}, "OpenBucket - read-only missing bucket")
+
+	b, err = OpenBucket(testdb, testbucket, WithReadOnly())
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.True(t, b.IsReadOnly(), "IsReadOnly - read-only")
+
+	// reads continue to work
+	assert.Equal(t, testvalue, b.Get(testkey), "Get - read-only")
+	assert.Nil(t, b.Ping(), "Ping - read-only")
+
+	tests := []struct {
+		name string
+		fn   func() error
+	}{
+		{"Put", func() error { return b.Put(testkey, testvalue) }},
+		{"Put - nil key", func() error { return b.Put(nil, testvalue) }},
+		{"PutV", func() error { _, err := b.PutV(testvalue); return err }},
+		{"PutVID", func() error { _, err := b.PutVID(testvalue); return err }},
+		{"PutAll", func() error { return b.PutAll(map[string][]byte{"a": testvalue}) }},
+		{"Encode", func() error { return b.Encode(testkey, "value") }},
+		{"Delete", func() error { return b.Delete(testkey) }},
+		{"DeleteID", func() error { return b.DeleteID(1) }},
+		{"CreateBucket", func() error { return b.db.CreateBucket(missing) }},
+		{"DeleteBucket", func() error { return b.db.DeleteBucket(testbucket) }},
+		{"HealthCheck", func() error { return b.HealthCheck() }},
+	}
+
+	for _, tt := range tests {
+		err := tt.fn()
+		assert.ErrorIs(t, err, ErrReadOnly{}, tt.name)
+		assert.ErrorIs(t, err, bolt.ErrDatabaseReadOnly, tt.name+" - underlying error")
+	}
+}
+
+func TestReadOnlyError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		readOnly bool
+	}{
+		{"nil", nil, false},
+		{"other", ErrBucketNotFound{}, false},
+		{"bolt read-only database", bolt.ErrDatabaseReadOnly, true},
+		{"bolt read-only transaction", bolt.ErrTxNotWritable, true},
+		{"read-only filesystem", &os.PathError{Op: "write", Path: testdb, Err: syscall.EROFS}, true},
+		{"already translated", ErrReadOnly{bolt.ErrDatabaseReadOnly}, true},
+	}
+
+	for _, tt := range tests {
+		err := readOnlyError(tt.err)
+		if !tt.readOnly {
+			assert.Equal(t, tt.err, err, tt.name)
+			continue
+		}
+
+		assert.ErrorIs(t, err, ErrReadOnly{}, tt.name)
+		assert.ErrorIs(t, err, tt.err, tt.name+" - underlying error")
+	}
+}
@@ -0,0 +1,137 @@
+package ubolt
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// OpStats contains cumulative counts of the operations performed through a Database since it was opened.
+type OpStats struct {
+	// Gets is the number of GetE calls, which includes calls made via Get, Decode and similar methods.
+	Gets uint64
+	// Puts is the number of keys written by Put, PutV, Encode and similar methods.
+	Puts uint64
+	// Deletes is the number of keys and buckets deleted.
+	Deletes uint64
+	// Writes is the number of read/write transactions performed.
+	Writes uint64
+	// WriteTime is the total time spent in read/write transactions, including time spent waiting for the writer lock.
+	WriteTime time.Duration
+	// Touches is the number of key expiries refreshed by Touch or by reads when WithSlidingTTL is enabled.
+	Touches uint64
+}
+
+type opCounters struct {
+	gets       atomic.Uint64
+	puts       atomic.Uint64
+	deletes    atomic.Uint64
+	writes     atomic.Uint64
+	writeNanos atomic.Uint64
+	touches    atomic.Uint64
+}
+
+func (c *opCounters) count(op Op) {
+	switch op {
+	case OpDelete, OpDeleteBucket:
+		c.deletes.Add(1)
+	default:
+		c.puts.Add(1)
+	}
+}
+
+// OpStats returns the cumulative operation counters of the database.
+func (db *Database) OpStats() OpStats {
+	return OpStats{
+		Gets:      db.counters.gets.Load(),
+		Puts:      db.counters.puts.Load(),
+		Deletes:   db.counters.deletes.Load(),
+		Writes:    db.counters.writes.Load(),
+		WriteTime: time.Duration(db.counters.writeNanos.Load()),
+		Touches:   db.counters.touches.Load(),
+	}
+}
+
+// OpStats returns the cumulative operation counters of the database.
+func (b *Bucket) OpStats() OpStats {
+	return b.db.OpStats()
+}
+
+// StatsDelta is the change in statistics between two calls to StatsRecorder.Delta.
+type StatsDelta struct {
+	// Interval is the time between the two samples.
+	Interval time.Duration
+	// Bolt is the difference between the bolt statistics. Freelist values are the current values rather than a difference.
+	Bolt bolt.Stats
+	// Ops is the difference between the operation counters.
+	Ops OpStats
+
+	ReadTxPerSecond  float64
+	WriteTxPerSecond float64
+	GetsPerSecond    float64
+	PutsPerSecond    float64
+	DeletesPerSecond float64
+	// AvgWriteLatency is the mean duration of the read/write transactions performed during the interval.
+	AvgWriteLatency time.Duration
+}
+
+// StatsRecorder samples database statistics to produce rates over an interval. It is safe for concurrent use.
+type StatsRecorder struct {
+	db *Database
+
+	mu   sync.Mutex
+	at   time.Time
+	bolt bolt.Stats
+	ops  OpStats
+}
+
+// NewStatsRecorder returns a StatsRecorder whose first call to Delta reports the change since this call.
+func (db *Database) NewStatsRecorder() *StatsRecorder {
+	return &StatsRecorder{db: db, at: time.Now(), bolt: db.bdb().Stats(), ops: db.OpStats()}
+}
+
+// NewStatsRecorder returns a StatsRecorder whose first call to Delta reports the change since this call.
+func (b *Bucket) NewStatsRecorder() *StatsRecorder {
+	return b.db.NewStatsRecorder()
+}
+
+// Delta returns the change in statistics since the previous call to Delta, or since the recorder was created.
+func (r *StatsRecorder) Delta() StatsDelta {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	stats := r.db.bdb().Stats()
+	ops := r.db.OpStats()
+
+	d := StatsDelta{
+		Interval: now.Sub(r.at),
+		Bolt:     stats.Sub(&r.bolt),
+		Ops: OpStats{
+			Gets:      ops.Gets - r.ops.Gets,
+			Puts:      ops.Puts - r.ops.Puts,
+			Deletes:   ops.Deletes - r.ops.Deletes,
+			Writes:    ops.Writes - r.ops.Writes,
+			WriteTime: ops.WriteTime - r.ops.WriteTime,
+			Touches:   ops.Touches - r.ops.Touches,
+		},
+	}
+
+	if secs := d.Interval.Seconds(); secs > 0 {
+		d.ReadTxPerSecond = float64(d.Bolt.TxN) / secs
+		d.WriteTxPerSecond = float64(d.Ops.Writes) / secs
+		d.GetsPerSecond = float64(d.Ops.Gets) / secs
+		d.PutsPerSecond = float64(d.Ops.Puts) / secs
+		d.DeletesPerSecond = float64(d.Ops.Deletes) / secs
+	}
+
+	if d.Ops.Writes > 0 {
+		d.AvgWriteLatency = d.Ops.WriteTime / time.Duration(d.Ops.Writes)
+	}
+
+	r.at, r.bolt, r.ops = now, stats, ops
+
+	return d
+}
@@ -0,0 +1,53 @@
+package ubolt
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsRecorder(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	r := b.NewStatsRecorder()
+
+	for i := 0; i < 5; i++ {
+		if err := b.Put(testkey, testvalue); err != nil {
+			panic(err)
+		}
+	}
+	_ = b.Get(testkey)
+	_ = b.Delete(testkey)
+
+	time.Sleep(10 * time.Millisecond)
+
+	d := r.Delta()
+	assert.Equal(t, uint64(5), d.Ops.Puts, "Delta - puts")
+	assert.Equal(t, uint64(1), d.Ops.Gets, "Delta - gets")
+	assert.Equal(t, uint64(1), d.Ops.Deletes, "Delta - deletes")
+	assert.Equal(t, uint64(6), d.Ops.Writes, "Delta - writes")
+	assert.Greater(t, d.Bolt.TxN, 0, "Delta - read transactions")
+	assert.Greater(t, d.PutsPerSecond, 0.0, "Delta - puts per second")
+	assert.Greater(t, d.AvgWriteLatency, time.Duration(0), "Delta - write latency")
+	assert.GreaterOrEqual(t, d.Interval, 10*time.Millisecond, "Delta - interval")
+
+	_, err = json.Marshal(d)
+	assert.Nil(t, err, "Delta - json")
+
+	// a second delta only covers activity since the first
+	d = r.Delta()
+	assert.Equal(t, uint64(0), d.Ops.Puts, "Delta - second puts")
+	assert.Equal(t, time.Duration(0), d.AvgWriteLatency, "Delta - second write latency")
+
+	assert.Equal(t, uint64(5), b.OpStats().Puts, "OpStats - puts")
+}
@@ -0,0 +1,138 @@
+package ubolt
+
+import (
+	bolt "go.etcd.io/bbolt"
+)
+
+// ReencodeOptions controls the behaviour of ReencodeBucketWithOptions.
+type ReencodeOptions struct {
+	// ChunkSize is the maximum number of keys visited in each read/write transaction, which defaults to 1000 when zero or less.
+	ChunkSize int
+
+	// Progress, if set, is called as keys are visited with the number of keys visited so far, including those skipped. The total is
+	// reported as -1.
+	Progress ProgressFunc
+}
+
+// defaultReencodeChunkSize is used when ReencodeOptions.ChunkSize is not set.
+const defaultReencodeChunkSize = 1000
+
+// ReencodeBucket decodes every value in the chosen bucket as a T using the from Codec then encodes it using the to Codec, overwriting the
+// value in place, and returns the number of values converted. Keys are processed in chunks of 1000 per read/write transaction, so the
+// conversion is not atomic.
+//
+// Values already recorded as encoded by to in their encoding header are skipped, so an interrupted conversion may be resumed by calling
+// ReencodeBucket again. This requires from and to to have a different CodecID, so at least one must implement IdentifiedCodec. The first
+// value that cannot be decoded stops the conversion with ErrDecode, with the values converted in earlier chunks left in place.
+//
+// Once complete the database should be opened using WithCodec(to) so new values are encoded the same way.
+func ReencodeBucket[T any](db *Database, bucket []byte, from, to Codec) (int, error) {
+	return ReencodeBucketWithOptions[T](db, bucket, from, to, ReencodeOptions{})
+}
+
+// ReencodeBucketWithOptions performs the same process as ReencodeBucket with the behaviour controlled by the provided ReencodeOptions.
+func ReencodeBucketWithOptions[T any](db *Database, bucket []byte, from, to Codec, opts ReencodeOptions) (int, error) {
+	chunk := opts.ChunkSize
+	if chunk <= 0 {
+		chunk = defaultReencodeChunkSize
+	}
+
+	fromID, toID := codecID(from), codecID(to)
+
+	var total int
+	var after []byte
+
+	p := newProgress(opts.Progress, -1)
+
+	for {
+		visited, converted := 0, 0
+
+		if err := db.update(func(tx *bolt.Tx) error {
+			b := lookupBucket(tx, bucket)
+			if b == nil {
+				return ErrBucketNotFound{bucket: bucket}
+			}
+
+			var keys, values [][]byte
+			var last []byte
+
+			if err := scanPrefixFrom(b.Cursor(), nil, after, func(k, v []byte) error {
+				if visited == chunk {
+					return ErrStop{}
+				}
+
+				visited++
+				last = k
+				p.add(1)
+
+				// skip nested buckets
+				if v == nil {
+					return nil
+				}
+
+				v, err := db.unwrapValue(bucket, k, v)
+				if err != nil {
+					return ErrDecode{bucket: bucket, key: append([]byte{}, k...), err: err}
+				}
+
+				info, payload := parseEncoding(v)
+				if info.Header && info.Codec == toID && toID != fromID {
+					return nil
+				}
+
+				if info.Header && (info.Codec != fromID || info.Flags != 0) {
+					return ErrDecode{bucket: bucket, key: append([]byte{}, k...), err: ErrUnsupportedEncoding{codec: info.Codec, flags: info.Flags}}
+				}
+
+				var value T
+				if err := from.Unmarshal(payload, &value); err != nil {
+					return ErrDecode{bucket: bucket, key: append([]byte{}, k...), err: err}
+				}
+
+				data, err := marshalWith(to, value)
+				if err != nil {
+					return err
+				}
+
+				if data, err = db.wrapValue(bucket, k, data); err != nil {
+					return err
+				}
+
+				keys = append(keys, append([]byte{}, k...))
+				values = append(values, data)
+
+				return nil
+			}); ignoreStop(err) != nil {
+				return err
+			}
+
+			after = append([]byte{}, last...)
+
+			for i, k := range keys {
+				prev := previous(tx, bucket, b, k)
+
+				if err := b.Put(k, values[i]); err != nil {
+					return err
+				}
+
+				if err := db.onMutation(tx, mutation{op: OpEncode, bucket: bucket, key: k, value: values[i], prev: prev}); err != nil {
+					return err
+				}
+			}
+
+			converted = len(keys)
+
+			return nil
+		}); err != nil {
+			return total, err
+		}
+
+		total += converted
+
+		if visited < chunk {
+			p.finish()
+
+			return total, nil
+		}
+	}
+}
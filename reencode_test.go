@@ -0,0 +1,63 @@
+package ubolt
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReencodeBucket(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, b.Encode([]byte(fmt.Sprintf("key%d", i)), profile{Name: fmt.Sprintf("user%d", i), Age: i}), "Encode")
+	}
+
+	// a value written without an encoding header
+	legacy, err := GobCodec.Marshal(profile{Name: "legacy"})
+	assert.Nil(t, err, "Marshal")
+	assert.Nil(t, b.Put([]byte("key5"), legacy), "Put")
+
+	// a value that cannot be decoded stops the conversion
+	assert.Nil(t, b.Put([]byte("key3x"), []byte("broken")), "Put")
+
+	n, err := ReencodeBucketWithOptions[profile](b.db, testbucket, GobCodec, jsonCodec{}, ReencodeOptions{ChunkSize: 2})
+	assert.ErrorIs(t, err, ErrDecode{}, "ReencodeBucket - broken")
+	assert.EqualError(t, err, "Key key3x in bucket "+string(testbucket)+" could not be decoded: unexpected EOF", "ReencodeBucket - broken")
+	assert.Equal(t, 4, n, "ReencodeBucket - converted before error")
+
+	assert.Nil(t, b.Delete([]byte("key3x")), "Delete")
+
+	// resuming skips the values already converted
+	n, err = ReencodeBucket[profile](b.db, testbucket, GobCodec, jsonCodec{})
+	assert.Nil(t, err, "ReencodeBucket - resume")
+	assert.Equal(t, 2, n, "ReencodeBucket - resume")
+
+	n, err = ReencodeBucket[profile](b.db, testbucket, GobCodec, jsonCodec{})
+	assert.Nil(t, err, "ReencodeBucket - complete")
+	assert.Equal(t, 0, n, "ReencodeBucket - complete")
+
+	assert.Nil(t, b.Close(), "Close")
+
+	b, err = OpenBucket(testdb, testbucket, WithCodec(jsonCodec{}))
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	var got profile
+	assert.Nil(t, b.Decode([]byte("key5"), &got), "Decode - json")
+	assert.Equal(t, profile{Name: "legacy"}, got, "Decode - json")
+
+	data := b.Get([]byte("key1"))
+	assert.Equal(t, `{"Name":"user1","Age":1}`, string(data[encodingHeaderSize:]), "stored as json")
+}
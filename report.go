@@ -0,0 +1,139 @@
+package ubolt
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// report holds the figures printed by Report, which are gathered inside a single read-only transaction.
+type report struct {
+	path     string
+	fileSize int64
+	pages    PageStats
+	// bucketN is the number of top-level buckets, excluding reserved buckets
+	bucketN int
+	buckets []BucketSummary
+	// bucket is set for a report scoped to a single bucket
+	bucket         *BucketSummary
+	lastCompaction time.Time
+}
+
+// Report returns a compact multi-line summary of the database intended to be read by a person, such as when triaging a problem. This
+// includes the path, file size, page size, number of buckets, free pages and the space that may be reclaimed using CompactInPlace. When
+// includeBuckets is true the key count and size of every bucket is listed in name order. Reserved buckets are excluded.
+//
+// The figures are gathered inside a single read-only transaction so they all correspond to the same snapshot. Use PageStats and Overview
+// to retrieve the same figures in a structured form.
+func (db *Database) Report(includeBuckets bool) (string, error) {
+	r, err := db.report(nil, includeBuckets)
+	if err != nil {
+		return "", err
+	}
+
+	return r.String(), nil
+}
+
+// Report returns a compact multi-line summary of the database and the bucket opened, intended to be read by a person. Other buckets are
+// not listed.
+func (b *Bucket) Report() (string, error) {
+	r, err := b.db.report(b.bucket, false)
+	if err != nil {
+		return "", err
+	}
+
+	return r.String(), nil
+}
+
+// report gathers the figures for Report. When bucket is not nil only that bucket is summarised.
+func (db *Database) report(bucket []byte, includeBuckets bool) (report, error) {
+	r := report{path: db.Path()}
+
+	if ns := db.lastCompaction.Load(); ns != 0 {
+		r.lastCompaction = time.Unix(0, ns)
+	}
+
+	if err := db.view(func(tx *bolt.Tx) error {
+		info, err := db.fs.Stat(tx.DB().Path())
+		if err != nil {
+			return err
+		}
+
+		r.fileSize = info.Size()
+		r.pages = pageStats(tx)
+
+		if bucket != nil {
+			b := lookupBucket(tx, bucket)
+			if b == nil {
+				return ErrBucketNotFound{bucket: bucket}
+			}
+
+			summary := bucketSummary(bucketName(bucket), b)
+			r.bucket = &summary
+		}
+
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			if isReserved(name) {
+				return nil
+			}
+
+			r.bucketN++
+
+			if includeBuckets {
+				r.buckets = append(r.buckets, bucketSummary(string(name), b))
+			}
+
+			return nil
+		})
+	}); err != nil {
+		return report{}, err
+	}
+
+	return r, nil
+}
+
+// String formats the report with one figure per line.
+func (r report) String() string {
+	var sb strings.Builder
+
+	line := func(label, format string, args ...interface{}) {
+		fmt.Fprintf(&sb, "%-17s"+format+"\n", append([]interface{}{label + ":"}, args...)...)
+	}
+
+	line("Path", "%s", r.path)
+	line("File size", "%d bytes", r.fileSize)
+	line("Page size", "%d bytes", r.pages.PageSize)
+	line("Data size", "%d bytes (%d pages)", r.pages.Size, r.pages.TotalPageN)
+	line("Free pages", "%d (%d pending)", r.pages.FreePageN, r.pages.PendingPageN)
+	line("Reclaimable", "%d bytes (%.1f%%) by CompactInPlace", r.pages.FreeAlloc, r.pages.FragmentationRatio()*100)
+
+	if r.lastCompaction.IsZero() {
+		line("Last compaction", "never by this process")
+	} else {
+		line("Last compaction", "%s", r.lastCompaction.UTC().Format(time.RFC3339))
+	}
+
+	line("Buckets", "%d", r.bucketN)
+
+	if r.bucket != nil {
+		line("Bucket", "%s", formatBucketSummary(*r.bucket))
+	}
+
+	for _, b := range r.buckets {
+		fmt.Fprintf(&sb, "  %s\n", formatBucketSummary(b))
+	}
+
+	return sb.String()
+}
+
+// formatBucketSummary formats the summary of a bucket on a single line.
+func formatBucketSummary(b BucketSummary) string {
+	s := fmt.Sprintf("%s: %d keys, %d bytes", b.Name, b.KeyCount, b.LeafBytes+b.BranchBytes)
+	if b.HasNested {
+		s += ", has nested buckets"
+	}
+
+	return s
+}
@@ -0,0 +1,117 @@
+package ubolt
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportFormat(t *testing.T) {
+	r := report{
+		path:     "test.db",
+		fileSize: 65536,
+		pages: PageStats{
+			PageSize:     4096,
+			Size:         40960,
+			TotalPageN:   10,
+			FreePageN:    3,
+			PendingPageN: 1,
+			FreeAlloc:    16384,
+		},
+		bucketN: 2,
+		buckets: []BucketSummary{
+			{Name: "alpha", KeyCount: 12, LeafBytes: 4000, BranchBytes: 96},
+			{Name: "beta", KeyCount: 3, LeafBytes: 120, HasNested: true},
+		},
+	}
+
+	want := `Path:            test.db
+File size:       65536 bytes
+Page size:       4096 bytes
+Data size:       40960 bytes (10 pages)
+Free pages:      3 (1 pending)
+Reclaimable:     16384 bytes (40.0%) by CompactInPlace
+Last compaction: never by this process
+Buckets:         2
+  alpha: 12 keys, 4096 bytes
+  beta: 3 keys, 120 bytes, has nested buckets
+`
+	assert.Equal(t, want, r.String(), "report")
+
+	r.buckets = nil
+	r.bucket = &BucketSummary{Name: "alpha", KeyCount: 12, LeafBytes: 4096}
+	r.lastCompaction = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	want = `Path:            test.db
+File size:       65536 bytes
+Page size:       4096 bytes
+Data size:       40960 bytes (10 pages)
+Free pages:      3 (1 pending)
+Reclaimable:     16384 bytes (40.0%) by CompactInPlace
+Last compaction: 2024-01-02T03:04:05Z
+Buckets:         2
+Bucket:          alpha: 12 keys, 4096 bytes
+`
+	assert.Equal(t, want, r.String(), "report - bucket")
+}
+
+func TestReport(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket, WithTimestamps())
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	for i := 0; i < 10; i++ {
+		if err := b.Put([]byte(fmt.Sprintf("key%d", i)), testvalue); err != nil {
+			panic(err)
+		}
+	}
+
+	if err := b.db.CreateBucket([]byte("another")); err != nil {
+		panic(err)
+	}
+
+	report, err := b.db.Report(true)
+	assert.Nil(t, err, "Report")
+	assert.True(t, strings.HasPrefix(report, "Path:            "+testdb+"\n"), "Report - path")
+	assert.Contains(t, report, "Buckets:         2\n", "Report - reserved buckets excluded")
+	assert.Contains(t, report, "Last compaction: never by this process\n", "Report - never compacted")
+
+	overview, err := b.db.Overview()
+	if err != nil {
+		panic(err)
+	}
+
+	var buckets string
+	for _, s := range overview {
+		buckets += "  " + formatBucketSummary(s) + "\n"
+	}
+	assert.True(t, strings.HasSuffix(report, "Buckets:         2\n"+buckets), "Report - buckets")
+	assert.Contains(t, buckets, "  bucket1: 10 keys, ", "Report - bucket keys")
+
+	report, err = b.db.Report(false)
+	assert.Nil(t, err, "Report")
+	assert.NotContains(t, report, "another", "Report - buckets not included")
+
+	if _, _, err := b.CompactInPlace(); err != nil {
+		panic(err)
+	}
+
+	report, err = b.Report()
+	assert.Nil(t, err, "Bucket Report")
+	assert.NotContains(t, report, "never", "Bucket Report - compacted")
+	assert.Contains(t, report, "Bucket:          bucket1: 10 keys, ", "Bucket Report - bucket")
+	assert.NotContains(t, report, "another", "Bucket Report - other buckets")
+
+	missing := &Bucket{db: b.db, bucket: []byte("missing")}
+	_, err = missing.Report()
+	assert.ErrorIs(t, err, ErrBucketNotFound{}, "Bucket Report - missing")
+}
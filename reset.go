@@ -0,0 +1,87 @@
+package ubolt
+
+import (
+	"bytes"
+	"errors"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// resetReserved are the reserved buckets holding database-wide state, which Reset removes unless ResetOptions.KeepReserved is set. Reserved
+// buckets holding the state of a single bucket, such as its TTLs or indexes, are cleared as that bucket is deleted.
+var resetReserved = [][]byte{metaBucket, auditBucket, healthBucket}
+
+// ResetOptions controls the behaviour of ResetWithOptions.
+type ResetOptions struct {
+	// KeepReserved preserves the database-wide metadata written by SetMeta along with the audit log.
+	KeepReserved bool
+
+	// Keep lists top-level buckets that are preserved along with their contents and any state kept for them, such as TTLs and indexes.
+	Keep [][]byte
+}
+
+// Reset deletes every bucket in the database, along with the metadata written by SetMeta and the audit log, leaving the database file and
+// handle open so any Bucket continues to refer to it. Buckets are deleted in a single read/write transaction, so concurrent readers see
+// either the database as it was or an empty database. Operations using a Bucket return ErrBucketNotFound until Recreate is called.
+//
+// ErrReadOnly is returned for a read-only database.
+func (db *Database) Reset() error {
+	return db.ResetWithOptions(ResetOptions{})
+}
+
+// Reset deletes every bucket in the database. This is forwarded to the Database implementation.
+func (b *Bucket) Reset() error {
+	return b.db.Reset()
+}
+
+// ResetWithOptions performs the same process as Reset with the buckets preserved controlled by the provided ResetOptions.
+func (db *Database) ResetWithOptions(opts ResetOptions) error {
+	return db.update(func(tx *bolt.Tx) error {
+		var names [][]byte
+
+		if err := tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+			if !isReserved(name) && !containsBytes(opts.Keep, name) {
+				names = append(names, append([]byte{}, name...))
+			}
+
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			if err := db.deleteBucket(tx, name); err != nil {
+				return err
+			}
+		}
+
+		if opts.KeepReserved {
+			return nil
+		}
+
+		for _, name := range resetReserved {
+			if err := tx.DeleteBucket(name); err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ResetWithOptions performs the same process as Reset with the buckets preserved controlled by the provided ResetOptions. This is forwarded
+// to the Database implementation.
+func (b *Bucket) ResetWithOptions(opts ResetOptions) error {
+	return b.db.ResetWithOptions(opts)
+}
+
+// containsBytes reports whether list holds a value equal to b.
+func containsBytes(list [][]byte, b []byte) bool {
+	for _, v := range list {
+		if bytes.Equal(v, b) {
+			return true
+		}
+	}
+
+	return false
+}
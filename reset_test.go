@@ -0,0 +1,96 @@
+package ubolt
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestReset(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	sessions := []byte("sessions")
+
+	db, err := Open(testdb, WithTTL(sessions))
+	if err != nil {
+		panic(err)
+	}
+
+	populate := func() {
+		for _, name := range [][]byte{testbucket, []byte("other"), sessions} {
+			assert.Nil(t, db.CreateBucket(name), "CreateBucket")
+		}
+
+		assert.Nil(t, db.Put(testbucket, testkey, testvalue), "Put")
+		assert.Nil(t, db.CreateBucket(BucketPath([]byte("other"), []byte("nested"))), "CreateBucket - nested")
+		assert.Nil(t, db.PutTTL(sessions, testkey, testvalue, time.Hour), "PutTTL")
+		assert.Nil(t, db.SetMeta([]byte("schema"), []byte("3")), "SetMeta")
+	}
+
+	populate()
+
+	b, err := db.Bucket(testbucket)
+	assert.Nil(t, err, "Bucket")
+
+	// concurrent readers see either every bucket or none
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			if n := len(db.GetBuckets()); n != 0 && n != 3 {
+				t.Errorf("GetBuckets during Reset returned %d buckets", n)
+			}
+		}
+	}()
+
+	assert.Nil(t, b.Reset(), "Reset")
+	close(stop)
+	<-done
+
+	assert.Empty(t, db.GetBuckets(), "Reset - buckets")
+	assert.Nil(t, db.GetMeta([]byte("schema")), "Reset - meta")
+
+	// the handle remains usable once the bucket is recreated
+	_, err = b.GetE(testkey)
+	assert.ErrorIs(t, err, ErrBucketNotFound{}, "GetE after Reset")
+	assert.Nil(t, b.Recreate(), "Recreate")
+	assert.Nil(t, b.Put(testkey, testvalue), "Put after Reset")
+
+	// buckets and reserved metadata may be kept, including the state of kept buckets
+	populate()
+
+	assert.Nil(t, db.ResetWithOptions(ResetOptions{KeepReserved: true, Keep: [][]byte{sessions}}), "ResetWithOptions")
+	assert.Equal(t, [][]byte{sessions}, db.GetBuckets(), "ResetWithOptions - buckets")
+	assert.Equal(t, []byte("3"), db.GetMeta([]byte("schema")), "ResetWithOptions - meta")
+
+	assert.Nil(t, db.view(func(tx *bolt.Tx) error {
+		has, _ := db.ttlState(tx, sessions, testkey)
+		assert.True(t, has, "ResetWithOptions - TTL of kept bucket")
+
+		return nil
+	}), "View")
+
+	assert.Nil(t, db.Close(), "Close")
+
+	// read-only handles can not be reset
+	db, err = Open(testdb, WithReadOnly())
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	assert.ErrorIs(t, db.Reset(), ErrReadOnly{}, "Reset - read-only")
+}
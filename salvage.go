@@ -0,0 +1,277 @@
+package ubolt
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"runtime/debug"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// salvageChunkSize is the number of entries written to the destination per read/write transaction by Salvage.
+const salvageChunkSize = 1000
+
+// ErrCorrupted is passed to the report function of Salvage for data that could not be read, and is returned by Salvage if the source
+// database can not be opened at all.
+type ErrCorrupted struct {
+	reason interface{}
+}
+
+// Error returns the formatted configuration error.
+func (c ErrCorrupted) Error() string {
+	return fmt.Sprintf("Corrupted data: %v", c.reason)
+}
+
+// Is allows testing using errors.Is
+func (c ErrCorrupted) Is(target error) bool {
+	_, is := target.(ErrCorrupted)
+
+	return is
+}
+
+// Unwrap returns the underlying error, if the corruption was reported as an error.
+func (c ErrCorrupted) Unwrap() error {
+	err, _ := c.reason.(error)
+
+	return err
+}
+
+// Salvage copies every key and value that can still be read from the possibly corrupted bolt database at srcPath into a new database at
+// dstPath, returning the number of keys recovered. The source is opened read-only and is not modified, and dstPath must not exist.
+//
+// Each bucket is walked forwards from its first key then, if a damaged page stops the walk, backwards from its last key, so the keys either
+// side of a single damaged page are recovered. Reading corrupted pages may panic or fault, which is recovered and passed to report, if set,
+// as ErrCorrupted along with the bucket being walked and the last key read before the damage, or nil if none was read. Nested buckets and
+// bucket sequences are recovered, with the bucket of a nested key reported as a name created by BucketPath.
+//
+// Values are copied as stored, so a value within a page that is damaged without affecting its structure is copied as-is. The recovered
+// database should be checked before use, for example using the Check method of a bolt transaction, and the data it contains verified by
+// the application.
+func Salvage(srcPath, dstPath string, report func(bucket, key []byte, err error)) (recovered int, err error) {
+	if _, err := os.Stat(dstPath); err == nil {
+		return 0, &fs.PathError{Op: "salvage", Path: dstPath, Err: fs.ErrExist}
+	}
+
+	// faults reading a damaged memory map are recovered rather than crashing the process
+	defer debug.SetPanicOnFault(debug.SetPanicOnFault(true))
+
+	var src *bolt.DB
+	if err := salvageProtect(func() error {
+		var err error
+		src, err = bolt.Open(srcPath, 0o400, &bolt.Options{ReadOnly: true, Timeout: time.Second})
+
+		return err
+	}); err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	dst, err := bolt.Open(dstPath, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return 0, err
+	}
+	defer dst.Close()
+
+	var tx *bolt.Tx
+	if err := salvageProtect(func() error {
+		var err error
+		tx, err = src.Begin(false)
+
+		return err
+	}); err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = salvageProtect(tx.Rollback)
+	}()
+
+	s := &salvager{dst: dst, report: report}
+
+	s.walk(nil, tx.Cursor, tx.Bucket)
+
+	if err := s.flush(); err != nil {
+		return s.recovered, err
+	}
+
+	return s.recovered, nil
+}
+
+// salvager accumulates the entries recovered by Salvage and writes them to the destination in chunks.
+type salvager struct {
+	dst       *bolt.DB
+	report    func(bucket, key []byte, err error)
+	pending   []salvaged
+	recovered int
+	err       error
+}
+
+// salvaged is a recovered key and value, or a recovered bucket when value is nil.
+type salvaged struct {
+	path     [][]byte
+	key      []byte
+	value    []byte
+	sequence uint64
+}
+
+// walk recovers the keys of the bucket at path, which is the root bucket when path is empty, using the provided functions to open a cursor
+// and nested buckets.
+func (s *salvager) walk(path [][]byte, cursor func() *bolt.Cursor, bucket func(name []byte) *bolt.Bucket) {
+	var last []byte
+
+	visit := func(k, v []byte) {
+		k = append([]byte{}, k...)
+		last = k
+
+		if v != nil {
+			if len(path) == 0 {
+				s.fail(path, k, ErrCorrupted{"key outside of a bucket"})
+
+				return
+			}
+
+			s.add(salvaged{path: path, key: k, value: append([]byte{}, v...)})
+
+			return
+		}
+
+		var b *bolt.Bucket
+		var sequence uint64
+		if err := salvageProtect(func() error {
+			if b = bucket(k); b == nil {
+				return ErrCorrupted{"unreadable bucket"}
+			}
+
+			sequence = b.Sequence()
+
+			return nil
+		}); err != nil {
+			s.fail(path, k, err)
+
+			return
+		}
+
+		child := append(append([][]byte{}, path...), k)
+
+		s.add(salvaged{path: child, sequence: sequence})
+		s.walk(child, b.Cursor, b.Bucket)
+	}
+
+	err := salvageProtect(func() error {
+		c := cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			visit(k, v)
+		}
+
+		return nil
+	})
+	if err == nil {
+		return
+	}
+
+	s.fail(path, last, err)
+
+	// recover the keys after the damage by walking backwards
+	end := last
+	if err := salvageProtect(func() error {
+		c := cursor()
+		for k, v := c.Last(); k != nil && bytes.Compare(k, end) > 0; k, v = c.Prev() {
+			visit(k, v)
+		}
+
+		return nil
+	}); err != nil {
+		s.fail(path, last, err)
+	}
+}
+
+// add queues a recovered entry, writing the queue once it is full.
+func (s *salvager) add(e salvaged) {
+	if s.err != nil {
+		return
+	}
+
+	s.pending = append(s.pending, e)
+
+	if len(s.pending) >= salvageChunkSize {
+		s.err = s.flush()
+	}
+}
+
+// flush writes the queued entries to the destination in a single read/write transaction.
+func (s *salvager) flush() error {
+	if s.err != nil {
+		return s.err
+	}
+
+	if len(s.pending) == 0 {
+		return nil
+	}
+
+	n := 0
+
+	if err := s.dst.Update(func(tx *bolt.Tx) error {
+		for _, e := range s.pending {
+			b, err := tx.CreateBucketIfNotExists(e.path[0])
+			if err != nil {
+				return err
+			}
+
+			for _, name := range e.path[1:] {
+				if b, err = b.CreateBucketIfNotExists(name); err != nil {
+					return err
+				}
+			}
+
+			if e.value == nil {
+				if err := b.SetSequence(e.sequence); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			if err := b.Put(e.key, e.value); err != nil {
+				return err
+			}
+
+			n++
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	s.recovered += n
+	s.pending = s.pending[:0]
+
+	return nil
+}
+
+// fail passes a key that could not be read to the report function.
+func (s *salvager) fail(path [][]byte, key []byte, err error) {
+	if s.report == nil {
+		return
+	}
+
+	var bucket []byte
+	if len(path) > 0 {
+		bucket = BucketPath(path...)
+	}
+
+	s.report(bucket, key, err)
+}
+
+// salvageProtect calls fn, returning any panic as ErrCorrupted.
+func salvageProtect(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = ErrCorrupted{r}
+		}
+	}()
+
+	return fn()
+}
@@ -0,0 +1,128 @@
+package ubolt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+const testsalvage = "salvaged.db"
+
+func TestSalvage(t *testing.T) {
+	_ = os.Remove(testdb)
+	_ = os.Remove(testsalvage)
+	defer os.Remove(testdb)
+	defer os.Remove(testsalvage)
+
+	db, err := Open(testdb)
+	if err != nil {
+		panic(err)
+	}
+
+	large, small, nested := []byte("large"), []byte("small"), BucketPath([]byte("small"), []byte("nested"))
+
+	for _, bucket := range [][]byte{large, small, nested} {
+		if err := db.CreateBucket(bucket); err != nil {
+			panic(err)
+		}
+	}
+
+	m := make(map[string][]byte)
+	for i := 0; i < 2000; i++ {
+		m[fmt.Sprintf("key%05d", i)] = make([]byte, 100)
+	}
+
+	if err := db.PutAll(large, m); err != nil {
+		panic(err)
+	}
+
+	if err := db.Put(small, testkey, testvalue); err != nil {
+		panic(err)
+	}
+
+	if _, err := db.PutV(nested, testvalue); err != nil {
+		panic(err)
+	}
+
+	var root int
+	pageSize := db.bdb().Info().PageSize
+	if err := db.view(func(tx *bolt.Tx) error {
+		root = int(tx.Bucket(large).Root())
+
+		return nil
+	}); err != nil {
+		panic(err)
+	}
+
+	if err := db.Close(); err != nil {
+		panic(err)
+	}
+
+	// an intact database is recovered in full
+	var reported []string
+	report := func(bucket, key []byte, err error) {
+		assert.ErrorIs(t, err, ErrCorrupted{}, "Salvage - report error")
+		reported = append(reported, fmt.Sprintf("%s/%s", bucket, key))
+	}
+
+	n, err := Salvage(testdb, testsalvage, report)
+	assert.Nil(t, err, "Salvage")
+	// includes the key encoding recorded by PutV in a reserved bucket
+	assert.Equal(t, 2003, n, "Salvage - recovered")
+	assert.Empty(t, reported, "Salvage - reported")
+
+	_, err = Salvage(testdb, testsalvage, report)
+	assert.ErrorIs(t, err, fs.ErrExist, "Salvage - destination exists")
+	assert.Nil(t, os.Remove(testsalvage), "Remove")
+
+	// point the second child of the root page of the large bucket far beyond the end of the file
+	data, err := os.ReadFile(testdb)
+	if err != nil {
+		panic(err)
+	}
+
+	page := data[root*pageSize:]
+	assert.Equal(t, uint16(0x01), binary.LittleEndian.Uint16(page[8:10]), "root is a branch page")
+	assert.Greater(t, binary.LittleEndian.Uint16(page[10:12]), uint16(2), "root has more than two children")
+	binary.LittleEndian.PutUint64(page[16+16+8:], 1<<28)
+
+	if err := os.WriteFile(testdb, data, 0o600); err != nil {
+		panic(err)
+	}
+
+	n, err = Salvage(testdb, testsalvage, report)
+	assert.Nil(t, err, "Salvage - corrupted")
+	assert.Greater(t, n, 3, "Salvage - corrupted recovered")
+	assert.Less(t, n, 2003, "Salvage - corrupted lost keys")
+	assert.Len(t, reported, 2, "Salvage - reported forwards and backwards")
+
+	salvaged, err := Open(testsalvage)
+	if err != nil {
+		panic(err)
+	}
+	defer salvaged.Close()
+
+	assert.Equal(t, testvalue, salvaged.Get(small, testkey), "Salvage - small bucket")
+	keys := salvaged.GetKeys(large)
+	assert.Len(t, keys, n-3, "Salvage - large bucket")
+	assert.Equal(t, []byte("key00000"), keys[0], "Salvage - first key")
+	assert.Equal(t, []byte("key01999"), keys[len(keys)-1], "Salvage - last key")
+	assert.Equal(t, [][]byte{itob(1)}, salvaged.GetKeys(nested), "Salvage - nested bucket")
+
+	key, err := salvaged.PutV(nested, testvalue)
+	assert.Nil(t, err, "PutV")
+	assert.Equal(t, itob(2), key, "Salvage - sequence")
+
+	assert.Nil(t, salvaged.bdb().View(func(tx *bolt.Tx) error {
+		for err := range tx.Check() {
+			return err
+		}
+
+		return nil
+	}), "Check")
+}
@@ -0,0 +1,73 @@
+package ubolt
+
+import (
+	"errors"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrStop may be returned by the function passed to ForEach, ForEachAll, Scan or ScanFrom to stop iterating early without the call returning an error.
+type ErrStop struct{}
+
+// Error returns the formatted configuration error.
+func (s ErrStop) Error() string {
+	return "Iteration stopped"
+}
+
+// Is allows testing using errors.Is
+func (s ErrStop) Is(target error) bool {
+	_, is := target.(ErrStop)
+
+	return is
+}
+
+// ignoreStop returns nil if err is ErrStop, otherwise err is returned unchanged.
+func ignoreStop(err error) error {
+	if errors.Is(err, ErrStop{}) {
+		return nil
+	}
+
+	return err
+}
+
+// PrefixSuccessor returns the smallest key that sorts after every key beginning with prefix, which is the exclusive upper bound of the
+// range of keys with that prefix. Trailing 0xff bytes are removed before the last byte is incremented, so for example the successor of
+// "a\xff" is "b". A nil value is returned, meaning there is no upper bound, when prefix is empty or consists only of 0xff bytes.
+//
+// The result may be used as the end of GetKeysBetween or DeleteRange to select every key beginning with prefix.
+func PrefixSuccessor(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] != 0xff {
+			end := append([]byte{}, prefix[:i+1]...)
+			end[i]++
+
+			return end
+		}
+	}
+
+	return nil
+}
+
+// ScanFrom performs the same process as Scan however iteration starts at the first key sorting strictly after the key after, or at the
+// start of the prefix when after is nil. The key after need not exist, so a scan may be resumed from the last key processed even if it has
+// since been deleted.
+//
+// Returning ErrStop from fn stops the scan without error, allowing a prefix to be processed in chunks by resuming from the last key seen.
+func (db *Database) ScanFrom(bucket, prefix, after []byte, fn func(k, v []byte) error) error {
+	// after is a key passed to fn so is already in its stored form
+	prefix = db.canonicalKey(prefix)
+
+	return ignoreStop(db.view(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		return scanPrefixFrom(b.Cursor(), prefix, after, db.unwrapFunc(bucket, fn))
+	}))
+}
+
+// ScanFrom performs the same process as Scan however iteration starts at the first key sorting strictly after the key after.
+func (b *Bucket) ScanFrom(prefix, after []byte, fn func(k, v []byte) error) error {
+	return b.db.ScanFrom(b.bucket, prefix, after, fn)
+}
@@ -0,0 +1,145 @@
+package ubolt
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanFrom(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	for _, k := range []string{"a", "job1", "job2", "job3", "job4", "job5", "z"} {
+		if err := b.Put([]byte(k), testvalue); err != nil {
+			panic(err)
+		}
+	}
+
+	// process the prefix in chunks of two, resuming after the last key seen
+	chunk := func(after []byte) (keys []string) {
+		err := b.ScanFrom([]byte("job"), after, func(k, v []byte) error {
+			keys = append(keys, string(k))
+			if len(keys) == 2 {
+				return ErrStop{}
+			}
+
+			return nil
+		})
+		assert.Nil(t, err, fmt.Sprintf("ScanFrom - after %q", after))
+
+		return keys
+	}
+
+	assert.Equal(t, []string{"job1", "job2"}, chunk(nil), "ScanFrom - first chunk")
+	assert.Equal(t, []string{"job3", "job4"}, chunk([]byte("job2")), "ScanFrom - second chunk")
+
+	// the key resumed from may have been deleted
+	if err := b.Delete([]byte("job4")); err != nil {
+		panic(err)
+	}
+	assert.Equal(t, []string{"job5"}, chunk([]byte("job4")), "ScanFrom - deleted after")
+
+	// after sorting before the prefix starts at the prefix, and past the prefix finds nothing
+	assert.Equal(t, []string{"job1", "job2"}, chunk([]byte("a")), "ScanFrom - after before prefix")
+	assert.Nil(t, chunk([]byte("job5")), "ScanFrom - after end of prefix")
+	assert.Nil(t, chunk([]byte("k")), "ScanFrom - after past prefix")
+
+	// ErrStop is also honoured by Scan and ForEach
+	var n int
+	assert.Nil(t, b.ForEach(func(k, v []byte) error { n++; return ErrStop{} }), "ForEach - ErrStop")
+	assert.Equal(t, 1, n, "ForEach - ErrStop")
+	assert.Nil(t, b.Scan([]byte("job"), func(k, v []byte) error { n++; return ErrStop{} }), "Scan - ErrStop")
+	assert.Equal(t, 2, n, "Scan - ErrStop")
+
+	assert.ErrorIs(t, b.db.ScanFrom(missing, nil, nil, func(k, v []byte) error { return nil }), ErrBucketNotFound{}, "ScanFrom - missing bucket")
+}
+
+func TestPrefixSuccessor(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix []byte
+		want   []byte
+	}{
+		{"nil", nil, nil},
+		{"empty", []byte{}, nil},
+		{"simple", []byte("abc"), []byte("abd")},
+		{"single byte", []byte{0x00}, []byte{0x01}},
+		{"trailing 0xff", []byte{'a', 0xff}, []byte{'b'}},
+		{"multiple trailing 0xff", []byte{'a', 0xfe, 0xff, 0xff}, []byte{'a', 0xff}},
+		{"all 0xff", []byte{0xff, 0xff}, nil},
+		{"inner 0xff", []byte{0xff, 'a'}, []byte{0xff, 'b'}},
+	}
+
+	for _, tt := range tests {
+		prefix := append([]byte(nil), tt.prefix...)
+		assert.Equal(t, tt.want, PrefixSuccessor(tt.prefix), tt.name)
+		assert.Equal(t, prefix, append([]byte(nil), tt.prefix...), tt.name+" - prefix unchanged")
+	}
+}
+
+func TestScanPrefixBoundaries(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	keys := [][]byte{
+		{0x00},
+		[]byte("a"),
+		{'a', 0x00},
+		[]byte("ab"),
+		{'a', 0xff},
+		{'a', 0xff, 0xff},
+		[]byte("b"),
+		{0xff},
+		{0xff, 0x00},
+		{0xff, 0xff},
+		{0xff, 0xff, 0xff},
+	}
+	for _, k := range keys {
+		assert.Nil(t, b.Put(k, testvalue), "Put")
+	}
+
+	tests := []struct {
+		name   string
+		prefix []byte
+		want   [][]byte
+	}{
+		{"empty prefix", []byte{}, keys},
+		{"nil prefix", nil, keys},
+		{"prefix equal to key", []byte("a"), keys[1:6]},
+		{"prefix equal to longer key", []byte("ab"), keys[3:4]},
+		{"trailing 0xff", []byte{'a', 0xff}, keys[4:6]},
+		{"all 0xff", []byte{0xff}, keys[7:]},
+		{"all 0xff longer", []byte{0xff, 0xff}, keys[9:]},
+		{"no match between keys", []byte("aa"), nil},
+		{"no match after keys", []byte{0xff, 0xff, 0xff, 0xff}, nil},
+	}
+
+	for _, tt := range tests {
+		var got [][]byte
+		assert.Nil(t, b.Scan(tt.prefix, func(k, v []byte) error {
+			got = append(got, append([]byte{}, k...))
+			return nil
+		}), tt.name)
+		assert.Equal(t, tt.want, got, tt.name)
+
+		// the successor bounds the same range of keys
+		between, err := b.GetKeysBetween(tt.prefix, PrefixSuccessor(tt.prefix), 0)
+		assert.Nil(t, err, tt.name)
+		assert.Equal(t, tt.want, between, tt.name+" - GetKeysBetween")
+	}
+}
@@ -0,0 +1,100 @@
+package ubolt
+
+import (
+	"context"
+)
+
+// Entry is a key and value returned by ScanChan, where both are copies that remain valid after the scan completes, or written by PutBatch.
+type Entry struct {
+	Key   []byte
+	Value []byte
+}
+
+// ScanChanOptions control the behaviour of ScanChanWithOptions.
+type ScanChanOptions struct {
+	// ChunkSize is the maximum number of entries read in a single transaction. When zero the whole scan runs in one transaction.
+	ChunkSize int
+}
+
+// ScanChan runs Scan in a goroutine and sends a copy of each matching key and value to the returned entry channel, which has the provided
+// buffer size. Both channels are closed once the scan completes, and any error, including the error from ctx when it is cancelled, is sent
+// to the error channel before it is closed.
+//
+// The read-only transaction stays open until every entry has been received or ctx is cancelled, so a slow consumer holds the transaction
+// open for the entire scan. Use ScanChanWithOptions and set a ChunkSize for long scans.
+func (db *Database) ScanChan(ctx context.Context, bucket, prefix []byte, buffer int) (<-chan Entry, <-chan error) {
+	return db.ScanChanWithOptions(ctx, bucket, prefix, buffer, ScanChanOptions{})
+}
+
+// ScanChan runs Scan in a goroutine and sends a copy of each matching key and value to the returned entry channel.
+func (b *Bucket) ScanChan(ctx context.Context, prefix []byte, buffer int) (<-chan Entry, <-chan error) {
+	return b.db.ScanChan(ctx, b.bucket, prefix, buffer)
+}
+
+// ScanChanWithOptions performs the same process as ScanChan. When opts.ChunkSize is set at most that many entries are read per transaction
+// and the scan resumes from the last key sent in a new transaction, so the database is not held open by a slow consumer. Keys added or
+// removed between chunks may or may not be seen.
+func (db *Database) ScanChanWithOptions(ctx context.Context, bucket, prefix []byte, buffer int, opts ScanChanOptions) (<-chan Entry, <-chan error) {
+	entries := make(chan Entry, buffer)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(entries)
+
+		if err := db.scanChan(ctx, bucket, prefix, entries, opts.ChunkSize); err != nil {
+			errs <- err
+		}
+	}()
+
+	return entries, errs
+}
+
+// ScanChanWithOptions performs the same process as ScanChan with the chosen options.
+func (b *Bucket) ScanChanWithOptions(ctx context.Context, prefix []byte, buffer int, opts ScanChanOptions) (<-chan Entry, <-chan error) {
+	return b.db.ScanChanWithOptions(ctx, b.bucket, prefix, buffer, opts)
+}
+
+func (db *Database) scanChan(ctx context.Context, bucket, prefix []byte, entries chan<- Entry, chunkSize int) error {
+	var after []byte
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		n := 0
+		err := db.ScanFrom(bucket, prefix, after, func(k, v []byte) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			e := Entry{Key: append([]byte{}, k...)}
+			if v != nil {
+				e.Value = append([]byte{}, v...)
+			}
+
+			select {
+			case entries <- e:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			after = e.Key
+			n++
+
+			if chunkSize > 0 && n >= chunkSize {
+				return ErrStop{}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if chunkSize <= 0 || n < chunkSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package ubolt
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanChan(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	for i := 0; i < 25; i++ {
+		assert.Nil(t, b.Put([]byte(fmt.Sprintf("a-%02d", i)), []byte{byte(i)}), "Put")
+	}
+	assert.Nil(t, b.Put([]byte("b-00"), testvalue), "Put")
+
+	for _, chunk := range []int{0, 1, 5, 25, 100} {
+		entries, errs := b.ScanChanWithOptions(context.Background(), []byte("a-"), 2, ScanChanOptions{ChunkSize: chunk})
+
+		var keys []string
+		for e := range entries {
+			keys = append(keys, string(e.Key))
+			assert.Equal(t, []byte{byte(len(keys) - 1)}, e.Value, "ScanChan - value")
+		}
+
+		assert.Nil(t, <-errs, "ScanChan - error")
+		assert.Len(t, keys, 25, fmt.Sprintf("ScanChan - chunk %d", chunk))
+		assert.Equal(t, "a-24", keys[24], "ScanChan - order")
+	}
+
+	// cancellation ends the scan and reports the error
+	ctx, cancel := context.WithCancel(context.Background())
+	entries, errs := b.ScanChan(ctx, []byte("a-"), 0)
+	<-entries
+	cancel()
+
+	for range entries {
+	}
+	assert.ErrorIs(t, <-errs, context.Canceled, "ScanChan - cancelled")
+
+	// the write lock is not blocked once the scan has ended
+	assert.Nil(t, b.Put(testkey, testvalue), "Put - after cancel")
+
+	_, errs = b.db.ScanChan(context.Background(), []byte("missing"), nil, 0)
+	assert.ErrorIs(t, <-errs, ErrBucketNotFound{}, "ScanChan - missing bucket")
+}
@@ -0,0 +1,102 @@
+package ubolt
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ScanMultiOptions control the behaviour of ScanMultiWithOptions.
+type ScanMultiOptions struct {
+	// Limit is the maximum number of keys passed to fn for each prefix. When zero or less every matching key is passed.
+	Limit int
+}
+
+// errPrefixDone stops the scan of a prefix once its limit is reached.
+var errPrefixDone = errors.New("prefix limit reached")
+
+// ScanMulti performs the same process as Scan for each of the provided prefixes within a single read-only transaction, so the keys of every
+// prefix are read from the same consistent view of the bucket. Along with each key and value fn is passed the prefix the key matched, as
+// provided by the caller.
+//
+// Prefixes are scanned in ascending order with the keys of each in the same order as Scan. A prefix that begins with another requested
+// prefix, or repeats it, is scanned as part of the shorter prefix so no key is passed to fn more than once. Returning ErrStop from fn stops
+// the entire scan without error.
+func (db *Database) ScanMulti(bucket []byte, prefixes [][]byte, fn func(prefix, k, v []byte) error) error {
+	return db.ScanMultiWithOptions(bucket, prefixes, fn, ScanMultiOptions{})
+}
+
+// ScanMulti performs the same process as Scan for each of the provided prefixes within a single read-only transaction. This is forwarded to
+// the Database implementation.
+func (b *Bucket) ScanMulti(prefixes [][]byte, fn func(prefix, k, v []byte) error) error {
+	return b.db.ScanMulti(b.bucket, prefixes, fn)
+}
+
+// ScanMultiWithOptions performs the same process as ScanMulti with the behaviour controlled by the provided ScanMultiOptions. When a
+// prefix is scanned as part of a shorter prefix the limit of the shorter prefix applies to the keys of both.
+func (db *Database) ScanMultiWithOptions(bucket []byte, prefixes [][]byte, fn func(prefix, k, v []byte) error, opts ScanMultiOptions) error {
+	type scanned struct {
+		prefix, canonical []byte
+	}
+
+	scans := make([]scanned, 0, len(prefixes))
+	for _, p := range prefixes {
+		scans = append(scans, scanned{prefix: p, canonical: db.canonicalKey(p)})
+	}
+
+	// stable so the first of any repeated prefix is kept
+	sort.SliceStable(scans, func(i, j int) bool {
+		return bytes.Compare(scans[i].canonical, scans[j].canonical) < 0
+	})
+
+	// once sorted any prefix covered by another follows it directly
+	covering := scans[:0]
+	for _, s := range scans {
+		if n := len(covering); n > 0 && bytes.HasPrefix(s.canonical, covering[n-1].canonical) {
+			continue
+		}
+
+		covering = append(covering, s)
+	}
+
+	return ignoreStop(db.view(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		c := b.Cursor()
+
+		for _, s := range covering {
+			var n int
+
+			call := db.unwrapFunc(bucket, func(k, v []byte) error {
+				return fn(s.prefix, k, v)
+			})
+
+			if err := scanPrefix(c, s.canonical, func(k, v []byte) error {
+				if err := call(k, v); err != nil {
+					return err
+				}
+
+				if n++; opts.Limit > 0 && n == opts.Limit {
+					return errPrefixDone
+				}
+
+				return nil
+			}); err != nil && !errors.Is(err, errPrefixDone) {
+				return err
+			}
+		}
+
+		return nil
+	}))
+}
+
+// ScanMultiWithOptions performs the same process as ScanMulti with the behaviour controlled by the provided ScanMultiOptions. This is
+// forwarded to the Database implementation.
+func (b *Bucket) ScanMultiWithOptions(prefixes [][]byte, fn func(prefix, k, v []byte) error, opts ScanMultiOptions) error {
+	return b.db.ScanMultiWithOptions(b.bucket, prefixes, fn, opts)
+}
@@ -0,0 +1,70 @@
+package ubolt
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanMulti(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.Nil(t, b.PutAll(map[string][]byte{
+		"a1":   []byte("1"),
+		"a2":   []byte("2"),
+		"ab1":  []byte("3"),
+		"b1":   []byte("4"),
+		"b2":   []byte("5"),
+		"b3":   []byte("6"),
+		"c1":   []byte("7"),
+		"user": []byte("8"),
+	}), "PutAll")
+
+	scan := func(prefixes [][]byte, opts ScanMultiOptions) ([]string, error) {
+		var got []string
+		err := b.ScanMultiWithOptions(prefixes, func(prefix, k, v []byte) error {
+			got = append(got, fmt.Sprintf("%s:%s=%s", prefix, k, v))
+			return nil
+		}, opts)
+		return got, err
+	}
+
+	got, err := scan([][]byte{[]byte("c"), []byte("b")}, ScanMultiOptions{})
+	assert.Nil(t, err, "ScanMulti")
+	assert.Equal(t, []string{"b:b1=4", "b:b2=5", "b:b3=6", "c:c1=7"}, got, "ScanMulti - ordered by prefix")
+
+	// overlapping prefixes never produce duplicates
+	got, err = scan([][]byte{[]byte("ab"), []byte("a"), []byte("a"), []byte("x")}, ScanMultiOptions{})
+	assert.Nil(t, err, "ScanMulti - overlapping")
+	assert.Equal(t, []string{"a:a1=1", "a:a2=2", "a:ab1=3"}, got, "ScanMulti - overlapping")
+
+	got, err = scan([][]byte{[]byte("a"), []byte("b")}, ScanMultiOptions{Limit: 2})
+	assert.Nil(t, err, "ScanMultiWithOptions")
+	assert.Equal(t, []string{"a:a1=1", "a:a2=2", "b:b1=4", "b:b2=5"}, got, "ScanMultiWithOptions - limit per prefix")
+
+	var n int
+	assert.Nil(t, b.ScanMulti([][]byte{[]byte("a"), []byte("b")}, func(prefix, k, v []byte) error {
+		if n++; n == 4 {
+			return ErrStop{}
+		}
+		return nil
+	}), "ScanMulti - stop")
+	assert.Equal(t, 4, n, "ScanMulti - stop")
+
+	assert.ErrorIs(t, b.ScanMulti([][]byte{[]byte("a")}, func(prefix, k, v []byte) error {
+		return assert.AnError
+	}), assert.AnError, "ScanMulti - error")
+
+	assert.ErrorIs(t, b.db.ScanMulti(missing, [][]byte{[]byte("a")}, func(prefix, k, v []byte) error {
+		return nil
+	}), ErrBucketNotFound{}, "ScanMulti - missing bucket")
+}
@@ -0,0 +1,160 @@
+package ubolt
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// SequenceKeyEncoding controls how the auto-incrementing ids used by PutV and PutVID are encoded as keys.
+type SequenceKeyEncoding byte
+
+const (
+	// FixedSequenceKeys encodes ids as a fixed width 8-byte big-endian value. This is the default.
+	FixedSequenceKeys SequenceKeyEncoding = iota
+	// CompactSequenceKeys encodes ids as a single length byte followed by the minimum number of big-endian bytes required.
+	// Keys encoded this way still sort in numeric order.
+	CompactSequenceKeys
+)
+
+var sequenceBucket = []byte("__sequence")
+
+// String returns the name of the encoding.
+func (enc SequenceKeyEncoding) String() string {
+	switch enc {
+	case FixedSequenceKeys:
+		return "fixed"
+	case CompactSequenceKeys:
+		return "compact"
+	}
+
+	return fmt.Sprintf("unknown(%d)", byte(enc))
+}
+
+func (enc SequenceKeyEncoding) encode(id uint64) []byte {
+	if enc != CompactSequenceKeys {
+		return itob(id)
+	}
+
+	buf := itob(id)
+
+	n := 0
+	for n < len(buf) && buf[n] == 0 {
+		n++
+	}
+
+	return append([]byte{byte(len(buf) - n)}, buf[n:]...)
+}
+
+func (enc SequenceKeyEncoding) decode(key []byte) (uint64, error) {
+	if enc != CompactSequenceKeys {
+		if len(key) != 8 {
+			return 0, ErrInvalidSequenceKey{key: key, encoding: enc}
+		}
+
+		return btoi(key), nil
+	}
+
+	if len(key) == 0 || int(key[0]) != len(key)-1 || key[0] > 8 {
+		return 0, ErrInvalidSequenceKey{key: key, encoding: enc}
+	}
+
+	buf := make([]byte, 8)
+	copy(buf[8-len(key[1:]):], key[1:])
+
+	return binary.BigEndian.Uint64(buf), nil
+}
+
+// ErrInvalidSequenceKey is returned when a key could not be decoded as a sequence id.
+type ErrInvalidSequenceKey struct {
+	key      []byte
+	encoding SequenceKeyEncoding
+}
+
+// Error returns the formatted configuration error.
+func (isk ErrInvalidSequenceKey) Error() string {
+	return fmt.Sprintf("Key %x is not a valid %s sequence key", isk.key, isk.encoding)
+}
+
+// Is allows testing using errors.Is
+func (isk ErrInvalidSequenceKey) Is(target error) bool {
+	_, is := target.(ErrInvalidSequenceKey)
+
+	return is
+}
+
+// ErrSequenceKeyEncoding is returned when the configured sequence key encoding differs from the encoding previously used in a bucket.
+type ErrSequenceKeyEncoding struct {
+	bucket     []byte
+	stored     SequenceKeyEncoding
+	configured SequenceKeyEncoding
+}
+
+// Error returns the formatted configuration error.
+func (ske ErrSequenceKeyEncoding) Error() string {
+	return fmt.Sprintf("Bucket %s uses %s sequence keys but %s was requested", string(ske.bucket), ske.stored, ske.configured)
+}
+
+// Is allows testing using errors.Is
+func (ske ErrSequenceKeyEncoding) Is(target error) bool {
+	_, is := target.(ErrSequenceKeyEncoding)
+
+	return is
+}
+
+// IDKey converts a numeric id into a key using the configured sequence key encoding.
+func (db *Database) IDKey(id uint64) []byte {
+	return db.keyEncoding.encode(id)
+}
+
+// IDKey converts a numeric id into a key using the configured sequence key encoding.
+func (b *Bucket) IDKey(id uint64) []byte {
+	return b.db.IDKey(id)
+}
+
+// KeyID converts a key generated by PutV back into its numeric id using the configured sequence key encoding.
+func (db *Database) KeyID(key []byte) (uint64, error) {
+	return db.keyEncoding.decode(key)
+}
+
+// KeyID converts a key generated by PutV back into its numeric id using the configured sequence key encoding.
+func (b *Bucket) KeyID(key []byte) (uint64, error) {
+	return b.db.KeyID(key)
+}
+
+// checkKeyEncoding ensures the configured sequence key encoding matches the marker recorded for the bucket, recording it if none exists.
+//
+// Buckets that have a sequence but no marker were written before markers existed and are assumed to use FixedSequenceKeys.
+func (db *Database) checkKeyEncoding(tx *bolt.Tx, bucket []byte, b *bolt.Bucket) error {
+	if marker := tx.Bucket(sequenceBucket); marker != nil {
+		if v := marker.Get(bucket); len(v) == 1 {
+			if stored := SequenceKeyEncoding(v[0]); stored != db.keyEncoding {
+				return ErrSequenceKeyEncoding{bucket: bucket, stored: stored, configured: db.keyEncoding}
+			}
+
+			return nil
+		}
+	}
+
+	if b.Sequence() > 0 && db.keyEncoding != FixedSequenceKeys {
+		return ErrSequenceKeyEncoding{bucket: bucket, stored: FixedSequenceKeys, configured: db.keyEncoding}
+	}
+
+	marker, err := tx.CreateBucketIfNotExists(sequenceBucket)
+	if err != nil {
+		return err
+	}
+
+	return marker.Put(bucket, []byte{byte(db.keyEncoding)})
+}
+
+// clearKeyEncoding removes any sequence key encoding marker for the bucket.
+func clearKeyEncoding(tx *bolt.Tx, bucket []byte) error {
+	marker := tx.Bucket(sequenceBucket)
+	if marker == nil {
+		return nil
+	}
+
+	return marker.Delete(bucket)
+}
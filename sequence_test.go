@@ -0,0 +1,104 @@
+package ubolt
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSequenceKeyEncoding(t *testing.T) {
+	ids := []uint64{0, 1, 2, 255, 256, 65535, 65536, 1 << 32, 1<<64 - 1}
+
+	for _, enc := range []SequenceKeyEncoding{FixedSequenceKeys, CompactSequenceKeys} {
+		var prev []byte
+
+		for _, id := range ids {
+			key := enc.encode(id)
+
+			got, err := enc.decode(key)
+			assert.Nil(t, err, enc.String())
+			assert.Equal(t, id, got, enc.String())
+
+			// keys must sort in numeric order
+			if prev != nil {
+				assert.Equal(t, -1, bytes.Compare(prev, key), enc.String())
+			}
+			prev = key
+		}
+	}
+
+	assert.Equal(t, []byte{1, 1}, CompactSequenceKeys.encode(1), "compact - 1")
+	assert.Equal(t, 8, len(FixedSequenceKeys.encode(1)), "fixed - 1")
+
+	_, err := CompactSequenceKeys.decode([]byte{3, 1})
+	assert.ErrorIs(t, err, ErrInvalidSequenceKey{}, "compact - invalid")
+
+	_, err = FixedSequenceKeys.decode([]byte{1, 1})
+	assert.ErrorIs(t, err, ErrInvalidSequenceKey{}, "fixed - invalid")
+}
+
+func TestWithSequenceKeyEncoding(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	db, err := Open(testdb, WithSequenceKeyEncoding(CompactSequenceKeys))
+	if err != nil {
+		panic(err)
+	}
+
+	if err := db.CreateBucket(testbucket); err != nil {
+		panic(err)
+	}
+
+	key, err := db.PutV(testbucket, testvalue)
+	assert.Nil(t, err, "PutV - compact")
+	assert.Equal(t, []byte{1, 1}, key, "PutV - compact")
+
+	id, err := db.PutVID(testbucket, testvalue)
+	assert.Nil(t, err, "PutVID - compact")
+	assert.Equal(t, uint64(2), id, "PutVID - compact")
+
+	got, err := db.GetID(testbucket, id)
+	assert.Nil(t, err, "GetID - compact")
+	assert.Equal(t, testvalue, got, "GetID - compact")
+
+	got, err = db.GetE(testbucket, db.IDKey(id))
+	assert.Nil(t, err, "IDKey - compact")
+	assert.Equal(t, testvalue, got, "IDKey - compact")
+
+	id, err = db.KeyID(key)
+	assert.Nil(t, err, "KeyID - compact")
+	assert.Equal(t, uint64(1), id, "KeyID - compact")
+
+	// marker bucket is hidden
+	assert.Equal(t, [][]byte{testbucket}, db.GetBuckets(), "GetBuckets - compact")
+
+	if err := db.Close(); err != nil {
+		panic(err)
+	}
+
+	// reopening with the default encoding must be rejected for the same bucket
+	db, err = Open(testdb)
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	_, err = db.PutV(testbucket, testvalue)
+	assert.ErrorIs(t, err, ErrSequenceKeyEncoding{}, "PutV - mixed encoding")
+
+	// recreating the bucket clears the marker
+	if err := db.DeleteBucket(testbucket); err != nil {
+		panic(err)
+	}
+
+	if err := db.CreateBucket(testbucket); err != nil {
+		panic(err)
+	}
+
+	key, err = db.PutV(testbucket, testvalue)
+	assert.Nil(t, err, "PutV - after recreate")
+	assert.Equal(t, itob(1), key, "PutV - after recreate")
+}
@@ -0,0 +1,147 @@
+package ubolt
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrTooManyBytes is returned when the keys and values of a bucket exceed the size limit requested.
+type ErrTooManyBytes struct {
+	bucket []byte
+	limit  int64
+}
+
+// Error returns the formatted configuration error.
+func (tmb ErrTooManyBytes) Error() string {
+	return fmt.Sprintf("Bucket %s contains more than %d bytes", string(tmb.bucket), tmb.limit)
+}
+
+// Is allows testing using errors.Is
+func (tmb ErrTooManyBytes) Is(target error) bool {
+	_, is := target.(ErrTooManyBytes)
+
+	return is
+}
+
+// Snapshot is an immutable point-in-time copy of the keys and values of a bucket that may be read without holding a transaction.
+//
+// Keys and values passed to ForEach and Scan callbacks are shared with the snapshot and must not be modified.
+type Snapshot struct {
+	keys   [][]byte
+	values [][]byte
+}
+
+// Snapshot copies all keys and values of the chosen bucket into memory inside a single read-only transaction.
+// Nested buckets are not included.
+func (db *Database) Snapshot(bucket []byte) (*Snapshot, error) {
+	return db.SnapshotWithLimit(bucket, 0, 0)
+}
+
+// Snapshot copies all keys and values of the bucket into memory inside a single read-only transaction.
+func (b *Bucket) Snapshot() (*Snapshot, error) {
+	return b.db.Snapshot(b.bucket)
+}
+
+// SnapshotWithLimit performs the same process as Snapshot however ErrTooManyKeys or ErrTooManyBytes is returned if the bucket contains more
+// than maxKeys keys or more than maxBytes bytes of keys and values. A limit of zero or less means no limit.
+func (db *Database) SnapshotWithLimit(bucket []byte, maxKeys int, maxBytes int64) (*Snapshot, error) {
+	s := &Snapshot{}
+
+	if err := db.view(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		var size int64
+
+		return b.ForEach(db.unwrapFunc(bucket, func(k, v []byte) error {
+			// skip nested buckets
+			if v == nil {
+				return nil
+			}
+
+			if maxKeys > 0 && len(s.keys) >= maxKeys {
+				return ErrTooManyKeys{bucket: bucket, limit: maxKeys}
+			}
+
+			size += int64(len(k) + len(v))
+			if maxBytes > 0 && size > maxBytes {
+				return ErrTooManyBytes{bucket: bucket, limit: maxBytes}
+			}
+
+			s.keys = append(s.keys, append([]byte{}, k...))
+			s.values = append(s.values, append([]byte{}, v...))
+
+			return nil
+		}))
+	}); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// SnapshotWithLimit performs the same process as Snapshot however ErrTooManyKeys or ErrTooManyBytes is returned if the bucket is larger than the limits.
+func (b *Bucket) SnapshotWithLimit(maxKeys int, maxBytes int64) (*Snapshot, error) {
+	return b.db.SnapshotWithLimit(b.bucket, maxKeys, maxBytes)
+}
+
+// Len returns the number of keys in the snapshot.
+func (s *Snapshot) Len() int {
+	return len(s.keys)
+}
+
+// Get returns a copy of the value of the specified key, or nil if the key was not found.
+func (s *Snapshot) Get(key []byte) []byte {
+	i := sort.Search(len(s.keys), func(i int) bool {
+		return bytes.Compare(s.keys[i], key) >= 0
+	})
+
+	if i < len(s.keys) && bytes.Equal(s.keys[i], key) {
+		return append([]byte{}, s.values[i]...)
+	}
+
+	return nil
+}
+
+// Keys returns a copy of every key in the snapshot in sorted order.
+func (s *Snapshot) Keys() [][]byte {
+	keys := make([][]byte, len(s.keys))
+	for i, k := range s.keys {
+		keys[i] = append([]byte{}, k...)
+	}
+
+	return keys
+}
+
+// ForEach calls fn for every key in the snapshot in sorted order, stopping at the first error returned by fn.
+func (s *Snapshot) ForEach(fn func(k, v []byte) error) error {
+	for i := range s.keys {
+		if err := fn(s.keys[i], s.values[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Scan calls fn for every key in the snapshot that begins with prefix in sorted order, stopping at the first error returned by fn.
+func (s *Snapshot) Scan(prefix []byte, fn func(k, v []byte) error) error {
+	i := sort.Search(len(s.keys), func(i int) bool {
+		return bytes.Compare(s.keys[i], prefix) >= 0
+	})
+
+	end := PrefixSuccessor(prefix)
+
+	for ; i < len(s.keys) && (end == nil || bytes.Compare(s.keys[i], end) < 0); i++ {
+		if err := fn(s.keys[i], s.values[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package ubolt
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	for _, k := range []string{"a:1", "a:2", "b:1"} {
+		if err := b.Put([]byte(k), []byte("v"+k)); err != nil {
+			panic(err)
+		}
+	}
+
+	_, err = b.db.Snapshot(missing)
+	assert.ErrorIs(t, err, ErrBucketNotFound{}, "Snapshot - missing bucket")
+
+	s, err := b.Snapshot()
+	assert.Nil(t, err, "Snapshot")
+
+	// changes after the snapshot are not visible
+	if err := b.Put([]byte("a:3"), []byte("va:3")); err != nil {
+		panic(err)
+	}
+
+	assert.Equal(t, 3, s.Len(), "Len")
+	assert.Equal(t, []byte("va:1"), s.Get([]byte("a:1")), "Get")
+	assert.Nil(t, s.Get([]byte("a:3")), "Get - after snapshot")
+	assert.Nil(t, s.Get(missing), "Get - missing")
+	assert.Equal(t, [][]byte{[]byte("a:1"), []byte("a:2"), []byte("b:1")}, s.Keys(), "Keys")
+
+	// returned values are copies
+	s.Get([]byte("a:1"))[0] = 'X'
+	assert.Equal(t, []byte("va:1"), s.Get([]byte("a:1")), "Get - copy")
+
+	got := make([]string, 0)
+	_ = s.Scan([]byte("a:"), func(k, v []byte) error {
+		got = append(got, fmt.Sprintf("k=%s;v=%s", k, v))
+		return nil
+	})
+	assert.Equal(t, "k=a:1;v=va:1:k=a:2;v=va:2", strings.Join(got, ":"), "Scan")
+
+	got = make([]string, 0)
+	_ = s.ForEach(func(k, v []byte) error {
+		got = append(got, string(k))
+		return nil
+	})
+	assert.Equal(t, "a:1,a:2,b:1", strings.Join(got, ","), "ForEach")
+
+	_, err = b.SnapshotWithLimit(3, 0)
+	assert.ErrorIs(t, err, ErrTooManyKeys{}, "SnapshotWithLimit - keys")
+
+	_, err = b.SnapshotWithLimit(0, 10)
+	assert.ErrorIs(t, err, ErrTooManyBytes{}, "SnapshotWithLimit - bytes")
+
+	s, err = b.SnapshotWithLimit(4, 100)
+	assert.Nil(t, err, "SnapshotWithLimit - within limits")
+	assert.Equal(t, 4, s.Len(), "SnapshotWithLimit - within limits")
+}
@@ -0,0 +1,265 @@
+package ubolt
+
+import (
+	bolt "go.etcd.io/bbolt"
+)
+
+// Path returns the path to the currently open database file.
+func (db *Database) Path() string {
+	return db.bdb().Path()
+}
+
+// Path returns the path to the currently open database file.
+func (b *Bucket) Path() string {
+	return b.db.Path()
+}
+
+// Size returns the size in bytes of the database file on disk.
+func (db *Database) Size() (int64, error) {
+	info, err := db.fs.Stat(db.Path())
+	if err != nil {
+		return 0, err
+	}
+
+	return info.Size(), nil
+}
+
+// Size returns the size in bytes of the database file on disk.
+func (b *Bucket) Size() (int64, error) {
+	return b.db.Size()
+}
+
+// SizeBreakdown returns the number of bytes of the database that are in use and the number of bytes held in free pages that may be reclaimed by compaction.
+func (db *Database) SizeBreakdown() (used, free int64, err error) {
+	if err := db.view(func(tx *bolt.Tx) error {
+		free = int64(db.bdb().Stats().FreeAlloc)
+		used = tx.Size() - free
+
+		return nil
+	}); err != nil {
+		return 0, 0, err
+	}
+
+	return used, free, nil
+}
+
+// SizeBreakdown returns the number of bytes of the database that are in use and the number of bytes held in free pages that may be reclaimed by compaction.
+func (b *Bucket) SizeBreakdown() (used, free int64, err error) {
+	return b.db.SizeBreakdown()
+}
+
+// PageStats reports page and freelist utilisation of the database.
+type PageStats struct {
+	// PageSize is the size in bytes of each page.
+	PageSize int
+	// Size is the size in bytes of the database up to its high water mark.
+	Size int64
+	// TotalPageN is the number of pages up to the high water mark.
+	TotalPageN int64
+	// FreePageN is the number of free pages on the freelist.
+	FreePageN int
+	// PendingPageN is the number of pages that will be freed once no open transactions reference them.
+	PendingPageN int
+	// FreeAlloc is the number of bytes allocated in free pages.
+	FreeAlloc int
+	// FreelistInuse is the number of bytes used by the freelist itself.
+	FreelistInuse int
+	// PageCount is the cumulative number of page allocations made by write transactions.
+	PageCount int64
+	// PageAlloc is the cumulative number of bytes allocated by write transactions.
+	PageAlloc int64
+}
+
+// FragmentationRatio returns the fraction of the database, between 0 and 1, that is held in free pages and may be reclaimed by compaction.
+func (ps PageStats) FragmentationRatio() float64 {
+	if ps.Size <= 0 {
+		return 0
+	}
+
+	return float64(ps.FreeAlloc) / float64(ps.Size)
+}
+
+// PageStats returns the page and freelist utilisation of the database, gathered inside a single read-only transaction.
+func (db *Database) PageStats() (PageStats, error) {
+	var ps PageStats
+
+	if err := db.view(func(tx *bolt.Tx) error {
+		ps = pageStats(tx)
+
+		return nil
+	}); err != nil {
+		return PageStats{}, err
+	}
+
+	return ps, nil
+}
+
+// pageStats returns the page and freelist utilisation of the database as seen by tx.
+func pageStats(tx *bolt.Tx) PageStats {
+	stats := tx.DB().Stats()
+
+	ps := PageStats{
+		PageSize:      tx.DB().Info().PageSize,
+		Size:          tx.Size(),
+		FreePageN:     stats.FreePageN,
+		PendingPageN:  stats.PendingPageN,
+		FreeAlloc:     stats.FreeAlloc,
+		FreelistInuse: stats.FreelistInuse,
+		PageCount:     stats.TxStats.PageCount,
+		PageAlloc:     stats.TxStats.PageAlloc,
+	}
+
+	if ps.PageSize > 0 {
+		ps.TotalPageN = ps.Size / int64(ps.PageSize)
+	}
+
+	return ps
+}
+
+// PageStats returns the page and freelist utilisation of the database. This is forwarded to the Database implementation.
+func (b *Bucket) PageStats() (PageStats, error) {
+	return b.db.PageStats()
+}
+
+// BucketSummary describes a single top-level bucket as returned by Overview.
+type BucketSummary struct {
+	// Name is the name of the bucket.
+	Name string
+	// KeyCount is the number of keys in the bucket, including those held in any nested buckets.
+	KeyCount int
+	// LeafBytes is the number of bytes in use by leaf pages, including buckets stored inline in their parent.
+	LeafBytes int
+	// BranchBytes is the number of bytes in use by branch pages.
+	BranchBytes int
+	// HasNested is true when the bucket contains one or more nested buckets.
+	HasNested bool
+}
+
+// Overview returns a summary of every bucket in the database ordered by name, gathered inside a single read-only transaction so the figures
+// all correspond to the same snapshot. Reserved buckets are excluded.
+func (db *Database) Overview() (overview []BucketSummary, err error) {
+	if err := db.view(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			if isReserved(name) {
+				return nil
+			}
+
+			overview = append(overview, bucketSummary(string(name), b))
+
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	return overview, nil
+}
+
+// bucketSummary returns the summary of the bucket reported under name.
+func bucketSummary(name string, b *bolt.Bucket) BucketSummary {
+	stats := b.Stats()
+
+	return BucketSummary{
+		Name:        name,
+		KeyCount:    stats.KeyN,
+		LeafBytes:   stats.LeafInuse + stats.InlineBucketInuse,
+		BranchBytes: stats.BranchInuse,
+		// BucketN includes the bucket itself
+		HasNested: stats.BucketN > 1,
+	}
+}
+
+// MemStats reports the memory used by the database, as returned by MemoryStats.
+type MemStats struct {
+	// MmapSize is the size in bytes of the memory map of the database file, calculated from the file size using the same sizing rules bolt
+	// applies when mapping the file.
+	MmapSize int64
+	// DataSize is the size in bytes of the database up to its high water mark.
+	DataSize int64
+	// FileSize is the size in bytes of the database file on disk.
+	FileSize int64
+	// PageSize is the size in bytes of each page.
+	PageSize int
+	// OpenTxN is the number of read-only transactions currently open.
+	OpenTxN int
+	// TxN is the cumulative number of read-only transactions started.
+	TxN int
+	// LastWriteDirtyPages is the number of pages allocated by the most recently committed write transaction.
+	LastWriteDirtyPages int64
+	// LastWriteDirtyBytes is the number of bytes allocated by the most recently committed write transaction.
+	LastWriteDirtyBytes int64
+	// PageCount is the cumulative number of page allocations made by write transactions.
+	PageCount int64
+	// PageAlloc is the cumulative number of bytes allocated by write transactions.
+	PageAlloc int64
+	// FreeAlloc is the number of bytes allocated in free pages.
+	FreeAlloc int
+	// FreelistInuse is the number of bytes used by the freelist itself.
+	FreelistInuse int
+}
+
+// MemoryStats returns the memory map size, file size and page allocation counters of the database, gathered inside a single read-only
+// transaction. This is cheap enough to be polled by a metrics exporter, so no error is returned: a closed database reports a zero MemStats
+// and a FileSize of zero if the file can not be read, in which case MmapSize is calculated from the DataSize instead.
+func (db *Database) MemoryStats() MemStats {
+	var ms MemStats
+
+	if err := db.view(func(tx *bolt.Tx) error {
+		bdb := tx.DB()
+		stats := bdb.Stats()
+
+		ms = MemStats{
+			DataSize:      tx.Size(),
+			PageSize:      bdb.Info().PageSize,
+			OpenTxN:       stats.OpenTxN,
+			TxN:           stats.TxN,
+			PageCount:     stats.TxStats.GetPageCount(),
+			PageAlloc:     stats.TxStats.GetPageAlloc(),
+			FreeAlloc:     stats.FreeAlloc,
+			FreelistInuse: stats.FreelistInuse,
+		}
+
+		if info, err := db.fs.Stat(bdb.Path()); err == nil {
+			ms.FileSize = info.Size()
+		}
+
+		ms.MmapSize = mmapSize(max(ms.FileSize, ms.DataSize, int64(db.boltOptions.InitialMmapSize)), int64(ms.PageSize))
+
+		if last := db.lastWrite.Load(); last != nil {
+			ms.LastWriteDirtyPages = last.GetPageCount()
+			ms.LastWriteDirtyBytes = last.GetPageAlloc()
+		}
+
+		return nil
+	}); err != nil {
+		return MemStats{}
+	}
+
+	return ms
+}
+
+// MemoryStats returns the memory map size, file size and page allocation counters of the database. This is forwarded to the Database
+// implementation.
+func (b *Bucket) MemoryStats() MemStats {
+	return b.db.MemoryStats()
+}
+
+// mmapSize returns the size bolt maps for a file of the provided size, which doubles from 32KB up to 1GB then grows in 1GB steps.
+func mmapSize(size, pageSize int64) int64 {
+	for i := uint(15); i <= 30; i++ {
+		if size <= 1<<i {
+			return 1 << i
+		}
+	}
+
+	const step = 1 << 30
+	if remainder := size % step; remainder > 0 {
+		size += step - remainder
+	}
+
+	if pageSize > 0 && size%pageSize != 0 {
+		size = (size/pageSize + 1) * pageSize
+	}
+
+	return size
+}
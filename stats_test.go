@@ -0,0 +1,195 @@
+package ubolt
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestSize(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.Equal(t, testdb, b.Path(), "Path")
+
+	info, err := os.Stat(testdb)
+	if err != nil {
+		panic(err)
+	}
+
+	size, err := b.Size()
+	assert.Nil(t, err, "Size")
+	assert.Equal(t, info.Size(), size, "Size")
+
+	// write then delete some data so there are free pages
+	for i := 0; i < 1000; i++ {
+		if err := b.Put([]byte(fmt.Sprintf("key%04d", i)), make([]byte, 512)); err != nil {
+			panic(err)
+		}
+	}
+
+	if err := b.db.DeleteBucket(testbucket); err != nil {
+		panic(err)
+	}
+
+	used, free, err := b.SizeBreakdown()
+	assert.Nil(t, err, "SizeBreakdown")
+	assert.Greater(t, used, int64(0), "SizeBreakdown - used")
+	assert.Greater(t, free, int64(0), "SizeBreakdown - free")
+
+	size, err = b.Size()
+	assert.Nil(t, err, "Size")
+	assert.LessOrEqual(t, used+free, size, "SizeBreakdown - total")
+
+	// a closed database must return an error
+	if err := b.Close(); err != nil {
+		panic(err)
+	}
+
+	_, _, err = b.SizeBreakdown()
+	assert.NotNil(t, err, "SizeBreakdown - closed")
+}
+
+func TestPageStats(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	ps, err := b.PageStats()
+	assert.Nil(t, err, "PageStats")
+	assert.Equal(t, os.Getpagesize(), ps.PageSize, "PageStats - page size")
+	assert.Equal(t, ps.Size/int64(ps.PageSize), ps.TotalPageN, "PageStats - total pages")
+
+	before := ps.FragmentationRatio()
+
+	// write then delete some data so there are free pages
+	for i := 0; i < 1000; i++ {
+		if err := b.Put([]byte(fmt.Sprintf("key%04d", i)), make([]byte, 512)); err != nil {
+			panic(err)
+		}
+	}
+
+	if err := b.db.DeleteBucket(testbucket); err != nil {
+		panic(err)
+	}
+
+	ps, err = b.PageStats()
+	assert.Nil(t, err, "PageStats")
+	assert.Greater(t, ps.FreePageN, 0, "PageStats - free pages")
+	assert.Greater(t, ps.PageCount, int64(0), "PageStats - page count")
+	assert.Greater(t, ps.FragmentationRatio(), before, "PageStats - fragmentation")
+	assert.LessOrEqual(t, ps.FragmentationRatio(), 1.0, "PageStats - fragmentation")
+
+	_, err = json.Marshal(ps)
+	assert.Nil(t, err, "PageStats - json")
+
+	assert.Equal(t, 0.0, PageStats{}.FragmentationRatio(), "FragmentationRatio - empty")
+}
+
+func TestOverview(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	db, err := Open(testdb, WithAuditLog(nil))
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	if err := db.CreateBucket(testbucket); err != nil {
+		panic(err)
+	}
+	for i := 0; i < 100; i++ {
+		if err := db.Put(testbucket, []byte(fmt.Sprintf("key%04d", i)), make([]byte, 128)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a small bucket with a nested bucket
+	if err := db.CreateBucket([]byte("nested")); err != nil {
+		panic(err)
+	}
+	if err := db.bdb().Update(func(tx *bolt.Tx) error {
+		_, err := tx.Bucket([]byte("nested")).CreateBucket([]byte("child"))
+		return err
+	}); err != nil {
+		panic(err)
+	}
+
+	overview, err := db.Overview()
+	assert.Nil(t, err, "Overview")
+	assert.Len(t, overview, 2, "Overview - reserved buckets excluded")
+
+	assert.Equal(t, string(testbucket), overview[0].Name, "Overview - name")
+	assert.Equal(t, 100, overview[0].KeyCount, "Overview - key count")
+	assert.Greater(t, overview[0].LeafBytes, 100*128, "Overview - leaf bytes")
+	assert.False(t, overview[0].HasNested, "Overview - no nested")
+
+	assert.Equal(t, "nested", overview[1].Name, "Overview - name")
+	assert.Greater(t, overview[1].LeafBytes, 0, "Overview - inline leaf bytes")
+	assert.True(t, overview[1].HasNested, "Overview - nested")
+
+	_, err = json.Marshal(overview)
+	assert.Nil(t, err, "Overview - json")
+}
+
+func TestMemoryStats(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	ms := b.MemoryStats()
+	assert.Equal(t, os.Getpagesize(), ms.PageSize, "MemoryStats - page size")
+	assert.Equal(t, int64(32768), ms.MmapSize, "MemoryStats - initial mmap size")
+	assert.Equal(t, 1, ms.OpenTxN, "MemoryStats - open transactions")
+
+	for i := 0; i < 1000; i++ {
+		if err := b.Put([]byte(fmt.Sprintf("key%04d", i)), make([]byte, 512)); err != nil {
+			panic(err)
+		}
+	}
+
+	ms = b.MemoryStats()
+
+	size, err := b.Size()
+	assert.Nil(t, err, "Size")
+	assert.Equal(t, size, ms.FileSize, "MemoryStats - file size")
+	assert.GreaterOrEqual(t, ms.MmapSize, ms.FileSize, "MemoryStats - mmap covers file")
+	assert.GreaterOrEqual(t, ms.FileSize, ms.DataSize, "MemoryStats - data within file")
+	assert.Greater(t, ms.LastWriteDirtyPages, int64(0), "MemoryStats - last write pages")
+	assert.Greater(t, ms.LastWriteDirtyBytes, int64(0), "MemoryStats - last write bytes")
+	assert.Greater(t, ms.PageAlloc, ms.LastWriteDirtyBytes, "MemoryStats - cumulative allocations")
+
+	_, err = json.Marshal(ms)
+	assert.Nil(t, err, "MemoryStats - json")
+
+	assert.Nil(t, b.Close(), "Close")
+	assert.Equal(t, MemStats{}, b.MemoryStats(), "MemoryStats - closed")
+}
+
+func TestMmapSize(t *testing.T) {
+	assert.Equal(t, int64(1<<15), mmapSize(0, 4096), "mmapSize - minimum")
+	assert.Equal(t, int64(1<<16), mmapSize(1<<15+1, 4096), "mmapSize - doubling")
+	assert.Equal(t, int64(1<<30), mmapSize(1<<30, 4096), "mmapSize - 1GB")
+	assert.Equal(t, int64(2<<30), mmapSize(1<<30+1, 4096), "mmapSize - 1GB steps")
+}
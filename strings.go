@@ -0,0 +1,29 @@
+package ubolt
+
+// SPut performs the same process as Put using a string key.
+func (b *Bucket) SPut(key string, value []byte) error {
+	return b.Put([]byte(key), value)
+}
+
+// SGetE performs the same process as GetE using a string key.
+func (b *Bucket) SGetE(key string) (value []byte, err error) {
+	return b.GetE([]byte(key))
+}
+
+// SGet performs the same process as Get using a string key. The value returned may be nil which indicates the key was not found.
+func (b *Bucket) SGet(key string) (value []byte) {
+	return b.Get([]byte(key))
+}
+
+// SDelete performs the same process as Delete using a string key.
+func (b *Bucket) SDelete(key string) error {
+	return b.Delete([]byte(key))
+}
+
+// SScan performs the same process as Scan using a string prefix, with each key passed to fn as a string. Unlike the key, v is only valid
+// until fn returns.
+func (b *Bucket) SScan(prefix string, fn func(key string, v []byte) error) error {
+	return b.Scan([]byte(prefix), func(k, v []byte) error {
+		return fn(string(k), v)
+	})
+}
@@ -0,0 +1,41 @@
+package ubolt
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringKeys(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.Nil(t, b.SPut("user:1", []byte("alice")), "SPut")
+	assert.Nil(t, b.SPut("user:2", []byte("bob")), "SPut")
+	assert.Nil(t, b.SPut("group:1", []byte("admins")), "SPut")
+
+	v, err := b.SGetE("user:1")
+	assert.Nil(t, err, "SGetE")
+	assert.Equal(t, []byte("alice"), v, "SGetE")
+	assert.Equal(t, []byte("bob"), b.SGet("user:2"), "SGet")
+
+	_, err = b.SGetE("user:3")
+	assert.ErrorIs(t, err, ErrKeyNotFound{}, "SGetE - missing")
+
+	var keys []string
+	assert.Nil(t, b.SScan("user:", func(key string, v []byte) error {
+		keys = append(keys, key)
+		return nil
+	}), "SScan")
+	assert.Equal(t, []string{"user:1", "user:2"}, keys, "SScan")
+
+	assert.Nil(t, b.SDelete("user:1"), "SDelete")
+	assert.Nil(t, b.SGet("user:1"), "SGet - deleted")
+}
@@ -0,0 +1,369 @@
+package ubolt
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrMissingField is returned by LoadStruct when the key of a field tagged as required does not exist.
+type ErrMissingField struct {
+	bucket []byte
+	key    []byte
+	field  string
+}
+
+// Error returns the formatted configuration error.
+func (mf ErrMissingField) Error() string {
+	return fmt.Sprintf("Key %s in bucket %s for required field %s was not found", string(mf.key), bucketName(mf.bucket), mf.field)
+}
+
+// Is allows testing using errors.Is
+func (mf ErrMissingField) Is(target error) bool {
+	_, is := target.(ErrMissingField)
+
+	return is
+}
+
+// Field returns the name of the struct field whose key was not found.
+func (mf ErrMissingField) Field() string {
+	return mf.field
+}
+
+var errNotStruct = errors.New("value is not a struct or pointer to a struct")
+
+// SaveStruct writes each exported field of v, which must be a struct or a non-nil pointer to a struct, to its own key in the chosen bucket
+// so fields may be read and updated independently. The bucket is created if it does not exist and every field is written in a single
+// read/write transaction.
+//
+// Each field is stored under its name unless renamed using a struct tag such as `ubolt:"keyname"`, and fields tagged `ubolt:"-"` are
+// skipped. Strings, byte slices, booleans, integers, floats, time.Duration and types implementing encoding.TextMarshaler, which includes
+// time.Time, are stored in a human-readable text form. Other fields are stored using the configured Codec as per Encode, except nil
+// pointers, maps, slices and interfaces, whose keys are deleted. ErrNotEncodable is returned if v or a field can not be encoded.
+func (db *Database) SaveStruct(bucket []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return ErrNotEncodable{bucket: bucket, typ: fmt.Sprintf("%T", v), err: errNotStruct}
+	}
+
+	fields := structFields(rv.Type())
+	values := make([][]byte, len(fields))
+
+	for i, f := range fields {
+		fv := rv.Field(f.index)
+
+		switch fv.Kind() {
+		case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+			if fv.IsNil() {
+				continue
+			}
+		}
+
+		data, text, err := formatField(fv)
+		if err != nil {
+			return ErrNotEncodable{bucket: bucket, key: f.key, typ: fv.Type().String(), err: err}
+		}
+
+		if !text {
+			if data, err = db.encode(bucket, f.key, fv.Interface()); err != nil {
+				return err
+			}
+		} else if data == nil {
+			// nil marks a key to delete so empty text is stored as an empty value
+			data = []byte{}
+		}
+
+		values[i] = data
+	}
+
+	return db.update(func(tx *bolt.Tx) error {
+		b, err := createBucketPath(tx, bucket)
+		if err != nil {
+			return err
+		}
+
+		for i, f := range fields {
+			key := db.canonicalKey(f.key)
+			prev := previous(tx, bucket, b, key)
+
+			if values[i] == nil {
+				if err := b.Delete(key); err != nil {
+					return err
+				}
+
+				if err := db.onMutation(tx, mutation{op: OpDelete, bucket: bucket, key: key, prev: prev}); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			op := OpPut
+			if !f.text {
+				op = OpEncode
+			}
+
+			value, err := db.wrapValue(bucket, key, values[i])
+			if err != nil {
+				return err
+			}
+
+			if err := b.Put(key, value); err != nil {
+				return err
+			}
+
+			if err := db.onMutation(tx, mutation{op: op, bucket: bucket, key: key, value: value, prev: prev}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// SaveStruct writes each exported field of v to its own key. This is forwarded to the Database implementation.
+func (b *Bucket) SaveStruct(v interface{}) error {
+	return b.db.SaveStruct(b.bucket, v)
+}
+
+// LoadStruct reads the keys written by SaveStruct from the chosen bucket into the fields of v, which must be a non-nil pointer to a struct,
+// within a single read-only transaction. Fields whose key does not exist are left unchanged, so hold their zero value when v is new,
+// unless tagged with the required option such as `ubolt:"keyname,required"` in which case ErrMissingField is returned.
+//
+// ErrInvalidDestination is returned if v is not a non-nil pointer to a struct, ErrBucketNotFound if the bucket does not exist and ErrDecode
+// if a stored value can not be decoded into its field. Fields may be left partially loaded when an error is returned.
+func (db *Database) LoadStruct(bucket []byte, v interface{}) error {
+	if err := checkDestination(v); err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v).Elem()
+	if rv.Kind() != reflect.Struct {
+		return ErrInvalidDestination{typ: fmt.Sprintf("%T", v)}
+	}
+
+	return db.ViewMany(func(get func(bucket, key []byte) ([]byte, error)) error {
+		for _, f := range structFields(rv.Type()) {
+			data, err := get(bucket, f.key)
+			if errors.Is(err, ErrKeyNotFound{}) {
+				if f.required {
+					return ErrMissingField{bucket: bucket, key: f.key, field: f.name}
+				}
+
+				continue
+			}
+
+			if err != nil {
+				return err
+			}
+
+			fv := rv.Field(f.index)
+
+			if f.text {
+				err = parseField(fv, data)
+			} else {
+				err = db.safeUnmarshal(data, fv.Addr().Interface())
+			}
+
+			if err != nil {
+				return ErrDecode{bucket: bucket, key: f.key, err: err}
+			}
+		}
+
+		return nil
+	})
+}
+
+// LoadStruct reads the keys written by SaveStruct into the fields of v. This is forwarded to the Database implementation.
+func (b *Bucket) LoadStruct(v interface{}) error {
+	return b.db.LoadStruct(b.bucket, v)
+}
+
+// structField is an exported struct field saved by SaveStruct.
+type structField struct {
+	name     string
+	key      []byte
+	index    int
+	required bool
+	// text is true when the field is stored in a human-readable form rather than using the Codec
+	text bool
+}
+
+var structFieldsCache sync.Map
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// structFields returns the fields of the struct type t that are saved by SaveStruct.
+func structFields(t reflect.Type) []structField {
+	if cached, ok := structFieldsCache.Load(t); ok {
+		return cached.([]structField)
+	}
+
+	var fields []structField
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		field := structField{name: sf.Name, key: []byte(sf.Name), index: i, text: isTextType(sf.Type)}
+
+		if value, ok := sf.Tag.Lookup("ubolt"); ok {
+			if value == "-" {
+				continue
+			}
+
+			name, opts, _ := strings.Cut(value, ",")
+			if name != "" {
+				field.key = []byte(name)
+			}
+
+			for _, opt := range strings.Split(opts, ",") {
+				if opt == "required" {
+					field.required = true
+				}
+			}
+		}
+
+		fields = append(fields, field)
+	}
+
+	cached, _ := structFieldsCache.LoadOrStore(t, fields)
+
+	return cached.([]structField)
+}
+
+// isTextType returns true when values of type t are stored in a human-readable form.
+func isTextType(t reflect.Type) bool {
+	if t == durationType {
+		return true
+	}
+
+	if isTextMarshaler(t) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8
+	}
+
+	return false
+}
+
+// isTextMarshaler returns true when type t implements encoding.TextMarshaler and a pointer to t implements encoding.TextUnmarshaler.
+func isTextMarshaler(t reflect.Type) bool {
+	return t.Implements(textMarshalerType) && reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// formatField returns the human-readable form of v, with text false when v is stored using the Codec instead.
+func formatField(v reflect.Value) (data []byte, text bool, err error) {
+	t := v.Type()
+
+	if !isTextType(t) {
+		return nil, false, nil
+	}
+
+	if t == durationType {
+		return []byte(time.Duration(v.Int()).String()), true, nil
+	}
+
+	if isTextMarshaler(t) {
+		data, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return data, true, err
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return []byte(v.String()), true, nil
+	case reflect.Bool:
+		return strconv.AppendBool(nil, v.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.AppendInt(nil, v.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.AppendUint(nil, v.Uint(), 10), true, nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.AppendFloat(nil, v.Float(), 'g', -1, t.Bits()), true, nil
+	}
+
+	// a non-nil byte slice, which is copied so it may be stored
+	return append([]byte{}, v.Bytes()...), true, nil
+}
+
+// parseField stores the human-readable form written by formatField in v.
+func parseField(v reflect.Value, data []byte) error {
+	t := v.Type()
+
+	if t == durationType {
+		d, err := time.ParseDuration(string(data))
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(int64(d))
+
+		return nil
+	}
+
+	if isTextMarshaler(t) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(data)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(string(data))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(string(data))
+		if err != nil {
+			return err
+		}
+
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(string(data), 10, t.Bits())
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(string(data), 10, t.Bits())
+		if err != nil {
+			return err
+		}
+
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(string(data), t.Bits())
+		if err != nil {
+			return err
+		}
+
+		v.SetFloat(f)
+	case reflect.Slice:
+		v.SetBytes(append([]byte{}, data...))
+	}
+
+	return nil
+}
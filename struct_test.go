@@ -0,0 +1,111 @@
+package ubolt
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testSettings struct {
+	Name     string `ubolt:"name"`
+	Enabled  bool   `ubolt:"enabled"`
+	Retries  int    `ubolt:"retries,required"`
+	Limit    uint16
+	Ratio    float64
+	Timeout  time.Duration
+	Started  time.Time
+	Secret   []byte
+	Tags     []string
+	Labels   map[string]string
+	Ignored  string `ubolt:"-"`
+	internal string
+}
+
+func TestSaveStruct(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	started := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	settings := testSettings{
+		Name:     "example",
+		Enabled:  true,
+		Retries:  3,
+		Limit:    65535,
+		Ratio:    0.25,
+		Timeout:  90 * time.Second,
+		Started:  started,
+		Secret:   []byte("secret"),
+		Tags:     []string{"a", "b"},
+		Labels:   map[string]string{"env": "test"},
+		Ignored:  "ignored",
+		internal: "internal",
+	}
+
+	assert.ErrorIs(t, b.SaveStruct("not a struct"), ErrNotEncodable{}, "SaveStruct - not a struct")
+	assert.Nil(t, b.SaveStruct(&settings), "SaveStruct")
+
+	// scalar fields are human-readable
+	for key, want := range map[string]string{
+		"name":    "example",
+		"enabled": "true",
+		"retries": "3",
+		"Limit":   "65535",
+		"Ratio":   "0.25",
+		"Timeout": "1m30s",
+		"Started": "2024-01-02T03:04:05.000000006Z",
+		"Secret":  "secret",
+	} {
+		assert.Equal(t, want, string(b.Get([]byte(key))), "SaveStruct - %s", key)
+	}
+
+	assert.False(t, b.Exists([]byte("Ignored")), "SaveStruct - ignored field")
+	assert.False(t, b.Exists([]byte("internal")), "SaveStruct - unexported field")
+
+	var tags []string
+	assert.Nil(t, b.Decode([]byte("Tags"), &tags), "SaveStruct - complex field")
+	assert.Equal(t, settings.Tags, tags, "SaveStruct - complex field value")
+
+	var loaded testSettings
+	assert.Nil(t, b.LoadStruct(&loaded), "LoadStruct")
+	settings.Ignored, settings.internal = "", ""
+	assert.Equal(t, settings, loaded, "LoadStruct - value")
+
+	// fields may be updated independently
+	assert.Nil(t, b.SPut("retries", []byte("5")), "SPut")
+	assert.Nil(t, b.LoadStruct(&loaded), "LoadStruct - updated")
+	assert.Equal(t, 5, loaded.Retries, "LoadStruct - updated field")
+
+	// nil fields delete their keys
+	settings.Tags, settings.Labels = nil, nil
+	assert.Nil(t, b.SaveStruct(settings), "SaveStruct - nil fields")
+	assert.False(t, b.Exists([]byte("Tags")), "SaveStruct - nil field deleted")
+
+	// missing keys leave fields unchanged unless required
+	assert.Nil(t, b.Delete([]byte("name")), "Delete")
+	loaded = testSettings{Name: "unchanged"}
+	assert.Nil(t, b.LoadStruct(&loaded), "LoadStruct - missing key")
+	assert.Equal(t, "unchanged", loaded.Name, "LoadStruct - missing key unchanged")
+
+	assert.Nil(t, b.Delete([]byte("retries")), "Delete")
+	err = b.LoadStruct(&loaded)
+	assert.ErrorIs(t, err, ErrMissingField{}, "LoadStruct - missing required key")
+	if mf, ok := err.(ErrMissingField); ok {
+		assert.Equal(t, "Retries", mf.Field(), "ErrMissingField - field")
+	}
+
+	assert.Nil(t, b.Put([]byte("retries"), []byte("many")), "Put")
+	assert.ErrorIs(t, b.LoadStruct(&loaded), ErrDecode{}, "LoadStruct - invalid value")
+
+	assert.ErrorIs(t, b.LoadStruct(loaded), ErrInvalidDestination{}, "LoadStruct - not a pointer")
+	var notStruct string
+	assert.ErrorIs(t, b.LoadStruct(&notStruct), ErrInvalidDestination{}, "LoadStruct - not a struct")
+	assert.ErrorIs(t, b.db.LoadStruct(missing, &loaded), ErrBucketNotFound{}, "LoadStruct - missing bucket")
+}
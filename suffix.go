@@ -0,0 +1,196 @@
+package ubolt
+
+import (
+	"bytes"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var suffixBucketPrefix = []byte("__suffix/")
+
+// ErrNoSuffixIndex is returned by ScanSuffix when no suffix index was enabled for the bucket using WithSuffixIndex.
+type ErrNoSuffixIndex struct {
+	bucket []byte
+}
+
+// Error returns the formatted configuration error.
+func (nsi ErrNoSuffixIndex) Error() string {
+	return fmt.Sprintf("Bucket %s has no suffix index", string(nsi.bucket))
+}
+
+// Is allows testing using errors.Is
+func (nsi ErrNoSuffixIndex) Is(target error) bool {
+	_, is := target.(ErrNoSuffixIndex)
+
+	return is
+}
+
+// WithSuffixIndex maintains an index of the byte-reversed keys of the chosen bucket, which allows ScanSuffix to find all keys ending with a suffix.
+// The index is updated in the same transaction as every Put, PutV, Encode and Delete to the bucket. This option may be provided more than once to
+// index multiple buckets.
+//
+// When enabling the index for a bucket that already contains data, RebuildSuffixIndex must be called to index the existing keys.
+func WithSuffixIndex(bucket []byte) Option {
+	return func(db *Database) {
+		if db.suffixIndexes == nil {
+			db.suffixIndexes = make(map[string]bool)
+		}
+
+		db.suffixIndexes[string(bucket)] = true
+	}
+}
+
+// ScanSuffix iterates over all keys in the chosen bucket that end with the provided suffix, ordered by their reversed key.
+// ErrNoSuffixIndex is returned if WithSuffixIndex was not used for the bucket.
+func (db *Database) ScanSuffix(bucket, suffix []byte, fn func(k, v []byte) error) error {
+	if !db.suffixIndexes[string(bucket)] {
+		return ErrNoSuffixIndex{bucket}
+	}
+
+	return db.view(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		idx := tx.Bucket(suffixBucket(bucket))
+		if idx == nil {
+			return nil
+		}
+
+		prefix := reverse(suffix)
+		end := PrefixSuccessor(prefix)
+
+		c := idx.Cursor()
+		for rk, _ := c.Seek(prefix); rk != nil && (end == nil || bytes.Compare(rk, end) < 0); rk, _ = c.Next() {
+			key := reverse(rk)
+
+			// skip any stale index entries
+			val := b.Get(key)
+			if val == nil {
+				continue
+			}
+
+			val, err := db.unwrapValue(bucket, key, val)
+			if err != nil {
+				return err
+			}
+
+			if err := fn(key, val); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ScanSuffix iterates over all keys that end with the provided suffix, ordered by their reversed key.
+func (b *Bucket) ScanSuffix(suffix []byte, fn func(k, v []byte) error) error {
+	return b.db.ScanSuffix(b.bucket, suffix, fn)
+}
+
+// RebuildSuffixIndex discards and recreates the suffix index for the chosen bucket from its current keys in a single read/write transaction.
+func (db *Database) RebuildSuffixIndex(bucket []byte) error {
+	return db.RebuildSuffixIndexWithProgress(bucket, nil)
+}
+
+// RebuildSuffixIndexWithProgress performs the same process as RebuildSuffixIndex while reporting the number of keys indexed to progress. The
+// total is reported as -1.
+func (db *Database) RebuildSuffixIndexWithProgress(bucket []byte, progress ProgressFunc) error {
+	var p *progressReporter
+
+	if err := db.update(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		name := suffixBucket(bucket)
+		if tx.Bucket(name) != nil {
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+		}
+
+		idx, err := tx.CreateBucket(name)
+		if err != nil {
+			return err
+		}
+
+		p = newProgress(progress, -1)
+
+		return b.ForEach(func(k, v []byte) error {
+			// skip nested buckets
+			if v == nil {
+				return nil
+			}
+
+			p.add(1)
+
+			return idx.Put(reverse(k), []byte{})
+		})
+	}); err != nil {
+		return err
+	}
+
+	p.finish()
+
+	return nil
+}
+
+// RebuildSuffixIndex discards and recreates the suffix index for the bucket from its current keys in a single read/write transaction.
+func (b *Bucket) RebuildSuffixIndex() error {
+	return b.db.RebuildSuffixIndex(b.bucket)
+}
+
+// RebuildSuffixIndexWithProgress performs the same process as RebuildSuffixIndex while reporting the number of keys indexed to progress.
+func (b *Bucket) RebuildSuffixIndexWithProgress(progress ProgressFunc) error {
+	return b.db.RebuildSuffixIndexWithProgress(b.bucket, progress)
+}
+
+// updateSuffixIndex applies the mutation to the suffix index of the bucket if one is enabled.
+func (db *Database) updateSuffixIndex(tx *bolt.Tx, m mutation) error {
+	if !db.suffixIndexes[string(m.bucket)] {
+		return nil
+	}
+
+	name := suffixBucket(m.bucket)
+
+	switch m.op {
+	case OpDeleteBucket:
+		if tx.Bucket(name) == nil {
+			return nil
+		}
+
+		return tx.DeleteBucket(name)
+	case OpDelete:
+		idx := tx.Bucket(name)
+		if idx == nil {
+			return nil
+		}
+
+		return idx.Delete(reverse(m.key))
+	}
+
+	idx, err := tx.CreateBucketIfNotExists(name)
+	if err != nil {
+		return err
+	}
+
+	return idx.Put(reverse(m.key), []byte{})
+}
+
+func suffixBucket(bucket []byte) []byte {
+	return append(append([]byte{}, suffixBucketPrefix...), bucket...)
+}
+
+func reverse(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i, c := range b {
+		r[len(b)-1-i] = c
+	}
+
+	return r
+}
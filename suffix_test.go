@@ -0,0 +1,73 @@
+package ubolt
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuffixIndex(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	// write some data before the index is enabled
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := b.Put([]byte("www.example.com"), []byte("1")); err != nil {
+		panic(err)
+	}
+
+	assert.ErrorIs(t, b.ScanSuffix([]byte(".com"), nil), ErrNoSuffixIndex{}, "ScanSuffix - not enabled")
+
+	if err := b.Close(); err != nil {
+		panic(err)
+	}
+
+	b, err = OpenBucket(testdb, testbucket, WithSuffixIndex(testbucket))
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	scan := func(suffix string) string {
+		got := make([]string, 0)
+
+		if err := b.ScanSuffix([]byte(suffix), func(k, v []byte) error {
+			got = append(got, fmt.Sprintf("k=%s;v=%s", k, v))
+			return nil
+		}); err != nil {
+			panic(err)
+		}
+
+		return strings.Join(got, ":")
+	}
+
+	// existing data is not indexed until rebuilt
+	assert.Equal(t, "", scan(".example.com"), "ScanSuffix - before rebuild")
+	assert.Nil(t, b.RebuildSuffixIndex(), "RebuildSuffixIndex")
+	assert.Equal(t, "k=www.example.com;v=1", scan(".example.com"), "ScanSuffix - after rebuild")
+
+	assert.Nil(t, b.Put([]byte("mail.example.com"), []byte("2")), "Put")
+	assert.Nil(t, b.Put([]byte("www.example.org"), []byte("3")), "Put")
+	assert.Nil(t, b.Encode([]byte("api.example.com"), "4"), "Encode")
+
+	assert.Equal(t, "k=mail.example.com;v=2:k=www.example.com;v=1", scan("l.example.com")+":"+scan("w.example.com"), "ScanSuffix - after put")
+	assert.Equal(t, "k=www.example.org;v=3", scan(".org"), "ScanSuffix - other suffix")
+	assert.Equal(t, "", scan(".net"), "ScanSuffix - missing suffix")
+
+	assert.Nil(t, b.Delete([]byte("www.example.com")), "Delete")
+	assert.Equal(t, "k=mail.example.com;v=2", scan("w.example.com")+scan("l.example.com"), "ScanSuffix - after delete")
+
+	// the index bucket is hidden and removed with the bucket
+	assert.Equal(t, [][]byte{testbucket}, b.db.GetBuckets(), "GetBuckets")
+	assert.Nil(t, b.db.DeleteBucket(testbucket), "DeleteBucket")
+	assert.ErrorIs(t, b.ScanSuffix([]byte(".com"), nil), ErrBucketNotFound{}, "ScanSuffix - missing bucket")
+	assert.Nil(t, b.db.CreateBucket(testbucket), "CreateBucket")
+	assert.Equal(t, "", scan(".com"), "ScanSuffix - recreated bucket")
+}
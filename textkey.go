@@ -0,0 +1,80 @@
+package ubolt
+
+import (
+	"encoding"
+	"fmt"
+)
+
+// ErrInvalidKey is returned when a typed key cannot be marshaled to its text form or marshals to an empty key, or when an encoded key
+// can not be decoded.
+type ErrInvalidKey struct {
+	err error
+}
+
+// Error returns the formatted configuration error.
+func (ik ErrInvalidKey) Error() string {
+	if ik.err == nil {
+		return "Invalid key: key marshaled to an empty value"
+	}
+
+	return fmt.Sprintf("Invalid key: %v", ik.err)
+}
+
+// Is allows testing using errors.Is
+func (ik ErrInvalidKey) Is(target error) bool {
+	_, is := target.(ErrInvalidKey)
+
+	return is
+}
+
+// Unwrap returns the error returned by MarshalText or the reason the key could not be decoded, if any.
+func (ik ErrInvalidKey) Unwrap() error {
+	return ik.err
+}
+
+// textKey marshals key to the text form used for storage.
+func textKey(key encoding.TextMarshaler) ([]byte, error) {
+	k, err := key.MarshalText()
+	if err != nil {
+		return nil, ErrInvalidKey{err}
+	}
+
+	if len(k) == 0 {
+		return nil, ErrInvalidKey{}
+	}
+
+	return k, nil
+}
+
+// EncodeT performs the same process as Encode using the text form of key, as returned by its MarshalText method, as the key.
+//
+// MarshalText must be deterministic so the same key always maps to the same stored key. Keys are ordered by their text form rather than
+// their natural order, so for example the text of the integer 10 sorts before 9, which should be considered when using prefix scans.
+func (db *Database) EncodeT(bucket []byte, key encoding.TextMarshaler, value interface{}) error {
+	k, err := textKey(key)
+	if err != nil {
+		return err
+	}
+
+	return db.Encode(bucket, k, value)
+}
+
+// EncodeT performs the same process as Encode using the text form of key as the key.
+func (b *Bucket) EncodeT(key encoding.TextMarshaler, value interface{}) error {
+	return b.db.EncodeT(b.bucket, key, value)
+}
+
+// DecodeT performs the same process as Decode using the text form of key, as returned by its MarshalText method, as the key.
+func (db *Database) DecodeT(bucket []byte, key encoding.TextMarshaler, value interface{}) error {
+	k, err := textKey(key)
+	if err != nil {
+		return err
+	}
+
+	return db.Decode(bucket, k, value)
+}
+
+// DecodeT performs the same process as Decode using the text form of key as the key.
+func (b *Bucket) DecodeT(key encoding.TextMarshaler, value interface{}) error {
+	return b.db.DecodeT(b.bucket, key, value)
+}
@@ -0,0 +1,58 @@
+package ubolt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type orderID int
+
+func (id orderID) MarshalText() ([]byte, error) {
+	switch {
+	case id < 0:
+		return nil, errors.New("negative order id")
+	case id == 0:
+		return nil, nil
+	}
+
+	return []byte(fmt.Sprintf("order-%d", int(id))), nil
+}
+
+func TestEncodeT(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.Nil(t, b.EncodeT(orderID(1), "first"), "EncodeT")
+
+	var got string
+	assert.Nil(t, b.DecodeT(orderID(1), &got), "DecodeT")
+	assert.Equal(t, "first", got, "DecodeT")
+
+	// the text form is used as the key
+	assert.NotNil(t, b.Get([]byte("order-1")), "Get - text key")
+
+	assert.ErrorIs(t, b.DecodeT(orderID(2), &got), ErrKeyNotFound{}, "DecodeT - missing")
+
+	tests := []struct {
+		name string
+		key  orderID
+	}{
+		{"marshal error", orderID(-1)},
+		{"empty key", orderID(0)},
+	}
+
+	for _, tt := range tests {
+		assert.ErrorIs(t, b.EncodeT(tt.key, "value"), ErrInvalidKey{}, "EncodeT - "+tt.name)
+		assert.ErrorIs(t, b.DecodeT(tt.key, &got), ErrInvalidKey{}, "DecodeT - "+tt.name)
+	}
+}
@@ -0,0 +1,204 @@
+package ubolt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// maxThrottleChunk is the largest number of bytes passed to the underlying io.Writer in a single call by a ThrottledWriter, which keeps the
+// pacing smooth at high rates.
+const maxThrottleChunk = 64 * 1024
+
+// errMaxDuration is the cause of the context used by WriteToThrottledContext when ThrottleOptions.MaxDuration passes.
+var errMaxDuration = errors.New("maximum duration exceeded")
+
+// ErrWriteTimeout is returned by WriteToThrottledContext when the copy did not complete within ThrottleOptions.MaxDuration.
+type ErrWriteTimeout struct {
+	limit   time.Duration
+	written int64
+}
+
+// Error returns the formatted configuration error.
+func (wt ErrWriteTimeout) Error() string {
+	return fmt.Sprintf("Write did not complete within %s after writing %d bytes", wt.limit, wt.written)
+}
+
+// Is allows testing using errors.Is
+func (wt ErrWriteTimeout) Is(target error) bool {
+	_, is := target.(ErrWriteTimeout)
+
+	return is
+}
+
+// ThrottledWriter is an io.Writer that limits the rate bytes are written to an underlying io.Writer using a token bucket, allowing bursts of
+// up to one second's worth of bytes. It is not safe for concurrent use.
+type ThrottledWriter struct {
+	ctx    context.Context
+	w      io.Writer
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewThrottledWriter returns a ThrottledWriter that writes to w at no more than bytesPerSecond, or without any limit if bytesPerSecond is
+// zero or less.
+//
+// Once ctx is done any Write returns ctx.Err() without waiting further. If ctx can be done, each write to w is made from a separate
+// goroutine so that a Write blocked by w is also abandoned, in which case the goroutine continues until w returns.
+func NewThrottledWriter(ctx context.Context, w io.Writer, bytesPerSecond int64) *ThrottledWriter {
+	rate := float64(bytesPerSecond)
+
+	return &ThrottledWriter{ctx: ctx, w: w, rate: rate, burst: rate, tokens: rate, last: time.Now()}
+}
+
+// Write writes p to the underlying io.Writer, waiting as needed so the rate limit is not exceeded.
+func (tw *ThrottledWriter) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		chunk := p[:min(len(p), maxThrottleChunk)]
+		if tw.rate > 0 && float64(len(chunk)) > tw.burst {
+			chunk = chunk[:max(int(tw.burst), 1)]
+		}
+
+		if err := tw.wait(len(chunk)); err != nil {
+			return n, err
+		}
+
+		written, err := tw.write(chunk)
+		n += written
+
+		if err != nil {
+			return n, err
+		}
+
+		p = p[written:]
+	}
+
+	return n, nil
+}
+
+// wait blocks until n bytes may be written.
+func (tw *ThrottledWriter) wait(n int) error {
+	if err := tw.ctx.Err(); err != nil {
+		return err
+	}
+
+	if tw.rate <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	tw.tokens = min(tw.burst, tw.tokens+now.Sub(tw.last).Seconds()*tw.rate) - float64(n)
+	tw.last = now
+
+	if tw.tokens >= 0 {
+		return nil
+	}
+
+	// the deficit is repaid by the tokens accumulated while waiting
+	t := time.NewTimer(time.Duration(-tw.tokens / tw.rate * float64(time.Second)))
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-tw.ctx.Done():
+		return tw.ctx.Err()
+	}
+}
+
+// write passes p to the underlying io.Writer, abandoning it if the context is done first.
+func (tw *ThrottledWriter) write(p []byte) (int, error) {
+	if tw.ctx.Done() == nil {
+		return tw.w.Write(p)
+	}
+
+	type result struct {
+		n   int
+		err error
+	}
+
+	// the caller may reuse p once an abandoned write returns
+	buf := append([]byte{}, p...)
+	ch := make(chan result, 1)
+
+	go func() {
+		n, err := tw.w.Write(buf)
+		ch <- result{n, err}
+	}()
+
+	select {
+	case r := <-ch:
+		return r.n, r.err
+	case <-tw.ctx.Done():
+		return 0, tw.ctx.Err()
+	}
+}
+
+// ThrottleOptions controls the behaviour of WriteToThrottledContext.
+type ThrottleOptions struct {
+	// BytesPerSecond is the maximum rate the database is written, or no limit if zero or less.
+	BytesPerSecond int64
+
+	// MaxDuration is the longest the copy, and so the read-only transaction it holds open, may take before it is abandoned with
+	// ErrWriteTimeout. A value of zero or less applies no limit.
+	MaxDuration time.Duration
+}
+
+// WriteToThrottled performs the same process as WriteTo however the database is written to w at no more than bytesPerSecond, so a backup
+// over a constrained link does not saturate it. The read-only transaction is held open for the duration of the copy, which prevents pages
+// freed in the meantime from being reused, so a limit that is too low causes the database file to grow.
+func (db *Database) WriteToThrottled(w io.Writer, bytesPerSecond int64) (int64, error) {
+	return db.WriteToThrottledContext(context.Background(), w, ThrottleOptions{BytesPerSecond: bytesPerSecond})
+}
+
+// WriteToThrottled performs the same process as WriteTo however the database is written to w at no more than bytesPerSecond.
+func (b *Bucket) WriteToThrottled(w io.Writer, bytesPerSecond int64) (int64, error) {
+	return b.db.WriteToThrottled(w, bytesPerSecond)
+}
+
+// WriteToThrottledContext performs the same process as WriteToThrottled with the rate and maximum duration controlled by the provided
+// ThrottleOptions. The copy is abandoned with ctx.Err() once ctx is done, or with ErrWriteTimeout once MaxDuration has passed, including
+// when w is blocked, so a stalled destination can not hold the transaction open indefinitely. The number of bytes written before the copy
+// was abandoned is returned.
+func (db *Database) WriteToThrottledContext(ctx context.Context, w io.Writer, opts ThrottleOptions) (n int64, err error) {
+	if opts.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, opts.MaxDuration, errMaxDuration)
+		defer cancel()
+	}
+
+	tw := NewThrottledWriter(ctx, w, opts.BytesPerSecond)
+
+	if err := db.view(func(tx *bolt.Tx) error {
+		var err error
+
+		n, err = tx.WriteTo(tw)
+
+		return err
+	}); err != nil {
+		if errors.Is(context.Cause(ctx), errMaxDuration) {
+			return n, ErrWriteTimeout{limit: opts.MaxDuration, written: n}
+		}
+
+		// bolt does not wrap the error returned by the writer
+		if ctx.Err() != nil {
+			return n, ctx.Err()
+		}
+
+		return n, err
+	}
+
+	return n, nil
+}
+
+// WriteToThrottledContext performs the same process as WriteToThrottled with the rate and maximum duration controlled by the provided
+// ThrottleOptions.
+func (b *Bucket) WriteToThrottledContext(ctx context.Context, w io.Writer, opts ThrottleOptions) (int64, error) {
+	return b.db.WriteToThrottledContext(ctx, w, opts)
+}
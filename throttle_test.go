@@ -0,0 +1,80 @@
+package ubolt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stalledWriter blocks every Write until released.
+type stalledWriter struct {
+	release chan struct{}
+}
+
+func (sw stalledWriter) Write(p []byte) (int, error) {
+	<-sw.release
+
+	return len(p), nil
+}
+
+func TestWriteToThrottled(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	for i := 0; i < 50; i++ {
+		if err := b.Put([]byte(fmt.Sprintf("key%d", i)), make([]byte, 4096)); err != nil {
+			panic(err)
+		}
+	}
+
+	var want bytes.Buffer
+	size, err := b.db.WriteTo(&want)
+	assert.Nil(t, err, "WriteTo")
+
+	// unlimited
+	var buf bytes.Buffer
+	n, err := b.WriteToThrottled(&buf, 0)
+	assert.Nil(t, err, "WriteToThrottled - unlimited")
+	assert.Equal(t, size, n, "WriteToThrottled - unlimited size")
+	assert.Equal(t, want.Bytes(), buf.Bytes(), "WriteToThrottled - unlimited content")
+
+	// the first second's worth is written immediately then the remainder is paced
+	rate := size / 2
+	buf.Reset()
+	start := time.Now()
+	n, err = b.WriteToThrottled(&buf, rate)
+	elapsed := time.Since(start)
+	assert.Nil(t, err, "WriteToThrottled")
+	assert.Equal(t, size, n, "WriteToThrottled - size")
+	assert.Equal(t, want.Bytes(), buf.Bytes(), "WriteToThrottled - content")
+	assert.GreaterOrEqual(t, elapsed, 900*time.Millisecond, "WriteToThrottled - paced")
+	assert.Less(t, elapsed, 3*time.Second, "WriteToThrottled - not too slow")
+
+	// a stalled destination is abandoned
+	sw := stalledWriter{release: make(chan struct{})}
+	defer close(sw.release)
+
+	_, err = b.WriteToThrottledContext(context.Background(), sw, ThrottleOptions{MaxDuration: 50 * time.Millisecond})
+	assert.ErrorIs(t, err, ErrWriteTimeout{}, "WriteToThrottledContext - max duration")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = b.WriteToThrottledContext(ctx, sw, ThrottleOptions{BytesPerSecond: rate})
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "WriteToThrottledContext - context")
+	assert.NotErrorIs(t, err, ErrWriteTimeout{}, "WriteToThrottledContext - context is not a timeout")
+
+	// the database remains writable once the copy is abandoned
+	assert.Nil(t, b.Put(testkey, testvalue), "Put")
+}
@@ -0,0 +1,114 @@
+package ubolt
+
+import (
+	"encoding/binary"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var timestampBucketPrefix = []byte("__timestamps/")
+
+// KeyMeta holds the times a key was first created and last updated as recorded when WithTimestamps is enabled.
+type KeyMeta struct {
+	Created time.Time
+	Updated time.Time
+}
+
+// WithTimestamps records the time each key was created and last updated in a reserved bucket alongside the bucket holding the key. The times are
+// updated in the same transaction as every Put, PutV, Encode and Delete, and may be retrieved using KeyInfo.
+//
+// Reads are unaffected by this option, however each write stores an additional 16 bytes per key.
+func WithTimestamps() Option {
+	return func(db *Database) {
+		db.timestamps = true
+	}
+}
+
+// KeyInfo returns the times the key in the chosen bucket was created and last updated. ErrBucketNotFound or ErrKeyNotFound are returned if
+// the bucket or key does not exist.
+//
+// The times are zero if the key was written while WithTimestamps was not enabled.
+func (db *Database) KeyInfo(bucket, key []byte) (meta KeyMeta, err error) {
+	key = db.canonicalKey(key)
+
+	if err := db.view(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		if b.Get(key) == nil {
+			return ErrKeyNotFound{bucket: bucket, key: key}
+		}
+
+		if ts := tx.Bucket(timestampBucket(bucket)); ts != nil {
+			if v := ts.Get(key); len(v) == 16 {
+				meta.Created = time.Unix(0, int64(binary.BigEndian.Uint64(v[:8])))
+				meta.Updated = time.Unix(0, int64(binary.BigEndian.Uint64(v[8:])))
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return KeyMeta{}, err
+	}
+
+	return meta, nil
+}
+
+// KeyInfo returns the times the key was created and last updated.
+func (b *Bucket) KeyInfo(key []byte) (meta KeyMeta, err error) {
+	return b.db.KeyInfo(b.bucket, key)
+}
+
+// updateTimestamps applies the mutation to the timestamps of the bucket if WithTimestamps is enabled.
+func (db *Database) updateTimestamps(tx *bolt.Tx, m mutation) error {
+	if !db.timestamps {
+		return nil
+	}
+
+	name := timestampBucket(m.bucket)
+
+	switch m.op {
+	case OpDeleteBucket:
+		if tx.Bucket(name) == nil {
+			return nil
+		}
+
+		return tx.DeleteBucket(name)
+	case OpDelete:
+		ts := tx.Bucket(name)
+		if ts == nil {
+			return nil
+		}
+
+		return ts.Delete(m.key)
+	}
+
+	ts, err := tx.CreateBucketIfNotExists(name)
+	if err != nil {
+		return err
+	}
+
+	// the timestamps of an imported key are restored from the archive, so are only set if the archive has none
+	if m.op == OpImport && ts.Get(m.key) != nil {
+		return nil
+	}
+
+	now := uint64(time.Now().UnixNano())
+
+	v := make([]byte, 16)
+	if existing := ts.Get(m.key); len(existing) == 16 {
+		copy(v[:8], existing[:8])
+	} else {
+		binary.BigEndian.PutUint64(v[:8], now)
+	}
+	binary.BigEndian.PutUint64(v[8:], now)
+
+	return ts.Put(m.key, v)
+}
+
+func timestampBucket(bucket []byte) []byte {
+	return append(append([]byte{}, timestampBucketPrefix...), bucket...)
+}
@@ -0,0 +1,97 @@
+package ubolt
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimestamps(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket, WithTimestamps())
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	before := time.Now()
+	if err := b.Put(testkey, testvalue); err != nil {
+		panic(err)
+	}
+
+	meta, err := b.KeyInfo(testkey)
+	assert.Nil(t, err, "KeyInfo")
+	assert.False(t, meta.Created.Before(before), "KeyInfo - created")
+	assert.Equal(t, meta.Created, meta.Updated, "KeyInfo - new key")
+
+	// an update keeps the created time
+	time.Sleep(time.Millisecond)
+	if err := b.Encode(testkey, "updated"); err != nil {
+		panic(err)
+	}
+
+	updated, err := b.KeyInfo(testkey)
+	assert.Nil(t, err, "KeyInfo - updated")
+	assert.Equal(t, meta.Created, updated.Created, "KeyInfo - created unchanged")
+	assert.True(t, updated.Updated.After(meta.Updated), "KeyInfo - updated")
+
+	// PutV is also recorded
+	key, err := b.PutV(testvalue)
+	if err != nil {
+		panic(err)
+	}
+	meta, err = b.KeyInfo(key)
+	assert.Nil(t, err, "KeyInfo - PutV")
+	assert.False(t, meta.Created.IsZero(), "KeyInfo - PutV")
+
+	// the shadow bucket is hidden
+	assert.Equal(t, [][]byte{testbucket}, b.db.GetBuckets(), "GetBuckets")
+
+	var buckets []string
+	assert.Nil(t, b.db.ForEachAll(func(bucket, k, v []byte) error {
+		buckets = append(buckets, string(bucket))
+		return nil
+	}), "ForEachAll")
+	assert.Equal(t, []string{string(testbucket), string(testbucket)}, buckets, "ForEachAll")
+
+	// delete removes the metadata
+	if err := b.Delete(testkey); err != nil {
+		panic(err)
+	}
+	_, err = b.KeyInfo(testkey)
+	assert.ErrorIs(t, err, ErrKeyNotFound{}, "KeyInfo - deleted")
+
+	if err := b.Put(testkey, testvalue); err != nil {
+		panic(err)
+	}
+	meta, err = b.KeyInfo(testkey)
+	assert.Nil(t, err, "KeyInfo - recreated")
+	assert.True(t, meta.Created.After(updated.Created), "KeyInfo - recreated")
+
+	_, err = b.db.KeyInfo(missing, testkey)
+	assert.ErrorIs(t, err, ErrBucketNotFound{}, "KeyInfo - missing bucket")
+}
+
+func TestTimestampsDisabled(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	if err := b.Put(testkey, testvalue); err != nil {
+		panic(err)
+	}
+
+	meta, err := b.KeyInfo(testkey)
+	assert.Nil(t, err, "KeyInfo")
+	assert.True(t, meta.Created.IsZero(), "KeyInfo - not recorded")
+	assert.True(t, meta.Updated.IsZero(), "KeyInfo - not recorded")
+}
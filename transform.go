@@ -0,0 +1,172 @@
+package ubolt
+
+import (
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// DefaultTransformChunkSize is the number of source keys read and transformed per transaction by TransformBucket.
+const DefaultTransformChunkSize = 1000
+
+// TransformFunc returns the key and value written to the destination bucket by TransformBucket for a key and value of the source bucket.
+// Returning skip as true drops the entry and returning an error aborts the transform. The slices passed to a TransformFunc are only valid
+// until it returns, so must be copied if they are to be returned.
+type TransformFunc func(k, v []byte) (nk, nv []byte, skip bool, err error)
+
+// ErrTransform is returned by TransformBucket when the TransformFunc returns an error, reporting the source key that was being transformed.
+type ErrTransform struct {
+	bucket []byte
+	key    []byte
+	err    error
+}
+
+// Error returns the formatted configuration error.
+func (te ErrTransform) Error() string {
+	return fmt.Sprintf("Transform of key %s in bucket %s failed: %v", string(te.key), bucketName(te.bucket), te.err)
+}
+
+// Is allows testing using errors.Is
+func (te ErrTransform) Is(target error) bool {
+	_, is := target.(ErrTransform)
+
+	return is
+}
+
+// Unwrap returns the error returned by the TransformFunc.
+func (te ErrTransform) Unwrap() error {
+	return te.err
+}
+
+// Key returns the key in the source bucket that could not be transformed.
+func (te ErrTransform) Key() []byte {
+	return te.key
+}
+
+// TransformBucket copies every key of the src bucket, in key order, to the dst bucket using the keys and values returned by fn, and returns
+// the number of entries written. The dst bucket is created if it does not exist and must not be src. Nested buckets in src are skipped.
+//
+// Keys are read and transformed in chunks of DefaultTransformChunkSize per read-only transaction, with the results of each chunk written
+// in a single read/write transaction, so the transform is not atomic and entries written before an error are left in place. When a
+// transformed key already exists in dst, including one written earlier by the same transform, the policy set by WithConflictPolicy
+// decides the value kept, which defaults to OverwriteOnConflict. Use ErrorOnConflict to detect transformed keys that collide. Progress is
+// reported using WithImportProgress.
+func (db *Database) TransformBucket(src, dst []byte, fn TransformFunc, opts ...ImportOption) (n int, err error) {
+	o := newImportOptions(opts)
+	p := newProgress(o.progress, -1)
+
+	var after []byte
+
+	for {
+		var keys, values, sources [][]byte
+
+		visited := 0
+
+		if err := db.view(func(tx *bolt.Tx) error {
+			s := lookupBucket(tx, src)
+			if s == nil {
+				return ErrBucketNotFound{bucket: src}
+			}
+
+			return ignoreStop(scanPrefixFrom(s.Cursor(), nil, after, func(k, v []byte) error {
+				if visited == DefaultTransformChunkSize {
+					return ErrStop{}
+				}
+
+				visited++
+				after = append(after[:0], k...)
+
+				// skip nested buckets
+				if v == nil {
+					return nil
+				}
+
+				v, err := db.unwrapValue(src, k, v)
+				if err != nil {
+					return ErrDecode{bucket: src, key: append([]byte{}, k...), err: err}
+				}
+
+				nk, nv, skip, err := fn(k, v)
+				if err != nil {
+					return ErrTransform{bucket: src, key: append([]byte{}, k...), err: err}
+				}
+
+				if skip {
+					return nil
+				}
+
+				keys = append(keys, append([]byte{}, db.canonicalKey(nk)...))
+				values = append(values, append([]byte{}, nv...))
+				sources = append(sources, append([]byte{}, k...))
+
+				return nil
+			}))
+		}); err != nil {
+			return n, err
+		}
+
+		written := 0
+
+		if err := db.update(func(tx *bolt.Tx) error {
+			d, err := createBucketPath(tx, dst)
+			if err != nil {
+				return err
+			}
+
+			written = 0
+
+			for i, key := range keys {
+				var existing []byte
+
+				prev := d.Get(key)
+				if prev != nil {
+					if existing, err = db.unwrapValue(dst, key, prev); err != nil {
+						return ErrDecode{bucket: dst, key: key, err: err}
+					}
+				}
+
+				value, write, err := o.resolve(dst, key, existing, values[i])
+				if err != nil {
+					return ErrTransform{bucket: src, key: sources[i], err: err}
+				}
+
+				if !write {
+					continue
+				}
+
+				if value, err = db.wrapValue(dst, key, value); err != nil {
+					return err
+				}
+
+				if err := d.Put(key, value); err != nil {
+					return err
+				}
+
+				if err := db.onMutation(tx, mutation{op: OpPut, bucket: dst, key: key, value: value, prev: prev}); err != nil {
+					return err
+				}
+
+				written++
+			}
+
+			return nil
+		}); err != nil {
+			return n, err
+		}
+
+		n += written
+		p.add(int64(written))
+
+		if visited < DefaultTransformChunkSize {
+			p.finish()
+
+			return n, nil
+		}
+	}
+}
+
+// TransformBucket copies every key of the bucket opened to the dst bucket using the keys and values returned by fn, returning the number of
+// entries written.
+func (b *Bucket) TransformBucket(dst []byte, fn TransformFunc, opts ...ImportOption) (int, error) {
+	return b.db.TransformBucket(b.bucket, dst, fn, opts...)
+}
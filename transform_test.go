@@ -0,0 +1,89 @@
+package ubolt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransformBucket(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	db, err := Open(testdb)
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	src, dst := []byte("old"), []byte("new")
+
+	if err := db.CreateBucket(src); err != nil {
+		panic(err)
+	}
+
+	m := make(map[string][]byte)
+	for i := 0; i < DefaultTransformChunkSize+500; i++ {
+		m[fmt.Sprintf("user:%04d", i)] = []byte(fmt.Sprintf("value%d", i))
+	}
+
+	if err := db.PutAll(src, m); err != nil {
+		panic(err)
+	}
+
+	var reported int64
+	n, err := db.TransformBucket(src, dst, func(k, v []byte) ([]byte, []byte, bool, error) {
+		// drop every odd key
+		if k[len(k)-1]%2 == 1 {
+			return nil, nil, true, nil
+		}
+
+		return append([]byte("u/"), bytes.TrimPrefix(k, []byte("user:"))...), bytes.ToUpper(v), false, nil
+	}, WithImportProgress(func(done, total int64) { reported = done }))
+	assert.Nil(t, err, "TransformBucket")
+	assert.Equal(t, len(m)/2, n, "TransformBucket - count")
+	assert.Equal(t, int64(n), reported, "TransformBucket - progress")
+	assert.Len(t, db.GetKeys(dst), n, "TransformBucket - keys")
+	assert.Equal(t, []byte("VALUE1234"), db.Get(dst, []byte("u/1234")), "TransformBucket - value")
+	assert.Nil(t, db.Get(dst, []byte("u/1235")), "TransformBucket - skipped")
+
+	// colliding keys are detected using the conflict policy
+	collide := func(k, v []byte) ([]byte, []byte, bool, error) {
+		return []byte("same"), v, false, nil
+	}
+
+	_, err = db.TransformBucket(src, []byte("collide"), collide, WithConflictPolicy(ErrorOnConflict))
+	assert.ErrorIs(t, err, ErrConflict{}, "TransformBucket - conflict")
+
+	var te ErrTransform
+	if assert.True(t, errors.As(err, &te), "ErrTransform") {
+		assert.Equal(t, []byte("user:0001"), te.Key(), "ErrTransform - key")
+	}
+
+	n, err = db.TransformBucket(src, []byte("skip"), collide, WithConflictPolicy(SkipOnConflict))
+	assert.Nil(t, err, "TransformBucket - skip on conflict")
+	assert.Equal(t, 1, n, "TransformBucket - skip on conflict count")
+	assert.Equal(t, []byte("value0"), db.Get([]byte("skip"), []byte("same")), "TransformBucket - skip on conflict value")
+
+	// an error from the transform reports the source key
+	failure := errors.New("bad record")
+	n, err = db.TransformBucket(src, []byte("fail"), func(k, v []byte) ([]byte, []byte, bool, error) {
+		if string(k) == "user:0003" {
+			return nil, nil, false, failure
+		}
+
+		return k, v, false, nil
+	})
+	assert.ErrorIs(t, err, failure, "TransformBucket - error")
+	assert.ErrorIs(t, err, ErrTransform{}, "TransformBucket - ErrTransform")
+	assert.Equal(t, 0, n, "TransformBucket - error count")
+	assert.True(t, errors.As(err, &te), "ErrTransform")
+	assert.Equal(t, []byte("user:0003"), te.Key(), "ErrTransform - key")
+
+	_, err = db.TransformBucket([]byte("missing"), dst, collide)
+	assert.ErrorIs(t, err, ErrBucketNotFound{}, "TransformBucket - missing bucket")
+}
@@ -0,0 +1,283 @@
+package ubolt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var ttlBucketPrefix = []byte("__ttl/")
+
+// ErrNoTTL is returned by PutTTL when neither WithTTL nor WithSlidingTTL was used for the bucket.
+type ErrNoTTL struct {
+	bucket []byte
+}
+
+// Error returns the formatted configuration error.
+func (nt ErrNoTTL) Error() string {
+	return fmt.Sprintf("Bucket %s does not have TTL enabled", string(nt.bucket))
+}
+
+// Is allows testing using errors.Is
+func (nt ErrNoTTL) Is(target error) bool {
+	_, is := target.(ErrNoTTL)
+
+	return is
+}
+
+// WithTTL enables keys in the chosen bucket to be written with an expiry using PutTTL. Expired keys are treated as missing by GetE, Get,
+// GetOK, Decode and Exists and are removed by PurgeExpired, however other methods such as Scan and ForEach return them until purged.
+// Writing or deleting a key removes its expiry even when the database is opened without this option. This option may be provided more than
+// once to enable TTLs for multiple buckets.
+func WithTTL(bucket []byte) Option {
+	return func(db *Database) {
+		if db.ttls == nil {
+			db.ttls = make(map[string]bool)
+		}
+
+		if _, ok := db.ttls[string(bucket)]; !ok {
+			db.ttls[string(bucket)] = false
+		}
+	}
+}
+
+// WithSlidingTTL performs the same process as WithTTL however every successful GetE, Get, GetOK or Decode of a key written by PutTTL
+// pushes its expiry forward by its original TTL, so keys only expire after a period without reads.
+//
+// Each refresh is an additional read/write transaction made after the read, which is counted in OpStats.Touches and OpStats.Writes. Where
+// this write amplification is a concern use WithTTL and call Touch explicitly instead.
+func WithSlidingTTL(bucket []byte) Option {
+	return func(db *Database) {
+		if db.ttls == nil {
+			db.ttls = make(map[string]bool)
+		}
+
+		db.ttls[string(bucket)] = true
+	}
+}
+
+// PutTTL sets the specified key in the chosen bucket to the provided value, which expires once ttl has passed. ErrNoTTL is returned if
+// WithTTL or WithSlidingTTL was not used for the bucket. Writing the key again with Put removes the expiry.
+func (db *Database) PutTTL(bucket, key, value []byte, ttl time.Duration) error {
+	key = db.canonicalKey(key)
+
+	if _, ok := db.ttls[string(bucket)]; !ok {
+		return ErrNoTTL{bucket}
+	}
+
+	value, err := db.wrapValue(bucket, key, value)
+	if err != nil {
+		return err
+	}
+
+	return db.update(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		prev := previous(tx, bucket, b, key)
+
+		if err := b.Put(key, value); err != nil {
+			return err
+		}
+
+		if err := db.onMutation(tx, mutation{op: OpPut, bucket: bucket, key: key, value: value, prev: prev}); err != nil {
+			return err
+		}
+
+		ttlb, err := tx.CreateBucketIfNotExists(ttlBucket(bucket))
+		if err != nil {
+			return err
+		}
+
+		return ttlb.Put(key, ttlEntry(time.Now().Add(ttl), ttl))
+	})
+}
+
+// PutTTL sets the specified key to the provided value, which expires once ttl has passed.
+func (b *Bucket) PutTTL(key, value []byte, ttl time.Duration) error {
+	return b.db.PutTTL(b.bucket, key, value, ttl)
+}
+
+// Touch pushes the expiry of a key written by PutTTL forward by its original TTL. ErrKeyNotFound is returned if the key does not exist or
+// has already expired, as an expired key is never revived. Touching a key that has no expiry does nothing.
+func (db *Database) Touch(bucket, key []byte) error {
+	key = db.canonicalKey(key)
+
+	return db.update(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		if b.Get(key) == nil || db.ttlExpired(tx, bucket, key) {
+			return ErrKeyNotFound{bucket: bucket, key: key}
+		}
+
+		return db.touch(tx, bucket, key)
+	})
+}
+
+// Touch pushes the expiry of a key written by PutTTL forward by its original TTL.
+func (b *Bucket) Touch(key []byte) error {
+	return b.db.Touch(b.bucket, key)
+}
+
+// PurgeExpired removes every expired key from the chosen bucket in a single read/write transaction, returning the number removed.
+func (db *Database) PurgeExpired(bucket []byte) (int, error) {
+	var n int
+
+	if err := db.update(func(tx *bolt.Tx) error {
+		b, ttlb := lookupBucket(tx, bucket), tx.Bucket(ttlBucket(bucket))
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+		if ttlb == nil {
+			return nil
+		}
+
+		now := time.Now()
+
+		var expired [][]byte
+		if err := ttlb.ForEach(func(k, v []byte) error {
+			if deadline, _ := parseTTLEntry(v); now.After(deadline) {
+				expired = append(expired, append([]byte{}, k...))
+			}
+
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		for _, k := range expired {
+			prev := previous(tx, bucket, b, k)
+
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+
+			// this also removes the TTL entry
+			if err := db.onMutation(tx, mutation{op: OpDelete, bucket: bucket, key: k, prev: prev}); err != nil {
+				return err
+			}
+		}
+
+		n = len(expired)
+
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// PurgeExpired removes every expired key from the bucket in a single read/write transaction, returning the number removed.
+func (b *Bucket) PurgeExpired() (int, error) {
+	return b.db.PurgeExpired(b.bucket)
+}
+
+// ttlState returns whether the key has an expiry and if so whether it has passed. Buckets without TTL enabled are not checked.
+func (db *Database) ttlState(tx *bolt.Tx, bucket, key []byte) (has, expired bool) {
+	if _, ok := db.ttls[string(bucket)]; !ok {
+		return false, false
+	}
+
+	ttlb := tx.Bucket(ttlBucket(bucket))
+	if ttlb == nil {
+		return false, false
+	}
+
+	v := ttlb.Get(key)
+	if v == nil {
+		return false, false
+	}
+
+	deadline, _ := parseTTLEntry(v)
+
+	return true, time.Now().After(deadline)
+}
+
+// ttlExpired returns true if the key has an expiry that has passed.
+func (db *Database) ttlExpired(tx *bolt.Tx, bucket, key []byte) bool {
+	_, expired := db.ttlState(tx, bucket, key)
+
+	return expired
+}
+
+// slideTTL refreshes the expiry of a key with a TTL that was just read if WithSlidingTTL is enabled for the bucket.
+func (db *Database) slideTTL(bucket, key []byte, hasTTL bool) {
+	if !hasTTL || !db.ttls[string(bucket)] || db.IsReadOnly() {
+		return
+	}
+
+	// the key may have expired or been removed since it was read, in which case it is left alone
+	_ = db.Touch(bucket, key)
+}
+
+// touch moves the deadline of the key forward by its TTL, doing nothing if the key has no expiry.
+func (db *Database) touch(tx *bolt.Tx, bucket, key []byte) error {
+	ttlb := tx.Bucket(ttlBucket(bucket))
+	if ttlb == nil {
+		return nil
+	}
+
+	v := ttlb.Get(key)
+	if v == nil {
+		return nil
+	}
+
+	_, ttl := parseTTLEntry(v)
+	db.counters.touches.Add(1)
+
+	return ttlb.Put(key, ttlEntry(time.Now().Add(ttl), ttl))
+}
+
+// updateTTL removes the expiry of a key that is written or deleted, as PutTTL sets a new expiry after the write. This is done whenever the
+// bucket has expiries, even if WithTTL was not used when the database was opened, so a key written without it does not expire later.
+func (db *Database) updateTTL(tx *bolt.Tx, m mutation) error {
+	// the expiry of an imported key is restored from the archive
+	if m.op == OpImport {
+		return nil
+	}
+
+	name := ttlBucket(m.bucket)
+
+	if m.op == OpDeleteBucket {
+		if tx.Bucket(name) == nil {
+			return nil
+		}
+
+		return tx.DeleteBucket(name)
+	}
+
+	ttlb := tx.Bucket(name)
+	if ttlb == nil {
+		return nil
+	}
+
+	return ttlb.Delete(m.key)
+}
+
+func ttlEntry(deadline time.Time, ttl time.Duration) []byte {
+	v := make([]byte, 16)
+	binary.BigEndian.PutUint64(v[:8], uint64(deadline.UnixNano()))
+	binary.BigEndian.PutUint64(v[8:], uint64(ttl))
+
+	return v
+}
+
+func parseTTLEntry(v []byte) (deadline time.Time, ttl time.Duration) {
+	if len(v) != 16 {
+		return time.Time{}, 0
+	}
+
+	return time.Unix(0, int64(binary.BigEndian.Uint64(v[:8]))), time.Duration(binary.BigEndian.Uint64(v[8:]))
+}
+
+func ttlBucket(bucket []byte) []byte {
+	return append(append([]byte{}, ttlBucketPrefix...), bucket...)
+}
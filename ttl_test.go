@@ -0,0 +1,135 @@
+package ubolt
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestTTL(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket, WithTTL(testbucket))
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.ErrorIs(t, b.db.PutTTL(missing, testkey, testvalue, time.Second), ErrNoTTL{}, "PutTTL - not enabled")
+
+	assert.Nil(t, b.PutTTL(testkey, testvalue, 50*time.Millisecond), "PutTTL")
+	assert.Nil(t, b.PutTTL([]byte("forever"), testvalue, 50*time.Millisecond), "PutTTL")
+	assert.Nil(t, b.Put([]byte("forever"), testvalue), "Put - removes expiry")
+
+	assert.Equal(t, testvalue, b.Get(testkey), "Get - live")
+	assert.True(t, b.Exists(testkey), "Exists - live")
+
+	time.Sleep(60 * time.Millisecond)
+
+	_, err = b.GetE(testkey)
+	assert.ErrorIs(t, err, ErrKeyNotFound{}, "GetE - expired")
+	_, ok := b.GetOK(testkey)
+	assert.False(t, ok, "GetOK - expired")
+	assert.False(t, b.Exists(testkey), "Exists - expired")
+	assert.Equal(t, testvalue, b.Get([]byte("forever")), "Get - expiry removed")
+
+	// expired keys are never revived
+	assert.ErrorIs(t, b.Touch(testkey), ErrKeyNotFound{}, "Touch - expired")
+
+	n, err := b.PurgeExpired()
+	assert.Nil(t, err, "PurgeExpired")
+	assert.Equal(t, 1, n, "PurgeExpired")
+	assert.Equal(t, []string{"forever"}, b.GetKeysString(), "PurgeExpired - remaining")
+}
+
+func TestSlidingTTL(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket, WithSlidingTTL(testbucket))
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	ttl := 100 * time.Millisecond
+	assert.Nil(t, b.PutTTL(testkey, testvalue, ttl), "PutTTL")
+
+	// reading within the TTL keeps the key alive beyond its original expiry
+	before := b.OpStats()
+	for i := 0; i < 4; i++ {
+		time.Sleep(ttl / 2)
+		assert.Equal(t, testvalue, b.Get(testkey), "Get - sliding")
+	}
+	assert.Equal(t, uint64(4), b.OpStats().Touches-before.Touches, "OpStats - touches")
+
+	// an explicit Touch also refreshes the expiry
+	time.Sleep(ttl / 2)
+	assert.Nil(t, b.Touch(testkey), "Touch")
+	time.Sleep(ttl / 2)
+	_, ok := b.GetOK(testkey)
+	assert.True(t, ok, "GetOK - after Touch")
+
+	// once expired a read does not resurrect the key
+	time.Sleep(ttl + 10*time.Millisecond)
+	_, err = b.GetE(testkey)
+	assert.ErrorIs(t, err, ErrKeyNotFound{}, "GetE - expired")
+	_, err = b.GetE(testkey)
+	assert.ErrorIs(t, err, ErrKeyNotFound{}, "GetE - still expired")
+
+	// keys without an expiry are not touched
+	before = b.OpStats()
+	assert.Nil(t, b.Put([]byte("plain"), testvalue), "Put")
+	assert.Equal(t, testvalue, b.Get([]byte("plain")), "Get - plain")
+	assert.Equal(t, before.Touches, b.OpStats().Touches, "OpStats - no touch")
+	assert.Equal(t, before.Writes+1, b.OpStats().Writes, "OpStats - no refresh write")
+}
+
+func TestTTLReopenedWithout(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket, WithTTL(testbucket))
+	if err != nil {
+		panic(err)
+	}
+
+	assert.Nil(t, b.PutTTL(testkey, testvalue, 50*time.Millisecond), "PutTTL")
+	assert.Nil(t, b.PutTTL([]byte("deleted"), testvalue, 50*time.Millisecond), "PutTTL")
+	assert.Nil(t, b.Close(), "Close")
+
+	// writes made without WithTTL still remove the expiry
+	b, err = OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+
+	assert.Nil(t, b.Put(testkey, testvalue), "Put - without TTL")
+	assert.Nil(t, b.Delete([]byte("deleted")), "Delete - without TTL")
+	assert.Nil(t, b.Close(), "Close")
+
+	b, err = OpenBucket(testdb, testbucket, WithTTL(testbucket))
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	time.Sleep(60 * time.Millisecond)
+
+	assert.Equal(t, testvalue, b.Get(testkey), "Get - expiry removed by Put")
+
+	err = b.db.view(func(tx *bolt.Tx) error {
+		assert.Nil(t, tx.Bucket(ttlBucket(testbucket)).Get([]byte("deleted")), "Delete - expiry removed")
+
+		return nil
+	})
+	assert.Nil(t, err, "view")
+
+	n, err := b.PurgeExpired()
+	assert.Nil(t, err, "PurgeExpired")
+	assert.Equal(t, 0, n, "PurgeExpired")
+}
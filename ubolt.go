@@ -1,21 +1,117 @@
 // Package ubolt wraps various calls from "go.etcd.io/bbolt" to make basic use simpler and quicker.
 //
 // Various calls such as Get, Put etc are automatically wrapped in transactions to ensure consistency.
+//
+// Bucket names beginning with "__" are reserved for internal use and are not returned by GetBuckets.
 package ubolt
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
-	"encoding/gob"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	bolt "go.etcd.io/bbolt"
 )
 
+var reservedPrefix = []byte("__")
+
 type Database struct {
-	db *bolt.DB
+	// handle is replaced when the database is compacted by CompactInPlace
+	handle       atomic.Pointer[bolt.DB]
+	boltOptions  bolt.Options
+	fs           FS
+	keyEncoding  SequenceKeyEncoding
+	codec        Codec
+	keyTransform func([]byte) []byte
+	middleware   []ValueMiddleware
+	decodeLimit  int64
+	strictMode   bool
+	noCreate     bool
+	audit        bool
+	auditActor   func() []byte
+
+	suffixIndexes map[string]bool
+	timestamps    bool
+	blooms        map[string]*bloomConfig
+	// ttls maps buckets with TTL enabled to whether reads slide the expiry
+	ttls map[string]bool
+
+	preloads        map[string]*preloadCache
+	preloadMaxBytes int64
+	// preloadPending holds the changes to preloaded buckets made by the current read/write transaction, and is only accessed while holding
+	// the writer lock
+	preloadPending []preloadOp
+	// preloadMu is held by writers from commit until their changes are applied, so preloaded buckets see changes in commit order
+	preloadMu sync.Mutex
+
+	// gate is held for reading by writers and for writing by Freeze
+	gate           sync.RWMutex
+	failWhenFrozen bool
+
+	// generation is incremented after every committed write
+	generation atomic.Uint64
+	// lastWrite holds the statistics of the most recently committed write
+	lastWrite atomic.Pointer[bolt.TxStats]
+	flights   *flightGroup
+	// loads deduplicates concurrent calls of GetOrLoad loaders
+	loads flightGroup
+
+	counters opCounters
+
+	// closed is set once Close succeeds
+	closed atomic.Bool
+
+	// compactMu serialises CompactInPlace and Close
+	compactMu   sync.Mutex
+	autoCompact *autoCompact
+	// lastCompaction is the time in nanoseconds since the epoch that CompactInPlace last succeeded
+	lastCompaction atomic.Int64
+
+	// allocSize and preallocFrom are only accessed while holding the writer lock
+	allocSize    int
+	preallocFrom int64
+
+	// mirror is set by Mirror to replay committed mutations to a secondary database
+	mirror atomic.Pointer[mirror]
+	// mirrorPending holds the mutations of the current read/write transaction to replay, and is only accessed while holding the writer lock
+	mirrorPending []mirrorOp
+
+	// merges maps buckets to the MergeFunc registered using RegisterMerge
+	merges  map[string]MergeFunc
+	mergeMu sync.RWMutex
+}
+
+// Op describes the kind of mutation made to the database.
+type Op string
+
+const (
+	OpPut          Op = "put"
+	OpPutV         Op = "putv"
+	OpEncode       Op = "encode"
+	OpDelete       Op = "delete"
+	OpDeleteBucket Op = "deletebucket"
+	OpMerge        Op = "merge"
+	// OpImport is a key written by ImportArchive, whose expiry and timestamps are restored from the archive.
+	OpImport Op = "import"
+)
+
+type mutation struct {
+	op     Op
+	bucket []byte
+	key    []byte
+	value  []byte
+	// prev is the value stored under key before the mutation as returned by previous, which is only looked up for buckets with a quota
+	prev []byte
 }
 
 type Bucket struct {
@@ -26,11 +122,17 @@ type Bucket struct {
 // ErrBucketNotFound is returned when the bucket requested was not found.
 type ErrBucketNotFound struct {
 	bucket []byte
+	// missing is the first segment of a nested bucket path that does not exist, if known
+	missing []byte
 }
 
 // Error returns the formatted configuration error.
 func (bnf ErrBucketNotFound) Error() string {
-	return fmt.Sprintf("Bucket %s not found", string(bnf.bucket))
+	if bnf.missing != nil {
+		return fmt.Sprintf("Bucket %s not found as %s does not exist", bucketName(bnf.bucket), string(bnf.missing))
+	}
+
+	return fmt.Sprintf("Bucket %s not found", bucketName(bnf.bucket))
 }
 
 // Is allows testing using errors.Is
@@ -48,7 +150,7 @@ type ErrKeyNotFound struct {
 
 // Error returns the formatted configuration error.
 func (knf ErrKeyNotFound) Error() string {
-	return fmt.Sprintf("Key %s not found in bucket %s", string(knf.key), string(knf.bucket))
+	return fmt.Sprintf("Key %s not found in bucket %s", string(knf.key), bucketName(knf.bucket))
 }
 
 // Is allows testing using errors.Is
@@ -58,324 +160,1635 @@ func (knf ErrKeyNotFound) Is(target error) bool {
 	return is
 }
 
-// Open creates and opens a database at the given path. If the file does not exist it will be created automatically.
-// The database is opened with a file-mode of 0600 and a timeout of 5 seconds
-func Open(path string) (*Database, error) {
-	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
-	if err != nil {
-		return nil, err
-	}
-
-	return &Database{db}, nil
+// ErrKeyExists is returned by PutIfNotExists when the key already exists.
+type ErrKeyExists struct {
+	bucket []byte
+	key    []byte
 }
 
-// OpenBucket performs the same process as Open however only one bucket is usable in subsequent calls to Put, Get etc
-func OpenBucket(path string, bucket []byte) (*Bucket, error) {
-	db, err := Open(path)
-	if err != nil {
-		return nil, err
-	}
+// Error returns the formatted configuration error.
+func (ke ErrKeyExists) Error() string {
+	return fmt.Sprintf("Key %s already exists in bucket %s", string(ke.key), bucketName(ke.bucket))
+}
 
-	if err := db.CreateBucket(bucket); err != nil {
-		return nil, err
-	}
+// Is allows testing using errors.Is
+func (ke ErrKeyExists) Is(target error) bool {
+	_, is := target.(ErrKeyExists)
 
-	return &Bucket{db: db, bucket: bucket}, nil
+	return is
 }
 
-// Close releases all database resources and closes the file. This call will block while any open transactions complete.
-func (db *Database) Close() error {
-	return db.db.Close()
+// ErrReadOnly is returned when a mutating method is called on a database that was opened read-only or whose file lives on a read-only filesystem.
+type ErrReadOnly struct {
+	err error
 }
 
-// Close releases all database resources and closes the file. This call will block while any open transactions complete.
-func (b *Bucket) Close() error {
-	return b.db.Close()
-}
+// Error returns the formatted configuration error.
+func (ro ErrReadOnly) Error() string {
+	if ro.err == nil {
+		return "Database is read-only"
+	}
 
-// Ping tests the database by attempting to retrieve a list of buckets.
-func (db *Database) Ping() error {
-	_, err := db.GetBucketsE()
-	return err
+	return fmt.Sprintf("Database is read-only: %v", ro.err)
 }
 
-// Ping tests the database by attempting to retrieve a list of buckets.
-func (b *Bucket) Ping() error {
-	return b.db.Ping()
+// Is allows testing using errors.Is
+func (ro ErrReadOnly) Is(target error) bool {
+	_, is := target.(ErrReadOnly)
+
+	return is
 }
 
-// Put sets the specified key in the chosen bucket to the provided value. This process is wrapped in a read/write transaction.
-func (db *Database) Put(bucket, key, value []byte) error {
-	if key == nil {
-		_, err := db.PutV(bucket, value)
+// Unwrap returns the underlying error that indicated the database was read-only.
+func (ro ErrReadOnly) Unwrap() error {
+	return ro.err
+}
 
+// readOnlyError translates the various underlying causes of a failed write on a read-only database or filesystem into ErrReadOnly, returning
+// any other error unchanged.
+func readOnlyError(err error) error {
+	switch {
+	case err == nil, errors.Is(err, ErrReadOnly{}):
 		return err
+	case errors.Is(err, bolt.ErrDatabaseReadOnly), errors.Is(err, bolt.ErrTxNotWritable), errors.Is(err, syscall.EROFS):
+		return ErrReadOnly{err}
 	}
 
-	return db.db.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket(bucket)
-		if b == nil {
-			return ErrBucketNotFound{bucket}
-		}
-
-		return b.Put(key, value)
-	})
+	return err
 }
 
-// Put sets the specified key in the bucket opened to the provided value. This process is wrapped in a read/write transaction.
-func (b *Bucket) Put(key, value []byte) error {
-	return b.db.Put(b.bucket, key, value)
+// ErrDatabaseNotFound is returned by Open when WithNoCreate is used and the database file does not exist.
+type ErrDatabaseNotFound struct {
+	path string
 }
 
-// PutV sets a key based on an auto-incrementing value for the key.
-func (db *Database) PutV(bucket, value []byte) (key []byte, err error) {
-	err = db.db.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket(bucket)
-		if b == nil {
-			return ErrBucketNotFound{bucket}
-		}
-
-		// generate key
-		id, err := b.NextSequence()
-		if err != nil {
-			return err
-		}
+// Error returns the formatted configuration error.
+func (dnf ErrDatabaseNotFound) Error() string {
+	return fmt.Sprintf("Database %s not found", dnf.path)
+}
 
-		// convert id into []byte
-		key = itob(id)
+// Is allows testing using errors.Is
+func (dnf ErrDatabaseNotFound) Is(target error) bool {
+	_, is := target.(ErrDatabaseNotFound)
 
-		return b.Put(key, value)
-	})
+	return is
+}
 
-	if err != nil {
-		return nil, err
-	}
+// ErrLockTimeout is returned by Open when the file lock on the database could not be obtained before the timeout set by WithTimeout expired.
+// This usually means another process has the database open.
+type ErrLockTimeout struct {
+	// Path is the path to the database file.
+	Path string
+	// Waited is how long Open waited for the lock.
+	Waited time.Duration
 
-	return key, nil
+	err error
 }
 
-// PutV sets a key based on an auto-incrementing value for the key.
-func (b *Bucket) PutV(value []byte) (key []byte, err error) {
-	return b.db.PutV(b.bucket, value)
+// Error returns the formatted configuration error.
+func (lt ErrLockTimeout) Error() string {
+	return fmt.Sprintf("Timed out after %s waiting for the lock on database %s, another process likely has it open", lt.Waited.Round(time.Millisecond), lt.Path)
 }
 
-// GetE retrieves the specified key from the chosen bucket and returns the value and an error. The returned error is non-nil if a failure occurred, which includes if the bucket or key was not found.
-func (db *Database) GetE(bucket, key []byte) (value []byte, err error) {
-	if err := db.db.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket(bucket)
-		if b == nil {
-			return ErrBucketNotFound{bucket}
-		}
+// Is allows testing using errors.Is
+func (lt ErrLockTimeout) Is(target error) bool {
+	_, is := target.(ErrLockTimeout)
 
-		data := b.Get(key)
-		if data == nil {
-			return ErrKeyNotFound{bucket: bucket, key: key}
-		}
+	return is
+}
 
-		value = append(value, data...)
+// Unwrap returns the underlying timeout error from bolt.
+func (lt ErrLockTimeout) Unwrap() error {
+	return lt.err
+}
 
-		return nil
-	}); err != nil {
-		return nil, err
-	}
+// ErrReservedBucket is returned when attempting to delete one of the reserved buckets used internally, whose names begin with "__".
+type ErrReservedBucket struct {
+	bucket []byte
+}
 
-	return value, nil
+// Error returns the formatted configuration error.
+func (rb ErrReservedBucket) Error() string {
+	return fmt.Sprintf("Bucket %s is reserved", string(rb.bucket))
 }
 
-// GetE retrieves the specified key and returns the value and an error. The returned error is non-nil if a failure occurred, which includes if the key was not found.
-func (b *Bucket) GetE(key []byte) (value []byte, err error) {
-	return b.db.GetE(b.bucket, key)
+// Is allows testing using errors.Is
+func (rb ErrReservedBucket) Is(target error) bool {
+	_, is := target.(ErrReservedBucket)
+
+	return is
 }
 
-// Get retrieves the specified key from the chosen bucket and returns the value. The value returned may be nil which indicates the bucket or key was not found.
-func (db *Database) Get(bucket, key []byte) (value []byte) {
-	value, _ = db.GetE(bucket, key)
+// ErrTooManyKeys is returned when a bucket contains more keys than the limit requested.
+type ErrTooManyKeys struct {
+	bucket []byte
+	limit  int
+}
 
-	return value
+// Error returns the formatted configuration error.
+func (tmk ErrTooManyKeys) Error() string {
+	return fmt.Sprintf("Bucket %s contains more than %d keys", string(tmk.bucket), tmk.limit)
 }
 
-// Get retrieves the specified key and returns the value. The value returned may be nil which indicates the key was not found.
-func (b *Bucket) Get(key []byte) (value []byte) {
-	return b.db.Get(b.bucket, key)
+// Is allows testing using errors.Is
+func (tmk ErrTooManyKeys) Is(target error) bool {
+	_, is := target.(ErrTooManyKeys)
+
+	return is
 }
 
-// Encode encodes the provided value using "encoding/gob" then writes the resulting byte slice to the provided key
-func (db *Database) Encode(bucket, key []byte, value interface{}) error {
-	var buf bytes.Buffer
+// Open creates and opens a database at the given path. If the file does not exist it will be created automatically.
+// The database is opened with a file-mode of 0600 and a timeout of 5 seconds, which may be changed using the provided options.
+func Open(path string, opts ...Option) (*Database, error) {
+	db := &Database{
+		boltOptions: bolt.Options{Timeout: 5 * time.Second},
+		codec:       GobCodec,
+		fs:          osFS{},
+	}
 
-	enc := gob.NewEncoder(&buf)
-	if err := enc.Encode(value); err != nil {
-		return err
+	for _, o := range opts {
+		o(db)
 	}
 
-	return db.Put(bucket, key, buf.Bytes())
-}
+	if db.noCreate {
+		if err := checkExists(path, db.fs); err != nil {
+			return nil, err
+		}
+	}
 
-// Encode encodes the provided value using "encoding/gob" then writes the resulting byte slice to the provided key
-func (b *Bucket) Encode(key []byte, value interface{}) error {
-	return b.db.Encode(b.bucket, key, value)
-}
+	start := time.Now()
 
-// Decode retrieves and decodes a value set by Encode into the provided pointer value.
-func (db *Database) Decode(bucket, key []byte, value interface{}) error {
-	data, err := db.GetE(bucket, key)
+	bdb, err := bolt.Open(path, 0600, &db.boltOptions)
 	if err != nil {
-		return err
+		if errors.Is(err, bolt.ErrTimeout) {
+			return nil, ErrLockTimeout{Path: path, Waited: time.Since(start), err: err}
+		}
+
+		return nil, err
 	}
+	bdb.StrictMode = db.strictMode
+	db.handle.Store(bdb)
 
-	buf := bytes.NewBuffer(data)
-	dec := gob.NewDecoder(buf)
+	if err := db.buildBloomFilters(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
 
-	return dec.Decode(value)
-}
+	if err := db.loadPreloads(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
 
-// Decode retrieves and decodes a value set by Encode into the provided pointer value.
-func (b *Bucket) Decode(key []byte, value interface{}) error {
-	return b.db.Decode(b.bucket, key, value)
+	db.startAutoCompact()
+
+	return db, nil
 }
 
-// Delete removes the specified key in the chosen bucket. This process is wrapped in a read/write transaction.
-func (db *Database) Delete(bucket, key []byte) error {
-	return db.db.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket(bucket)
-		if b == nil {
-			return ErrBucketNotFound{bucket}
+// checkExists returns ErrDatabaseNotFound if the file at path does not exist. The file is opened using fsys so the check is made through the
+// same function bolt will use to open the database.
+func checkExists(path string, fsys FS) error {
+	f, err := fsys.OpenFile(path, os.O_RDONLY, 0)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return ErrDatabaseNotFound{path}
 		}
 
-		return b.Delete(key)
-	})
-}
+		return err
+	}
 
-// Delete removes the specified key. This process is wrapped in a read/write transaction.
-func (b *Bucket) Delete(key []byte) error {
-	return b.db.Delete(b.bucket, key)
+	return f.Close()
 }
 
-// DeleteBucket removes the specified bucket. This also deletes all keys contained in the bucket and any nested buckets.
-func (db *Database) DeleteBucket(bucket []byte) error {
-	return db.db.Update(func(tx *bolt.Tx) error {
-		return tx.DeleteBucket(bucket)
-	})
-}
+// OpenBucket performs the same process as Open however only one bucket is usable in subsequent calls to Put, Get etc. ErrInvalidBucketName
+// is returned if the name begins with the prefix reserved for nested paths without being one.
+func OpenBucket(path string, bucket []byte, opts ...Option) (*Bucket, error) {
+	if err := checkBucketName(bucket); err != nil {
+		return nil, err
+	}
 
-// DeleteBucket removes the specified bucket. This also deletes all keys contained in the bucket and any nested buckets.
-func (db *Database) CreateBucket(bucket []byte) error {
-	return db.db.Update(func(tx *bolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists(bucket)
+	db, err := Open(path, opts...)
+	if err != nil {
+		return nil, err
+	}
 
-		return err
-	})
-}
+	// a read-only database can only verify the bucket exists
+	if db.IsReadOnly() {
+		if err := db.view(func(tx *bolt.Tx) error {
+			if lookupBucket(tx, bucket) == nil {
+				return ErrBucketNotFound{bucket: bucket}
+			}
 
-func (db *Database) GetKeysE(bucket []byte) (keys [][]byte, err error) {
-	if err := db.db.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket(bucket)
-		if b == nil {
-			return ErrBucketNotFound{bucket}
+			return nil
+		}); err != nil {
+			db.Close()
+			return nil, err
 		}
 
-		c := b.Cursor()
-		for k, _ := c.First(); k != nil; k, _ = c.Next() {
-			keys = append(keys, k)
-		}
+		return &Bucket{db: db, bucket: bucket}, nil
+	}
 
-		return nil
-	}); err != nil {
+	if err := db.CreateBucket(bucket); err != nil {
 		return nil, err
 	}
 
-	return keys, nil
-}
-
-func (b *Bucket) GetKeysE() (keys [][]byte, err error) {
-	return b.db.GetKeysE(b.bucket)
+	return &Bucket{db: db, bucket: bucket}, nil
 }
 
-func (db *Database) GetKeys(bucket []byte) (keys [][]byte) {
-	keys, _ = db.GetKeysE(bucket)
-
-	return keys
+// ErrOpenBucket is returned by OpenBuckets when one of the buckets could not be opened, naming the bucket that failed.
+type ErrOpenBucket struct {
+	bucket []byte
+	err    error
 }
 
-func (b *Bucket) GetKeys() (keys [][]byte) {
-	return b.db.GetKeys(b.bucket)
+// Error returns the formatted configuration error.
+func (ob ErrOpenBucket) Error() string {
+	return fmt.Sprintf("Could not open bucket %s: %v", bucketName(ob.bucket), ob.err)
 }
 
-func (db *Database) GetBucketsE() (buckets [][]byte, err error) {
-	if err := db.db.View(func(tx *bolt.Tx) error {
-		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
-			buckets = append(buckets, name)
-			return nil
-		})
-	}); err != nil {
-		return nil, err
-	}
+// Is allows testing using errors.Is
+func (ob ErrOpenBucket) Is(target error) bool {
+	_, is := target.(ErrOpenBucket)
 
-	return buckets, nil
+	return is
 }
 
-func (db *Database) GetBuckets() (buckets [][]byte) {
-	buckets, _ = db.GetBucketsE()
-
-	return buckets
+// Unwrap returns the error that prevented the bucket from being opened.
+func (ob ErrOpenBucket) Unwrap() error {
+	return ob.err
 }
 
-func (db *Database) ForEach(bucket []byte, fn func(k, v []byte) error) error {
-	return db.db.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket(bucket)
+// Bucket returns the name of the bucket that could not be opened.
+func (ob ErrOpenBucket) Bucket() []byte {
+	return ob.bucket
+}
 
-		if b == nil {
-			return ErrBucketNotFound{bucket}
+// OpenBuckets opens the database at path once and returns a Bucket for each of the provided names, keyed by name, along with the Database
+// they share. Any buckets that do not exist are created in a single read/write transaction, so either all of them are created or none are.
+// A read-only database can only verify the buckets exist.
+//
+// If any bucket can not be opened the database is closed and ErrOpenBucket is returned naming the bucket that failed. Every returned Bucket
+// shares the one Database, so closing the Database, or any of the buckets, closes them all.
+func OpenBuckets(path string, names [][]byte, opts ...Option) (map[string]*Bucket, *Database, error) {
+	for _, name := range names {
+		if err := checkBucketName(name); err != nil {
+			return nil, nil, ErrOpenBucket{bucket: name, err: err}
 		}
+	}
 
-		return b.ForEach(fn)
-	})
-}
-
-func (b *Bucket) ForEach(fn func(k, v []byte) error) error {
-	return b.db.ForEach(b.bucket, fn)
-}
+	db, err := Open(path, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
 
-func (db *Database) Scan(bucket, prefix []byte, fn func(k, v []byte) error) error {
-	return db.db.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket(bucket)
+	txn := db.update
+	if db.IsReadOnly() {
+		txn = db.view
+	}
 
-		if b == nil {
-			return ErrBucketNotFound{bucket}
-		}
+	if err := txn(func(tx *bolt.Tx) error {
+		for _, name := range names {
+			if db.IsReadOnly() {
+				if lookupBucket(tx, name) == nil {
+					return ErrOpenBucket{bucket: name, err: ErrBucketNotFound{bucket: name}}
+				}
 
-		c := b.Cursor()
+				continue
+			}
 
-		for key, val := c.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, val = c.Next() {
-			if err := fn(key, val); err != nil {
-				return err
+			if _, err := createBucketPath(tx, name); err != nil {
+				return ErrOpenBucket{bucket: name, err: err}
 			}
 		}
 
 		return nil
-	})
-}
+	}); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
 
-func (b *Bucket) Scan(prefix []byte, fn func(k, v []byte) error) error {
-	return b.db.Scan(b.bucket, prefix, fn)
+	buckets := make(map[string]*Bucket, len(names))
+	for _, name := range names {
+		buckets[string(name)] = &Bucket{db: db, bucket: name}
+	}
+
+	return buckets, db, nil
 }
 
-func (db *Database) WriteTo(w io.Writer) (n int64, err error) {
-	if err := db.db.View(func(tx *bolt.Tx) error {
-		var err error
+// Close releases all database resources and closes the file. This call will block while any open transactions complete.
+func (db *Database) Close() error {
+	db.stopAutoCompact()
 
-		n, err = tx.WriteTo(w)
+	db.compactMu.Lock()
+	defer db.compactMu.Unlock()
 
+	if err := db.bdb().Close(); err != nil {
 		return err
-	}); err != nil {
-		return n, err
 	}
 
-	return n, nil
+	db.closed.Store(true)
+
+	return nil
 }
 
-func itob(v uint64) []byte {
-	b := make([]byte, 8)
-	binary.BigEndian.PutUint64(b, v)
-	return b
+// Close releases all database resources and closes the file. This call will block while any open transactions complete.
+func (b *Bucket) Close() error {
+	return b.db.Close()
+}
+
+// IsReadOnly returns true if the database was opened read-only, in which case all mutating methods return ErrReadOnly.
+func (db *Database) IsReadOnly() bool {
+	return db.bdb().IsReadOnly()
+}
+
+// IsReadOnly returns true if the database was opened read-only, in which case all mutating methods return ErrReadOnly.
+func (b *Bucket) IsReadOnly() bool {
+	return b.db.IsReadOnly()
+}
+
+// Ping tests the database by attempting to retrieve a list of buckets.
+func (db *Database) Ping() error {
+	_, err := db.GetBucketsE()
+	return err
+}
+
+// Ping tests the database by verifying the bucket still exists. ErrBucketNotFound is returned if the bucket has been deleted.
+func (b *Bucket) Ping() error {
+	return b.db.view(func(tx *bolt.Tx) error {
+		if lookupBucket(tx, b.bucket) == nil {
+			return ErrBucketNotFound{bucket: b.bucket}
+		}
+
+		return nil
+	})
+}
+
+// Put sets the specified key in the chosen bucket to the provided value. This process is wrapped in a read/write transaction.
+func (db *Database) Put(bucket, key, value []byte) error {
+	return db.PutContext(context.Background(), bucket, key, value)
+}
+
+// Put sets the specified key in the bucket opened to the provided value. This process is wrapped in a read/write transaction.
+func (b *Bucket) Put(key, value []byte) error {
+	return b.db.Put(b.bucket, key, value)
+}
+
+// PutIfNotExists sets the specified key in the chosen bucket to the provided value only if the key does not already exist, returning
+// ErrKeyExists otherwise. The check and the write are made within the same read/write transaction so concurrent calls for the same key
+// never both succeed. A key that has expired does not exist so is overwritten. As with Put a nil key writes the value under a generated
+// key as per PutV, which never exists.
+func (db *Database) PutIfNotExists(bucket, key, value []byte) error {
+	if key == nil {
+		_, err := db.PutV(bucket, value)
+
+		return err
+	}
+
+	key = db.canonicalKey(key)
+
+	value, err := db.wrapValue(bucket, key, value)
+	if err != nil {
+		return err
+	}
+
+	return db.update(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		if b.Get(key) != nil && !db.ttlExpired(tx, bucket, key) {
+			return ErrKeyExists{bucket: bucket, key: key}
+		}
+
+		prev := previous(tx, bucket, b, key)
+
+		if err := b.Put(key, value); err != nil {
+			return err
+		}
+
+		return db.onMutation(tx, mutation{op: OpPut, bucket: bucket, key: key, value: value, prev: prev})
+	})
+}
+
+// PutIfNotExists sets the specified key to the provided value only if the key does not already exist. This is forwarded to the Database
+// implementation.
+func (b *Bucket) PutIfNotExists(key, value []byte) error {
+	return b.db.PutIfNotExists(b.bucket, key, value)
+}
+
+// PutContext performs the same process as Put however the context is returned if ctx is done before the read/write transaction can begin.
+func (db *Database) PutContext(ctx context.Context, bucket, key, value []byte) error {
+	return db.put(ctx, OpPut, bucket, key, value)
+}
+
+// PutContext performs the same process as Put however the context is returned if ctx is done before the read/write transaction can begin.
+func (b *Bucket) PutContext(ctx context.Context, key, value []byte) error {
+	return b.db.PutContext(ctx, b.bucket, key, value)
+}
+
+// PutAllOptions controls the behaviour of PutAllWithOptions.
+type PutAllOptions struct {
+	// CreateBucket creates the bucket if it does not exist rather than returning ErrBucketNotFound.
+	CreateBucket bool
+
+	// ChunkSize splits the writes across multiple transactions of at most ChunkSize keys each. A value of zero or less writes
+	// every key in a single transaction.
+	//
+	// When chunking is used the operation is no longer atomic, as chunks committed before a failure are not rolled back. A failure after
+	// at least one chunk was committed is returned as ErrPartialWrite.
+	ChunkSize int
+
+	// MaxBytesPerTx splits the writes across multiple transactions so the keys and values written by each total at most MaxBytesPerTx
+	// bytes, with a single key and value larger than this written in a transaction of its own. A value of zero or less applies no limit.
+	// This may be combined with ChunkSize, in which case a new transaction is started when either limit is reached.
+	MaxBytesPerTx int
+
+	// Progress, if set, is called as transactions are committed with the number of keys written out of the number of keys in the map.
+	Progress ProgressFunc
+}
+
+// ErrPartialWrite is returned when a write split across multiple transactions fails after some transactions were committed.
+type ErrPartialWrite struct {
+	committed int
+	err       error
+}
+
+// Error returns the formatted configuration error.
+func (pw ErrPartialWrite) Error() string {
+	return fmt.Sprintf("Write failed after %d entries were committed: %v", pw.committed, pw.err)
+}
+
+// Is allows testing using errors.Is
+func (pw ErrPartialWrite) Is(target error) bool {
+	_, is := target.(ErrPartialWrite)
+
+	return is
+}
+
+// Unwrap returns the error that caused the write to fail.
+func (pw ErrPartialWrite) Unwrap() error {
+	return pw.err
+}
+
+// Committed returns the number of entries that were durably committed before the write failed.
+func (pw ErrPartialWrite) Committed() int {
+	return pw.committed
+}
+
+// PutAll sets every key in the chosen bucket to the value provided in the map. All keys are written in a single read/write transaction in sorted key order.
+func (db *Database) PutAll(bucket []byte, m map[string][]byte) error {
+	return db.PutAllWithOptions(bucket, m, PutAllOptions{})
+}
+
+// PutAll sets every key in the bucket to the value provided in the map. All keys are written in a single read/write transaction in sorted key order.
+func (b *Bucket) PutAll(m map[string][]byte) error {
+	return b.db.PutAll(b.bucket, m)
+}
+
+// PutAllWithOptions performs the same process as PutAll with the behaviour controlled by the provided PutAllOptions.
+func (db *Database) PutAllWithOptions(bucket []byte, m map[string][]byte, opts PutAllOptions) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	committed := 0
+	p := newProgress(opts.Progress, int64(len(keys)))
+
+	for start := 0; ; {
+		end := start
+		size := 0
+
+		for end < len(keys) {
+			n := len(keys[end]) + len(m[keys[end]])
+
+			// every chunk holds at least one key so an oversized value is still written
+			if end > start && ((opts.ChunkSize > 0 && end-start >= opts.ChunkSize) || (opts.MaxBytesPerTx > 0 && size+n > opts.MaxBytesPerTx)) {
+				break
+			}
+
+			size += n
+			end++
+		}
+
+		if err := db.update(func(tx *bolt.Tx) error {
+			b := lookupBucket(tx, bucket)
+			if b == nil {
+				if !opts.CreateBucket {
+					return ErrBucketNotFound{bucket: bucket}
+				}
+
+				var err error
+				b, err = createBucketPath(tx, bucket)
+				if err != nil {
+					return err
+				}
+			}
+
+			for _, k := range keys[start:end] {
+				key := db.canonicalKey([]byte(k))
+
+				value, err := db.wrapValue(bucket, key, m[k])
+				if err != nil {
+					return err
+				}
+
+				prev := previous(tx, bucket, b, key)
+
+				if err := b.Put(key, value); err != nil {
+					return err
+				}
+
+				if err := db.onMutation(tx, mutation{op: OpPut, bucket: bucket, key: key, value: value, prev: prev}); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}); err != nil {
+			if committed > 0 {
+				return ErrPartialWrite{committed: committed, err: err}
+			}
+
+			return err
+		}
+
+		committed += end - start
+		p.add(int64(end - start))
+
+		if end >= len(keys) {
+			p.finish()
+
+			return nil
+		}
+
+		start = end
+	}
+}
+
+// PutAllWithOptions performs the same process as PutAll with the behaviour controlled by the provided PutAllOptions.
+func (b *Bucket) PutAllWithOptions(m map[string][]byte, opts PutAllOptions) error {
+	return b.db.PutAllWithOptions(b.bucket, m, opts)
+}
+
+// PutV sets a key based on an auto-incrementing value for the key.
+func (db *Database) PutV(bucket, value []byte) (key []byte, err error) {
+	return db.PutVContext(context.Background(), bucket, value)
+}
+
+// PutVContext performs the same process as PutV however the context is returned if ctx is done before the read/write transaction can begin.
+func (db *Database) PutVContext(ctx context.Context, bucket, value []byte) (key []byte, err error) {
+	err = db.updateContext(ctx, func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		if err := db.checkKeyEncoding(tx, bucket, b); err != nil {
+			return err
+		}
+
+		// generate key
+		id, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		// convert id into []byte
+		key = db.keyEncoding.encode(id)
+
+		if value, err = db.wrapValue(bucket, key, value); err != nil {
+			return err
+		}
+
+		prev := previous(tx, bucket, b, key)
+
+		if err := b.Put(key, value); err != nil {
+			return err
+		}
+
+		return db.onMutation(tx, mutation{op: OpPutV, bucket: bucket, key: key, value: value, prev: prev})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// PutV sets a key based on an auto-incrementing value for the key.
+func (b *Bucket) PutV(value []byte) (key []byte, err error) {
+	return b.db.PutV(b.bucket, value)
+}
+
+// PutVContext performs the same process as PutV however the context is returned if ctx is done before the read/write transaction can begin.
+func (b *Bucket) PutVContext(ctx context.Context, value []byte) (key []byte, err error) {
+	return b.db.PutVContext(ctx, b.bucket, value)
+}
+
+// PutVID sets a key based on an auto-incrementing value for the key and returns the numeric id rather than the encoded key.
+func (db *Database) PutVID(bucket, value []byte) (id uint64, err error) {
+	key, err := db.PutV(bucket, value)
+	if err != nil {
+		return 0, err
+	}
+
+	return db.keyEncoding.decode(key)
+}
+
+// PutVID sets a key based on an auto-incrementing value for the key and returns the numeric id rather than the encoded key.
+func (b *Bucket) PutVID(value []byte) (id uint64, err error) {
+	return b.db.PutVID(b.bucket, value)
+}
+
+// GetE retrieves the specified key from the chosen bucket and returns the value and an error. The returned error is non-nil if a failure occurred, which includes if the bucket or key was not found.
+//
+// The value returned without error is never nil, so a key holding an empty value returns a zero-length slice.
+func (db *Database) GetE(bucket, key []byte) (value []byte, err error) {
+	db.counters.gets.Add(1)
+
+	return db.getKey(bucket, db.canonicalKey(key))
+}
+
+// getKey performs the lookup for GetE using a key that has already had any WithKeyTransform function applied.
+func (db *Database) getKey(bucket, key []byte) (value []byte, err error) {
+	if data, found, ok := db.preloadGet(bucket, key); ok {
+		if !found {
+			return nil, ErrKeyNotFound{bucket: bucket, key: key}
+		}
+
+		if data, err = db.unwrapValue(bucket, key, data); err != nil {
+			return nil, err
+		}
+
+		return append([]byte{}, data...), nil
+	}
+
+	if db.bloomMiss(bucket, key) {
+		return nil, ErrKeyNotFound{bucket: bucket, key: key}
+	}
+
+	if db.flights != nil {
+		return db.flights.do(db.flightKey(bucket, key), func() ([]byte, error) {
+			return db.getE(bucket, key)
+		})
+	}
+
+	return db.getE(bucket, key)
+}
+
+// getE performs the read for GetE.
+func (db *Database) getE(bucket, key []byte) (value []byte, err error) {
+	var hasTTL bool
+
+	if err := db.view(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		data := b.Get(key)
+		if data == nil {
+			return ErrKeyNotFound{bucket: bucket, key: key}
+		}
+
+		var expired bool
+		if hasTTL, expired = db.ttlState(tx, bucket, key); expired {
+			return ErrKeyNotFound{bucket: bucket, key: key}
+		}
+
+		data, err := db.unwrapValue(bucket, key, data)
+		if err != nil {
+			return err
+		}
+
+		// an existing empty value is returned as a non-nil empty slice
+		value = append([]byte{}, data...)
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	db.slideTTL(bucket, key, hasTTL)
+
+	return value, nil
+}
+
+// GetE retrieves the specified key and returns the value and an error. The returned error is non-nil if a failure occurred, which includes if the key was not found.
+func (b *Bucket) GetE(key []byte) (value []byte, err error) {
+	return b.db.GetE(b.bucket, key)
+}
+
+// Get retrieves the specified key from the chosen bucket and returns the value. The value returned may be nil which indicates the bucket or key was not found.
+//
+// An empty value is returned as a zero-length slice, however as this is easily mistaken for nil, and any error is discarded, callers that
+// need to distinguish a missing key from an empty value should use GetOK.
+func (db *Database) Get(bucket, key []byte) (value []byte) {
+	value, _ = db.GetE(bucket, key)
+
+	return value
+}
+
+// Get retrieves the specified key and returns the value. The value returned may be nil which indicates the key was not found.
+//
+// An empty value is returned as a zero-length slice, however as this is easily mistaken for nil, and any error is discarded, callers that
+// need to distinguish a missing key from an empty value should use GetOK.
+func (b *Bucket) Get(key []byte) (value []byte) {
+	return b.db.Get(b.bucket, key)
+}
+
+// GetOK retrieves a copy of the specified key from the chosen bucket. The value ok is true whenever the key exists, in which case value is
+// never nil, so an empty value is returned as a zero-length slice. A missing bucket or key returns a nil value and false.
+func (db *Database) GetOK(bucket, key []byte) (value []byte, ok bool) {
+	db.counters.gets.Add(1)
+
+	key = db.canonicalKey(key)
+
+	if data, found, ok := db.preloadGet(bucket, key); ok {
+		if !found {
+			return nil, false
+		}
+
+		data, err := db.unwrapValue(bucket, key, data)
+		if err != nil {
+			return nil, false
+		}
+
+		return append([]byte{}, data...), true
+	}
+
+	if db.bloomMiss(bucket, key) {
+		return nil, false
+	}
+
+	var hasTTL bool
+
+	_ = db.view(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return nil
+		}
+
+		data := b.Get(key)
+		if data == nil {
+			return nil
+		}
+
+		var expired bool
+		if hasTTL, expired = db.ttlState(tx, bucket, key); expired {
+			return nil
+		}
+
+		data, err := db.unwrapValue(bucket, key, data)
+		if err != nil {
+			return err
+		}
+
+		value, ok = make([]byte, len(data)), true
+		copy(value, data)
+
+		return nil
+	})
+
+	if ok {
+		db.slideTTL(bucket, key, hasTTL)
+	}
+
+	return value, ok
+}
+
+// GetOK retrieves a copy of the specified key. The value ok is true whenever the key exists, even if the value stored is empty.
+func (b *Bucket) GetOK(key []byte) (value []byte, ok bool) {
+	return b.db.GetOK(b.bucket, key)
+}
+
+// Exists returns true if the specified key exists in the chosen bucket. A missing bucket returns false.
+func (db *Database) Exists(bucket, key []byte) (exists bool) {
+	exists, _ = db.Has(bucket, key)
+
+	return exists
+}
+
+// Exists returns true if the specified key exists.
+func (b *Bucket) Exists(key []byte) (exists bool) {
+	return b.db.Exists(b.bucket, key)
+}
+
+// Has reports whether the specified key exists in the chosen bucket within a read-only transaction, without copying its value. A missing or
+// expired key returns false with a nil error, while ErrBucketNotFound is returned if the bucket does not exist.
+func (db *Database) Has(bucket, key []byte) (bool, error) {
+	key = db.canonicalKey(key)
+
+	if _, found, ok := db.preloadGet(bucket, key); ok {
+		return found, nil
+	}
+
+	if db.bloomMiss(bucket, key) {
+		return false, nil
+	}
+
+	var exists bool
+
+	if err := db.view(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		exists = b.Get(key) != nil && !db.ttlExpired(tx, bucket, key)
+
+		return nil
+	}); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// Has reports whether the specified key exists without copying its value. This is forwarded to the Database implementation.
+func (b *Bucket) Has(key []byte) (bool, error) {
+	return b.db.Has(b.bucket, key)
+}
+
+// GetID retrieves the value stored under the numeric id returned by PutVID from the chosen bucket. Errors are returned as per GetE.
+func (db *Database) GetID(bucket []byte, id uint64) (value []byte, err error) {
+	db.counters.gets.Add(1)
+
+	// generated keys are stored as is so the key transform is not applied
+	return db.getKey(bucket, db.keyEncoding.encode(id))
+}
+
+// GetID retrieves the value stored under the numeric id returned by PutVID. Errors are returned as per GetE.
+func (b *Bucket) GetID(id uint64) (value []byte, err error) {
+	return b.db.GetID(b.bucket, id)
+}
+
+// Encode encodes the provided value using the configured Codec, which defaults to "encoding/gob", then writes the resulting byte slice to the provided key.
+// ErrNotEncodable is returned if the value is nil, a nil pointer or cannot be encoded by the Codec.
+func (db *Database) Encode(bucket, key []byte, value interface{}) error {
+	return db.EncodeContext(context.Background(), bucket, key, value)
+}
+
+// EncodeContext performs the same process as Encode however the context is returned if ctx is done before the read/write transaction can begin.
+func (db *Database) EncodeContext(ctx context.Context, bucket, key []byte, value interface{}) error {
+	data, err := db.encode(bucket, key, value)
+	if err != nil {
+		return err
+	}
+
+	return db.put(ctx, OpEncode, bucket, key, data)
+}
+
+// Encode encodes the provided value using the configured Codec then writes the resulting byte slice to the provided key
+func (b *Bucket) Encode(key []byte, value interface{}) error {
+	return b.db.Encode(b.bucket, key, value)
+}
+
+// EncodeContext performs the same process as Encode however the context is returned if ctx is done before the read/write transaction can begin.
+func (b *Bucket) EncodeContext(ctx context.Context, key []byte, value interface{}) error {
+	return b.db.EncodeContext(ctx, b.bucket, key, value)
+}
+
+// Decode retrieves and decodes a value set by Encode into the provided pointer value. Errors returned by the Codec are returned as ErrDecode,
+// and values larger than any limit set by WithDecodeLimit are rejected as per DecodeLimited.
+func (db *Database) Decode(bucket, key []byte, value interface{}) error {
+	return db.DecodeLimited(bucket, key, value, db.decodeLimit)
+}
+
+// Decode retrieves and decodes a value set by Encode into the provided pointer value.
+func (b *Bucket) Decode(key []byte, value interface{}) error {
+	return b.db.Decode(b.bucket, key, value)
+}
+
+// Delete removes the specified key in the chosen bucket. This process is wrapped in a read/write transaction.
+func (db *Database) Delete(bucket, key []byte) error {
+	return db.DeleteContext(context.Background(), bucket, key)
+}
+
+// DeleteContext performs the same process as Delete however the context is returned if ctx is done before the read/write transaction can begin.
+func (db *Database) DeleteContext(ctx context.Context, bucket, key []byte) error {
+	return db.deleteKey(ctx, bucket, db.canonicalKey(key))
+}
+
+// deleteKey performs the delete for DeleteContext using a key that has already had any WithKeyTransform function applied.
+func (db *Database) deleteKey(ctx context.Context, bucket, key []byte) error {
+	return db.updateContext(ctx, func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		prev := previous(tx, bucket, b, key)
+
+		if err := b.Delete(key); err != nil {
+			return err
+		}
+
+		return db.onMutation(tx, mutation{op: OpDelete, bucket: bucket, key: key, prev: prev})
+	})
+}
+
+// Delete removes the specified key. This process is wrapped in a read/write transaction.
+func (b *Bucket) Delete(key []byte) error {
+	return b.db.Delete(b.bucket, key)
+}
+
+// DeleteContext performs the same process as Delete however the context is returned if ctx is done before the read/write transaction can begin.
+func (b *Bucket) DeleteContext(ctx context.Context, key []byte) error {
+	return b.db.DeleteContext(ctx, b.bucket, key)
+}
+
+// GetDelete removes the specified key from the chosen bucket and returns a copy of the value it held, reading and deleting the key within
+// a single read/write transaction so concurrent callers never receive the same value. Errors are returned as per GetE, so a missing or
+// expired key returns ErrKeyNotFound and a missing bucket ErrBucketNotFound.
+func (db *Database) GetDelete(bucket, key []byte) (value []byte, err error) {
+	db.counters.gets.Add(1)
+
+	key = db.canonicalKey(key)
+
+	if err := db.update(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		data := b.Get(key)
+		if data == nil || db.ttlExpired(tx, bucket, key) {
+			return ErrKeyNotFound{bucket: bucket, key: key}
+		}
+
+		unwrapped, err := db.unwrapValue(bucket, key, data)
+		if err != nil {
+			return err
+		}
+
+		// the value is copied before the delete as it is only valid for the life of the transaction
+		value = append([]byte{}, unwrapped...)
+
+		prev := previous(tx, bucket, b, key)
+
+		if err := b.Delete(key); err != nil {
+			return err
+		}
+
+		return db.onMutation(tx, mutation{op: OpDelete, bucket: bucket, key: key, prev: prev})
+	}); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// GetDelete removes the specified key and returns a copy of the value it held. This is forwarded to the Database implementation.
+func (b *Bucket) GetDelete(key []byte) (value []byte, err error) {
+	return b.db.GetDelete(b.bucket, key)
+}
+
+// DeleteID removes the key for the numeric id returned by PutVID in the chosen bucket. This process is wrapped in a read/write transaction.
+func (db *Database) DeleteID(bucket []byte, id uint64) error {
+	return db.deleteKey(context.Background(), bucket, db.keyEncoding.encode(id))
+}
+
+// DeleteID removes the key for the numeric id returned by PutVID. This process is wrapped in a read/write transaction.
+func (b *Bucket) DeleteID(id uint64) error {
+	return b.db.DeleteID(b.bucket, id)
+}
+
+// DeleteBucket removes the specified bucket. This also deletes all keys contained in the bucket and any nested buckets.
+func (db *Database) DeleteBucket(bucket []byte) error {
+	return db.DeleteBucketContext(context.Background(), bucket)
+}
+
+// DeleteBucketContext performs the same process as DeleteBucket however the context is returned if ctx is done before the read/write transaction can begin.
+func (db *Database) DeleteBucketContext(ctx context.Context, bucket []byte) error {
+	if isReserved(bucket) {
+		return ErrReservedBucket{bucket}
+	}
+
+	return db.updateContext(ctx, func(tx *bolt.Tx) error {
+		return db.deleteBucket(tx, bucket)
+	})
+}
+
+// deleteBucket removes the bucket along with any state kept for it in reserved buckets within the provided read/write transaction.
+func (db *Database) deleteBucket(tx *bolt.Tx, bucket []byte) error {
+	if err := deleteBucketPath(tx, bucket); err != nil {
+		return err
+	}
+
+	if err := clearKeyEncoding(tx, bucket); err != nil {
+		return err
+	}
+
+	if err := clearPartialClone(tx, bucket); err != nil {
+		return err
+	}
+
+	return db.onMutation(tx, mutation{op: OpDeleteBucket, bucket: bucket})
+}
+
+// Drop removes the bucket opened along with all keys it contains. Operations using the Bucket return ErrBucketNotFound until Recreate is
+// called.
+func (b *Bucket) Drop() error {
+	return b.db.DeleteBucket(b.bucket)
+}
+
+// Recreate removes the bucket opened, if it exists, and creates it again as an empty bucket within a single read/write transaction, which
+// resets its contents and sequence. The Bucket remains usable afterwards.
+func (b *Bucket) Recreate() error {
+	if isReserved(b.bucket) {
+		return ErrReservedBucket{b.bucket}
+	}
+
+	return b.db.update(func(tx *bolt.Tx) error {
+		if lookupBucket(tx, b.bucket) != nil {
+			if err := b.db.deleteBucket(tx, b.bucket); err != nil {
+				return err
+			}
+		}
+
+		if _, err := createBucketPath(tx, b.bucket); err != nil {
+			return err
+		}
+
+		b.db.recordMirror(tx, mutation{op: opCreateBucket, bucket: b.bucket})
+
+		return nil
+	})
+}
+
+// CreateBucket creates the specified bucket, which may be a nested path returned by BucketPath, if it does not exist. ErrInvalidBucketName
+// is returned if the name begins with the prefix reserved for nested paths without being one.
+func (db *Database) CreateBucket(bucket []byte) error {
+	return db.CreateBucketContext(context.Background(), bucket)
+}
+
+// CreateBucketContext performs the same process as CreateBucket however the context is returned if ctx is done before the read/write transaction can begin.
+func (db *Database) CreateBucketContext(ctx context.Context, bucket []byte) error {
+	if err := checkBucketName(bucket); err != nil {
+		return err
+	}
+
+	return db.updateContext(ctx, func(tx *bolt.Tx) error {
+		if _, err := createBucketPath(tx, bucket); err != nil {
+			return err
+		}
+
+		db.recordMirror(tx, mutation{op: opCreateBucket, bucket: bucket})
+
+		return nil
+	})
+}
+
+func (db *Database) GetKeysE(bucket []byte) (keys [][]byte, err error) {
+	if err := db.view(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil; k, _ = c.Next() {
+			keys = append(keys, k)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (b *Bucket) GetKeysE() (keys [][]byte, err error) {
+	return b.db.GetKeysE(b.bucket)
+}
+
+func (db *Database) GetKeys(bucket []byte) (keys [][]byte) {
+	keys, _ = db.GetKeysE(bucket)
+
+	return keys
+}
+
+func (b *Bucket) GetKeys() (keys [][]byte) {
+	return b.db.GetKeys(b.bucket)
+}
+
+// GetKeysStringE returns every key in the chosen bucket as a string ordered by key. An error is returned if the bucket was not found.
+//
+// Keys that are not valid UTF-8 are returned unchanged, as a Go string may hold arbitrary bytes, so converting back to []byte round-trips exactly.
+func (db *Database) GetKeysStringE(bucket []byte) (keys []string, err error) {
+	return db.GetKeysStringPrefixE(bucket, nil)
+}
+
+// GetKeysStringE returns every key in the bucket as a string ordered by key.
+func (b *Bucket) GetKeysStringE() (keys []string, err error) {
+	return b.db.GetKeysStringE(b.bucket)
+}
+
+// GetKeysString returns every key in the chosen bucket as a string ordered by key. The value returned may be nil which indicates the bucket was not found or was empty.
+func (db *Database) GetKeysString(bucket []byte) (keys []string) {
+	keys, _ = db.GetKeysStringE(bucket)
+
+	return keys
+}
+
+// GetKeysString returns every key in the bucket as a string ordered by key.
+func (b *Bucket) GetKeysString() (keys []string) {
+	return b.db.GetKeysString(b.bucket)
+}
+
+// GetKeysStringPrefixE returns every key that begins with prefix in the chosen bucket as a string ordered by key. An error is returned if the bucket was not found.
+//
+// As with GetKeysStringE, keys that are not valid UTF-8 are returned unchanged.
+func (db *Database) GetKeysStringPrefixE(bucket, prefix []byte) (keys []string, err error) {
+	prefix = db.canonicalKey(prefix)
+
+	if err := db.view(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		return scanPrefix(b.Cursor(), prefix, func(k, v []byte) error {
+			// string conversion copies the key out of the transaction
+			keys = append(keys, string(k))
+
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// GetKeysStringPrefixE returns every key that begins with prefix in the bucket as a string ordered by key.
+func (b *Bucket) GetKeysStringPrefixE(prefix []byte) (keys []string, err error) {
+	return b.db.GetKeysStringPrefixE(b.bucket, prefix)
+}
+
+// GetKeysStringPrefix returns every key that begins with prefix in the chosen bucket as a string ordered by key. The value returned may be nil which indicates the bucket was not found or no keys matched.
+func (db *Database) GetKeysStringPrefix(bucket, prefix []byte) (keys []string) {
+	keys, _ = db.GetKeysStringPrefixE(bucket, prefix)
+
+	return keys
+}
+
+// GetKeysStringPrefix returns every key that begins with prefix in the bucket as a string ordered by key.
+func (b *Bucket) GetKeysStringPrefix(prefix []byte) (keys []string) {
+	return b.db.GetKeysStringPrefix(b.bucket, prefix)
+}
+
+// GetValuesE returns a copy of every value in the chosen bucket ordered by key. An error is returned if the bucket was not found.
+func (db *Database) GetValuesE(bucket []byte) (values [][]byte, err error) {
+	return db.GetValuesPrefixE(bucket, nil)
+}
+
+// GetValuesE returns a copy of every value in the bucket ordered by key.
+func (b *Bucket) GetValuesE() (values [][]byte, err error) {
+	return b.db.GetValuesE(b.bucket)
+}
+
+// GetValues returns a copy of every value in the chosen bucket ordered by key. The value returned may be nil which indicates the bucket was not found or was empty.
+func (db *Database) GetValues(bucket []byte) (values [][]byte) {
+	values, _ = db.GetValuesE(bucket)
+
+	return values
+}
+
+// GetValues returns a copy of every value in the bucket ordered by key. The value returned may be nil which indicates the bucket was empty.
+func (b *Bucket) GetValues() (values [][]byte) {
+	return b.db.GetValues(b.bucket)
+}
+
+// GetValuesPrefixE returns a copy of every value whose key begins with prefix in the chosen bucket ordered by key. An error is returned if the bucket was not found.
+func (db *Database) GetValuesPrefixE(bucket, prefix []byte) (values [][]byte, err error) {
+	prefix = db.canonicalKey(prefix)
+
+	if err := db.view(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		return scanPrefix(b.Cursor(), prefix, db.unwrapFunc(bucket, func(k, v []byte) error {
+			// skip nested buckets
+			if v != nil {
+				values = append(values, append([]byte{}, v...))
+			}
+
+			return nil
+		}))
+	}); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// GetValuesPrefixE returns a copy of every value whose key begins with prefix ordered by key.
+func (b *Bucket) GetValuesPrefixE(prefix []byte) (values [][]byte, err error) {
+	return b.db.GetValuesPrefixE(b.bucket, prefix)
+}
+
+// GetValuesPrefix returns a copy of every value whose key begins with prefix in the chosen bucket ordered by key. The value returned may be nil which indicates the bucket was not found or no keys matched.
+func (db *Database) GetValuesPrefix(bucket, prefix []byte) (values [][]byte) {
+	values, _ = db.GetValuesPrefixE(bucket, prefix)
+
+	return values
+}
+
+// GetValuesPrefix returns a copy of every value whose key begins with prefix ordered by key. The value returned may be nil which indicates no keys matched.
+func (b *Bucket) GetValuesPrefix(prefix []byte) (values [][]byte) {
+	return b.db.GetValuesPrefix(b.bucket, prefix)
+}
+
+// GetAllE returns a copy of every key and value in the chosen bucket as a map. An empty bucket returns an empty map and a missing bucket returns ErrBucketNotFound.
+//
+// The entire bucket is loaded into memory, so GetAllLimitE should be preferred for buckets that may grow large.
+func (db *Database) GetAllE(bucket []byte) (all map[string][]byte, err error) {
+	return db.GetAllLimitE(bucket, 0)
+}
+
+// GetAllE returns a copy of every key and value in the bucket as a map. An empty bucket returns an empty map.
+func (b *Bucket) GetAllE() (all map[string][]byte, err error) {
+	return b.db.GetAllE(b.bucket)
+}
+
+// GetAllLimitE performs the same process as GetAllE however ErrTooManyKeys is returned if the bucket contains more than limit keys. A limit of zero or less means no limit.
+func (db *Database) GetAllLimitE(bucket []byte, limit int) (all map[string][]byte, err error) {
+	all = make(map[string][]byte)
+
+	if err := db.view(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		return b.ForEach(db.unwrapFunc(bucket, func(k, v []byte) error {
+			// skip nested buckets
+			if v == nil {
+				return nil
+			}
+
+			if limit > 0 && len(all) >= limit {
+				return ErrTooManyKeys{bucket: bucket, limit: limit}
+			}
+
+			all[string(k)] = append([]byte{}, v...)
+
+			return nil
+		}))
+	}); err != nil {
+		return nil, err
+	}
+
+	return all, nil
+}
+
+// GetAllLimitE performs the same process as GetAllE however ErrTooManyKeys is returned if the bucket contains more than limit keys. A limit of zero or less means no limit.
+func (b *Bucket) GetAllLimitE(limit int) (all map[string][]byte, err error) {
+	return b.db.GetAllLimitE(b.bucket, limit)
+}
+
+// GetAll returns a copy of every key and value in the chosen bucket as a map. The value returned is nil if the bucket was not found and an
+// empty map if the bucket was empty. As with GetAllE the entire bucket is loaded into memory.
+func (db *Database) GetAll(bucket []byte) (all map[string][]byte) {
+	all, _ = db.GetAllE(bucket)
+
+	return all
+}
+
+// GetAll returns a copy of every key and value in the bucket as a map. The value returned is nil if the bucket was not found.
+func (b *Bucket) GetAll() (all map[string][]byte) {
+	return b.db.GetAll(b.bucket)
+}
+
+func (db *Database) GetBucketsE() (buckets [][]byte, err error) {
+	if err := db.view(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			if isReserved(name) {
+				return nil
+			}
+
+			buckets = append(buckets, name)
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	return buckets, nil
+}
+
+func (db *Database) GetBuckets() (buckets [][]byte) {
+	buckets, _ = db.GetBucketsE()
+
+	return buckets
+}
+
+// BucketsWithPrefix returns a copy of the name of every top-level bucket beginning with prefix in lexicographic order. Reserved buckets
+// are excluded. An empty slice is returned when no buckets match.
+func (db *Database) BucketsWithPrefix(prefix []byte) (buckets [][]byte, err error) {
+	buckets = [][]byte{}
+
+	if err := db.view(func(tx *bolt.Tx) error {
+		return scanPrefix(tx.Cursor(), prefix, func(name, _ []byte) error {
+			if !isReserved(name) {
+				buckets = append(buckets, append([]byte{}, name...))
+			}
+
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	return buckets, nil
+}
+
+// CountBucketsWithPrefix returns the number of top-level buckets beginning with prefix, excluding reserved buckets.
+func (db *Database) CountBucketsWithPrefix(prefix []byte) (n int, err error) {
+	if err := db.view(func(tx *bolt.Tx) error {
+		return scanPrefix(tx.Cursor(), prefix, func(name, _ []byte) error {
+			if !isReserved(name) {
+				n++
+			}
+
+			return nil
+		})
+	}); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+func (db *Database) ForEach(bucket []byte, fn func(k, v []byte) error) error {
+	return db.ForEachContext(context.Background(), bucket, fn)
+}
+
+func (b *Bucket) ForEach(fn func(k, v []byte) error) error {
+	return b.db.ForEach(b.bucket, fn)
+}
+
+// ForEachContext performs the same process as ForEach however ctx is checked before each call to fn, and if it is done the read-only
+// transaction is ended and ErrIterationCanceled is returned wrapping ctx.Err().
+func (db *Database) ForEachContext(ctx context.Context, bucket []byte, fn func(k, v []byte) error) error {
+	check := newCancelCheck(ctx)
+
+	return ignoreStop(db.view(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		return b.ForEach(check.wrap(bucket, db.unwrapFunc(bucket, fn)))
+	}))
+}
+
+// ForEachContext performs the same process as ForEach however iteration stops with ErrIterationCanceled once ctx is done.
+func (b *Bucket) ForEachContext(ctx context.Context, fn func(k, v []byte) error) error {
+	return b.db.ForEachContext(ctx, b.bucket, fn)
+}
+
+// ForEachAll calls fn for every key in every bucket ordered by bucket then key. Reserved buckets, such as those used by WithTimestamps or
+// WithAuditLog, are excluded. Returning ErrStop from fn stops iterating without error.
+func (db *Database) ForEachAll(fn func(bucket, k, v []byte) error) error {
+	return db.forEachAll(context.Background(), fn, nil)
+}
+
+// ForEachAllWithProgress performs the same process as ForEachAll while reporting the number of keys visited to progress. The total is
+// reported as -1.
+func (db *Database) ForEachAllWithProgress(fn func(bucket, k, v []byte) error, progress ProgressFunc) error {
+	return db.forEachAll(context.Background(), fn, progress)
+}
+
+// ForEachAllContext performs the same process as ForEachAll however ctx is checked before each call to fn, and if it is done the read-only
+// transaction is ended and ErrIterationCanceled is returned wrapping ctx.Err().
+func (db *Database) ForEachAllContext(ctx context.Context, fn func(bucket, k, v []byte) error) error {
+	return db.forEachAll(ctx, fn, nil)
+}
+
+func (db *Database) forEachAll(ctx context.Context, fn func(bucket, k, v []byte) error, progress ProgressFunc) error {
+	p := newProgress(progress, -1)
+	check := newCancelCheck(ctx)
+
+	if err := ignoreStop(db.view(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			if isReserved(name) {
+				return nil
+			}
+
+			return b.ForEach(check.wrap(name, db.unwrapFunc(name, func(k, v []byte) error {
+				p.add(1)
+
+				return fn(name, k, v)
+			})))
+		})
+	})); err != nil {
+		return err
+	}
+
+	p.finish()
+
+	return nil
+}
+
+func (db *Database) Scan(bucket, prefix []byte, fn func(k, v []byte) error) error {
+	return db.ScanContext(context.Background(), bucket, prefix, fn)
+}
+
+func (b *Bucket) Scan(prefix []byte, fn func(k, v []byte) error) error {
+	return b.db.Scan(b.bucket, prefix, fn)
+}
+
+// ScanContext performs the same process as Scan however ctx is checked before each call to fn, and if it is done the read-only transaction
+// is ended and ErrIterationCanceled is returned wrapping ctx.Err().
+func (db *Database) ScanContext(ctx context.Context, bucket, prefix []byte, fn func(k, v []byte) error) error {
+	prefix = db.canonicalKey(prefix)
+	check := newCancelCheck(ctx)
+
+	return ignoreStop(db.view(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		return scanPrefix(b.Cursor(), prefix, check.wrap(bucket, db.unwrapFunc(bucket, fn)))
+	}))
+}
+
+// ScanContext performs the same process as Scan however iteration stops with ErrIterationCanceled once ctx is done.
+func (b *Bucket) ScanContext(ctx context.Context, prefix []byte, fn func(k, v []byte) error) error {
+	return b.db.ScanContext(ctx, b.bucket, prefix, fn)
+}
+
+func (db *Database) WriteTo(w io.Writer) (n int64, err error) {
+	if err := db.view(func(tx *bolt.Tx) error {
+		var err error
+
+		n, err = tx.WriteTo(w)
+
+		return err
+	}); err != nil {
+		return n, err
+	}
+
+	return n, nil
+}
+
+// put sets key in the chosen bucket to value, recording the mutation as op. A nil key is handled as per PutV.
+func (db *Database) put(ctx context.Context, op Op, bucket, key, value []byte) error {
+	if key == nil {
+		_, err := db.PutVContext(ctx, bucket, value)
+
+		return err
+	}
+
+	key = db.canonicalKey(key)
+
+	value, err := db.wrapValue(bucket, key, value)
+	if err != nil {
+		return err
+	}
+
+	return db.updateContext(ctx, func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		prev := previous(tx, bucket, b, key)
+
+		if err := b.Put(key, value); err != nil {
+			return err
+		}
+
+		return db.onMutation(tx, mutation{op: op, bucket: bucket, key: key, value: value, prev: prev})
+	})
+}
+
+// onMutation is called within the read/write transaction of every mutation so any enabled features are updated atomically with it.
+// Mutations of reserved buckets are ignored.
+func (db *Database) onMutation(tx *bolt.Tx, m mutation) error {
+	if isReserved(m.bucket) {
+		return nil
+	}
+
+	if err := db.updateQuota(tx, m); err != nil {
+		return err
+	}
+
+	db.counters.count(m.op)
+	db.recordMirror(tx, m)
+	db.recordPreload(m)
+
+	if m.op != OpDelete && m.op != OpDeleteBucket {
+		db.bloomAdd(m.bucket, m.key)
+	}
+
+	if db.audit {
+		if err := db.appendAudit(tx, m); err != nil {
+			return err
+		}
+	}
+
+	if err := db.updateSuffixIndex(tx, m); err != nil {
+		return err
+	}
+
+	if err := db.updateTimestamps(tx, m); err != nil {
+		return err
+	}
+
+	if err := db.updateTTL(tx, m); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// bdb returns the current bolt handle.
+func (db *Database) bdb() *bolt.DB {
+	return db.handle.Load()
+}
+
+// view wraps fn in a read-only transaction. If the handle is replaced by CompactInPlace before the transaction begins it is retried using
+// the new handle.
+func (db *Database) view(fn func(tx *bolt.Tx) error) error {
+	for {
+		h := db.bdb()
+
+		err := h.View(fn)
+		if errors.Is(err, bolt.ErrDatabaseNotOpen) && db.bdb() != h {
+			continue
+		}
+
+		return db.closedError(err)
+	}
+}
+
+// closedError returns ErrDatabaseClosed in place of the error returned by bolt once the database has been closed, returning any other error
+// unchanged.
+func (db *Database) closedError(err error) error {
+	if errors.Is(err, bolt.ErrDatabaseNotOpen) && db.closed.Load() {
+		return ErrDatabaseClosed{}
+	}
+
+	return err
+}
+
+// update wraps fn in a read/write transaction. Failures caused by a read-only database or filesystem are returned as ErrReadOnly.
+func (db *Database) update(fn func(tx *bolt.Tx) error) error {
+	return db.updateContext(context.Background(), fn)
+}
+
+// scanPrefix calls fn for every key in the cursor's bucket that begins with prefix, stopping at the first error returned by fn.
+func scanPrefix(c *bolt.Cursor, prefix []byte, fn func(k, v []byte) error) error {
+	return scanPrefixFrom(c, prefix, nil, fn)
+}
+
+// scanPrefixFrom performs the same process as scanPrefix however only keys sorting strictly after after are passed to fn. A nil after starts
+// at the beginning of the prefix.
+func scanPrefixFrom(c *bolt.Cursor, prefix, after []byte, fn func(k, v []byte) error) error {
+	seek := prefix
+	if bytes.Compare(after, prefix) > 0 {
+		seek = after
+	}
+
+	end := PrefixSuccessor(prefix)
+
+	key, val := c.Seek(seek)
+	if after != nil && bytes.Equal(key, after) {
+		key, val = c.Next()
+	}
+
+	for ; key != nil && (end == nil || bytes.Compare(key, end) < 0); key, val = c.Next() {
+		if err := fn(key, val); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isReserved(name []byte) bool {
+	return bytes.HasPrefix(splitBucketPath(name)[0], reservedPrefix)
+}
+
+func itob(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+func btoi(b []byte) uint64 {
+	return binary.BigEndian.Uint64(b)
 }
@@ -5,10 +5,14 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+
+	"github.com/andrewheberle/ubolt/ubolttest"
 )
 
 var (
@@ -30,15 +34,22 @@ type UboltDBTestSuite struct {
 	db     *Database
 	b      *Bucket
 	Bucket bool
+	// FS is the filesystem used for the database, or the local filesystem if nil
+	FS FS
 }
 
 func (s *UboltDBTestSuite) SetupTest() {
 	// start with no database
 	_ = os.Remove(testdb)
 
+	opts := []Option{WithStrictMode()}
+	if s.FS != nil {
+		opts = append(opts, WithFS(s.FS))
+	}
+
 	if s.Bucket {
 		// set up db
-		db, err := OpenBucket(testdb, testbucket)
+		db, err := OpenBucket(testdb, testbucket, opts...)
 		if err != nil {
 			panic(err)
 		}
@@ -50,7 +61,7 @@ func (s *UboltDBTestSuite) SetupTest() {
 		s.b = db
 	} else {
 		// set up db
-		db, err := Open(testdb)
+		db, err := Open(testdb, opts...)
 		if err != nil {
 			panic(err)
 		}
@@ -118,6 +129,130 @@ func (s *UboltDBTestSuite) TestPut() {
 	}
 }
 
+func (s *UboltDBTestSuite) TestPutIfNotExists() {
+	tests := []struct {
+		name    string
+		bucket  []byte
+		key     []byte
+		wantErr error
+	}{
+		{"PutIfNotExists - missing bucket", missing, testkey, ErrBucketNotFound{}},
+		{"PutIfNotExists - existing key", testbucket, testkey, ErrKeyExists{}},
+		{"PutIfNotExists - new key", testbucket, []byte("new"), nil},
+		{"PutIfNotExists - written key", testbucket, []byte("new"), ErrKeyExists{}},
+		{"PutIfNotExists - nil key", testbucket, nil, nil},
+	}
+
+	for _, tt := range tests {
+		var err error
+
+		// skip test if this is a bucket only test looking for a missing bucket
+		if s.Bucket && bytes.Equal(tt.bucket, missing) {
+			continue
+		}
+
+		if s.Bucket {
+			err = s.b.PutIfNotExists(tt.key, []byte("written"))
+		} else {
+			err = s.db.PutIfNotExists(tt.bucket, tt.key, []byte("written"))
+		}
+
+		if tt.wantErr != nil {
+			assert.ErrorIs(s.T(), err, tt.wantErr, tt.name)
+		} else {
+			assert.Nil(s.T(), err, tt.name)
+		}
+	}
+
+	// existing values are never replaced
+	if s.Bucket {
+		assert.Equal(s.T(), testvalue, s.b.Get(testkey), "PutIfNotExists - existing value kept")
+	} else {
+		assert.Equal(s.T(), testvalue, s.db.Get(testbucket, testkey), "PutIfNotExists - existing value kept")
+	}
+
+	// only one of many concurrent writers succeeds
+	db := s.db
+	if s.Bucket {
+		db = s.b.db
+	}
+
+	var wg sync.WaitGroup
+	var written atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if db.PutIfNotExists(testbucket, []byte("once"), testvalue) == nil {
+				written.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(s.T(), int32(1), written.Load(), "PutIfNotExists - concurrent")
+}
+
+func (s *UboltDBTestSuite) TestPutAll() {
+	tests := []struct {
+		name    string
+		bucket  []byte
+		m       map[string][]byte
+		opts    PutAllOptions
+		wantErr bool
+	}{
+		{"PutAll - missing bucket", missing, map[string][]byte{"a": testvalue}, PutAllOptions{}, true},
+		{"PutAll - create bucket", []byte("bucket2"), map[string][]byte{"a": testvalue}, PutAllOptions{CreateBucket: true}, false},
+		{"PutAll - empty map", testbucket, map[string][]byte{}, PutAllOptions{}, false},
+		{"PutAll - valid bucket", testbucket, map[string][]byte{"a": testvalue, "b": testvalue, "c": testvalue}, PutAllOptions{}, false},
+		{"PutAll - chunked", testbucket, map[string][]byte{"d": testvalue, "e": testvalue, "f": testvalue, "g": testvalue, "h": testvalue}, PutAllOptions{ChunkSize: 2}, false},
+		{"PutAll - invalid key", testbucket, map[string][]byte{"": testvalue}, PutAllOptions{}, true},
+	}
+
+	for _, tt := range tests {
+		var err error
+
+		// skip test if this is a bucket only test not using the test bucket
+		if s.Bucket && !bytes.Equal(tt.bucket, testbucket) {
+			continue
+		}
+
+		if s.Bucket {
+			err = s.b.PutAllWithOptions(tt.m, tt.opts)
+		} else {
+			err = s.db.PutAllWithOptions(tt.bucket, tt.m, tt.opts)
+		}
+
+		if tt.wantErr {
+			assert.NotNil(s.T(), err, tt.name)
+			continue
+		}
+
+		assert.Nil(s.T(), err, tt.name)
+
+		for k, v := range tt.m {
+			var got []byte
+
+			if s.Bucket {
+				got = s.b.Get([]byte(k))
+			} else {
+				got = s.db.Get(tt.bucket, []byte(k))
+			}
+
+			assert.Equal(s.T(), v, got, tt.name)
+		}
+	}
+
+	// PutAll without options
+	var err error
+	if s.Bucket {
+		err = s.b.PutAll(map[string][]byte{"z": testvalue})
+	} else {
+		err = s.db.PutAll(testbucket, map[string][]byte{"z": testvalue})
+	}
+	assert.Nil(s.T(), err, "PutAll - no options")
+}
+
 func (s *UboltDBTestSuite) TestPutV() {
 	tests := []struct {
 		name    string
@@ -155,6 +290,78 @@ func (s *UboltDBTestSuite) TestPutV() {
 	}
 }
 
+func (s *UboltDBTestSuite) TestPutVID() {
+	tests := []struct {
+		name    string
+		bucket  []byte
+		id      uint64
+		value   []byte
+		wantErr bool
+	}{
+		{"PutVID - missing bucket", missing, 0, nil, true},
+		{"PutVID - valid bucket - 1", testbucket, 1, testvalue, false},
+		{"PutVID - valid bucket - 2", testbucket, 2, testvalue, false},
+	}
+
+	for _, tt := range tests {
+		var id uint64
+		var err error
+
+		// skip test if this is a bucket only test looking for a missing bucket
+		if s.Bucket && bytes.Equal(tt.bucket, missing) {
+			continue
+		}
+
+		if s.Bucket {
+			id, err = s.b.PutVID(tt.value)
+		} else {
+			id, err = s.db.PutVID(tt.bucket, tt.value)
+		}
+
+		if tt.wantErr {
+			assert.NotNil(s.T(), err, tt.name)
+		} else {
+			assert.Nil(s.T(), err, tt.name)
+			assert.Equal(s.T(), tt.id, id, tt.name)
+		}
+	}
+}
+
+func (s *UboltDBTestSuite) TestGetIDDeleteID() {
+	var id uint64
+	var got []byte
+	var err error
+
+	if s.Bucket {
+		id, err = s.b.PutVID(testvalue)
+	} else {
+		id, err = s.db.PutVID(testbucket, testvalue)
+	}
+	assert.Nil(s.T(), err, "GetID - PutVID")
+
+	if s.Bucket {
+		got, err = s.b.GetID(id)
+	} else {
+		got, err = s.db.GetID(testbucket, id)
+	}
+	assert.Nil(s.T(), err, "GetID - valid id")
+	assert.Equal(s.T(), testvalue, got, "GetID - valid id")
+
+	if s.Bucket {
+		err = s.b.DeleteID(id)
+	} else {
+		err = s.db.DeleteID(testbucket, id)
+	}
+	assert.Nil(s.T(), err, "DeleteID - valid id")
+
+	if s.Bucket {
+		_, err = s.b.GetID(id)
+	} else {
+		_, err = s.db.GetID(testbucket, id)
+	}
+	assert.ErrorIs(s.T(), err, ErrKeyNotFound{}, "GetID - deleted id")
+}
+
 func (s *UboltDBTestSuite) TestGet() {
 	tests := []struct {
 		name    string
@@ -291,6 +498,399 @@ func (s *UboltDBTestSuite) TestGetKeysE() {
 
 }
 
+func (s *UboltDBTestSuite) TestGetValues() {
+	tests := []struct {
+		name    string
+		bucket  []byte
+		prefix  []byte
+		values  [][]byte
+		wantErr bool
+	}{
+		{"GetValues - missing bucket", missing, nil, nil, true},
+		{"GetValues - valid bucket", testbucket, nil, [][]byte{testvalue, []byte("value2"), []byte("value3")}, false},
+		{"GetValues - prefix", testbucket, []byte("key"), [][]byte{testvalue, []byte("value2")}, false},
+		{"GetValues - missing prefix", testbucket, missing, nil, false},
+	}
+
+	// put additional values for test
+	if s.Bucket {
+		_ = s.b.Put([]byte("key2"), []byte("value2"))
+		_ = s.b.Put([]byte("other"), []byte("value3"))
+	} else {
+		_ = s.db.Put(testbucket, []byte("key2"), []byte("value2"))
+		_ = s.db.Put(testbucket, []byte("other"), []byte("value3"))
+	}
+
+	for _, tt := range tests {
+		var values, valuesE [][]byte
+		var err error
+
+		// skip test if this is a bucket only test looking for a missing bucket
+		if s.Bucket && bytes.Equal(tt.bucket, missing) {
+			continue
+		}
+
+		switch {
+		case s.Bucket && tt.prefix == nil:
+			values = s.b.GetValues()
+			valuesE, err = s.b.GetValuesE()
+		case s.Bucket:
+			values = s.b.GetValuesPrefix(tt.prefix)
+			valuesE, err = s.b.GetValuesPrefixE(tt.prefix)
+		case tt.prefix == nil:
+			values = s.db.GetValues(tt.bucket)
+			valuesE, err = s.db.GetValuesE(tt.bucket)
+		default:
+			values = s.db.GetValuesPrefix(tt.bucket, tt.prefix)
+			valuesE, err = s.db.GetValuesPrefixE(tt.bucket, tt.prefix)
+		}
+
+		if tt.wantErr {
+			assert.ErrorIs(s.T(), err, ErrBucketNotFound{}, tt.name)
+			assert.Nil(s.T(), values, tt.name)
+		} else {
+			assert.Nil(s.T(), err, tt.name)
+			assert.Equal(s.T(), tt.values, values, tt.name)
+			assert.Equal(s.T(), tt.values, valuesE, tt.name)
+		}
+	}
+}
+
+func (s *UboltDBTestSuite) TestGetKeysString() {
+	tests := []struct {
+		name    string
+		bucket  []byte
+		prefix  []byte
+		keys    []string
+		wantErr bool
+	}{
+		{"GetKeysString - missing bucket", missing, nil, nil, true},
+		{"GetKeysString - valid bucket", testbucket, nil, []string{string(testkey), "key2", "other", "\xff\xfe"}, false},
+		{"GetKeysString - prefix", testbucket, []byte("key"), []string{string(testkey), "key2"}, false},
+		{"GetKeysString - missing prefix", testbucket, missing, nil, false},
+	}
+
+	// put additional keys for test including one that is not valid UTF-8
+	for _, k := range [][]byte{[]byte("key2"), []byte("other"), {0xff, 0xfe}} {
+		if s.Bucket {
+			_ = s.b.Put(k, testvalue)
+		} else {
+			_ = s.db.Put(testbucket, k, testvalue)
+		}
+	}
+
+	for _, tt := range tests {
+		var keys, keysE []string
+		var err error
+
+		// skip test if this is a bucket only test looking for a missing bucket
+		if s.Bucket && bytes.Equal(tt.bucket, missing) {
+			continue
+		}
+
+		switch {
+		case s.Bucket && tt.prefix == nil:
+			keys = s.b.GetKeysString()
+			keysE, err = s.b.GetKeysStringE()
+		case s.Bucket:
+			keys = s.b.GetKeysStringPrefix(tt.prefix)
+			keysE, err = s.b.GetKeysStringPrefixE(tt.prefix)
+		case tt.prefix == nil:
+			keys = s.db.GetKeysString(tt.bucket)
+			keysE, err = s.db.GetKeysStringE(tt.bucket)
+		default:
+			keys = s.db.GetKeysStringPrefix(tt.bucket, tt.prefix)
+			keysE, err = s.db.GetKeysStringPrefixE(tt.bucket, tt.prefix)
+		}
+
+		if tt.wantErr {
+			assert.ErrorIs(s.T(), err, ErrBucketNotFound{}, tt.name)
+			assert.Nil(s.T(), keys, tt.name)
+		} else {
+			assert.Nil(s.T(), err, tt.name)
+			assert.Equal(s.T(), tt.keys, keys, tt.name)
+			assert.Equal(s.T(), tt.keys, keysE, tt.name)
+		}
+	}
+
+	// non UTF-8 keys round-trip
+	if !s.Bucket {
+		keys := s.db.GetKeysString(testbucket)
+		assert.Equal(s.T(), testvalue, s.db.Get(testbucket, []byte(keys[len(keys)-1])), "round-trip non UTF-8 key")
+	}
+}
+
+func (s *UboltDBTestSuite) TestGetOK() {
+	tests := []struct {
+		name   string
+		bucket []byte
+		key    []byte
+		want   []byte
+		wantOK bool
+	}{
+		{"GetOK - missing bucket", missing, testkey, nil, false},
+		{"GetOK - missing key", testbucket, missing, nil, false},
+		{"GetOK - existing key", testbucket, testkey, testvalue, true},
+		{"GetOK - empty value", testbucket, []byte("empty"), []byte{}, true},
+	}
+
+	// put an empty value for test
+	if s.Bucket {
+		_ = s.b.Put([]byte("empty"), []byte{})
+	} else {
+		_ = s.db.Put(testbucket, []byte("empty"), []byte{})
+	}
+
+	for _, tt := range tests {
+		var value []byte
+		var ok bool
+
+		// skip test if this is a bucket only test looking for a missing bucket
+		if s.Bucket && bytes.Equal(tt.bucket, missing) {
+			continue
+		}
+
+		if s.Bucket {
+			value, ok = s.b.GetOK(tt.key)
+		} else {
+			value, ok = s.db.GetOK(tt.bucket, tt.key)
+		}
+
+		assert.Equal(s.T(), tt.wantOK, ok, tt.name)
+		assert.Equal(s.T(), tt.want, value, tt.name)
+		// assert.Equal treats nil and empty byte slices as equal
+		assert.Equal(s.T(), tt.wantOK, value != nil, tt.name)
+	}
+}
+
+func (s *UboltDBTestSuite) TestHas() {
+	tests := []struct {
+		name    string
+		bucket  []byte
+		key     []byte
+		want    bool
+		wantErr bool
+	}{
+		{"Has - missing bucket", missing, testkey, false, true},
+		{"Has - missing key", testbucket, missing, false, false},
+		{"Has - existing key", testbucket, testkey, true, false},
+		{"Has - nil key", testbucket, nil, false, false},
+	}
+
+	for _, tt := range tests {
+		var got bool
+		var err error
+
+		// skip test if this is a bucket only test looking for a missing bucket
+		if s.Bucket && bytes.Equal(tt.bucket, missing) {
+			continue
+		}
+
+		if s.Bucket {
+			got, err = s.b.Has(tt.key)
+		} else {
+			got, err = s.db.Has(tt.bucket, tt.key)
+		}
+
+		if tt.wantErr {
+			assert.ErrorIs(s.T(), err, ErrBucketNotFound{}, tt.name)
+		} else {
+			assert.Nil(s.T(), err, tt.name)
+		}
+
+		assert.Equal(s.T(), tt.want, got, tt.name)
+	}
+}
+
+func (s *UboltDBTestSuite) TestEmptyValue() {
+	empty := []byte("empty")
+
+	// the bucket handle forwards to the same methods so the database is used directly
+	db := s.db
+	if s.Bucket {
+		db = s.b.db
+	}
+
+	assert.Nil(s.T(), db.Put(testbucket, empty, []byte{}), "Put")
+
+	// existence aware reads return a non-nil empty slice
+	value, err := db.GetE(testbucket, empty)
+	assert.Nil(s.T(), err, "GetE - empty value")
+	assert.NotNil(s.T(), value, "GetE - empty value")
+	assert.Len(s.T(), value, 0, "GetE - empty value")
+
+	value, ok := db.GetOK(testbucket, empty)
+	assert.True(s.T(), ok, "GetOK - empty value")
+	assert.NotNil(s.T(), value, "GetOK - empty value")
+
+	assert.True(s.T(), db.Exists(testbucket, empty), "Exists - empty value")
+
+	has, err := db.Has(testbucket, empty)
+	assert.Nil(s.T(), err, "Has - empty value")
+	assert.True(s.T(), has, "Has - empty value")
+
+	// Get returns nil only for a missing key
+	assert.NotNil(s.T(), db.Get(testbucket, empty), "Get - empty value")
+	assert.Nil(s.T(), db.Get(testbucket, missing), "Get - missing key")
+
+	// iteration passes an empty value as a non-nil empty slice, as nil is reserved for nested buckets
+	seen := 0
+	check := func(k, v []byte) error {
+		if bytes.Equal(k, empty) {
+			seen++
+			assert.NotNil(s.T(), v, "iteration - empty value")
+			assert.Len(s.T(), v, 0, "iteration - empty value")
+		}
+
+		return nil
+	}
+	assert.Nil(s.T(), db.Scan(testbucket, empty, check), "Scan")
+	assert.Nil(s.T(), db.ForEach(testbucket, check), "ForEach")
+	assert.Equal(s.T(), 2, seen, "iteration - empty value seen")
+
+	all, err := db.GetAllE(testbucket)
+	assert.Nil(s.T(), err, "GetAllE")
+	assert.NotNil(s.T(), all[string(empty)], "GetAllE - empty value")
+
+	err = db.ViewMany(func(get func(bucket, key []byte) ([]byte, error)) error {
+		value, err := get(testbucket, empty)
+		assert.Nil(s.T(), err, "ViewMany - empty value")
+		assert.NotNil(s.T(), value, "ViewMany - empty value")
+
+		return nil
+	})
+	assert.Nil(s.T(), err, "ViewMany")
+}
+
+func (s *UboltDBTestSuite) TestGetAllE() {
+	tests := []struct {
+		name    string
+		bucket  []byte
+		limit   int
+		want    map[string][]byte
+		wantErr error
+	}{
+		{"GetAllE - missing bucket", missing, 0, nil, ErrBucketNotFound{}},
+		{"GetAllE - valid bucket", testbucket, 0, map[string][]byte{"key1": testvalue, "key2": []byte("value2")}, nil},
+		{"GetAllE - within limit", testbucket, 2, map[string][]byte{"key1": testvalue, "key2": []byte("value2")}, nil},
+		{"GetAllE - over limit", testbucket, 1, nil, ErrTooManyKeys{}},
+	}
+
+	// put additional value for test
+	if s.Bucket {
+		_ = s.b.Put([]byte("key2"), []byte("value2"))
+	} else {
+		_ = s.db.Put(testbucket, []byte("key2"), []byte("value2"))
+	}
+
+	for _, tt := range tests {
+		var got map[string][]byte
+		var err error
+
+		// skip test if this is a bucket only test looking for a missing bucket
+		if s.Bucket && bytes.Equal(tt.bucket, missing) {
+			continue
+		}
+
+		if s.Bucket {
+			got, err = s.b.GetAllLimitE(tt.limit)
+		} else {
+			got, err = s.db.GetAllLimitE(tt.bucket, tt.limit)
+		}
+
+		if tt.wantErr != nil {
+			assert.ErrorIs(s.T(), err, tt.wantErr, tt.name)
+			assert.Nil(s.T(), got, tt.name)
+		} else {
+			assert.Nil(s.T(), err, tt.name)
+			assert.Equal(s.T(), tt.want, got, tt.name)
+		}
+	}
+
+	// an empty bucket returns an empty map
+	if s.Bucket {
+		_ = s.b.Delete(testkey)
+		_ = s.b.Delete([]byte("key2"))
+	} else {
+		_ = s.db.Delete(testbucket, testkey)
+		_ = s.db.Delete(testbucket, []byte("key2"))
+	}
+
+	var got map[string][]byte
+	var err error
+
+	if s.Bucket {
+		got, err = s.b.GetAllE()
+	} else {
+		got, err = s.db.GetAllE(testbucket)
+	}
+
+	assert.Nil(s.T(), err, "GetAllE - empty bucket")
+	assert.NotNil(s.T(), got, "GetAllE - empty bucket")
+	assert.Empty(s.T(), got, "GetAllE - empty bucket")
+}
+
+func (s *UboltDBTestSuite) TestGetAll() {
+	tests := []struct {
+		name   string
+		bucket []byte
+		want   map[string][]byte
+	}{
+		{"GetAll - missing bucket", missing, nil},
+		{"GetAll - valid bucket", testbucket, map[string][]byte{"key1": testvalue, "key2": []byte("value2")}},
+	}
+
+	// put additional value for test
+	if s.Bucket {
+		_ = s.b.Put([]byte("key2"), []byte("value2"))
+	} else {
+		_ = s.db.Put(testbucket, []byte("key2"), []byte("value2"))
+	}
+
+	for _, tt := range tests {
+		var got map[string][]byte
+
+		// skip test if this is a bucket only test looking for a missing bucket
+		if s.Bucket && bytes.Equal(tt.bucket, missing) {
+			continue
+		}
+
+		if s.Bucket {
+			got = s.b.GetAll()
+		} else {
+			got = s.db.GetAll(tt.bucket)
+		}
+
+		assert.Equal(s.T(), tt.want, got, tt.name)
+	}
+
+	// values remain valid once the transaction has finished
+	var got map[string][]byte
+	if s.Bucket {
+		got = s.b.GetAll()
+		_ = s.b.Put([]byte("key2"), []byte("changed"))
+	} else {
+		got = s.db.GetAll(testbucket)
+		_ = s.db.Put(testbucket, []byte("key2"), []byte("changed"))
+	}
+
+	assert.Equal(s.T(), []byte("value2"), got["key2"], "GetAll - copied value")
+
+	// an empty bucket returns an empty map
+	if s.Bucket {
+		_ = s.b.Delete(testkey)
+		_ = s.b.Delete([]byte("key2"))
+		got = s.b.GetAll()
+	} else {
+		_ = s.db.Delete(testbucket, testkey)
+		_ = s.db.Delete(testbucket, []byte("key2"))
+		got = s.db.GetAll(testbucket)
+	}
+
+	assert.NotNil(s.T(), got, "GetAll - empty bucket")
+	assert.Empty(s.T(), got, "GetAll - empty bucket")
+}
+
 func (s *UboltDBTestSuite) TestGetBuckets() {
 	if s.Bucket {
 		return
@@ -345,6 +945,72 @@ func (s *UboltDBTestSuite) TestDelete() {
 
 }
 
+func (s *UboltDBTestSuite) TestGetDelete() {
+	tests := []struct {
+		name    string
+		bucket  []byte
+		key     []byte
+		want    []byte
+		wantErr error
+	}{
+		{"GetDelete - missing bucket", missing, testkey, nil, ErrBucketNotFound{}},
+		{"GetDelete - missing key", testbucket, missing, nil, ErrKeyNotFound{}},
+		{"GetDelete - valid key", testbucket, testkey, testvalue, nil},
+		{"GetDelete - deleted key", testbucket, testkey, nil, ErrKeyNotFound{}},
+	}
+
+	for _, tt := range tests {
+		var value []byte
+		var err error
+
+		// skip test if this is a bucket only test looking for a missing bucket
+		if s.Bucket && bytes.Equal(tt.bucket, missing) {
+			continue
+		}
+
+		if s.Bucket {
+			value, err = s.b.GetDelete(tt.key)
+		} else {
+			value, err = s.db.GetDelete(tt.bucket, tt.key)
+		}
+
+		if tt.wantErr != nil {
+			assert.ErrorIs(s.T(), err, tt.wantErr, tt.name)
+		} else {
+			assert.Nil(s.T(), err, tt.name)
+		}
+
+		assert.Equal(s.T(), tt.want, value, tt.name)
+	}
+
+	// each value is only received by one of many concurrent consumers
+	db := s.db
+	if s.Bucket {
+		db = s.b.db
+	}
+
+	for i := 0; i < 20; i++ {
+		assert.Nil(s.T(), db.Put(testbucket, []byte(fmt.Sprintf("item%02d", i)), testvalue), "Put")
+	}
+
+	var wg sync.WaitGroup
+	var received atomic.Int32
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if _, err := db.GetDelete(testbucket, []byte(fmt.Sprintf("item%02d", j))); err == nil {
+					received.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(s.T(), int32(20), received.Load(), "GetDelete - concurrent")
+}
+
 func (s *UboltDBTestSuite) TestDeleteBucket() {
 	if s.Bucket {
 		return
@@ -542,6 +1208,14 @@ func (s *UboltDBTestSuite) TestEncode() {
 	}
 }
 
+func (s *UboltDBTestSuite) TestStrictMode() {
+	if s.Bucket {
+		assert.True(s.T(), s.b.db.bdb().StrictMode, "StrictMode")
+	} else {
+		assert.True(s.T(), s.db.bdb().StrictMode, "StrictMode")
+	}
+}
+
 func (s *UboltDBTestSuite) TestPing() {
 	var err error
 
@@ -552,6 +1226,18 @@ func (s *UboltDBTestSuite) TestPing() {
 	}
 
 	assert.Nil(s.T(), err, "Ping")
+
+	if !s.Bucket {
+		return
+	}
+
+	// delete the bucket out from under the handle
+	if err := s.b.db.DeleteBucket(testbucket); err != nil {
+		panic(err)
+	}
+
+	assert.ErrorIs(s.T(), s.b.Ping(), ErrBucketNotFound{}, "Ping - deleted bucket")
+	assert.Nil(s.T(), s.b.db.Ping(), "Ping - database after deleted bucket")
 }
 
 func (s *UboltDBTestSuite) TestWriteTo() {
@@ -606,11 +1292,21 @@ func (s *UboltDBTestSuite) TearDownTest() {
 	}
 
 	_ = os.Remove(testdb)
+
+	if s.FS != nil {
+		_ = s.FS.Remove(testdb)
+	}
 }
 
 func TestUboltDBTestSuite(t *testing.T) {
 	suite.Run(t, new(UboltDBTestSuite))
 	suite.Run(t, &UboltDBTestSuite{Bucket: true})
+
+	fsys := ubolttest.NewMemFS()
+	defer fsys.Close()
+
+	suite.Run(t, &UboltDBTestSuite{FS: fsys})
+	suite.Run(t, &UboltDBTestSuite{Bucket: true, FS: fsys})
 }
 
 func (s *UboltDBTestSuite) stringencode(name string, bucket []byte, key []byte, value string, wantErr bool) {
@@ -0,0 +1,99 @@
+// Package uboltgokv adapts a ubolt.Bucket to the Store interface used by the github.com/philippgille/gokv ecosystem.
+//
+// The package does not import gokv, instead Store satisfies gokv.Store and any gokv encoding.Codec satisfies Codec as both are structural.
+package uboltgokv
+
+import (
+	"errors"
+
+	"github.com/andrewheberle/ubolt"
+)
+
+// Codec marshals and unmarshals values, matching the gokv encoding.Codec interface.
+type Codec = ubolt.Codec
+
+// Store stores values in a single ubolt bucket using string keys.
+type Store struct {
+	b     *ubolt.Bucket
+	codec Codec
+}
+
+// NewStore returns a Store backed by the provided bucket. If codec is nil values are encoded using the Codec configured for the database.
+func NewStore(b *ubolt.Bucket, codec Codec) *Store {
+	return &Store{b: b, codec: codec}
+}
+
+// Set stores the value for the key, overwriting any existing value.
+func (s *Store) Set(k string, v interface{}) error {
+	if err := checkKeyAndValue(k, v); err != nil {
+		return err
+	}
+
+	if s.codec == nil {
+		return s.b.Encode([]byte(k), v)
+	}
+
+	data, err := s.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return s.b.Put([]byte(k), data)
+}
+
+// Get retrieves the value for the key into v, which must be a pointer. If the key does not exist found is false and no error is returned.
+func (s *Store) Get(k string, v interface{}) (found bool, err error) {
+	if err := checkKeyAndValue(k, v); err != nil {
+		return false, err
+	}
+
+	if s.codec == nil {
+		err = s.b.Decode([]byte(k), v)
+	} else {
+		var data []byte
+		data, err = s.b.GetE([]byte(k))
+		if err == nil {
+			err = s.codec.Unmarshal(data, v)
+		}
+	}
+
+	if errors.Is(err, ubolt.ErrKeyNotFound{}) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Delete removes the key. Deleting a key that does not exist is not an error.
+func (s *Store) Delete(k string) error {
+	if k == "" {
+		return errEmptyKey
+	}
+
+	return s.b.Delete([]byte(k))
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.b.Close()
+}
+
+var (
+	errEmptyKey = errors.New("the passed key is an empty string, which is invalid")
+	errNilValue = errors.New("the passed value is nil, which is not allowed")
+)
+
+func checkKeyAndValue(k string, v interface{}) error {
+	if k == "" {
+		return errEmptyKey
+	}
+
+	if v == nil {
+		return errNilValue
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package uboltgokv
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/andrewheberle/ubolt"
+	"github.com/stretchr/testify/assert"
+)
+
+// gokvStore mirrors the gokv.Store interface.
+type gokvStore interface {
+	Set(k string, v interface{}) error
+	Get(k string, v interface{}) (found bool, err error)
+	Delete(k string) error
+	Close() error
+}
+
+var _ gokvStore = (*Store)(nil)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+type value struct {
+	Name  string
+	Count int
+}
+
+func TestStore(t *testing.T) {
+	tests := []struct {
+		name  string
+		codec Codec
+	}{
+		{"gob", nil},
+		{"json", jsonCodec{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = os.Remove("test.db")
+			defer os.Remove("test.db")
+
+			b, err := ubolt.OpenBucket("test.db", []byte("gokv"))
+			if err != nil {
+				panic(err)
+			}
+
+			s := NewStore(b, tt.codec)
+			defer s.Close()
+
+			// get missing
+			var got value
+			found, err := s.Get("missing", &got)
+			assert.Nil(t, err, "Get - missing")
+			assert.False(t, found, "Get - missing")
+
+			// set then get
+			assert.Nil(t, s.Set("key", value{"one", 1}), "Set")
+			found, err = s.Get("key", &got)
+			assert.Nil(t, err, "Get")
+			assert.True(t, found, "Get")
+			assert.Equal(t, value{"one", 1}, got, "Get")
+
+			// overwrite
+			assert.Nil(t, s.Set("key", value{"two", 2}), "Set - overwrite")
+			found, err = s.Get("key", &got)
+			assert.Nil(t, err, "Get - overwrite")
+			assert.True(t, found, "Get - overwrite")
+			assert.Equal(t, value{"two", 2}, got, "Get - overwrite")
+
+			// delete then get
+			assert.Nil(t, s.Delete("key"), "Delete")
+			found, err = s.Get("key", &got)
+			assert.Nil(t, err, "Get - deleted")
+			assert.False(t, found, "Get - deleted")
+
+			// delete missing
+			assert.Nil(t, s.Delete("missing"), "Delete - missing")
+
+			// invalid arguments
+			assert.NotNil(t, s.Set("", value{}), "Set - empty key")
+			assert.NotNil(t, s.Set("key", nil), "Set - nil value")
+			_, err = s.Get("", &got)
+			assert.NotNil(t, err, "Get - empty key")
+			assert.NotNil(t, s.Delete(""), "Delete - empty key")
+		})
+	}
+}
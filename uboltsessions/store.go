@@ -0,0 +1,208 @@
+// Package uboltsessions provides a github.com/gorilla/sessions Store that keeps session values in a ubolt.Bucket.
+package uboltsessions
+
+import (
+	"encoding/base32"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/andrewheberle/ubolt"
+	"github.com/gorilla/securecookie"
+	"github.com/gorilla/sessions"
+)
+
+// DefaultCleanupBatchSize is the number of expired sessions deleted per transaction by Cleanup.
+const DefaultCleanupBatchSize = 1000
+
+var base32RawStdEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// record is the value stored for each session, encoded using the Codec configured for the database which must support the types stored in
+// the session values.
+type record struct {
+	Expires time.Time
+	Values  map[interface{}]interface{}
+}
+
+// Store stores sessions in a ubolt bucket keyed by session ID, with only the signed ID held in the cookie.
+type Store struct {
+	Codecs  []securecookie.Codec
+	Options *sessions.Options // default configuration
+
+	// CleanupBatchSize is the number of expired sessions deleted per transaction by Cleanup.
+	CleanupBatchSize int
+
+	b   *ubolt.Bucket
+	now func() time.Time
+}
+
+// NewStore returns a Store backed by the provided bucket.
+//
+// Keys are defined in pairs to allow key rotation, as with sessions.NewCookieStore.
+func NewStore(b *ubolt.Bucket, keyPairs ...[]byte) *Store {
+	s := &Store{
+		Codecs: securecookie.CodecsFromPairs(keyPairs...),
+		Options: &sessions.Options{
+			Path:   "/",
+			MaxAge: 86400 * 30,
+		},
+		CleanupBatchSize: DefaultCleanupBatchSize,
+		b:                b,
+		now:              time.Now,
+	}
+
+	s.MaxAge(s.Options.MaxAge)
+
+	return s
+}
+
+// Get returns a session for the given name after adding it to the registry.
+func (s *Store) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(r).Get(s, name)
+}
+
+// New returns a session for the given name without adding it to the registry.
+//
+// A cookie that refers to a session that is missing or has expired results in a new session without error.
+func (s *Store) New(r *http.Request, name string) (*sessions.Session, error) {
+	session := sessions.NewSession(s, name)
+	opts := *s.Options
+	session.Options = &opts
+	session.IsNew = true
+
+	c, err := r.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+
+	if err := securecookie.DecodeMulti(name, c.Value, &session.ID, s.Codecs...); err != nil {
+		return session, err
+	}
+
+	found, err := s.load(session)
+	if err != nil {
+		return session, err
+	}
+
+	session.IsNew = !found
+
+	return session, nil
+}
+
+// Save persists the session and adds its cookie to the response.
+//
+// If the Options.MaxAge of the session is <= 0 the stored session is deleted and the cookie expired.
+func (s *Store) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	if session.Options.MaxAge <= 0 {
+		if session.ID != "" {
+			if err := s.b.Delete([]byte(session.ID)); err != nil {
+				return err
+			}
+		}
+
+		http.SetCookie(w, sessions.NewCookie(session.Name(), "", session.Options))
+
+		return nil
+	}
+
+	if session.ID == "" {
+		session.ID = base32RawStdEncoding.EncodeToString(securecookie.GenerateRandomKey(32))
+	}
+
+	if err := s.b.Encode([]byte(session.ID), record{
+		Expires: s.now().Add(time.Duration(session.Options.MaxAge) * time.Second),
+		Values:  session.Values,
+	}); err != nil {
+		return err
+	}
+
+	encoded, err := securecookie.EncodeMulti(session.Name(), session.ID, s.Codecs...)
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, sessions.NewCookie(session.Name(), encoded, session.Options))
+
+	return nil
+}
+
+// MaxAge sets the maximum age for the store and the underlying cookie implementation. Individual sessions can be deleted by setting
+// Options.MaxAge = -1 for that session.
+func (s *Store) MaxAge(age int) {
+	s.Options.MaxAge = age
+
+	for _, codec := range s.Codecs {
+		if sc, ok := codec.(*securecookie.SecureCookie); ok {
+			sc.MaxAge(age)
+		}
+	}
+}
+
+// Cleanup deletes all expired sessions from the bucket, returning the number removed. Deletes are made in batches of CleanupBatchSize
+// per transaction so a large number of expired sessions does not hold the writer lock for long.
+func (s *Store) Cleanup() (int, error) {
+	now := s.now()
+
+	var expired [][]byte
+	if err := s.b.ForEach(func(k, v []byte) error {
+		var rec record
+		if err := s.b.DecodeValue(v, &rec); err != nil {
+			return err
+		}
+
+		if now.After(rec.Expires) {
+			expired = append(expired, append([]byte{}, k...))
+		}
+
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	batch := s.CleanupBatchSize
+	if batch <= 0 {
+		batch = len(expired)
+	}
+
+	w := s.b.NewWriter()
+	for start := 0; start < len(expired); start += batch {
+		end := start + batch
+		if end > len(expired) {
+			end = len(expired)
+		}
+
+		for _, k := range expired[start:end] {
+			if err := w.Delete(k); err != nil {
+				return start, err
+			}
+		}
+
+		if err := w.Flush(); err != nil {
+			return start, err
+		}
+	}
+
+	return len(expired), nil
+}
+
+// load decodes the stored session values, returning false if the session does not exist or has expired.
+func (s *Store) load(session *sessions.Session) (bool, error) {
+	var rec record
+	if err := s.b.Decode([]byte(session.ID), &rec); err != nil {
+		if errors.Is(err, ubolt.ErrKeyNotFound{}) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	if s.now().After(rec.Expires) {
+		return false, nil
+	}
+
+	if rec.Values != nil {
+		session.Values = rec.Values
+	}
+
+	return true, nil
+}
@@ -0,0 +1,126 @@
+package uboltsessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/andrewheberle/ubolt"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testdb      = "test.db"
+	testbucket  = []byte("sessions")
+	testsession = "session"
+	testkeypair = []byte("0123456789abcdef0123456789abcdef")
+)
+
+// roundTrip saves the session and returns a new request carrying the resulting cookie.
+func roundTrip(t *testing.T, s *Store, r *http.Request, name string, fn func(values map[interface{}]interface{})) *http.Request {
+	t.Helper()
+
+	session, err := s.Get(r, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn(session.Values)
+
+	rec := httptest.NewRecorder()
+	if err := session.Save(r, rec); err != nil {
+		t.Fatal(err)
+	}
+
+	next := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, c := range rec.Result().Cookies() {
+		next.AddCookie(c)
+	}
+
+	return next
+}
+
+func TestStore(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := ubolt.OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	s := NewStore(b, testkeypair)
+
+	// a new session is created and saved
+	r := roundTrip(t, s, httptest.NewRequest(http.MethodGet, "/", nil), testsession, func(values map[interface{}]interface{}) {
+		values["user"] = "alice"
+	})
+
+	session, err := s.Get(r, testsession)
+	assert.Nil(t, err, "Get")
+	assert.False(t, session.IsNew, "Get - existing session")
+	assert.Equal(t, "alice", session.Values["user"], "Get - values")
+	assert.Len(t, b.GetKeys(), 1, "stored sessions")
+
+	// an expired session is treated as new
+	s.now = func() time.Time { return time.Now().Add(time.Duration(s.Options.MaxAge+1) * time.Second) }
+	session, err = s.New(r, testsession)
+	assert.Nil(t, err, "New - expired")
+	assert.True(t, session.IsNew, "New - expired")
+	assert.Empty(t, session.Values, "New - expired")
+
+	// cleanup removes expired sessions only
+	s.now = time.Now
+	_ = roundTrip(t, s, httptest.NewRequest(http.MethodGet, "/", nil), testsession, func(values map[interface{}]interface{}) {
+		values["user"] = "bob"
+	})
+	assert.Len(t, b.GetKeys(), 2, "stored sessions")
+
+	s.CleanupBatchSize = 1
+	s.now = func() time.Time { return time.Now().Add(time.Duration(s.Options.MaxAge+1) * time.Second) }
+	n, err := s.Cleanup()
+	assert.Nil(t, err, "Cleanup")
+	assert.Equal(t, 2, n, "Cleanup - expired")
+	assert.Nil(t, b.GetKeys(), "Cleanup - none remain")
+
+	s.now = time.Now
+	n, err = s.Cleanup()
+	assert.Nil(t, err, "Cleanup - nothing expired")
+	assert.Equal(t, 0, n, "Cleanup - nothing expired")
+}
+
+func TestStoreDelete(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := ubolt.OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	s := NewStore(b, testkeypair)
+
+	r := roundTrip(t, s, httptest.NewRequest(http.MethodGet, "/", nil), testsession, func(values map[interface{}]interface{}) {
+		values["user"] = "alice"
+	})
+	assert.Len(t, b.GetKeys(), 1, "stored sessions")
+
+	// a negative MaxAge removes the stored record as well as expiring the cookie
+	session, err := s.Get(r, testsession)
+	if err != nil {
+		panic(err)
+	}
+	session.Options.MaxAge = -1
+
+	rec := httptest.NewRecorder()
+	assert.Nil(t, session.Save(r, rec), "Save - delete")
+	assert.Nil(t, b.GetKeys(), "Save - record removed")
+
+	cookies := rec.Result().Cookies()
+	if assert.Len(t, cookies, 1, "Save - cookie") {
+		assert.Less(t, cookies[0].MaxAge, 0, "Save - cookie expired")
+	}
+}
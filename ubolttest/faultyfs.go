@@ -0,0 +1,244 @@
+package ubolttest
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FaultyFS is a filesystem for use with ubolt.WithFS that keeps real files in a private temporary directory and can be programmed to fail,
+// so tests can check how an application behaves when writes start failing or files can not be created.
+//
+// Bolt writes, syncs and truncates the database file using the *os.File returned by OpenFile, so individual writes can not be intercepted.
+// Instead FailWrites swaps the descriptor of every open file for a read-only descriptor of the same file, so every write made until Disarm
+// is called fails, including those made part way through committing a transaction. This is only supported on Linux. Failures of OpenFile
+// and Rename may be scheduled on every platform using FailOpenFile and FailRename.
+//
+// Names are resolved within the directory, so any name may be used and no files are left behind once Close is called.
+type FaultyFS struct {
+	dir string
+	err error
+
+	mu sync.Mutex
+	// files holds every file opened, so descriptors may be swapped when writes are failed
+	files []*faultyFile
+	// failWrites is true while writes are failing
+	failWrites bool
+
+	openFault   fault
+	renameFault fault
+}
+
+// fault is an error returned by the nth later call of an operation.
+type fault struct {
+	n   int
+	err error
+}
+
+// next counts a call of the operation, returning the error if the call should fail.
+func (f *fault) next() error {
+	if f.err == nil {
+		return nil
+	}
+
+	if f.n--; f.n > 0 {
+		return nil
+	}
+
+	err := f.err
+	*f = fault{}
+
+	return err
+}
+
+// NewFaultyFS returns a FaultyFS with no faults armed.
+func NewFaultyFS() *FaultyFS {
+	dir, err := os.MkdirTemp("", "ubolttest-")
+
+	return &FaultyFS{dir: dir, err: err}
+}
+
+// OpenFile opens the named file as per os.OpenFile. If writes are failing the descriptor of the file is swapped before it is returned.
+func (f *FaultyFS) OpenFile(name string, flag int, perm os.FileMode) (*os.File, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: f.err}
+	}
+
+	if err := f.openFault.next(); err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	f.prune()
+
+	path := f.path(name)
+
+	if flag&os.O_CREATE != 0 {
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := os.OpenFile(path, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+
+	ff := &faultyFile{File: file, saved: -1}
+
+	if f.failWrites {
+		if err := ff.failWrites(); err != nil {
+			_ = file.Close()
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+	}
+
+	f.files = append(f.files, ff)
+
+	return file, nil
+}
+
+// Stat returns the FileInfo of the named file as per os.Stat.
+func (f *FaultyFS) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(f.path(name))
+}
+
+// Rename replaces newpath with oldpath as per os.Rename.
+func (f *FaultyFS) Rename(oldpath, newpath string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.renameFault.next(); err != nil {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: err}
+	}
+
+	return os.Rename(f.path(oldpath), f.path(newpath))
+}
+
+// Remove removes the named file as per os.Remove.
+func (f *FaultyFS) Remove(name string) error {
+	return os.Remove(f.path(name))
+}
+
+// FailWrites makes every write to the files opened using the FaultyFS fail, both those already open and those opened later, until Disarm
+// is called. Writes fail with a "bad file descriptor" error, while reads and syncs continue to succeed. On platforms other than Linux
+// errors.ErrUnsupported is returned.
+func (f *FaultyFS) FailWrites() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := writeFaultsSupported(); err != nil {
+		return err
+	}
+
+	if f.failWrites {
+		return nil
+	}
+
+	f.prune()
+
+	for _, ff := range f.files {
+		if err := ff.failWrites(); err != nil {
+			return err
+		}
+	}
+
+	f.failWrites = true
+
+	return nil
+}
+
+// FailOpenFile makes the nth later call of OpenFile fail with err, such as syscall.ENOSPC, wrapped in an *fs.PathError. A value of n less
+// than one fails the next call. Only the one call fails, and any earlier FailOpenFile that has not yet failed a call is replaced.
+func (f *FaultyFS) FailOpenFile(n int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.openFault = fault{n: n, err: err}
+}
+
+// FailRename makes the nth later call of Rename fail with err wrapped in an *os.LinkError, leaving both files untouched. A value of n less
+// than one fails the next call. Only the one call fails, and any earlier FailRename that has not yet failed a call is replaced.
+func (f *FaultyFS) FailRename(n int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.renameFault = fault{n: n, err: err}
+}
+
+// Disarm removes every fault, so writes to open files succeed once more. Files may be closed and opened again while writes are failing,
+// however Disarm, FailWrites and OpenFile must not be called while a file opened using the FaultyFS is being closed.
+func (f *FaultyFS) Disarm() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.openFault, f.renameFault = fault{}, fault{}
+
+	f.prune()
+
+	for _, ff := range f.files {
+		if err := ff.restore(); err != nil {
+			return err
+		}
+	}
+
+	f.failWrites = false
+
+	return nil
+}
+
+// Close disarms every fault and removes every file. Any database using the FaultyFS should be closed first.
+func (f *FaultyFS) Close() error {
+	if err := f.Disarm(); err != nil {
+		return err
+	}
+
+	if f.err != nil {
+		return nil
+	}
+
+	return os.RemoveAll(f.dir)
+}
+
+// path returns the location of the named file within the directory. Names already within the directory, such as the name of an open file,
+// are returned unchanged.
+func (f *FaultyFS) path(name string) string {
+	if rel, err := filepath.Rel(f.dir, name); err == nil && filepath.IsAbs(name) && filepath.IsLocal(rel) {
+		return name
+	}
+
+	return filepath.Join(f.dir, filepath.Clean(string(filepath.Separator)+name))
+}
+
+// prune forgets files that have been closed, releasing the duplicate of the original descriptor of those closed while writes were failing
+// so any lock held on the file is released.
+func (f *FaultyFS) prune() {
+	files := f.files[:0]
+
+	for _, ff := range f.files {
+		if !ff.closed() {
+			files = append(files, ff)
+			continue
+		}
+
+		_ = ff.restore()
+	}
+
+	clear(f.files[len(files):])
+	f.files = files
+}
+
+// faultyFile is a file opened by a FaultyFS.
+type faultyFile struct {
+	*os.File
+	// saved is a duplicate of the original descriptor while writes are failing, otherwise -1
+	saved int
+}
+
+// closed returns true once the file has been closed.
+func (ff *faultyFile) closed() bool {
+	return ff.Fd() == ^uintptr(0)
+}
@@ -0,0 +1,59 @@
+package ubolttest
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+func writeFaultsSupported() error {
+	return nil
+}
+
+// failWrites keeps a duplicate of the descriptor of the file then replaces it with a read-only descriptor of the same file. As the
+// duplicate shares the original open file description any lock held on the file is kept.
+func (ff *faultyFile) failWrites() error {
+	if ff.saved >= 0 || ff.closed() {
+		return nil
+	}
+
+	fd := int(ff.Fd())
+
+	saved, err := unix.FcntlInt(uintptr(fd), unix.F_DUPFD_CLOEXEC, 0)
+	if err != nil {
+		return err
+	}
+
+	ro, err := unix.Open(fmt.Sprintf("/proc/self/fd/%d", fd), unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		_ = unix.Close(saved)
+		return err
+	}
+	defer unix.Close(ro)
+
+	if err := unix.Dup3(ro, fd, unix.O_CLOEXEC); err != nil {
+		_ = unix.Close(saved)
+		return err
+	}
+
+	ff.saved = saved
+
+	return nil
+}
+
+// restore puts back the original descriptor of the file, unless the file was closed while writes were failing.
+func (ff *faultyFile) restore() error {
+	if ff.saved < 0 {
+		return nil
+	}
+
+	saved := ff.saved
+	ff.saved = -1
+	defer unix.Close(saved)
+
+	if ff.closed() {
+		return nil
+	}
+
+	return unix.Dup3(saved, int(ff.Fd()), unix.O_CLOEXEC)
+}
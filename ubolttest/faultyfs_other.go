@@ -0,0 +1,19 @@
+//go:build !linux
+
+package ubolttest
+
+import (
+	"errors"
+)
+
+func writeFaultsSupported() error {
+	return errors.ErrUnsupported
+}
+
+func (ff *faultyFile) failWrites() error {
+	return errors.ErrUnsupported
+}
+
+func (ff *faultyFile) restore() error {
+	return nil
+}
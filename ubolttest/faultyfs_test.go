@@ -0,0 +1,103 @@
+package ubolttest_test
+
+import (
+	"fmt"
+	"runtime"
+	"syscall"
+	"testing"
+
+	"github.com/andrewheberle/ubolt"
+	"github.com/andrewheberle/ubolt/ubolttest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFaultyFSFailWrites(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("write faults are only supported on Linux")
+	}
+
+	fsys := ubolttest.NewFaultyFS()
+	defer fsys.Close()
+
+	bucket := []byte("bucket")
+
+	b, err := ubolt.OpenBucket("faulty.db", bucket, ubolt.WithFS(fsys))
+	if err != nil {
+		panic(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		assert.Nil(t, b.Put([]byte(fmt.Sprintf("key%03d", i)), []byte("value")), "Put")
+	}
+
+	// a put failing part way through its commit is rolled back
+	assert.Nil(t, fsys.FailWrites(), "FailWrites")
+	assert.NotNil(t, b.Put([]byte("failed"), make([]byte, 1<<20)), "Put - writes failing")
+	assert.NotNil(t, b.Delete([]byte("key000")), "Delete - writes failing")
+
+	assert.Equal(t, []byte("value"), b.Get([]byte("key000")), "Get - writes failing")
+	assert.False(t, b.Exists([]byte("failed")), "Exists - writes failing")
+
+	// writes succeed once disarmed
+	assert.Nil(t, fsys.Disarm(), "Disarm")
+	assert.Nil(t, b.Put([]byte("after"), []byte("value")), "Put - disarmed")
+
+	// writes fail until disarmed even if the database is closed and reopened
+	assert.Nil(t, fsys.FailWrites(), "FailWrites")
+	assert.Nil(t, b.Close(), "Close - writes failing")
+
+	b, err = ubolt.OpenBucket("faulty.db", bucket, ubolt.WithFS(fsys), ubolt.WithReadOnly())
+	if err != nil {
+		panic(err)
+	}
+
+	assert.Equal(t, []byte("value"), b.Get([]byte("after")), "Get - reopened")
+	assert.Nil(t, b.Close(), "Close")
+	assert.Nil(t, fsys.Disarm(), "Disarm")
+
+	// the database is consistent once reopened
+	b, err = ubolt.OpenBucket("faulty.db", bucket, ubolt.WithFS(fsys), ubolt.WithNoCreate())
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	var keys int
+	assert.Nil(t, b.ForEach(func(k, v []byte) error {
+		keys++
+		return nil
+	}), "ForEach")
+	assert.Equal(t, 101, keys, "ForEach - keys")
+	assert.False(t, b.Exists([]byte("failed")), "Exists - reopened")
+	assert.Nil(t, b.Put([]byte("failed"), []byte("value")), "Put - reopened")
+}
+
+func TestFaultyFSFailOpenFile(t *testing.T) {
+	fsys := ubolttest.NewFaultyFS()
+	defer fsys.Close()
+
+	fsys.FailOpenFile(1, syscall.ENOSPC)
+	_, err := ubolt.Open("faulty.db", ubolt.WithFS(fsys))
+	assert.ErrorIs(t, err, syscall.ENOSPC, "Open - open fails")
+
+	b, err := ubolt.OpenBucket("faulty.db", []byte("bucket"), ubolt.WithFS(fsys))
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.Nil(t, b.Put([]byte("key"), []byte("value")), "Put")
+	assert.Nil(t, b.Delete([]byte("key")), "Delete")
+
+	// compaction is abandoned when its file can not be created or renamed
+	fsys.FailOpenFile(1, syscall.ENOSPC)
+	_, _, err = b.CompactInPlace()
+	assert.ErrorIs(t, err, syscall.ENOSPC, "CompactInPlace - open fails")
+
+	fsys.FailRename(1, syscall.EIO)
+	_, _, err = b.CompactInPlace()
+	assert.ErrorIs(t, err, syscall.EIO, "CompactInPlace - rename fails")
+
+	assert.Nil(t, b.Put([]byte("key"), []byte("value")), "Put - after compaction failed")
+	assert.Equal(t, []byte("value"), b.Get([]byte("key")), "Get - after compaction failed")
+}
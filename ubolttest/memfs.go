@@ -0,0 +1,191 @@
+// Package ubolttest provides helpers for testing code that uses ubolt.
+//
+// The package does not import ubolt so it may be used by the tests of ubolt itself. MemFS and FaultyFS satisfy ubolt.FS as the interface is
+// structural.
+package ubolttest
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// MemFS is a filesystem that keeps files in memory for use with ubolt.WithFS, so tests do not touch the local filesystem and never leave
+// files behind.
+//
+// As bolt requires a real file descriptor to memory map and lock the database file, on Linux each file is an anonymous memory-backed file
+// created using memfd_create. On other platforms files are kept in a private temporary directory that is removed by Close.
+type MemFS struct {
+	mu    sync.Mutex
+	files map[string]*os.File
+	// aliases maps the names of handles that differ from the name they were opened with back to that name
+	aliases map[string]string
+	store   *store
+}
+
+// NewMemFS returns an empty MemFS.
+func NewMemFS() *MemFS {
+	return &MemFS{files: make(map[string]*os.File), aliases: make(map[string]string), store: newStore()}
+}
+
+// OpenFile opens the named file as per os.OpenFile. Every call returns a new handle to the same underlying file, so locks taken by bolt
+// behave as they would for the local filesystem.
+func (m *MemFS) OpenFile(name string, flag int, perm os.FileMode) (*os.File, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = m.resolve(name)
+
+	f, ok := m.files[name]
+	if ok && flag&os.O_CREATE != 0 && flag&os.O_EXCL != 0 {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+	}
+
+	if !ok {
+		if flag&os.O_CREATE == 0 {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+
+		var err error
+		if f, err = m.store.create(name); err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+
+		m.files[name] = f
+	}
+
+	nf, err := m.store.reopen(f, name, flag&^(os.O_CREATE|os.O_EXCL|os.O_TRUNC))
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	if alias := filepath.Clean(nf.Name()); alias != name {
+		m.aliases[alias] = name
+	}
+
+	if flag&os.O_TRUNC != 0 {
+		if err := nf.Truncate(0); err != nil {
+			_ = nf.Close()
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+	}
+
+	return nf, nil
+}
+
+// Stat returns the FileInfo of the named file as per os.Stat.
+func (m *MemFS) Stat(name string) (os.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = m.resolve(name)
+
+	f, ok := m.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+
+	return fileInfo{FileInfo: info, name: filepath.Base(name)}, nil
+}
+
+// Rename replaces newpath with oldpath as per os.Rename. Handles already open on either file remain usable.
+func (m *MemFS) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	oldpath, newpath = m.resolve(oldpath), m.resolve(newpath)
+
+	f, ok := m.files[oldpath]
+	if !ok {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrNotExist}
+	}
+
+	if oldpath == newpath {
+		return nil
+	}
+
+	if existing, ok := m.files[newpath]; ok {
+		m.store.release(existing)
+		m.forget(newpath)
+	}
+
+	m.files[newpath] = f
+	delete(m.files, oldpath)
+
+	for alias, name := range m.aliases {
+		if name == oldpath {
+			m.aliases[alias] = newpath
+		}
+	}
+
+	return nil
+}
+
+// Remove removes the named file as per os.Remove. Handles already open on the file remain usable until they are closed.
+func (m *MemFS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = m.resolve(name)
+
+	f, ok := m.files[name]
+	if !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+
+	m.store.release(f)
+	delete(m.files, name)
+	m.forget(name)
+
+	return nil
+}
+
+// Close removes every file. Any database using the MemFS should be closed first.
+func (m *MemFS) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for name, f := range m.files {
+		m.store.release(f)
+		delete(m.files, name)
+		m.forget(name)
+	}
+
+	return m.store.close()
+}
+
+// resolve returns the cleaned name of the file, translating the name of a handle back to the name it was opened with.
+func (m *MemFS) resolve(name string) string {
+	name = filepath.Clean(name)
+
+	if original, ok := m.aliases[name]; ok {
+		return original
+	}
+
+	return name
+}
+
+// forget removes every alias of the named file.
+func (m *MemFS) forget(name string) {
+	for alias, original := range m.aliases {
+		if original == name {
+			delete(m.aliases, alias)
+		}
+	}
+}
+
+// fileInfo reports the name the file was opened with rather than the name of the backing file.
+type fileInfo struct {
+	os.FileInfo
+	name string
+}
+
+func (fi fileInfo) Name() string {
+	return fi.name
+}
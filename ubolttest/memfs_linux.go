@@ -0,0 +1,46 @@
+package ubolttest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// store creates memory-backed files using memfd_create.
+type store struct{}
+
+func newStore() *store {
+	return &store{}
+}
+
+// create returns the handle that keeps a new memory-backed file alive until it is released.
+func (s *store) create(name string) (*os.File, error) {
+	fd, err := unix.MemfdCreate(filepath.Base(name), unix.MFD_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.NewFile(uintptr(fd), name), nil
+}
+
+// reopen returns a new handle with its own open file description, so locks taken on it are independent of other handles. The handle is
+// named as requested as bolt reports the name of the file as the path of the database.
+func (s *store) reopen(f *os.File, name string, flag int) (*os.File, error) {
+	fd, err := unix.Open(fmt.Sprintf("/proc/self/fd/%d", f.Fd()), flag|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.NewFile(uintptr(fd), name), nil
+}
+
+// release drops the handle keeping the file alive, which frees the memory once every other handle is closed.
+func (s *store) release(f *os.File) {
+	_ = f.Close()
+}
+
+func (s *store) close() error {
+	return nil
+}
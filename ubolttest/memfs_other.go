@@ -0,0 +1,47 @@
+//go:build !linux
+
+package ubolttest
+
+import (
+	"os"
+)
+
+// store creates files in a private temporary directory on platforms without memfd_create.
+type store struct {
+	dir string
+	err error
+}
+
+func newStore() *store {
+	dir, err := os.MkdirTemp("", "ubolttest-")
+
+	return &store{dir: dir, err: err}
+}
+
+// create returns the handle that keeps a new file until it is released.
+func (s *store) create(name string) (*os.File, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return os.CreateTemp(s.dir, "file-")
+}
+
+// reopen returns a new handle to the file, which is named after the temporary file rather than as requested.
+func (s *store) reopen(f *os.File, name string, flag int) (*os.File, error) {
+	return os.OpenFile(f.Name(), flag, 0)
+}
+
+// release closes and removes the file.
+func (s *store) release(f *os.File) {
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+}
+
+func (s *store) close() error {
+	if s.err != nil {
+		return nil
+	}
+
+	return os.RemoveAll(s.dir)
+}
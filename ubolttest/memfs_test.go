@@ -0,0 +1,90 @@
+package ubolttest_test
+
+import (
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/andrewheberle/ubolt"
+	"github.com/andrewheberle/ubolt/ubolttest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemFS(t *testing.T) {
+	fsys := ubolttest.NewMemFS()
+	defer fsys.Close()
+
+	_, err := fsys.OpenFile("a", os.O_RDONLY, 0)
+	assert.ErrorIs(t, err, fs.ErrNotExist, "OpenFile - missing")
+
+	f, err := fsys.OpenFile("a", os.O_RDWR|os.O_CREATE, 0600)
+	assert.Nil(t, err, "OpenFile - create")
+
+	_, err = f.Write([]byte("hello"))
+	assert.Nil(t, err, "Write")
+	assert.Nil(t, f.Close(), "Close")
+
+	_, err = fsys.OpenFile("a", os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+	assert.ErrorIs(t, err, fs.ErrExist, "OpenFile - exclusive")
+
+	info, err := fsys.Stat("./a")
+	assert.Nil(t, err, "Stat")
+	assert.Equal(t, "a", info.Name(), "Stat - name")
+	assert.Equal(t, int64(5), info.Size(), "Stat - size")
+
+	assert.Nil(t, fsys.Rename("a", "b"), "Rename")
+
+	_, err = fsys.Stat("a")
+	assert.ErrorIs(t, err, fs.ErrNotExist, "Stat - renamed")
+
+	f, err = fsys.OpenFile("b", os.O_RDWR|os.O_TRUNC, 0)
+	assert.Nil(t, err, "OpenFile - truncate")
+	assert.Nil(t, f.Close(), "Close")
+
+	info, err = fsys.Stat("b")
+	assert.Nil(t, err, "Stat")
+	assert.Equal(t, int64(0), info.Size(), "Stat - truncated")
+
+	assert.Nil(t, fsys.Remove("b"), "Remove")
+	assert.ErrorIs(t, fsys.Remove("b"), fs.ErrNotExist, "Remove - missing")
+}
+
+func TestMemFSDatabase(t *testing.T) {
+	fsys := ubolttest.NewMemFS()
+	defer fsys.Close()
+
+	_, err := ubolt.Open("mem.db", ubolt.WithFS(fsys), ubolt.WithNoCreate())
+	assert.ErrorIs(t, err, ubolt.ErrDatabaseNotFound{}, "Open - not created")
+
+	b, err := ubolt.OpenBucket("mem.db", []byte("bucket"), ubolt.WithFS(fsys))
+	if err != nil {
+		panic(err)
+	}
+
+	assert.Equal(t, "mem.db", b.Path(), "Path")
+	assert.Nil(t, b.Put([]byte("key"), []byte("value")), "Put")
+	assert.Nil(t, b.Preallocate(1<<20), "Preallocate")
+
+	size, err := b.Size()
+	assert.Nil(t, err, "Size")
+	assert.GreaterOrEqual(t, size, int64(1<<20), "Size - preallocated")
+
+	before, after, err := b.CompactInPlace()
+	assert.Nil(t, err, "CompactInPlace")
+	assert.Less(t, after, before, "CompactInPlace - smaller")
+	assert.Equal(t, []byte("value"), b.Get([]byte("key")), "Get - after compaction")
+
+	assert.Nil(t, b.Close(), "Close")
+
+	// the database only exists in memory
+	_, err = os.Stat("mem.db")
+	assert.ErrorIs(t, err, fs.ErrNotExist, "Stat - local filesystem")
+
+	b, err = ubolt.OpenBucket("mem.db", []byte("bucket"), ubolt.WithFS(fsys), ubolt.WithNoCreate())
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	assert.Equal(t, []byte("value"), b.Get([]byte("key")), "Get - after reopen")
+}
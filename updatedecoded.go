@@ -0,0 +1,96 @@
+package ubolt
+
+import (
+	"errors"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrDeleteKey may be returned by the function passed to UpdateDecoded to delete the key rather than writing a new value.
+type ErrDeleteKey struct{}
+
+// Error returns the formatted configuration error.
+func (dk ErrDeleteKey) Error() string {
+	return "Delete key"
+}
+
+// Is allows testing using errors.Is
+func (dk ErrDeleteKey) Is(target error) bool {
+	_, is := target.(ErrDeleteKey)
+
+	return is
+}
+
+// UpdateDecoded performs a read-modify-write of an encoded value within a single read/write transaction. The current value of the key is
+// decoded as a T using the configured Codec and passed to fn, or the zero value with exists set to false if the key does not exist or has
+// expired. The value returned by fn is then encoded and written to the key, or the key is deleted if fn returns ErrDeleteKey.
+//
+// Any other error returned by fn, or a failure to decode or encode the value, rolls back the transaction leaving the key untouched. The
+// error from fn is returned unchanged while decode failures are returned as ErrDecode and encode failures as ErrNotEncodable. As fn is
+// called while the write transaction is held it must not use the Database.
+func UpdateDecoded[T any](db *Database, bucket, key []byte, fn func(v T, exists bool) (T, error)) error {
+	ck := db.canonicalKey(key)
+
+	return db.update(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: bucket}
+		}
+
+		var value T
+
+		data := b.Get(ck)
+		exists := data != nil && !db.ttlExpired(tx, bucket, ck)
+		if exists {
+			data, err := db.unwrapValue(bucket, ck, data)
+			if err != nil {
+				return ErrDecode{bucket: bucket, key: key, err: err}
+			}
+
+			if db.decodeLimit > 0 && int64(len(data)) > db.decodeLimit {
+				return ErrValueTooLarge{bucket: bucket, key: key, size: int64(len(data)), limit: db.decodeLimit}
+			}
+
+			if err := db.safeUnmarshal(data, &value); err != nil {
+				return ErrDecode{bucket: bucket, key: key, err: err}
+			}
+		}
+
+		value, err := fn(value, exists)
+		if errors.Is(err, ErrDeleteKey{}) {
+			// an expired key is removed as well
+			if data == nil {
+				return nil
+			}
+
+			if err := b.Delete(ck); err != nil {
+				return err
+			}
+
+			return db.onMutation(tx, mutation{op: OpDelete, bucket: bucket, key: ck, prev: data})
+		}
+		if err != nil {
+			return err
+		}
+
+		encoded, err := db.encode(bucket, key, value)
+		if err != nil {
+			return err
+		}
+
+		if encoded, err = db.wrapValue(bucket, ck, encoded); err != nil {
+			return err
+		}
+
+		if err := b.Put(ck, encoded); err != nil {
+			return err
+		}
+
+		return db.onMutation(tx, mutation{op: OpEncode, bucket: bucket, key: ck, value: encoded, prev: data})
+	})
+}
+
+// UpdateDecodedBucket performs the same process as UpdateDecoded for the bucket opened by OpenBucket.
+func UpdateDecodedBucket[T any](b *Bucket, key []byte, fn func(v T, exists bool) (T, error)) error {
+	return UpdateDecoded(b.db, b.bucket, key, fn)
+}
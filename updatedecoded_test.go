@@ -0,0 +1,90 @@
+package ubolt
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateDecoded(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	increment := func(v enctest, exists bool) (enctest, error) {
+		if !exists {
+			v.Name = "counter"
+		}
+		v.Number++
+
+		return v, nil
+	}
+
+	// created when missing then updated
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, UpdateDecodedBucket(b, testkey, increment), "UpdateDecoded")
+	}
+
+	var got enctest
+	assert.Nil(t, b.Decode(testkey, &got), "Decode")
+	assert.Equal(t, enctest{Name: "counter", Number: 3}, got, "UpdateDecoded - value")
+
+	// an error from fn leaves the value untouched
+	errFailed := errors.New("failed")
+	err = UpdateDecodedBucket(b, testkey, func(v enctest, exists bool) (enctest, error) {
+		assert.True(t, exists, "UpdateDecoded - exists")
+		v.Number = 100
+
+		return v, errFailed
+	})
+	assert.ErrorIs(t, err, errFailed, "UpdateDecoded - fn error")
+
+	assert.Nil(t, b.Decode(testkey, &got), "Decode")
+	assert.Equal(t, 3, got.Number, "UpdateDecoded - rolled back")
+
+	// a value that can not be encoded leaves the value untouched
+	err = UpdateDecodedBucket(b, testkey, func(v *enctest, exists bool) (*enctest, error) {
+		return nil, nil
+	})
+	assert.ErrorIs(t, err, ErrNotEncodable{}, "UpdateDecoded - encode error")
+
+	assert.Nil(t, b.Decode(testkey, &got), "Decode")
+	assert.Equal(t, 3, got.Number, "UpdateDecoded - not encoded")
+
+	// a value that can not be decoded is not passed to fn
+	if err := b.Put([]byte("invalid"), []byte("not gob")); err != nil {
+		panic(err)
+	}
+
+	err = UpdateDecodedBucket(b, []byte("invalid"), func(v enctest, exists bool) (enctest, error) {
+		t.Error("UpdateDecoded - fn called for undecodable value")
+
+		return v, nil
+	})
+	assert.ErrorIs(t, err, ErrDecode{}, "UpdateDecoded - decode error")
+	assert.Equal(t, []byte("not gob"), b.Get([]byte("invalid")), "UpdateDecoded - undecodable value untouched")
+
+	// ErrDeleteKey removes the key
+	err = UpdateDecodedBucket(b, testkey, func(v enctest, exists bool) (enctest, error) {
+		return v, ErrDeleteKey{}
+	})
+	assert.Nil(t, err, "UpdateDecoded - delete")
+	assert.False(t, b.Exists(testkey), "UpdateDecoded - deleted")
+
+	err = UpdateDecodedBucket(b, testkey, func(v enctest, exists bool) (enctest, error) {
+		assert.False(t, exists, "UpdateDecoded - missing")
+
+		return v, ErrDeleteKey{}
+	})
+	assert.Nil(t, err, "UpdateDecoded - delete missing")
+
+	err = UpdateDecoded(b.db, missing, testkey, increment)
+	assert.ErrorIs(t, err, ErrBucketNotFound{}, "UpdateDecoded - missing bucket")
+}
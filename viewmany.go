@@ -0,0 +1,99 @@
+package ubolt
+
+import (
+	bolt "go.etcd.io/bbolt"
+)
+
+// ViewMany calls fn with a get function that reads keys from any bucket using a single read-only transaction, so every value read by fn is
+// from the same consistent view of the database.
+//
+// The values returned by get are copies that remain valid after ViewMany returns. As with GetE, get returns ErrBucketNotFound or
+// ErrKeyNotFound for each missing bucket or key, which allows fn to decide which values are optional. The error returned by fn is returned
+// by ViewMany.
+func (db *Database) ViewMany(fn func(get func(bucket, key []byte) ([]byte, error)) error) error {
+	return db.ViewManyDecode(func(get func(bucket, key []byte) ([]byte, error), _ func(bucket, key []byte, value interface{}) error) error {
+		return fn(get)
+	})
+}
+
+// ViewMany calls fn with a get function that reads keys from any bucket using a single read-only transaction. This is forwarded to the
+// Database implementation so get is not limited to this bucket.
+func (b *Bucket) ViewMany(fn func(get func(bucket, key []byte) ([]byte, error)) error) error {
+	return b.db.ViewMany(fn)
+}
+
+// ViewManyDecode performs the same process as ViewMany and also provides a decode function, which retrieves a key within the same
+// transaction and decodes it into value using the configured Codec as per Decode.
+func (db *Database) ViewManyDecode(fn func(get func(bucket, key []byte) ([]byte, error), decode func(bucket, key []byte, value interface{}) error) error) error {
+	type ttlKey struct {
+		bucket, key []byte
+	}
+
+	var slide []ttlKey
+
+	err := db.view(func(tx *bolt.Tx) error {
+		// keys are copied as fn may reuse the slices it passes to get
+		get := func(bucket, key []byte) ([]byte, error) {
+			db.counters.gets.Add(1)
+
+			key = db.canonicalKey(key)
+
+			if db.bloomMiss(bucket, key) {
+				return nil, ErrKeyNotFound{bucket: bucket, key: key}
+			}
+
+			b := lookupBucket(tx, bucket)
+			if b == nil {
+				return nil, ErrBucketNotFound{bucket: bucket}
+			}
+
+			data := b.Get(key)
+			if data == nil {
+				return nil, ErrKeyNotFound{bucket: bucket, key: key}
+			}
+
+			hasTTL, expired := db.ttlState(tx, bucket, key)
+			if expired {
+				return nil, ErrKeyNotFound{bucket: bucket, key: key}
+			}
+
+			data, err := db.unwrapValue(bucket, key, data)
+			if err != nil {
+				return nil, err
+			}
+
+			if hasTTL {
+				slide = append(slide, ttlKey{append([]byte{}, bucket...), append([]byte{}, key...)})
+			}
+
+			return append([]byte{}, data...), nil
+		}
+
+		decode := func(bucket, key []byte, value interface{}) error {
+			if err := checkDestination(value); err != nil {
+				return err
+			}
+
+			data, err := get(bucket, key)
+			if err != nil {
+				return err
+			}
+
+			return db.unmarshal(data, value)
+		}
+
+		return fn(get, decode)
+	})
+
+	// sliding expiry requires a write so is done once the read-only transaction has ended
+	for _, k := range slide {
+		db.slideTTL(k.bucket, k.key, true)
+	}
+
+	return err
+}
+
+// ViewManyDecode performs the same process as ViewMany and also provides a decode function that uses the configured Codec.
+func (b *Bucket) ViewManyDecode(fn func(get func(bucket, key []byte) ([]byte, error), decode func(bucket, key []byte, value interface{}) error) error) error {
+	return b.db.ViewManyDecode(fn)
+}
@@ -0,0 +1,60 @@
+package ubolt
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestViewMany(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	db, err := Open(testdb)
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	users, settings := []byte("users"), []byte("settings")
+	assert.Nil(t, db.CreateBucket(users), "CreateBucket")
+	assert.Nil(t, db.CreateBucket(settings), "CreateBucket")
+	assert.Nil(t, db.Put(users, []byte("alice"), []byte("Alice")), "Put")
+	assert.Nil(t, db.Put(settings, []byte("theme"), []byte("dark")), "Put")
+	assert.Nil(t, db.Encode(settings, []byte("limits"), []int{1, 2, 3}), "Encode")
+
+	err = db.ViewMany(func(get func(bucket, key []byte) ([]byte, error)) error {
+		v, err := get(users, []byte("alice"))
+		assert.Nil(t, err, "get")
+		assert.Equal(t, []byte("Alice"), v, "get")
+
+		v, err = get(settings, []byte("theme"))
+		assert.Nil(t, err, "get")
+		assert.Equal(t, []byte("dark"), v, "get")
+
+		_, err = get(settings, []byte("missing"))
+		assert.ErrorIs(t, err, ErrKeyNotFound{}, "get - missing key")
+
+		_, err = get([]byte("flags"), []byte("beta"))
+		assert.ErrorIs(t, err, ErrBucketNotFound{}, "get - missing bucket")
+
+		return nil
+	})
+	assert.Nil(t, err, "ViewMany")
+
+	errTest := errors.New("test")
+	assert.ErrorIs(t, db.ViewMany(func(get func(bucket, key []byte) ([]byte, error)) error {
+		return errTest
+	}), errTest, "ViewMany - error")
+
+	err = db.ViewManyDecode(func(get func(bucket, key []byte) ([]byte, error), decode func(bucket, key []byte, value interface{}) error) error {
+		var limits []int
+		assert.Nil(t, decode(settings, []byte("limits"), &limits), "decode")
+		assert.Equal(t, []int{1, 2, 3}, limits, "decode")
+
+		return nil
+	})
+	assert.Nil(t, err, "ViewManyDecode")
+}
@@ -0,0 +1,156 @@
+package ubolt
+
+import (
+	"errors"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrDatabaseClosed is returned when a Database, or a Writer using it, is used after the Database has been closed, including when it was
+// closed because CompactInPlace could not reopen the database file.
+type ErrDatabaseClosed struct{}
+
+// Error returns the formatted configuration error.
+func (dc ErrDatabaseClosed) Error() string {
+	return "Database is closed"
+}
+
+// Is allows testing using errors.Is
+func (dc ErrDatabaseClosed) Is(target error) bool {
+	_, is := target.(ErrDatabaseClosed)
+
+	return is
+}
+
+// Unwrap returns the error returned by bolt for a closed database, so existing checks for bolt.ErrDatabaseNotOpen continue to match.
+func (dc ErrDatabaseClosed) Unwrap() error {
+	return bolt.ErrDatabaseNotOpen
+}
+
+// WriterOption configures a Writer returned by NewWriter.
+type WriterOption func(*Writer)
+
+// WithAutoFlush makes the Writer Flush automatically once n operations are pending. A value of zero or less disables auto-flush.
+func WithAutoFlush(n int) WriterOption {
+	return func(w *Writer) {
+		w.autoFlush = n
+	}
+}
+
+// Writer accumulates Put and Delete operations for a single bucket in memory and applies them in one read/write transaction when Flush
+// is called. A Writer is not safe for concurrent use.
+type Writer struct {
+	db        *Database
+	bucket    []byte
+	ops       []mutation
+	autoFlush int
+}
+
+// NewWriter returns a Writer that buffers operations for the chosen bucket until Flush is called.
+func (db *Database) NewWriter(bucket []byte, opts ...WriterOption) *Writer {
+	w := &Writer{db: db, bucket: bucket}
+	for _, o := range opts {
+		o(w)
+	}
+
+	return w
+}
+
+// NewWriter returns a Writer that buffers operations for the bucket until Flush is called.
+func (b *Bucket) NewWriter(opts ...WriterOption) *Writer {
+	return b.db.NewWriter(b.bucket, opts...)
+}
+
+// Put queues the key to be set to the provided value. The key and value are copied so may be reused by the caller.
+//
+// An error is only returned if the database is closed, any WithValueMiddleware failed to wrap the value or an automatic Flush failed.
+func (w *Writer) Put(key, value []byte) error {
+	key = w.db.canonicalKey(key)
+
+	value, err := w.db.wrapValue(w.bucket, key, value)
+	if err != nil {
+		return err
+	}
+
+	return w.add(mutation{op: OpPut, bucket: w.bucket, key: append([]byte{}, key...), value: append([]byte{}, value...)})
+}
+
+// Delete queues the removal of the key. The key is copied so may be reused by the caller.
+//
+// An error is only returned if the database is closed or an automatic Flush failed.
+func (w *Writer) Delete(key []byte) error {
+	return w.add(mutation{op: OpDelete, bucket: w.bucket, key: append([]byte{}, w.db.canonicalKey(key)...)})
+}
+
+// Len returns the number of pending operations.
+func (w *Writer) Len() int {
+	return len(w.ops)
+}
+
+// Reset discards all pending operations.
+func (w *Writer) Reset() {
+	w.ops = nil
+}
+
+// Flush applies all pending operations in the order they were queued within a single read/write transaction. Either every operation is
+// committed or, if any fails, none are and the operations remain pending so the Flush may be retried or the Writer Reset.
+func (w *Writer) Flush() error {
+	if w.db.closed.Load() {
+		return ErrDatabaseClosed{}
+	}
+
+	if len(w.ops) == 0 {
+		return nil
+	}
+
+	if err := w.db.update(func(tx *bolt.Tx) error {
+		b := lookupBucket(tx, w.bucket)
+		if b == nil {
+			return ErrBucketNotFound{bucket: w.bucket}
+		}
+
+		for _, m := range w.ops {
+			m.prev = previous(tx, w.bucket, b, m.key)
+
+			var err error
+			if m.op == OpDelete {
+				err = b.Delete(m.key)
+			} else {
+				err = b.Put(m.key, m.value)
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := w.db.onMutation(tx, m); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		if errors.Is(err, bolt.ErrDatabaseNotOpen) {
+			return ErrDatabaseClosed{}
+		}
+
+		return err
+	}
+
+	w.Reset()
+
+	return nil
+}
+
+func (w *Writer) add(m mutation) error {
+	if w.db.closed.Load() {
+		return ErrDatabaseClosed{}
+	}
+
+	w.ops = append(w.ops, m)
+
+	if w.autoFlush > 0 && len(w.ops) >= w.autoFlush {
+		return w.Flush()
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package ubolt
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriter(t *testing.T) {
+	_ = os.Remove(testdb)
+	defer os.Remove(testdb)
+
+	b, err := OpenBucket(testdb, testbucket)
+	if err != nil {
+		panic(err)
+	}
+	defer b.Close()
+
+	w := b.NewWriter()
+
+	key := []byte("key")
+	for i := 0; i < 3; i++ {
+		// reuse the key buffer to ensure it is copied
+		key = append(key[:3], fmt.Sprint(i)...)
+		assert.Nil(t, w.Put(key, testvalue), "Put")
+	}
+	assert.Nil(t, w.Delete([]byte("key1")), "Delete")
+	assert.Equal(t, 4, w.Len(), "Len")
+
+	// nothing is written before Flush
+	assert.Nil(t, b.GetKeys(), "GetKeys - before Flush")
+
+	assert.Nil(t, w.Flush(), "Flush")
+	assert.Equal(t, 0, w.Len(), "Len - after Flush")
+	assert.Equal(t, []string{"key0", "key2"}, b.GetKeysString(), "GetKeysString - after Flush")
+
+	// a failing operation leaves nothing applied and the operations pending
+	assert.Nil(t, w.Put([]byte("key3"), testvalue), "Put")
+	assert.Nil(t, w.Put(nil, testvalue), "Put - nil key")
+	assert.NotNil(t, w.Flush(), "Flush - invalid key")
+	assert.Equal(t, 2, w.Len(), "Len - after failed Flush")
+	assert.Nil(t, b.Get([]byte("key3")), "Get - after failed Flush")
+	assert.Equal(t, []string{"key0", "key2"}, b.GetKeysString(), "GetKeysString - after failed Flush")
+
+	w.Reset()
+	assert.Equal(t, 0, w.Len(), "Len - after Reset")
+
+	// auto-flush
+	w = b.NewWriter(WithAutoFlush(2))
+	assert.Nil(t, w.Put([]byte("key4"), testvalue), "Put")
+	assert.Equal(t, 1, w.Len(), "Len - below threshold")
+	assert.Nil(t, w.Put([]byte("key5"), testvalue), "Put")
+	assert.Equal(t, 0, w.Len(), "Len - auto-flushed")
+	assert.Equal(t, testvalue, b.Get([]byte("key5")), "Get - auto-flushed")
+
+	// use after close
+	assert.Nil(t, w.Put([]byte("key6"), testvalue), "Put")
+	if err := b.Close(); err != nil {
+		panic(err)
+	}
+	assert.ErrorIs(t, w.Flush(), ErrDatabaseClosed{}, "Flush - closed")
+	assert.ErrorIs(t, w.Put(testkey, testvalue), ErrDatabaseClosed{}, "Put - closed")
+}